@@ -0,0 +1,96 @@
+//Package api is the typed Go entry of litewallet for the Cosmos, QOS and ETH wallets.
+//Every operation takes a context.Context first and returns its result with an error,
+//the string functions of the mobile and javasdk packages are JSON adapters on top of it.
+package api
+
+import (
+	"context"
+
+	"github.com/QOSGroup/litewallet/litewallet/errcode"
+	"github.com/QOSGroup/litewallet/litewallet/sdksource"
+	"github.com/QOSGroup/litewallet/litewallet/slim/tendermint/crypto/funcInlocal/bip39local"
+)
+
+//call runs fn and waits for it until ctx is done. The Cosmos and QOS clients take no context,
+//so fn keeps running in the background after a cancellation and a broadcast may still reach the node.
+func call(ctx context.Context, fn func() error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- fn()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+//CreateSeed returns a new 12-word bip39 mnemonic
+func CreateSeed(ctx context.Context) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return sdksource.GenerateSeed()
+}
+
+//CreateSeedIn returns a new 12-word bip39 mnemonic in language, one of MnemonicLanguages
+func CreateSeedIn(ctx context.Context, language string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return sdksource.GenerateSeedIn(language)
+}
+
+//CreateMnemonic returns a new bip39 mnemonic of 12, 15, 18, 21 or 24 words in language with the entropy of
+//source, the system entropy when it is nil
+func CreateMnemonic(ctx context.Context, words int, language string, source bip39local.EntropySource) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return sdksource.GenerateMnemonic(words, language, source)
+}
+
+//MnemonicLanguages returns the languages of the bip39 wordlists, the mnemonics of all of them are recovered
+func MnemonicLanguages() []string {
+	return bip39local.Languages()
+}
+
+//RegisterMnemonicLanguage adds the 2048 words of a bip39 wordlist missing from litewallet, such as the Portuguese one
+func RegisterMnemonicLanguage(language string, words []string) error {
+	if err := bip39local.RegisterLanguage(language, words); err != nil {
+		return errcode.InvalidInput(err)
+	}
+	return nil
+}
+
+//MnemonicComplete returns at most limit words of the wordlist of language starting with prefix, English when
+//language is empty
+func MnemonicComplete(prefix, language string, limit int) ([]string, error) {
+	if language == "" {
+		language = bip39local.English
+	}
+	l, err := bip39local.GetLanguage(language)
+	if err != nil {
+		return nil, errcode.InvalidInput(err)
+	}
+	return l.Complete(prefix, limit), nil
+}
+
+//MnemonicDiagnose tells the unknown words of mnemonic from a wrong checksum and suggests the replacements of
+//a single wrong word, the language is detected when it is empty
+func MnemonicDiagnose(mnemonic, language string) (*bip39local.Diagnosis, error) {
+	d, err := bip39local.Diagnose(mnemonic, language)
+	if err != nil {
+		return nil, errcode.InvalidInput(err)
+	}
+	return d, nil
+}
+
+//WalletAddressCheck returns the chain of addr: ETH, COSMOS, QOS or None
+func WalletAddressCheck(addr string) string {
+	return sdksource.WalletAddressCheck(addr)
+}
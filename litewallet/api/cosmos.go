@@ -0,0 +1,311 @@
+package api
+
+import (
+	"context"
+
+	"github.com/QOSGroup/litewallet/litewallet/sdksource"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/auth"
+	"github.com/cosmos/cosmos-sdk/x/staking"
+)
+
+//CosmosCreateAccount stores a new key under name, a new mnemonic is generated if seed is empty
+func CosmosCreateAccount(ctx context.Context, rootDir, name, password, seed string) (*sdksource.KeyOutput, error) {
+	var out *sdksource.KeyOutput
+	err := call(ctx, func() (err error) {
+		out, err = sdksource.CreateKey(rootDir, name, password, seed)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+//CosmosRecoverKey stores the key of seed under name
+func CosmosRecoverKey(ctx context.Context, rootDir, name, password, seed string) (*sdksource.KeyOutput, error) {
+	var out *sdksource.KeyOutput
+	err := call(ctx, func() (err error) {
+		out, err = sdksource.RestoreKey(rootDir, name, password, seed)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+//CosmosUpdateKey changes the password of the key name
+func CosmosUpdateKey(ctx context.Context, rootDir, name, oldpass, newpass string) error {
+	return call(ctx, func() error {
+		return sdksource.ChangePassword(rootDir, name, oldpass, newpass)
+	})
+}
+
+//CosmosListKeys returns the keys stored under rootDir
+func CosmosListKeys(ctx context.Context, rootDir string) ([]sdksource.KeyOutput, error) {
+	var out []sdksource.KeyOutput
+	err := call(ctx, func() (err error) {
+		out, err = sdksource.ListKeys(rootDir)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+//CosmosDeleteKey removes the key name
+func CosmosDeleteKey(ctx context.Context, rootDir, name, password string) error {
+	return call(ctx, func() error {
+		return sdksource.DeleteKey(rootDir, name, password)
+	})
+}
+
+//CosmosExportKey returns the private key of name armored and encrypted with exportPass
+func CosmosExportKey(ctx context.Context, rootDir, name, password, exportPass string) (string, error) {
+	var out string
+	err := call(ctx, func() (err error) {
+		out, err = sdksource.ExportKey(rootDir, name, password, exportPass)
+		return
+	})
+	if err != nil {
+		return "", err
+	}
+	return out, nil
+}
+
+//CosmosImportKey stores the armored private key of CosmosExportKey under name
+func CosmosImportKey(ctx context.Context, rootDir, name, password, armor, armorPass string) (*sdksource.KeyOutput, error) {
+	var out *sdksource.KeyOutput
+	err := call(ctx, func() (err error) {
+		out, err = sdksource.ImportKey(rootDir, name, password, armor, armorPass)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+//CosmosGetAccount returns the account of addr
+func CosmosGetAccount(ctx context.Context, rootDir, node, chainID, addr string) (auth.Account, error) {
+	var out auth.Account
+	err := call(ctx, func() (err error) {
+		out, err = sdksource.QueryAccount(rootDir, node, chainID, addr)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+//CosmosTransfer sends coinStr from the key fromName to toStr
+func CosmosTransfer(ctx context.Context, rootDir, node, chainID, fromName, password, toStr, coinStr, feeStr, broadcastMode string) (sdk.TxResponse, error) {
+	return txCall(ctx, func() (sdk.TxResponse, error) {
+		return sdksource.SendCoins(rootDir, node, chainID, fromName, password, toStr, coinStr, feeStr, broadcastMode)
+	})
+}
+
+//CosmosDelegate delegates delegationCoinStr of the key delegatorName to validatorAddr
+func CosmosDelegate(ctx context.Context, rootDir, node, chainID, delegatorName, password, delegatorAddr, validatorAddr, delegationCoinStr, feeStr, broadcastMode string) (sdk.TxResponse, error) {
+	return txCall(ctx, func() (sdk.TxResponse, error) {
+		return sdksource.DelegateCoins(rootDir, node, chainID, delegatorName, password, delegatorAddr, validatorAddr, delegationCoinStr, feeStr, broadcastMode)
+	})
+}
+
+//CosmosGetDelegationShares returns the delegation of delegatorAddr to validatorAddr
+func CosmosGetDelegationShares(ctx context.Context, rootDir, node, chainID, delegatorAddr, validatorAddr string) (staking.Delegation, error) {
+	var out staking.Delegation
+	err := call(ctx, func() (err error) {
+		out, err = sdksource.QueryDelegation(rootDir, node, chainID, delegatorAddr, validatorAddr)
+		return
+	})
+	if err != nil {
+		return staking.Delegation{}, err
+	}
+	return out, nil
+}
+
+//CosmosUnbondingDelegation unbonds Ubdshares of the key delegatorName from validatorAddr
+func CosmosUnbondingDelegation(ctx context.Context, rootDir, node, chainID, delegatorName, password, delegatorAddr, validatorAddr, Ubdshares, feeStr, broadcastMode string) (sdk.TxResponse, error) {
+	return txCall(ctx, func() (sdk.TxResponse, error) {
+		return sdksource.UndelegateCoins(rootDir, node, chainID, delegatorName, password, delegatorAddr, validatorAddr, Ubdshares, feeStr, broadcastMode)
+	})
+}
+
+//CosmosGetAllUnbondingDelegations returns all unbonding delegations of delegatorAddr
+func CosmosGetAllUnbondingDelegations(ctx context.Context, rootDir, node, chainID, delegatorAddr string) (staking.UnbondingDelegations, error) {
+	var out staking.UnbondingDelegations
+	err := call(ctx, func() (err error) {
+		out, err = sdksource.QueryUnbondingDelegations(rootDir, node, chainID, delegatorAddr)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+//CosmosGetBondValidators returns the validators delegatorAddr delegated to, empty if there is none
+func CosmosGetBondValidators(ctx context.Context, rootDir, node, chainID, delegatorAddr string) ([]sdksource.ValidPlus, error) {
+	var out []sdksource.ValidPlus
+	err := call(ctx, func() (err error) {
+		out, err = sdksource.QueryBondedValidators(rootDir, node, chainID, delegatorAddr)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+//CosmosGetAllValidators returns the validators with tendermint power of at least 1
+func CosmosGetAllValidators(ctx context.Context, rootDir, node, chainID string) ([]sdksource.ValidPlus, error) {
+	var out []sdksource.ValidPlus
+	err := call(ctx, func() (err error) {
+		out, err = sdksource.QueryValidators(rootDir, node, chainID)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+//CosmosGetAllDelegations returns all delegations of delegatorAddr
+func CosmosGetAllDelegations(ctx context.Context, rootDir, node, chainID, delegatorAddr string) (staking.Delegations, error) {
+	var out staking.Delegations
+	err := call(ctx, func() (err error) {
+		out, err = sdksource.QueryDelegations(rootDir, node, chainID, delegatorAddr)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+//CosmosWithdrawDelegationReward withdraws the rewards of the key delegatorName from validatorAddr
+func CosmosWithdrawDelegationReward(ctx context.Context, rootDir, node, chainID, delegatorName, password, delegatorAddr, validatorAddr, feeStr, broadcastMode string) (sdk.TxResponse, error) {
+	return txCall(ctx, func() (sdk.TxResponse, error) {
+		return sdksource.WithdrawReward(rootDir, node, chainID, delegatorName, password, delegatorAddr, validatorAddr, feeStr, broadcastMode)
+	})
+}
+
+//CosmosGetDelegationRewards returns the rewards of delegatorAddr from validatorAddr
+func CosmosGetDelegationRewards(ctx context.Context, rootDir, node, chainID, delegatorAddr, validatorAddr string) (sdk.DecCoins, error) {
+	var out sdk.DecCoins
+	err := call(ctx, func() (err error) {
+		out, err = sdksource.QueryDelegationRewards(rootDir, node, chainID, delegatorAddr, validatorAddr)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+//CosmosSetVerified turns the verified mode of the Cosmos queries on or off, see sdksource.SetVerified
+func CosmosSetVerified(on bool) {
+	sdksource.SetVerified(on)
+}
+
+//CosmosQueryTx returns the tx of the hex hash txHash
+func CosmosQueryTx(ctx context.Context, rootDir, node, chainID, txHash string) (sdk.TxResponse, error) {
+	return txCall(ctx, func() (sdk.TxResponse, error) {
+		return sdksource.GetTx(rootDir, node, chainID, txHash)
+	})
+}
+
+//CosmosGetValSelfBondShares returns the self delegation of validatorAddr
+func CosmosGetValSelfBondShares(ctx context.Context, rootDir, node, chainID, validatorAddr string) (staking.Delegation, error) {
+	var out staking.Delegation
+	err := call(ctx, func() (err error) {
+		out, err = sdksource.QueryValidatorSelfDelegation(rootDir, node, chainID, validatorAddr)
+		return
+	})
+	if err != nil {
+		return staking.Delegation{}, err
+	}
+	return out, nil
+}
+
+//CosmosGetDelegtorRewardsShares returns the rewards and shares of delegatorAddr on each of its validators
+func CosmosGetDelegtorRewardsShares(ctx context.Context, rootDir, node, chainID, delegatorAddr string) ([]sdksource.Delrewards, error) {
+	var out []sdksource.Delrewards
+	err := call(ctx, func() (err error) {
+		out, err = sdksource.QueryDelegatorRewards(rootDir, node, chainID, delegatorAddr)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+//CosmosWithdrawDelegatorAllRewards withdraws the rewards of the key delegatorName from all its validators
+func CosmosWithdrawDelegatorAllRewards(ctx context.Context, rootDir, node, chainID, delegatorName, password, delegatorAddr, feeStr, broadcastMode string) (sdk.TxResponse, error) {
+	return txCall(ctx, func() (sdk.TxResponse, error) {
+		return sdksource.WithdrawAllRewards(rootDir, node, chainID, delegatorName, password, delegatorAddr, feeStr, broadcastMode)
+	})
+}
+
+//CosmosQueryTxsWithTags returns a page of the txs addr sent, delegated or received
+func CosmosQueryTxsWithTags(ctx context.Context, rootDir, node, chainID, addr string, page, limit int) ([]sdk.TxResponse, error) {
+	var out []sdk.TxResponse
+	err := call(ctx, func() (err error) {
+		out, err = sdksource.SearchAddressTxs(rootDir, node, chainID, addr, page, limit)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+//CosmosTransferB4send returns the signed transfer tx bytes without broadcasting them
+func CosmosTransferB4send(ctx context.Context, rootDir, node, chainID, fromName, password, toStr, coinStr, feeStr string) ([]byte, error) {
+	var out []byte
+	err := call(ctx, func() (err error) {
+		out, err = sdksource.SignTransfer(rootDir, node, chainID, fromName, password, toStr, coinStr, feeStr)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+//CosmosBroadcastTransferTx broadcasts the signed tx bytes
+func CosmosBroadcastTransferTx(ctx context.Context, rootDir, node, chainID string, txBytes []byte, broadcastMode string) (sdk.TxResponse, error) {
+	return txCall(ctx, func() (sdk.TxResponse, error) {
+		return sdksource.BroadcastTxBytes(rootDir, node, chainID, txBytes, broadcastMode)
+	})
+}
+
+//CosmosLocalGenTx returns the transfer as a signed StdTx
+func CosmosLocalGenTx(ctx context.Context, rootDir, node, chainID, fromName, password, toStr, coinStr, feeStr string) (auth.StdTx, error) {
+	var out auth.StdTx
+	err := call(ctx, func() (err error) {
+		out, err = sdksource.GenSignedTransfer(rootDir, node, chainID, fromName, password, toStr, coinStr, feeStr)
+		return
+	})
+	if err != nil {
+		return auth.StdTx{}, err
+	}
+	return out, nil
+}
+
+func txCall(ctx context.Context, fn func() (sdk.TxResponse, error)) (sdk.TxResponse, error) {
+	var out sdk.TxResponse
+	err := call(ctx, func() (err error) {
+		out, err = fn()
+		return
+	})
+	if err != nil {
+		return sdk.TxResponse{}, err
+	}
+	return out, nil
+}
@@ -0,0 +1,149 @@
+package api
+
+import (
+	"context"
+
+	"github.com/QOSGroup/litewallet/litewallet/eth"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+//EthCreateAccount stores the key of mnemonic under name, the mnemonic is required
+func EthCreateAccount(ctx context.Context, rootDir, name, password, mnemonic string) (*eth.KeyOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return eth.CreateKey(rootDir, name, password, mnemonic)
+}
+
+//EthRecoverAccount stores the key of mnemonic under name
+func EthRecoverAccount(ctx context.Context, rootDir, name, password, mnemonic string) (*eth.KeyOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return eth.RestoreKey(rootDir, name, password, mnemonic)
+}
+
+//EthCreateAccountWithPath stores the key at hdPath of mnemonic under name, hdPath is absolute or relative to m/44'/60'/0'/0
+func EthCreateAccountWithPath(ctx context.Context, rootDir, name, password, mnemonic, hdPath string) (*eth.KeyOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return eth.CreateKeyWithPath(rootDir, name, password, mnemonic, hdPath)
+}
+
+//EthCreateAccountAt stores the account index of mnemonic under name
+func EthCreateAccountAt(ctx context.Context, rootDir, name, password, mnemonic string, index uint32) (*eth.KeyOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return eth.CreateKeyAt(rootDir, name, password, mnemonic, index)
+}
+
+//EthDeriveAccounts returns count accounts of mnemonic from the index start, with their balances when node is not empty
+func EthDeriveAccounts(ctx context.Context, node, mnemonic string, start uint32, count int) ([]eth.DerivedAccount, error) {
+	return eth.DeriveAccounts(ctx, node, mnemonic, start, count)
+}
+
+//EthListAccounts returns the keys stored under rootDir
+func EthListAccounts(ctx context.Context, rootDir string) ([]eth.KeyOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return eth.ListKeys(rootDir)
+}
+
+//EthReplaceAccount stores the key at hdPath of mnemonic under name, replacing the key stored under name if any
+func EthReplaceAccount(ctx context.Context, rootDir, name, password, mnemonic, hdPath string) (*eth.KeyOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return eth.ReplaceKeyWithPath(rootDir, name, password, mnemonic, hdPath)
+}
+
+//EthCheckStore returns the inconsistencies of the store under rootDir and with repair fixes the address records
+func EthCheckStore(ctx context.Context, rootDir string, repair bool) ([]eth.StoreIssue, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return eth.CheckStore(rootDir, repair)
+}
+
+//EthDeleteAccount removes the key name
+func EthDeleteAccount(ctx context.Context, rootDir, name, password string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return eth.DeleteKey(rootDir, name, password)
+}
+
+//EthUpdateKey changes the password of the key name
+func EthUpdateKey(ctx context.Context, rootDir, name, oldpass, newpass string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return eth.ChangePassword(rootDir, name, oldpass, newpass)
+}
+
+//EthImportKeystore stores the key of the keystore v3 json under name, keystorePassword decrypts the json
+func EthImportKeystore(ctx context.Context, rootDir, name, password, keystoreJSON, keystorePassword string) (*eth.KeyOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return eth.ImportKeystore(rootDir, name, password, keystoreJSON, keystorePassword)
+}
+
+//EthImportPrivateKey stores the hex private key under name
+func EthImportPrivateKey(ctx context.Context, rootDir, name, password, privKeyHex string) (*eth.KeyOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return eth.ImportPrivateKey(rootDir, name, password, privKeyHex)
+}
+
+//EthExportKeystore returns the key name as keystore v3 json encrypted with keystorePassword, kdf is scrypt by default or pbkdf2
+func EthExportKeystore(ctx context.Context, rootDir, name, password, keystorePassword, kdf string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return eth.ExportKeystore(rootDir, name, password, keystorePassword, kdf)
+}
+
+//EthGetAccount returns the ETH balance of addr
+func EthGetAccount(ctx context.Context, node, addr string) (*eth.Balance, error) {
+	return eth.GetBalance(ctx, node, addr)
+}
+
+//EthGetErc20Account returns the balance of addr on the ERC20 contract tokenAddr
+func EthGetErc20Account(ctx context.Context, node, addr, tokenAddr string) (*eth.Balance, error) {
+	return eth.GetTokenBalance(ctx, node, addr, tokenAddr)
+}
+
+//EthTransferETH sends amount ETH from the key name to toAddr, gasPrice is in gwei
+func EthTransferETH(ctx context.Context, rootDir, node, name, password, toAddr, gasPrice, amount string, gasLimit int64) (common.Hash, error) {
+	return eth.SendETH(ctx, rootDir, node, name, password, toAddr, gasPrice, amount, gasLimit)
+}
+
+//EthTransferErc20 sends tokenValue of the ERC20 contract tokenAddr from the key name to toAddr
+func EthTransferErc20(ctx context.Context, rootDir, node, name, password, toAddr, tokenAddr, tokenValue, gasPrice string, gasLimit int64) (common.Hash, error) {
+	return eth.SendERC20(ctx, rootDir, node, name, password, toAddr, tokenAddr, tokenValue, gasPrice, gasLimit)
+}
+
+//EthSpeedTransferETH resends an ETH transfer with pendingNonce, to replace a pending tx
+func EthSpeedTransferETH(ctx context.Context, rootDir, node, name, password, toAddr, gasPrice, amount string, gasLimit, pendingNonce int64) (common.Hash, error) {
+	return eth.ResendETH(ctx, rootDir, node, name, password, toAddr, gasPrice, amount, gasLimit, pendingNonce)
+}
+
+//EthSpeedTransferERC20 resends an ERC20 transfer with pendingNonce, to replace a pending tx
+func EthSpeedTransferERC20(ctx context.Context, rootDir, node, name, password, toAddr, tokenAddr, tokenValue, gasPrice string, gasLimit, pendingNonce int64) (common.Hash, error) {
+	return eth.ResendERC20(ctx, rootDir, node, name, password, toAddr, tokenAddr, tokenValue, gasPrice, gasLimit, pendingNonce)
+}
+
+//EthGetNonceAt returns the nonce of the key name at the latest block
+func EthGetNonceAt(ctx context.Context, rootDir, node, name, password string) (uint64, error) {
+	return eth.Nonce(ctx, rootDir, node, name, password)
+}
+
+//EthGetPendingNonceAt returns the nonce of the key name in the pending state
+func EthGetPendingNonceAt(ctx context.Context, rootDir, node, name, password string) (uint64, error) {
+	return eth.PendingNonce(ctx, rootDir, node, name, password)
+}
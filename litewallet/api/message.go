@@ -0,0 +1,144 @@
+package api
+
+import (
+	"context"
+
+	"github.com/QOSGroup/litewallet/litewallet/eth"
+	"github.com/QOSGroup/litewallet/litewallet/sdksource"
+	"github.com/QOSGroup/litewallet/litewallet/signer"
+	"github.com/QOSGroup/litewallet/litewallet/slim"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+//The messages are signed to prove the ownership of an address, such as the login challenge of a service:
+//ETH signs as personal_sign (EIP-191), Cosmos an ADR-036 sign doc and QOS its ed25519 message. None of the
+//signed bytes can be a tx. The Verify functions tell whether a signature is the one of an address. ETH also
+//signs the EIP-712 typed data of the dApps, such as permits and orders.
+
+//EthSignMessage signs msg with the local key name as personal_sign does, V is 27 or 28
+func EthSignMessage(ctx context.Context, rootDir, name, password string, msg []byte) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return eth.SignMessage(rootDir, name, password, msg)
+}
+
+//EthSignMessageWithSigner is EthSignMessage signed by s
+func EthSignMessageWithSigner(ctx context.Context, s signer.Signer, msg []byte) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return eth.SignMessageWithSigner(s, msg)
+}
+
+//EthRecoverMessageSigner returns the address which signed msg as personal_sign does
+func EthRecoverMessageSigner(ctx context.Context, msg, sig []byte) (common.Address, error) {
+	if err := ctx.Err(); err != nil {
+		return common.Address{}, err
+	}
+	return eth.RecoverMessageSigner(msg, sig)
+}
+
+//EthVerifyMessage tells whether sig is the personal_sign signature of msg by addr
+func EthVerifyMessage(ctx context.Context, addr string, msg, sig []byte) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	return eth.VerifyMessage(addr, msg, sig)
+}
+
+//CosmosSignMessage signs msg with the local key name as an ADR-036 doc
+func CosmosSignMessage(ctx context.Context, rootDir, name, password string, msg []byte) (signer.MessageSignature, error) {
+	if err := ctx.Err(); err != nil {
+		return signer.MessageSignature{}, err
+	}
+	return sdksource.SignMessage(rootDir, name, password, msg)
+}
+
+//CosmosSignMessageWithSigner is CosmosSignMessage signed by s
+func CosmosSignMessageWithSigner(ctx context.Context, s signer.Signer, msg []byte) (signer.MessageSignature, error) {
+	if err := ctx.Err(); err != nil {
+		return signer.MessageSignature{}, err
+	}
+	return sdksource.SignMessageWithSigner(s, msg)
+}
+
+//CosmosVerifyMessage tells whether sig is the ADR-036 signature of msg by addr
+func CosmosVerifyMessage(ctx context.Context, addr string, msg []byte, sig signer.MessageSignature) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	return sdksource.VerifyMessage(addr, msg, sig)
+}
+
+//QOSSignMessage signs msg with the base64 private key privkey
+func QOSSignMessage(ctx context.Context, privkey string, msg []byte) (signer.MessageSignature, error) {
+	if err := ctx.Err(); err != nil {
+		return signer.MessageSignature{}, err
+	}
+	return slim.SignMessage(privkey, msg)
+}
+
+//QOSSignMessageByName is QOSSignMessage with the local key name
+func QOSSignMessageByName(ctx context.Context, rootDir, name, password string, msg []byte) (signer.MessageSignature, error) {
+	if err := ctx.Err(); err != nil {
+		return signer.MessageSignature{}, err
+	}
+	return slim.SignMessageByName(rootDir, name, password, msg)
+}
+
+//QOSSignMessageWithSigner is QOSSignMessage signed by s
+func QOSSignMessageWithSigner(ctx context.Context, s signer.Signer, msg []byte) (signer.MessageSignature, error) {
+	if err := ctx.Err(); err != nil {
+		return signer.MessageSignature{}, err
+	}
+	return slim.SignMessageWithSigner(s, msg)
+}
+
+//QOSVerifyMessage tells whether sig is the signature of msg by addr
+func QOSVerifyMessage(ctx context.Context, addr string, msg []byte, sig signer.MessageSignature) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	return slim.VerifyMessage(addr, msg, sig)
+}
+
+//EthSignTypedData signs the EIP-712 typed data with the local key name, V is 27 or 28
+func EthSignTypedData(ctx context.Context, rootDir, name, password string, td *eth.TypedData) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return eth.SignTypedData(rootDir, name, password, td)
+}
+
+//EthSignTypedDataWithSigner is EthSignTypedData signed by s
+func EthSignTypedDataWithSigner(ctx context.Context, s signer.Signer, td *eth.TypedData) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return eth.SignTypedDataWithSigner(s, td)
+}
+
+//EthRecoverTypedDataSigner returns the address which signed the typed data
+func EthRecoverTypedDataSigner(ctx context.Context, td *eth.TypedData, sig []byte) (common.Address, error) {
+	if err := ctx.Err(); err != nil {
+		return common.Address{}, err
+	}
+	return eth.RecoverTypedDataSigner(td, sig)
+}
+
+//EthVerifyTypedData tells whether sig is the signature of the typed data by addr
+func EthVerifyTypedData(ctx context.Context, addr string, td *eth.TypedData, sig []byte) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	return eth.VerifyTypedData(addr, td, sig)
+}
+
+//EthTypedDataSummary renders the typed data for the user to confirm before signing it
+func EthTypedDataSummary(ctx context.Context, td *eth.TypedData) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return td.Summary()
+}
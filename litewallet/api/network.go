@@ -0,0 +1,261 @@
+package api
+
+import (
+	"context"
+
+	"github.com/QOSGroup/litewallet/litewallet/errcode"
+	"github.com/QOSGroup/litewallet/litewallet/eth"
+	"github.com/QOSGroup/litewallet/litewallet/network"
+	"github.com/QOSGroup/litewallet/litewallet/slim"
+	"github.com/QOSGroup/litewallet/litewallet/slim/base/account"
+	"github.com/QOSGroup/litewallet/litewallet/slim/base/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/auth"
+	"github.com/ethereum/go-ethereum/common"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+)
+
+//The functions ending with On take the name of a network profile of the network package
+//instead of the node and chain id. The calls go through the pool of the network endpoints and fail over
+//to the next endpoint on a network error. The bare amounts of coins and fees get the fee denom
+//of the network and the addresses are checked against its prefix. The QOS queries are verified on the
+//chain id of the profile.
+
+//LoadNetworks registers the network profiles of a json or toml file
+func LoadNetworks(path string) error {
+	return network.LoadFile(path)
+}
+
+//Networks returns the registered network profiles
+func Networks() []network.Profile {
+	return network.Profiles()
+}
+
+//getPool returns the endpoint pool of the network profile name, which has to be of family
+func getPool(name string, family network.Family) (*network.Pool, error) {
+	pool, err := network.GetPool(name)
+	if err != nil {
+		return nil, err
+	}
+	if pool.Profile.Family != family {
+		return nil, errcode.Errorf(errcode.CodeInvalidInput, "network %s is %s, not %s", name, pool.Profile.Family, family)
+	}
+	return pool, nil
+}
+
+//CosmosGetAccountOn is CosmosGetAccount on the network profile
+func CosmosGetAccountOn(ctx context.Context, profileName, rootDir, addr string) (auth.Account, error) {
+	pool, err := getPool(profileName, network.FamilyCosmos)
+	if err != nil {
+		return nil, err
+	}
+	p := pool.Profile
+	if err := p.CheckAddress(addr); err != nil {
+		return nil, err
+	}
+	var out auth.Account
+	err = pool.Do(ctx, func(node string) (err error) {
+		out, err = CosmosGetAccount(ctx, rootDir, node, p.ChainID, addr)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+//CosmosTransferOn is CosmosTransfer on the network profile
+func CosmosTransferOn(ctx context.Context, profileName, rootDir, fromName, password, toStr, coinStr, feeStr, broadcastMode string) (sdk.TxResponse, error) {
+	pool, err := getPool(profileName, network.FamilyCosmos)
+	if err != nil {
+		return sdk.TxResponse{}, err
+	}
+	p := pool.Profile
+	if err := p.CheckAddress(toStr); err != nil {
+		return sdk.TxResponse{}, err
+	}
+	var out sdk.TxResponse
+	err = pool.Do(ctx, func(node string) (err error) {
+		out, err = CosmosTransfer(ctx, rootDir, node, p.ChainID, fromName, password, toStr, p.Coins(coinStr), p.Coins(feeStr), broadcastMode)
+		return
+	})
+	return out, err
+}
+
+//CosmosDelegateOn is CosmosDelegate on the network profile
+func CosmosDelegateOn(ctx context.Context, profileName, rootDir, delegatorName, password, delegatorAddr, validatorAddr, delegationCoinStr, feeStr, broadcastMode string) (sdk.TxResponse, error) {
+	pool, err := getPool(profileName, network.FamilyCosmos)
+	if err != nil {
+		return sdk.TxResponse{}, err
+	}
+	p := pool.Profile
+	if err := p.CheckAddress(delegatorAddr); err != nil {
+		return sdk.TxResponse{}, err
+	}
+	var out sdk.TxResponse
+	err = pool.Do(ctx, func(node string) (err error) {
+		out, err = CosmosDelegate(ctx, rootDir, node, p.ChainID, delegatorName, password, delegatorAddr, validatorAddr, p.Coins(delegationCoinStr), p.Coins(feeStr), broadcastMode)
+		return
+	})
+	return out, err
+}
+
+//CosmosQueryTxOn is CosmosQueryTx on the network profile
+func CosmosQueryTxOn(ctx context.Context, profileName, rootDir, txHash string) (sdk.TxResponse, error) {
+	pool, err := getPool(profileName, network.FamilyCosmos)
+	if err != nil {
+		return sdk.TxResponse{}, err
+	}
+	var out sdk.TxResponse
+	err = pool.Do(ctx, func(node string) (err error) {
+		out, err = CosmosQueryTx(ctx, rootDir, node, pool.Profile.ChainID, txHash)
+		return
+	})
+	return out, err
+}
+
+//QOSQueryAccountOn is QOSQueryAccount on the network profile
+func QOSQueryAccountOn(ctx context.Context, profileName, addr string) (account.Account, error) {
+	pool, err := getPool(profileName, network.FamilyQOS)
+	if err != nil {
+		return nil, err
+	}
+	if err := pool.Profile.CheckAddress(addr); err != nil {
+		return nil, err
+	}
+	var out account.Account
+	err = pool.Do(ctx, func(remote string) (err error) {
+		return call(ctx, func() (err error) {
+			out, err = slim.FetchAccountOnChain(remote, pool.Profile.ChainID, addr)
+			return
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+//QOSTransferSendOn is QOSTransferSend on the network profile
+func QOSTransferSendOn(ctx context.Context, profileName, addrto, coinstr, privkey string) (*ctypes.ResultBroadcastTx, error) {
+	pool, err := getPool(profileName, network.FamilyQOS)
+	if err != nil {
+		return nil, err
+	}
+	p := pool.Profile
+	if err := p.CheckAddress(addrto); err != nil {
+		return nil, err
+	}
+	var out *ctypes.ResultBroadcastTx
+	err = pool.Do(ctx, func(remote string) (err error) {
+		out, err = broadcastCall(ctx, func() (*ctypes.ResultBroadcastTx, error) {
+			return slim.SendTransferOnChain(remote, addrto, p.Coins(coinstr), privkey, p.ChainID)
+		})
+		return
+	})
+	return out, err
+}
+
+//QOSDelegationSendOn is QOSDelegationSend on the network profile
+func QOSDelegationSendOn(ctx context.Context, profileName, validatorAddr string, coins int64, privkey string) (*ctypes.ResultBroadcastTx, error) {
+	pool, err := getPool(profileName, network.FamilyQOS)
+	if err != nil {
+		return nil, err
+	}
+	var out *ctypes.ResultBroadcastTx
+	err = pool.Do(ctx, func(remote string) (err error) {
+		out, err = broadcastCall(ctx, func() (*ctypes.ResultBroadcastTx, error) {
+			return slim.SendDelegationOnChain(remote, validatorAddr, coins, privkey, pool.Profile.ChainID)
+		})
+		return
+	})
+	return out, err
+}
+
+//QOSTransferSendOnByName is QOSTransferSendOn signed with the key name of the QOS keystore under rootDir
+func QOSTransferSendOnByName(ctx context.Context, profileName, rootDir, name, password, addrto, coinstr string) (*ctypes.ResultBroadcastTx, error) {
+	privkey, err := QOSExportKey(ctx, rootDir, name, password)
+	if err != nil {
+		return nil, err
+	}
+	return QOSTransferSendOn(ctx, profileName, addrto, coinstr, privkey)
+}
+
+//QOSDelegationSendOnByName is QOSDelegationSendOn signed with the key name of the QOS keystore under rootDir
+func QOSDelegationSendOnByName(ctx context.Context, profileName, rootDir, name, password, validatorAddr string, coins int64) (*ctypes.ResultBroadcastTx, error) {
+	privkey, err := QOSExportKey(ctx, rootDir, name, password)
+	if err != nil {
+		return nil, err
+	}
+	return QOSDelegationSendOn(ctx, profileName, validatorAddr, coins, privkey)
+}
+
+//QOSGetTxOn is QOSGetTx on the network profile
+func QOSGetTxOn(ctx context.Context, profileName, tx string) (types.TxResponse, error) {
+	pool, err := getPool(profileName, network.FamilyQOS)
+	if err != nil {
+		return types.TxResponse{}, err
+	}
+	var out types.TxResponse
+	err = pool.Do(ctx, func(remote string) (err error) {
+		out, err = QOSGetTx(ctx, remote, tx)
+		return
+	})
+	return out, err
+}
+
+//EthGetAccountOn is EthGetAccount on the network profile
+func EthGetAccountOn(ctx context.Context, profileName, addr string) (*eth.Balance, error) {
+	pool, err := getPool(profileName, network.FamilyETH)
+	if err != nil {
+		return nil, err
+	}
+	var out *eth.Balance
+	err = pool.Do(ctx, func(node string) (err error) {
+		out, err = EthGetAccount(ctx, node, addr)
+		return
+	})
+	return out, err
+}
+
+//EthGetErc20AccountOn is EthGetErc20Account on the network profile
+func EthGetErc20AccountOn(ctx context.Context, profileName, addr, tokenAddr string) (*eth.Balance, error) {
+	pool, err := getPool(profileName, network.FamilyETH)
+	if err != nil {
+		return nil, err
+	}
+	var out *eth.Balance
+	err = pool.Do(ctx, func(node string) (err error) {
+		out, err = EthGetErc20Account(ctx, node, addr, tokenAddr)
+		return
+	})
+	return out, err
+}
+
+//EthTransferETHOn is EthTransferETH on the network profile
+func EthTransferETHOn(ctx context.Context, profileName, rootDir, name, password, toAddr, gasPrice, amount string, gasLimit int64) (common.Hash, error) {
+	pool, err := getPool(profileName, network.FamilyETH)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	var out common.Hash
+	err = pool.Do(ctx, func(node string) (err error) {
+		out, err = EthTransferETH(ctx, rootDir, node, name, password, toAddr, gasPrice, amount, gasLimit)
+		return
+	})
+	return out, err
+}
+
+//EthTransferErc20On is EthTransferErc20 on the network profile
+func EthTransferErc20On(ctx context.Context, profileName, rootDir, name, password, toAddr, tokenAddr, tokenValue, gasPrice string, gasLimit int64) (common.Hash, error) {
+	pool, err := getPool(profileName, network.FamilyETH)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	var out common.Hash
+	err = pool.Do(ctx, func(node string) (err error) {
+		out, err = EthTransferErc20(ctx, rootDir, node, name, password, toAddr, tokenAddr, tokenValue, gasPrice, gasLimit)
+		return
+	})
+	return out, err
+}
@@ -0,0 +1,180 @@
+package api
+
+import (
+	"context"
+
+	"github.com/QOSGroup/litewallet/litewallet/errcode"
+	"github.com/QOSGroup/litewallet/litewallet/eth"
+	"github.com/QOSGroup/litewallet/litewallet/offline"
+	"github.com/QOSGroup/litewallet/litewallet/sdksource"
+	"github.com/QOSGroup/litewallet/litewallet/signer"
+	"github.com/QOSGroup/litewallet/litewallet/slim"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+)
+
+//The offline txs are signed on an air-gapped device: the Build functions run online with the address of the
+//sender and return the unsigned document, OfflineSign signs it on the device holding the key and
+//OfflineBroadcast sends it from any online device. Every step returns the document with its hash, see the
+//offline package.
+
+//CosmosBuildOfflineTransfer is CosmosTransfer from fromAddr left unsigned
+func CosmosBuildOfflineTransfer(ctx context.Context, rootDir, node, chainID, fromAddr, toStr, coinStr, feeStr string) (offline.Tx, error) {
+	return offlineCall(ctx, func() (offline.Tx, error) {
+		return sdksource.BuildOfflineTransfer(rootDir, node, chainID, fromAddr, toStr, coinStr, feeStr)
+	})
+}
+
+//CosmosBuildOfflineDelegate is CosmosDelegate left unsigned
+func CosmosBuildOfflineDelegate(ctx context.Context, rootDir, node, chainID, delegatorAddr, validatorAddr, delegationCoinStr, feeStr string) (offline.Tx, error) {
+	return offlineCall(ctx, func() (offline.Tx, error) {
+		return sdksource.BuildOfflineDelegate(rootDir, node, chainID, delegatorAddr, validatorAddr, delegationCoinStr, feeStr)
+	})
+}
+
+//CosmosBuildOfflineUnbondingDelegation is CosmosUnbondingDelegation left unsigned
+func CosmosBuildOfflineUnbondingDelegation(ctx context.Context, rootDir, node, chainID, delegatorAddr, validatorAddr, Ubdshares, feeStr string) (offline.Tx, error) {
+	return offlineCall(ctx, func() (offline.Tx, error) {
+		return sdksource.BuildOfflineUndelegate(rootDir, node, chainID, delegatorAddr, validatorAddr, Ubdshares, feeStr)
+	})
+}
+
+//CosmosBuildOfflineWithdrawDelegationReward is CosmosWithdrawDelegationReward left unsigned
+func CosmosBuildOfflineWithdrawDelegationReward(ctx context.Context, rootDir, node, chainID, delegatorAddr, validatorAddr, feeStr string) (offline.Tx, error) {
+	return offlineCall(ctx, func() (offline.Tx, error) {
+		return sdksource.BuildOfflineWithdrawReward(rootDir, node, chainID, delegatorAddr, validatorAddr, feeStr)
+	})
+}
+
+//CosmosBuildOfflineWithdrawDelegatorAllRewards is CosmosWithdrawDelegatorAllRewards left unsigned
+func CosmosBuildOfflineWithdrawDelegatorAllRewards(ctx context.Context, rootDir, node, chainID, delegatorAddr, feeStr string) (offline.Tx, error) {
+	return offlineCall(ctx, func() (offline.Tx, error) {
+		return sdksource.BuildOfflineWithdrawAllRewards(rootDir, node, chainID, delegatorAddr, feeStr)
+	})
+}
+
+//EthBuildOfflineETH is EthTransferETH from fromAddr left unsigned, with its pending nonce
+func EthBuildOfflineETH(ctx context.Context, node, fromAddr, toAddr, gasPrice, amount string, gasLimit int64) (offline.Tx, error) {
+	return eth.BuildOfflineETH(ctx, node, fromAddr, toAddr, gasPrice, amount, gasLimit)
+}
+
+//EthBuildOfflineErc20 is EthTransferErc20 from fromAddr left unsigned, with its pending nonce
+func EthBuildOfflineErc20(ctx context.Context, node, fromAddr, toAddr, tokenAddr, tokenValue, gasPrice string, gasLimit int64) (offline.Tx, error) {
+	return eth.BuildOfflineERC20(ctx, node, fromAddr, toAddr, tokenAddr, tokenValue, gasPrice, gasLimit)
+}
+
+//QOSBuildOfflineTransfer is QOSTransferSend from fromAddr left unsigned
+func QOSBuildOfflineTransfer(ctx context.Context, remote, fromAddr, addrto, coinstr, chainid string) (offline.Tx, error) {
+	return offlineCall(ctx, func() (offline.Tx, error) {
+		return slim.BuildOfflineTransfer(remote, fromAddr, addrto, coinstr, chainid)
+	})
+}
+
+//QOSBuildOfflineDelegation is QOSDelegationSend left unsigned
+func QOSBuildOfflineDelegation(ctx context.Context, remote, fromAddr, validatorAddr string, coins int64, chainid string) (offline.Tx, error) {
+	return offlineCall(ctx, func() (offline.Tx, error) {
+		return slim.BuildOfflineDelegation(remote, fromAddr, validatorAddr, coins, chainid)
+	})
+}
+
+//QOSBuildOfflineUnbondDelegation is QOSUnbondDelegationSend left unsigned
+func QOSBuildOfflineUnbondDelegation(ctx context.Context, remote, fromAddr, validatorAddr string, coins int64, chainid string) (offline.Tx, error) {
+	return offlineCall(ctx, func() (offline.Tx, error) {
+		return slim.BuildOfflineUnbondDelegation(remote, fromAddr, validatorAddr, coins, chainid)
+	})
+}
+
+//QOSBuildOfflineReDelegation is QOSReDelegationSend left unsigned
+func QOSBuildOfflineReDelegation(ctx context.Context, remote, fromAddr, fromValidatorAddr, toValidatorAddr string, coins int64, chainid string) (offline.Tx, error) {
+	return offlineCall(ctx, func() (offline.Tx, error) {
+		return slim.BuildOfflineReDelegation(remote, fromAddr, fromValidatorAddr, toValidatorAddr, coins, chainid)
+	})
+}
+
+//OfflineSign signs the unsigned tx of any chain with s, without any node
+func OfflineSign(ctx context.Context, tx offline.Tx, s signer.Signer) (offline.Tx, error) {
+	if err := ctx.Err(); err != nil {
+		return offline.Tx{}, err
+	}
+	switch tx.Chain {
+	case offline.ChainCosmos:
+		return sdksource.SignOffline(tx, s)
+	case offline.ChainETH:
+		return eth.SignOffline(tx, s)
+	case offline.ChainQOS:
+		return slim.SignOffline(tx, s)
+	}
+	return offline.Tx{}, errcode.Errorf(errcode.CodeInvalidInput, "unknown chain %q of the offline tx", tx.Chain)
+}
+
+//OfflineSignByName is OfflineSign with the local key name of the chain of tx
+func OfflineSignByName(ctx context.Context, rootDir, name, password string, tx offline.Tx) (offline.Tx, error) {
+	if err := ctx.Err(); err != nil {
+		return offline.Tx{}, err
+	}
+	var s signer.Signer
+	switch tx.Chain {
+	case offline.ChainCosmos:
+		ks, err := sdksource.KeySigner(rootDir, name, password)
+		if err != nil {
+			return offline.Tx{}, err
+		}
+		s = ks
+	case offline.ChainETH:
+		key, err := eth.FetchtoSign(rootDir, name, password)
+		if err != nil {
+			return offline.Tx{}, err
+		}
+		s = signer.NewSecp256k1(key)
+	case offline.ChainQOS:
+		key, err := slim.FetchKey(rootDir, name, password)
+		if err != nil {
+			return offline.Tx{}, err
+		}
+		s = signer.NewEd25519(key)
+	}
+	return OfflineSign(ctx, tx, s)
+}
+
+//OfflineBroadcast broadcasts the signed tx to node, the node of its chain, and returns its hash. rootDir is the
+//one of the Cosmos queries and broadcastMode the mode of the Cosmos broadcast, ETH and QOS are sent in sync.
+func OfflineBroadcast(ctx context.Context, rootDir, node string, tx offline.Tx, broadcastMode string) (string, error) {
+	switch tx.Chain {
+	case offline.ChainCosmos:
+		res, err := txCall(ctx, func() (sdk.TxResponse, error) {
+			return sdksource.BroadcastOffline(rootDir, node, tx, broadcastMode)
+		})
+		if err != nil {
+			return "", err
+		}
+		return res.TxHash, nil
+	case offline.ChainETH:
+		hash, err := eth.BroadcastOffline(ctx, node, tx)
+		if err != nil {
+			return "", err
+		}
+		return hash.Hex(), nil
+	case offline.ChainQOS:
+		res, err := broadcastCall(ctx, func() (*ctypes.ResultBroadcastTx, error) {
+			return slim.BroadcastOffline(node, tx)
+		})
+		if err != nil {
+			return "", err
+		}
+		return res.Hash.String(), nil
+	}
+	return "", errcode.Errorf(errcode.CodeInvalidInput, "unknown chain %q of the offline tx", tx.Chain)
+}
+
+//offlineCall is for the builders of the Cosmos and QOS offline txs, they query the account of the sender
+func offlineCall(ctx context.Context, fn func() (offline.Tx, error)) (offline.Tx, error) {
+	var out offline.Tx
+	err := call(ctx, func() (err error) {
+		out, err = fn()
+		return
+	})
+	if err != nil {
+		return offline.Tx{}, err
+	}
+	return out, nil
+}
@@ -0,0 +1,372 @@
+package api
+
+import (
+	"context"
+
+	"github.com/QOSGroup/litewallet/litewallet/slim"
+	"github.com/QOSGroup/litewallet/litewallet/slim/base/account"
+	btxs "github.com/QOSGroup/litewallet/litewallet/slim/base/txs"
+	"github.com/QOSGroup/litewallet/litewallet/slim/base/types"
+	"github.com/QOSGroup/litewallet/litewallet/slim/module"
+	"github.com/QOSGroup/litewallet/litewallet/slim/txs"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+)
+
+//QOSAccountCreate creates a new QOS account with a new mnemonic
+func QOSAccountCreate(ctx context.Context, password string) (*slim.ResultCreateAccount, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return slim.CreateAccount(password)
+}
+
+//QOSAccountCreateFromSeed returns the QOS keys of mncode
+func QOSAccountCreateFromSeed(ctx context.Context, mncode string) (*slim.AccountKeyOut, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return slim.CreateAccountFromSeed(mncode)
+}
+
+//QOSAccountRecover returns the QOS account of mncode
+func QOSAccountRecover(ctx context.Context, mncode, password string) (*slim.ResultCreateAccount, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return slim.RecoverAccount(mncode, password)
+}
+
+//QOSPubAddrRetrieval returns the public key and address of the base64 private key priv
+func QOSPubAddrRetrieval(ctx context.Context, priv string) (*slim.PubAddrRetrieval, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return slim.RetrievePubAddr(priv)
+}
+
+//QOSSetBlockchainEntrance sets the qstars and qmoon hosts used by the QSC functions
+func QOSSetBlockchainEntrance(sh, mh string) {
+	txs.SetBlockchainEntrance(sh, mh)
+}
+
+//QOSQueryAccount returns the QOS account of addr
+func QOSQueryAccount(ctx context.Context, remote, addr string) (account.Account, error) {
+	var out account.Account
+	err := call(ctx, func() (err error) {
+		out, err = slim.FetchAccount(remote, addr)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+//QOSTransferSend sends coinstr to addrto, signed with privkey
+func QOSTransferSend(ctx context.Context, remote, addrto, coinstr, privkey, chainid string) (*ctypes.ResultBroadcastTx, error) {
+	return broadcastCall(ctx, func() (*ctypes.ResultBroadcastTx, error) {
+		return slim.SendTransfer(remote, addrto, coinstr, privkey, chainid)
+	})
+}
+
+//QOSDelegationSend delegates coins to validatorAddr, signed with privkey
+func QOSDelegationSend(ctx context.Context, remote, validatorAddr string, coins int64, privkey, chainid string) (*ctypes.ResultBroadcastTx, error) {
+	return broadcastCall(ctx, func() (*ctypes.ResultBroadcastTx, error) {
+		return slim.SendDelegation(remote, validatorAddr, coins, privkey, chainid)
+	})
+}
+
+//QOSUnbondDelegationSend unbonds coins from validatorAddr, signed with privkey
+func QOSUnbondDelegationSend(ctx context.Context, remote, validatorAddr string, coins int64, privkey, chainid string) (*ctypes.ResultBroadcastTx, error) {
+	return broadcastCall(ctx, func() (*ctypes.ResultBroadcastTx, error) {
+		return slim.SendUnbondDelegation(remote, validatorAddr, coins, privkey, chainid)
+	})
+}
+
+//QOSReDelegationSend moves coins from fromValidatorAddr to toValidatorAddr, signed with privkey
+func QOSReDelegationSend(ctx context.Context, remote, fromValidatorAddr, toValidatorAddr string, coins int64, privkey, chainid string) (*ctypes.ResultBroadcastTx, error) {
+	return broadcastCall(ctx, func() (*ctypes.ResultBroadcastTx, error) {
+		return slim.SendReDelegation(remote, fromValidatorAddr, toValidatorAddr, coins, privkey, chainid)
+	})
+}
+
+//QOSGetTx returns the tx of the hex hash tx
+func QOSGetTx(ctx context.Context, remote, tx string) (types.TxResponse, error) {
+	var out types.TxResponse
+	err := call(ctx, func() (err error) {
+		out, err = slim.FetchTx(remote, tx)
+		return
+	})
+	if err != nil {
+		return types.TxResponse{}, err
+	}
+	return out, nil
+}
+
+//QOSGetBlance returns the coins of addrs on the blockchain entrance
+func QOSGetBlance(ctx context.Context, addrs string) (types.BaseCoins, error) {
+	var out types.BaseCoins
+	err := call(ctx, func() (err error) {
+		out, err = slim.QueryBalance(addrs)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+//QOSAesEncrypt encrypts plainText with key, the cipher text is base64 encoded
+func QOSAesEncrypt(ctx context.Context, key, plainText string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return slim.Encrypt(key, plainText)
+}
+
+//QOSAesDecrypt decrypts the base64 cipherText with key
+func QOSAesDecrypt(ctx context.Context, key, cipherText string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return slim.Decrypt(key, cipherText)
+}
+
+//QOSAdvertisers returns the signed tx to deposit, isDeposit "2", or redeem, isDeposit "1", as an advertiser
+func QOSAdvertisers(ctx context.Context, privatekey, coinsType, coinAmount, isDeposit, qscchainid string) (*btxs.TxStd, error) {
+	return signCall(ctx, func() (*btxs.TxStd, error) {
+		return module.AdvertisersTx(coinAmount, privatekey, coinsType, isDeposit, qscchainid)
+	})
+}
+
+//QOSAcutionAd returns the signed tx to bid coinAmount on the ad slot articleHash
+func QOSAcutionAd(ctx context.Context, articleHash, privatekey, coinsType string, coinAmount int, qscchainid string) (*btxs.TxStd, error) {
+	return signCall(ctx, func() (*btxs.TxStd, error) {
+		return module.AcutionAdTx(articleHash, privatekey, coinsType, coinAmount, qscchainid)
+	})
+}
+
+//QOSExtract returns the signed extract tx
+func QOSExtract(ctx context.Context, privatekey, coinsType, coinAmount, qscchainid string) (*btxs.TxStd, error) {
+	return signCall(ctx, func() (*btxs.TxStd, error) {
+		return module.ExtractTx(coinAmount, privatekey, coinsType, qscchainid)
+	})
+}
+
+//QOSCommHandler returns the signed tx calling funcName of the QSC contract with args
+func QOSCommHandler(ctx context.Context, funcName, privatekey string, args []string, qscchainid string) (*btxs.TxStd, error) {
+	return signCall(ctx, func() (*btxs.TxStd, error) {
+		return module.CommHandlerTx(funcName, privatekey, args, qscchainid)
+	})
+}
+
+//QOSInvestAd returns the signed tx investing coins, in AOE, in the article articleHash
+func QOSInvestAd(ctx context.Context, QOSchainId, QSCchainId, articleHash, coins, privatekey string) (*btxs.TxStd, error) {
+	return signCall(ctx, func() (*btxs.TxStd, error) {
+		return module.InvestAdTx(QOSchainId, QSCchainId, articleHash, coins, privatekey)
+	})
+}
+
+//QOSBroadcastTransferTxToQSC broadcasts the tx bytes to the QSC chain, in "sync" mode or async by default
+func QOSBroadcastTransferTxToQSC(ctx context.Context, txBytes []byte, broadcastModes string) (*ctypes.ResultBroadcastTx, error) {
+	return broadcastCall(ctx, func() (*ctypes.ResultBroadcastTx, error) {
+		return txs.BroadcastTxToQSC(txBytes, broadcastModes)
+	})
+}
+
+func broadcastCall(ctx context.Context, fn func() (*ctypes.ResultBroadcastTx, error)) (*ctypes.ResultBroadcastTx, error) {
+	var out *ctypes.ResultBroadcastTx
+	err := call(ctx, func() (err error) {
+		out, err = fn()
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+//signCall is for the qsc builders, they query the account of the signer before signing
+func signCall(ctx context.Context, fn func() (*btxs.TxStd, error)) (*btxs.TxStd, error) {
+	var out *btxs.TxStd
+	err := call(ctx, func() (err error) {
+		out, err = fn()
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+//The functions ending with ByName sign with the key name of the QOS keystore under rootDir instead of a
+//private key held by the app
+
+//QOSCreateKey stores the key of mnemonic under name, a new mnemonic is generated when it is empty
+func QOSCreateKey(ctx context.Context, rootDir, name, password, mnemonic string) (*slim.KeyOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return slim.CreateKey(rootDir, name, password, mnemonic)
+}
+
+//QOSRecoverKey stores the key of mnemonic under name
+func QOSRecoverKey(ctx context.Context, rootDir, name, password, mnemonic string) (*slim.KeyOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return slim.RestoreKey(rootDir, name, password, mnemonic)
+}
+
+//QOSCreateKeyWithPath stores the key at the SLIP-0010 path hdPath of mnemonic under name, a new mnemonic is generated when it is empty
+func QOSCreateKeyWithPath(ctx context.Context, rootDir, name, password, mnemonic, hdPath string) (*slim.KeyOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return slim.CreateKeyWithPath(rootDir, name, password, mnemonic, hdPath)
+}
+
+//QOSCreateKeyAt stores the account index of mnemonic under name
+func QOSCreateKeyAt(ctx context.Context, rootDir, name, password, mnemonic string, index uint32) (*slim.KeyOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return slim.CreateKeyAt(rootDir, name, password, mnemonic, index)
+}
+
+//QOSRecoverLegacyKey stores the key of mnemonic derived as QOSAccountRecover did, passphrase is the password of the account
+func QOSRecoverLegacyKey(ctx context.Context, rootDir, name, password, mnemonic, passphrase string) (*slim.KeyOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return slim.RestoreLegacyKey(rootDir, name, password, mnemonic, passphrase)
+}
+
+//QOSDeriveAccounts returns count accounts of mnemonic from the index start
+func QOSDeriveAccounts(ctx context.Context, mnemonic string, start uint32, count int) ([]slim.DerivedAccount, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return slim.DeriveAccounts(mnemonic, start, count)
+}
+
+//QOSImportKey stores the base64 private key under name
+func QOSImportKey(ctx context.Context, rootDir, name, password, privkey string) (*slim.KeyOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return slim.ImportKey(rootDir, name, password, privkey)
+}
+
+//QOSListKeys returns the keys stored under rootDir
+func QOSListKeys(ctx context.Context, rootDir string) ([]slim.KeyOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return slim.ListKeys(rootDir)
+}
+
+//QOSDeleteKey removes the key name
+func QOSDeleteKey(ctx context.Context, rootDir, name, password string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return slim.DeleteKey(rootDir, name, password)
+}
+
+//QOSUpdateKey changes the password of the key name
+func QOSUpdateKey(ctx context.Context, rootDir, name, oldpass, newpass string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return slim.ChangePassword(rootDir, name, oldpass, newpass)
+}
+
+//QOSExportKey returns the base64 private key of name
+func QOSExportKey(ctx context.Context, rootDir, name, password string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return slim.ExportKey(rootDir, name, password)
+}
+
+//QOSTransferSendByName is QOSTransferSend signed with the key name
+func QOSTransferSendByName(ctx context.Context, rootDir, remote, name, password, addrto, coinstr, chainid string) (*ctypes.ResultBroadcastTx, error) {
+	privkey, err := QOSExportKey(ctx, rootDir, name, password)
+	if err != nil {
+		return nil, err
+	}
+	return QOSTransferSend(ctx, remote, addrto, coinstr, privkey, chainid)
+}
+
+//QOSDelegationSendByName is QOSDelegationSend signed with the key name
+func QOSDelegationSendByName(ctx context.Context, rootDir, remote, name, password, validatorAddr string, coins int64, chainid string) (*ctypes.ResultBroadcastTx, error) {
+	privkey, err := QOSExportKey(ctx, rootDir, name, password)
+	if err != nil {
+		return nil, err
+	}
+	return QOSDelegationSend(ctx, remote, validatorAddr, coins, privkey, chainid)
+}
+
+//QOSUnbondDelegationSendByName is QOSUnbondDelegationSend signed with the key name
+func QOSUnbondDelegationSendByName(ctx context.Context, rootDir, remote, name, password, validatorAddr string, coins int64, chainid string) (*ctypes.ResultBroadcastTx, error) {
+	privkey, err := QOSExportKey(ctx, rootDir, name, password)
+	if err != nil {
+		return nil, err
+	}
+	return QOSUnbondDelegationSend(ctx, remote, validatorAddr, coins, privkey, chainid)
+}
+
+//QOSReDelegationSendByName is QOSReDelegationSend signed with the key name
+func QOSReDelegationSendByName(ctx context.Context, rootDir, remote, name, password, fromValidatorAddr, toValidatorAddr string, coins int64, chainid string) (*ctypes.ResultBroadcastTx, error) {
+	privkey, err := QOSExportKey(ctx, rootDir, name, password)
+	if err != nil {
+		return nil, err
+	}
+	return QOSReDelegationSend(ctx, remote, fromValidatorAddr, toValidatorAddr, coins, privkey, chainid)
+}
+
+//QOSAdvertisersByName is QOSAdvertisers signed with the key name
+func QOSAdvertisersByName(ctx context.Context, rootDir, name, password, coinsType, coinAmount, isDeposit, qscchainid string) (*btxs.TxStd, error) {
+	privkey, err := QOSExportKey(ctx, rootDir, name, password)
+	if err != nil {
+		return nil, err
+	}
+	return QOSAdvertisers(ctx, privkey, coinsType, coinAmount, isDeposit, qscchainid)
+}
+
+//QOSAcutionAdByName is QOSAcutionAd signed with the key name
+func QOSAcutionAdByName(ctx context.Context, rootDir, articleHash, name, password, coinsType string, coinAmount int, qscchainid string) (*btxs.TxStd, error) {
+	privkey, err := QOSExportKey(ctx, rootDir, name, password)
+	if err != nil {
+		return nil, err
+	}
+	return QOSAcutionAd(ctx, articleHash, privkey, coinsType, coinAmount, qscchainid)
+}
+
+//QOSExtractByName is QOSExtract signed with the key name
+func QOSExtractByName(ctx context.Context, rootDir, name, password, coinsType, coinAmount, qscchainid string) (*btxs.TxStd, error) {
+	privkey, err := QOSExportKey(ctx, rootDir, name, password)
+	if err != nil {
+		return nil, err
+	}
+	return QOSExtract(ctx, privkey, coinsType, coinAmount, qscchainid)
+}
+
+//QOSCommHandlerByName is QOSCommHandler signed with the key name
+func QOSCommHandlerByName(ctx context.Context, rootDir, funcName, name, password string, args []string, qscchainid string) (*btxs.TxStd, error) {
+	privkey, err := QOSExportKey(ctx, rootDir, name, password)
+	if err != nil {
+		return nil, err
+	}
+	return QOSCommHandler(ctx, funcName, privkey, args, qscchainid)
+}
+
+//QOSInvestAdByName is QOSInvestAd signed with the key name
+func QOSInvestAdByName(ctx context.Context, rootDir, QOSchainId, QSCchainId, articleHash, coins, name, password string) (*btxs.TxStd, error) {
+	privkey, err := QOSExportKey(ctx, rootDir, name, password)
+	if err != nil {
+		return nil, err
+	}
+	return QOSInvestAd(ctx, QOSchainId, QSCchainId, articleHash, coins, privkey)
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"context"
+
+	"github.com/QOSGroup/litewallet/litewallet/eth"
+	"github.com/QOSGroup/litewallet/litewallet/sdksource"
+	"github.com/QOSGroup/litewallet/litewallet/signer"
+	"github.com/QOSGroup/litewallet/litewallet/slim"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/ethereum/go-ethereum/common"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+)
+
+//The WithSigner variants sign the txs with a signer.Signer instead of a local key, such as a key of the
+//Android Keystore, the iOS Secure Enclave or an external device the app signs with, see signer.NewCallback.
+//The sender is the address of the signer.
+
+//EthTransferETHWithSigner is EthTransferETH signed by s
+func EthTransferETHWithSigner(ctx context.Context, node string, s signer.Signer, toAddr, gasPrice, amount string, gasLimit int64) (common.Hash, error) {
+	return eth.SendETHWithSigner(ctx, node, s, toAddr, gasPrice, amount, gasLimit)
+}
+
+//EthTransferErc20WithSigner is EthTransferErc20 signed by s
+func EthTransferErc20WithSigner(ctx context.Context, node string, s signer.Signer, toAddr, tokenAddr, tokenValue, gasPrice string, gasLimit int64) (common.Hash, error) {
+	return eth.SendERC20WithSigner(ctx, node, s, toAddr, tokenAddr, tokenValue, gasPrice, gasLimit)
+}
+
+//CosmosTransferWithSigner is CosmosTransfer signed by s
+func CosmosTransferWithSigner(ctx context.Context, rootDir, node, chainID string, s signer.Signer, toStr, coinStr, feeStr, broadcastMode string) (sdk.TxResponse, error) {
+	return txCall(ctx, func() (sdk.TxResponse, error) {
+		return sdksource.SendCoinsWithSigner(rootDir, node, chainID, s, toStr, coinStr, feeStr, broadcastMode)
+	})
+}
+
+//CosmosDelegateWithSigner is CosmosDelegate signed by s
+func CosmosDelegateWithSigner(ctx context.Context, rootDir, node, chainID string, s signer.Signer, validatorAddr, delegationCoinStr, feeStr, broadcastMode string) (sdk.TxResponse, error) {
+	return txCall(ctx, func() (sdk.TxResponse, error) {
+		return sdksource.DelegateCoinsWithSigner(rootDir, node, chainID, s, validatorAddr, delegationCoinStr, feeStr, broadcastMode)
+	})
+}
+
+//CosmosUnbondingDelegationWithSigner is CosmosUnbondingDelegation signed by s
+func CosmosUnbondingDelegationWithSigner(ctx context.Context, rootDir, node, chainID string, s signer.Signer, validatorAddr, Ubdshares, feeStr, broadcastMode string) (sdk.TxResponse, error) {
+	return txCall(ctx, func() (sdk.TxResponse, error) {
+		return sdksource.UndelegateCoinsWithSigner(rootDir, node, chainID, s, validatorAddr, Ubdshares, feeStr, broadcastMode)
+	})
+}
+
+//CosmosWithdrawDelegationRewardWithSigner is CosmosWithdrawDelegationReward signed by s
+func CosmosWithdrawDelegationRewardWithSigner(ctx context.Context, rootDir, node, chainID string, s signer.Signer, validatorAddr, feeStr, broadcastMode string) (sdk.TxResponse, error) {
+	return txCall(ctx, func() (sdk.TxResponse, error) {
+		return sdksource.WithdrawRewardWithSigner(rootDir, node, chainID, s, validatorAddr, feeStr, broadcastMode)
+	})
+}
+
+//CosmosWithdrawDelegatorAllRewardsWithSigner is CosmosWithdrawDelegatorAllRewards signed by s
+func CosmosWithdrawDelegatorAllRewardsWithSigner(ctx context.Context, rootDir, node, chainID string, s signer.Signer, feeStr, broadcastMode string) (sdk.TxResponse, error) {
+	return txCall(ctx, func() (sdk.TxResponse, error) {
+		return sdksource.WithdrawAllRewardsWithSigner(rootDir, node, chainID, s, feeStr, broadcastMode)
+	})
+}
+
+//QOSTransferSendWithSigner is QOSTransferSend signed by s
+func QOSTransferSendWithSigner(ctx context.Context, remote, addrto, coinstr string, s signer.Signer, chainid string) (*ctypes.ResultBroadcastTx, error) {
+	return broadcastCall(ctx, func() (*ctypes.ResultBroadcastTx, error) {
+		return slim.SendTransferWithSigner(remote, addrto, coinstr, s, chainid)
+	})
+}
+
+//QOSDelegationSendWithSigner is QOSDelegationSend signed by s
+func QOSDelegationSendWithSigner(ctx context.Context, remote, validatorAddr string, coins int64, s signer.Signer, chainid string) (*ctypes.ResultBroadcastTx, error) {
+	return broadcastCall(ctx, func() (*ctypes.ResultBroadcastTx, error) {
+		return slim.SendDelegationWithSigner(remote, validatorAddr, coins, s, chainid)
+	})
+}
+
+//QOSUnbondDelegationSendWithSigner is QOSUnbondDelegationSend signed by s
+func QOSUnbondDelegationSendWithSigner(ctx context.Context, remote, validatorAddr string, coins int64, s signer.Signer, chainid string) (*ctypes.ResultBroadcastTx, error) {
+	return broadcastCall(ctx, func() (*ctypes.ResultBroadcastTx, error) {
+		return slim.SendUnbondDelegationWithSigner(remote, validatorAddr, coins, s, chainid)
+	})
+}
+
+//QOSReDelegationSendWithSigner is QOSReDelegationSend signed by s
+func QOSReDelegationSendWithSigner(ctx context.Context, remote, fromValidatorAddr, toValidatorAddr string, coins int64, s signer.Signer, chainid string) (*ctypes.ResultBroadcastTx, error) {
+	return broadcastCall(ctx, func() (*ctypes.ResultBroadcastTx, error) {
+		return slim.SendReDelegationWithSigner(remote, fromValidatorAddr, toValidatorAddr, coins, s, chainid)
+	})
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"context"
+
+	"github.com/QOSGroup/litewallet/litewallet/errcode"
+	"github.com/QOSGroup/litewallet/litewallet/keycrypt"
+	"github.com/QOSGroup/litewallet/litewallet/slim/tendermint/crypto/funcInlocal/slip39local"
+	"github.com/QOSGroup/litewallet/litewallet/wallet"
+)
+
+//WalletCreate stores the Cosmos, ETH and QOS keys of mnemonic under name, a new mnemonic is generated when it is empty
+func WalletCreate(ctx context.Context, rootDir, name, password, mnemonic string) (*wallet.Wallet, error) {
+	var out *wallet.Wallet
+	err := call(ctx, func() (err error) {
+		out, err = wallet.Create(rootDir, name, password, mnemonic)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+//WalletRecover stores the Cosmos, ETH and QOS keys of mnemonic under name
+func WalletRecover(ctx context.Context, rootDir, name, password, mnemonic string) (*wallet.Wallet, error) {
+	var out *wallet.Wallet
+	err := call(ctx, func() (err error) {
+		out, err = wallet.Recover(rootDir, name, password, mnemonic)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+//WalletGet returns the wallet name with the addresses of its accounts
+func WalletGet(ctx context.Context, rootDir, name string) (*wallet.Wallet, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return wallet.Get(rootDir, name)
+}
+
+//WalletList returns the wallets stored under rootDir with the addresses of their accounts
+func WalletList(ctx context.Context, rootDir string) ([]wallet.Wallet, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return wallet.List(rootDir)
+}
+
+//WalletBackupShares returns the SLIP-0039 shares of mnemonic for each group, groupThreshold of the groups restore it
+func WalletBackupShares(ctx context.Context, mnemonic, passphrase string, groupThreshold int, groups []slip39local.Group) ([][]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return wallet.BackupShares(mnemonic, passphrase, groupThreshold, groups)
+}
+
+//WalletRestoreShares stores under name the Cosmos, ETH and QOS keys of the mnemonic in language of the shares
+func WalletRestoreShares(ctx context.Context, rootDir, name, password string, shares []string, passphrase, language string) (*wallet.Wallet, error) {
+	var out *wallet.Wallet
+	err := call(ctx, func() (err error) {
+		out, err = wallet.RestoreShares(rootDir, name, password, shares, passphrase, language)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+//WalletMigrateKeys encrypts the keys under rootDir again in the current envelope, passwords are the passwords by key name
+func WalletMigrateKeys(ctx context.Context, rootDir string, passwords map[string]string) ([]wallet.KeyMigration, error) {
+	var out []wallet.KeyMigration
+	err := call(ctx, func() (err error) {
+		out, err = wallet.MigrateKeys(rootDir, passwords)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+//SetKeyEncryption encrypts the keys with the default parameters of kdf, keycrypt.KDFArgon2id or keycrypt.KDFScrypt,
+//the keys of other parameters are encrypted again with them on their next unlock
+func SetKeyEncryption(kdf string) error {
+	switch kdf {
+	case keycrypt.KDFArgon2id:
+		return keycrypt.SetCurrent(keycrypt.Argon2id)
+	case keycrypt.KDFScrypt:
+		return keycrypt.SetCurrent(keycrypt.Scrypt)
+	}
+	return errcode.Errorf(errcode.CodeInvalidInput, "unknown KDF %q, argon2id or scrypt", kdf)
+}
@@ -0,0 +1,100 @@
+//Package errcode categorizes the errors returned by the wallet functions,
+//so the apps can tell a network failure from a wrong password without parsing the message.
+//
+//The codes are shared by the QOS, Cosmos and ETH wallets and reach the apps as the error.code
+//of the response of the mobile package. They are stable: a code is never renumbered or reused,
+//new categories are only appended.
+package errcode
+
+import (
+	"errors"
+	"fmt"
+)
+
+//CodeType is the category of a wallet error
+type CodeType uint32
+
+//the catalog of the codes, keep it in sync with the README
+const (
+	CodeOK                CodeType = 0
+	CodeInternal          CodeType = 1 //unexpected failure inside the wallet
+	CodeNetwork           CodeType = 2 //the node can not be reached or did not answer
+	CodeInvalidInput      CodeType = 3 //malformed address, amount, mnemonic or missing argument
+	CodeInsufficientFunds CodeType = 4 //the account can not pay the amount and the fee
+	CodeKeyNotFound       CodeType = 5 //no local key or account with the given name or address
+	CodeWrongPassword     CodeType = 6 //the password does not decrypt the local key
+	CodeNodeRejected      CodeType = 7 //the node refused the tx or the query
+	CodeUnverified        CodeType = 8 //the answer of the node does not match the headers signed by the validators
+)
+
+//Error is an error with its category
+type Error struct {
+	Code CodeType
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+//New returns an error of code with the message msg
+func New(code CodeType, msg string) error {
+	return &Error{code, errors.New(msg)}
+}
+
+//Errorf returns an error of code with the formatted message
+func Errorf(code CodeType, format string, args ...interface{}) error {
+	return &Error{code, fmt.Errorf(format, args...)}
+}
+
+//Wrap puts err in the category code, nil stays nil and an already categorized error keeps its code
+func Wrap(code CodeType, err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := err.(*Error); ok {
+		return err
+	}
+	return &Error{code, err}
+}
+
+//Code returns the category of err, CodeOK for nil and CodeInternal for an uncategorized error
+func Code(err error) CodeType {
+	if err == nil {
+		return CodeOK
+	}
+	if e, ok := err.(*Error); ok {
+		return e.Code
+	}
+	return CodeInternal
+}
+
+//Network, InvalidInput, ... are shorthands of Wrap
+
+func Network(err error) error {
+	return Wrap(CodeNetwork, err)
+}
+
+func InvalidInput(err error) error {
+	return Wrap(CodeInvalidInput, err)
+}
+
+func KeyNotFound(err error) error {
+	return Wrap(CodeKeyNotFound, err)
+}
+
+func WrongPassword(err error) error {
+	return Wrap(CodeWrongPassword, err)
+}
+
+func NodeRejected(err error) error {
+	return Wrap(CodeNodeRejected, err)
+}
+
+func Unverified(err error) error {
+	return Wrap(CodeUnverified, err)
+}
+
+func Internal(err error) error {
+	return Wrap(CodeInternal, err)
+}
@@ -0,0 +1,26 @@
+package errcode
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCode(t *testing.T) {
+	if Code(nil) != CodeOK {
+		t.Error("nil should be CodeOK")
+	}
+	if Code(errors.New("boom")) != CodeInternal {
+		t.Error("an uncategorized error should be CodeInternal")
+	}
+	err := Network(errors.New("connection refused"))
+	if Code(err) != CodeNetwork || err.Error() != "connection refused" {
+		t.Errorf("got %d %q", Code(err), err)
+	}
+	//the first category wins
+	if Code(InvalidInput(err)) != CodeNetwork {
+		t.Error("Wrap should keep the code of a categorized error")
+	}
+	if Wrap(CodeNodeRejected, nil) != nil {
+		t.Error("Wrap of nil should be nil")
+	}
+}
@@ -0,0 +1,47 @@
+package eth
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/QOSGroup/litewallet/litewallet/errcode"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+//Client is the part of the node API used by the wallet, *ethclient.Client implements it
+type Client interface {
+	bind.ContractBackend
+	BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error)
+	NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
+	NetworkID(ctx context.Context) (*big.Int, error)
+	Close()
+}
+
+//Dialer connects to the node at the url given to the wallet functions
+type Dialer func(ctx context.Context, node string) (Client, error)
+
+var dialer Dialer = dialRPC
+
+//SetDialer replaces the way the wallet functions connect to the node, e.g. with a simulated chain in the tests.
+//A nil dialer restores the json-rpc client.
+func SetDialer(d Dialer) {
+	if d == nil {
+		d = dialRPC
+	}
+	dialer = d
+}
+
+func dialRPC(ctx context.Context, node string) (Client, error) {
+	return ethclient.DialContext(ctx, node)
+}
+
+//dial connects to the node, a failure is a network error
+func dial(ctx context.Context, node string) (Client, error) {
+	client, err := dialer(ctx, node)
+	if err != nil {
+		return nil, errcode.Network(err)
+	}
+	return client, nil
+}
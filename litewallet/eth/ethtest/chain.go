@@ -0,0 +1,172 @@
+//Package ethtest is a simulated Ethereum chain for the tests of the eth wallet.
+//It is the SimulatedBackend of go-ethereum with a tx pool in front, so the txs wait for Commit
+//like on a real node and a pending tx can be replaced by one with the same nonce and a higher gas price.
+package ethtest
+
+import (
+	"context"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+//GasLimit is the gas limit of the blocks
+const GasLimit = 8000000
+
+//ChainID is the chain id of the simulated chain, the txs are signed with EIP155 on it
+var ChainID = params.AllEthashProtocolChanges.ChainID
+
+//Chain is a simulated chain, it implements the Client of the eth package
+type Chain struct {
+	*backends.SimulatedBackend
+
+	mtx  sync.Mutex
+	pool map[common.Address][]*types.Transaction //the pending txs of each sender, in nonce order
+}
+
+//NewChain returns a chain whose genesis holds alloc
+func NewChain(alloc core.GenesisAlloc) *Chain {
+	return &Chain{
+		SimulatedBackend: backends.NewSimulatedBackend(alloc, GasLimit),
+		pool:             make(map[common.Address][]*types.Transaction),
+	}
+}
+
+//NetworkID returns ChainID, as the networks the wallet signs for
+func (c *Chain) NetworkID(ctx context.Context) (*big.Int, error) {
+	return new(big.Int).Set(ChainID), nil
+}
+
+//Close does nothing, the chain outlives the clients of the wallet functions
+func (c *Chain) Close() {}
+
+//PendingNonceAt is the nonce at the latest block plus the pending txs of account
+func (c *Chain) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	nonce, err := c.SimulatedBackend.NonceAt(ctx, account, nil)
+	if err != nil {
+		return 0, err
+	}
+	return nonce + uint64(len(c.pool[account])), nil
+}
+
+//SendTransaction checks tx like the tx pool of a node and adds it to the pending txs,
+//the errors are json-rpc errors as a node would answer
+func (c *Chain) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	sender, err := types.Sender(signer{types.NewEIP155Signer(ChainID)}, tx)
+	if err != nil {
+		return rpcError("invalid sender")
+	}
+	gas, err := core.IntrinsicGas(tx.Data(), tx.To() == nil, true)
+	if err != nil {
+		return rpcError(err.Error())
+	}
+	if tx.Gas() < gas {
+		return rpcError("intrinsic gas too low")
+	}
+	if tx.Gas() > GasLimit {
+		return rpcError("exceeds block gas limit")
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	nonce, err := c.SimulatedBackend.NonceAt(ctx, sender, nil)
+	if err != nil {
+		return err
+	}
+	pending := c.pool[sender]
+	if tx.Nonce() < nonce {
+		return rpcError("nonce too low")
+	}
+	//the pool keeps no queue, a tx leaving a nonce gap is refused
+	i := int(tx.Nonce() - nonce)
+	if i > len(pending) {
+		return rpcError("nonce too high")
+	}
+	//a replacement has to raise the gas price by 10%
+	if i < len(pending) {
+		min := new(big.Int).Mul(pending[i].GasPrice(), big.NewInt(110))
+		if new(big.Int).Mul(tx.GasPrice(), big.NewInt(100)).Cmp(min) < 0 {
+			return rpcError("replacement transaction underpriced")
+		}
+	}
+
+	cost := tx.Cost()
+	for j, ptx := range pending {
+		if j != i {
+			cost.Add(cost, ptx.Cost())
+		}
+	}
+	balance, err := c.SimulatedBackend.BalanceAt(ctx, sender, nil)
+	if err != nil {
+		return err
+	}
+	if cost.Cmp(balance) > 0 {
+		return rpcError("insufficient funds for gas * price + value")
+	}
+
+	if i == len(pending) {
+		c.pool[sender] = append(pending, tx)
+	} else {
+		pending[i] = tx
+	}
+	return nil
+}
+
+//Pending returns the pending txs of account, in nonce order
+func (c *Chain) Pending(account common.Address) []*types.Transaction {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return append([]*types.Transaction(nil), c.pool[account]...)
+}
+
+//Commit mines the pending txs in a new block
+func (c *Chain) Commit() {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	for sender, pending := range c.pool {
+		for _, tx := range pending {
+			c.SimulatedBackend.SendTransaction(context.Background(), tx)
+		}
+		delete(c.pool, sender)
+	}
+	c.SimulatedBackend.Commit()
+}
+
+//Rollback drops the pending txs
+func (c *Chain) Rollback() {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.pool = make(map[common.Address][]*types.Transaction)
+	c.SimulatedBackend.Rollback()
+}
+
+//signer recovers the sender with EIP155 and caches it for the homestead signer too,
+//the SimulatedBackend of this go-ethereum version checks the sender of the txs with the homestead signer
+type signer struct {
+	types.EIP155Signer
+}
+
+func (s signer) Equal(s2 types.Signer) bool {
+	if _, ok := s2.(types.HomesteadSigner); ok {
+		return true
+	}
+	return s.EIP155Signer.Equal(s2)
+}
+
+//rpcError is an error answered by the node, it implements rpc.Error like the errors of a json-rpc client
+type rpcError string
+
+func (e rpcError) Error() string {
+	return string(e)
+}
+
+func (e rpcError) ErrorCode() int {
+	return -32000
+}
@@ -2,84 +2,106 @@ package eth
 
 import (
 	"context"
-	"log"
 	"math"
 	"math/big"
 
 	"github.com/QOSGroup/litewallet/litewallet/eth/contracts_erc20"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
-	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/ethclient"
 )
 
+//Balance is an account balance in the smallest unit of the asset
+type Balance struct {
+	Amount   *big.Int `json:"amount"`
+	Decimals int      `json:"decimals"`
+	Symbol   string   `json:"symbol"`
+}
+
+//String formats the balance in whole units followed by the symbol, e.g. 1.5ETH
+func (b *Balance) String() string {
+	fbalance := new(big.Float)
+	fbalance.SetString(b.Amount.String())
+	value := new(big.Float).Quo(fbalance, big.NewFloat(math.Pow10(b.Decimals)))
+	return value.String() + b.Symbol
+}
+
 func GetAccount(node, addr string) string {
+	balance, err := GetBalance(context.Background(), node, addr)
+	if err != nil {
+		return err.Error()
+	}
+	return balance.String()
+}
+
+//GetBalance returns the ETH balance of addr at the latest block
+func GetBalance(ctx context.Context, node, addr string) (*Balance, error) {
 	//setup the client, here use the infura own project "eth_wallet" node="https://kovan.infura.io/v3/ef4fee2bd9954c6c8303854e0dce1ffe"
-	client, err := ethclient.Dial(node)
+	client, err := dial(ctx, node)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
+	defer client.Close()
 
 	//convert the addr string to common.Address type
-	address := common.HexToAddress(addr)
-
-	//get the latest block header
-	header, err := client.HeaderByNumber(context.Background(), nil)
+	address, err := hexAddress(addr)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
-	blockNumber := big.NewInt(header.Number.Int64())
 
-	balance, err := client.BalanceAt(context.Background(), address, blockNumber)
+	//a nil block number is the latest block
+	balance, err := client.BalanceAt(ctx, address, nil)
 	if err != nil {
-		log.Fatal(err)
+		return nil, nodeError(err)
 	}
-
-	fbalance := new(big.Float)
-	fbalance.SetString(balance.String())
-	ethValue := new(big.Float).Quo(fbalance, big.NewFloat(math.Pow10(18)))
-
-	accountStr := ethValue.String() + "ETH"
-	return accountStr
+	return &Balance{Amount: balance, Decimals: 18, Symbol: "ETH"}, nil
 }
 
 func GetAccountERC20(node, addr, tokenAddr string) string {
+	balance, err := GetTokenBalance(context.Background(), node, addr, tokenAddr)
+	if err != nil {
+		return err.Error()
+	}
+	return balance.String()
+}
+
+//GetTokenBalance returns the ERC20 balance of addr on the token contract at tokenAddr
+func GetTokenBalance(ctx context.Context, node, addr, tokenAddr string) (*Balance, error) {
 	//setup the client, here use the infura own project "eth_wallet" node="https://kovan.infura.io/v3/ef4fee2bd9954c6c8303854e0dce1ffe"
-	client, err := ethclient.Dial(node)
+	client, err := dial(ctx, node)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
+	defer client.Close()
 
 	//ERC20 Token QT Address
-	tokenAddress := common.HexToAddress(tokenAddr)
+	tokenAddress, err := hexAddress(tokenAddr)
+	if err != nil {
+		return nil, err
+	}
 	instance, err := contracts_erc20.NewContractsErc20(tokenAddress, client)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
+	opts := &bind.CallOpts{Context: ctx}
 	//convert the addr string to common.Address type
-	address := common.HexToAddress(addr)
+	address, err := hexAddress(addr)
+	if err != nil {
+		return nil, err
+	}
 	//Enter smart contract querying
-	balance, err := instance.BalanceOf(&bind.CallOpts{}, address)
+	balance, err := instance.BalanceOf(opts, address)
 	if err != nil {
-		log.Fatal(err)
+		return nil, nodeError(err)
 	}
 
 	//details of the token in ERC20 standards: including symbol and decimals
-	symbol, err := instance.Symbol(&bind.CallOpts{})
+	symbol, err := instance.Symbol(opts)
 	if err != nil {
 		symbol = ""
 	}
 
-	decimals, err := instance.Decimals(&bind.CallOpts{})
+	decimals, err := instance.Decimals(opts)
 	if err != nil {
-		log.Fatal(err)
+		return nil, nodeError(err)
 	}
-	digit := int(decimals)
-
-	//format the output in digit
-	fbalance := new(big.Float)
-	fbalance.SetString(balance.String())
-	ethValue := new(big.Float).Quo(fbalance, big.NewFloat(math.Pow10(digit)))
-
-	accountStr := ethValue.String() + symbol
-	return accountStr
+	return &Balance{Amount: balance, Decimals: int(decimals), Symbol: symbol}, nil
 }
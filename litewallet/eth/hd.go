@@ -0,0 +1,135 @@
+package eth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+
+	"github.com/QOSGroup/litewallet/litewallet/errcode"
+	"github.com/QOSGroup/litewallet/litewallet/slim/tendermint/crypto/funcInlocal/bip39local"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcutil/hdkeychain"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	//DefaultHDPath is the path of the first account, m / purpose' / coin_type' / account' / change / address_index
+	DefaultHDPath = "m/44'/60'/0'/0/0"
+	//accountsBasePath is the parent of the accounts of a mnemonic, as MetaMask derives them
+	accountsBasePath = "m/44'/60'/0'/0"
+	//maxDerive is the most addresses one DeriveAccounts call derives
+	maxDerive = 100
+)
+
+//DerivedAccount is an account of a mnemonic, the balance is only set when a node is given
+type DerivedAccount struct {
+	Index   uint32   `json:"index"`
+	Path    string   `json:"path"`
+	Address string   `json:"address"`
+	Balance *Balance `json:"balance,omitempty"`
+}
+
+//AccountPath returns the path of the account index of a mnemonic, AccountPath(0) is DefaultHDPath
+func AccountPath(index uint32) string {
+	return fmt.Sprintf("%s/%d", accountsBasePath, index)
+}
+
+//CreateKeyAt derives the account index of the mnemonic and stores it encrypted under name
+func CreateKeyAt(rootDir, name, password, mnemonic string, index uint32) (*KeyOutput, error) {
+	if index >= hdkeychain.HardenedKeyStart {
+		return nil, errcode.Errorf(errcode.CodeInvalidInput, "account index %d out of range", index)
+	}
+	return CreateKeyWithPath(rootDir, name, password, mnemonic, AccountPath(index))
+}
+
+//DeriveAccounts returns the count accounts of the mnemonic from the index start, with their balances
+//when node is not empty
+func DeriveAccounts(ctx context.Context, node, mnemonic string, start uint32, count int) ([]DerivedAccount, error) {
+	if count <= 0 || count > maxDerive {
+		return nil, errcode.Errorf(errcode.CodeInvalidInput, "count %d is out of 1 to %d", count, maxDerive)
+	}
+	base, _ := accounts.ParseDerivationPath(accountsBasePath)
+	parent, err := deriveExtendedKey(mnemonic, base)
+	if err != nil {
+		return nil, err
+	}
+	var client Client
+	if node != "" {
+		if client, err = dial(ctx, node); err != nil {
+			return nil, err
+		}
+		defer client.Close()
+	}
+
+	out := make([]DerivedAccount, 0, count)
+	for i := 0; i < count; i++ {
+		index := start + uint32(i)
+		if index >= hdkeychain.HardenedKeyStart {
+			return nil, errcode.Errorf(errcode.CodeInvalidInput, "account index %d out of range", index)
+		}
+		child, err := parent.Child(index)
+		if err != nil {
+			return nil, errcode.Internal(err)
+		}
+		pubKey, err := child.ECPubKey()
+		if err != nil {
+			return nil, errcode.Internal(err)
+		}
+		address := crypto.PubkeyToAddress(*pubKey.ToECDSA())
+		account := DerivedAccount{Index: index, Path: AccountPath(index), Address: address.Hex()}
+		if client != nil {
+			balance, err := client.BalanceAt(ctx, address, nil)
+			if err != nil {
+				return nil, nodeError(err)
+			}
+			account.Balance = &Balance{Amount: balance, Decimals: 18, Symbol: "ETH"}
+		}
+		out = append(out, account)
+	}
+	return out, nil
+}
+
+//deriveKey returns the key at hdPath of the mnemonic and the parsed path, hdPath is absolute or relative
+//to m/44'/60'/0'/0
+func deriveKey(mnemonic, hdPath string) (*ecdsa.PrivateKey, accounts.DerivationPath, error) {
+	dpath, err := accounts.ParseDerivationPath(hdPath)
+	if err != nil {
+		return nil, nil, errcode.InvalidInput(err)
+	}
+	key, err := deriveExtendedKey(mnemonic, dpath)
+	if err != nil {
+		return nil, nil, err
+	}
+	privateKey, err := key.ECPrivKey()
+	if err != nil {
+		return nil, nil, errcode.Internal(err)
+	}
+	return privateKey.ToECDSA(), dpath, nil
+}
+
+//deriveExtendedKey returns the bip32 key at dpath of the mnemonic, without a bip39 passphrase
+func deriveExtendedKey(mnemonic string, dpath accounts.DerivationPath) (*hdkeychain.ExtendedKey, error) {
+	//generate wallet with mnemonic
+	if mnemonic == "" {
+		return nil, errMissingMnemonic()
+	}
+	//convert mnemonic string of any bip39 language to seed byte
+	seed, err := bip39local.MnemonicToSeed(mnemonic, "")
+	if err != nil {
+		return nil, errInvalidMnemonic()
+	}
+
+	//fetch the masterKey for the wallet
+	key, err := hdkeychain.NewMaster(seed, &chaincfg.MainNetParams)
+	if err != nil {
+		return nil, errcode.Internal(err)
+	}
+	for _, n := range dpath {
+		key, err = key.Child(n)
+		if err != nil {
+			return nil, errcode.Internal(err)
+		}
+	}
+	return key, nil
+}
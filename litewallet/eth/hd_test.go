@@ -0,0 +1,86 @@
+package eth
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/QOSGroup/litewallet/litewallet/errcode"
+)
+
+func TestDeriveAccounts(t *testing.T) {
+	//the address of MetaMask for the mnemonic
+	accounts, err := DeriveAccounts(context.Background(), "", "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about", 0, 1)
+	if err != nil || accounts[0].Address != "0x9858EfFD232B4033E47d90003D41EC34EcaEda94" || accounts[0].Path != DefaultHDPath {
+		t.Fatalf("got %+v, %v", accounts, err)
+	}
+
+	accounts, err = DeriveAccounts(context.Background(), "", seed, 3, 2)
+	if err != nil || len(accounts) != 2 || accounts[0].Index != 3 || accounts[1].Path != "m/44'/60'/0'/0/4" || accounts[0].Balance != nil {
+		t.Fatalf("got %+v, %v, want the accounts 3 and 4 without balances", accounts, err)
+	}
+	if _, err := DeriveAccounts(context.Background(), "", seed, 0, maxDerive+1); errcode.Code(err) != errcode.CodeInvalidInput {
+		t.Errorf("got %v, want an invalid input error", err)
+	}
+	if _, err := DeriveAccounts(context.Background(), "", "monster soap", 0, 1); errcode.Code(err) != errcode.CodeInvalidInput {
+		t.Errorf("got %v for an invalid mnemonic, want an invalid input error", err)
+	}
+}
+
+func TestDeriveAccountsBalances(t *testing.T) {
+	chain := newTestChain(t)
+	defer chain.Close()
+
+	accounts, err := DeriveAccounts(context.Background(), node, seed, 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if accounts[0].Address != chain.from.Hex() || accounts[0].Balance.String() != "10ETH" || accounts[1].Balance.String() != "0ETH" {
+		t.Errorf("got %+v, %+v, want the key of the chain with 10ETH first", accounts[0], accounts[1])
+	}
+}
+
+func TestCreateKeyWithPath(t *testing.T) {
+	rootDir, err := ioutil.TempDir("", "ethkeys")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(rootDir)
+
+	accounts, err := DeriveAccounts(context.Background(), "", seed, 0, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := CreateKey(rootDir, "first", password, seed)
+	if err != nil || first.Address != accounts[0].Address || first.Path != DefaultHDPath {
+		t.Fatalf("got %+v, %v, want %+v", first, err, accounts[0])
+	}
+	third, err := CreateKeyAt(rootDir, "third", password, seed, 2)
+	if err != nil || third.Address != accounts[2].Address {
+		t.Fatalf("got %+v, %v, want %+v", third, err, accounts[2])
+	}
+	//a relative path is under m/44'/60'/0'/0
+	second, err := RestoreKeyWithPath(rootDir, "second", password, seed, "1")
+	if err != nil || second.Address != accounts[1].Address || second.Path != accounts[1].Path {
+		t.Fatalf("got %+v, %v, want %+v", second, err, accounts[1])
+	}
+	if _, err := CreateKeyWithPath(rootDir, "bad", password, seed, "m/44'/x"); errcode.Code(err) != errcode.CodeInvalidInput {
+		t.Errorf("got %v for a bad path, want an invalid input error", err)
+	}
+
+	//the paths are kept to derive the same keys again
+	kos, err := ListKeys(rootDir)
+	if err != nil || len(kos) != 3 {
+		t.Fatalf("got %+v, %v", kos, err)
+	}
+	for _, ko := range kos {
+		found := false
+		for _, account := range accounts {
+			found = found || account.Path == ko.Path && account.Address == ko.Address
+		}
+		if !found {
+			t.Errorf("got %+v, want one of the derived accounts", ko)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package eth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"strings"
+
+	"github.com/QOSGroup/litewallet/litewallet/errcode"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pborman/uuid"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+//The keys are moved in and out of litewallet as Web3 Secret Storage files, the keystore v3 json of geth
+//and MetaMask. An imported key is stored encrypted with the local password as the created ones are.
+
+const (
+	KDFScrypt = "scrypt"
+	KDFPBKDF2 = "pbkdf2"
+
+	keystoreVersion  = 3
+	pbkdf2Iterations = 262144
+	pbkdf2DKLen      = 32
+)
+
+//ScryptN and ScryptP are the scrypt parameters of the exported keystores, the geth standard ones need
+//256MB, keystore.LightScryptN and keystore.LightScryptP need 4MB on devices short of memory
+var (
+	ScryptN = keystore.StandardScryptN
+	ScryptP = keystore.StandardScryptP
+)
+
+//keystoreV3 is the Web3 Secret Storage json
+type keystoreV3 struct {
+	Address string              `json:"address"`
+	Crypto  keystore.CryptoJSON `json:"crypto"`
+	Id      string              `json:"id"`
+	Version int                 `json:"version"`
+}
+
+//ImportKeystore decrypts the keystore v3 json with keystorePassword and stores the key under name
+func ImportKeystore(rootDir, name, password, keystoreJSON, keystorePassword string) (*KeyOutput, error) {
+	var ks keystoreV3
+	//the files saved by some editors start with a utf-8 byte order mark
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(keystoreJSON, "\ufeff")), &ks); err != nil {
+		return nil, errcode.InvalidInput(err)
+	}
+	if ks.Version != keystoreVersion {
+		return nil, errcode.Errorf(errcode.CodeInvalidInput, "keystore version %d not supported", ks.Version)
+	}
+	if err := checkKDFParams(ks.Crypto); err != nil {
+		return nil, err
+	}
+	keyBytes, err := keystore.DecryptDataV3(ks.Crypto, keystorePassword)
+	if err == keystore.ErrDecrypt {
+		return nil, errcode.WrongPassword(err)
+	} else if err != nil {
+		return nil, errcode.InvalidInput(err)
+	}
+	//geth wrote the keys with leading zeros stripped
+	if len(keyBytes) < 32 {
+		keyBytes = append(make([]byte, 32-len(keyBytes)), keyBytes...)
+	}
+	privateKey, err := crypto.ToECDSA(keyBytes)
+	if err != nil {
+		return nil, errcode.InvalidInput(err)
+	}
+	if ks.Address != "" && common.HexToAddress(ks.Address) != crypto.PubkeyToAddress(privateKey.PublicKey) {
+		return nil, errcode.Errorf(errcode.CodeInvalidInput, "the key is not the one of address %s", ks.Address)
+	}
+	return storeKey(rootDir, name, password, privateKey, "", false)
+}
+
+//ImportPrivateKey stores the hex private key, with or without 0x, under name
+func ImportPrivateKey(rootDir, name, password, privKeyHex string) (*KeyOutput, error) {
+	privateKey, err := crypto.HexToECDSA(strings.TrimPrefix(strings.TrimSpace(privKeyHex), "0x"))
+	if err != nil {
+		return nil, errcode.InvalidInput(err)
+	}
+	return storeKey(rootDir, name, password, privateKey, "", false)
+}
+
+//ExportKeystore returns the key name as keystore v3 json encrypted with keystorePassword, kdf is
+//KDFScrypt, the default, or KDFPBKDF2
+func ExportKeystore(rootDir, name, password, keystorePassword, kdf string) (string, error) {
+	privateKey, err := FetchtoSign(rootDir, name, password)
+	if err != nil {
+		return "", err
+	}
+	keyBytes := crypto.FromECDSA(privateKey)
+	var cj keystore.CryptoJSON
+	switch kdf {
+	case "", KDFScrypt:
+		cj, err = keystore.EncryptDataV3(keyBytes, []byte(keystorePassword), ScryptN, ScryptP)
+	case KDFPBKDF2:
+		cj, err = encryptDataPBKDF2(keyBytes, []byte(keystorePassword))
+	default:
+		return "", errcode.Errorf(errcode.CodeInvalidInput, "unsupported KDF %s", kdf)
+	}
+	if err != nil {
+		return "", errcode.Internal(err)
+	}
+	ks := keystoreV3{
+		Address: hex.EncodeToString(crypto.PubkeyToAddress(privateKey.PublicKey).Bytes()),
+		Crypto:  cj,
+		Id:      uuid.NewRandom().String(),
+		Version: keystoreVersion,
+	}
+	bz, err := json.Marshal(ks)
+	if err != nil {
+		return "", errcode.Internal(err)
+	}
+	return string(bz), nil
+}
+
+//the bounds of the kdf params of the imported keystores, so that a file can not make the key derivation
+//take all the memory or time of the device
+const (
+	minKDFDKLen  = 32
+	maxKDFDKLen  = 64
+	maxScryptN   = 1 << 20
+	maxScryptMem = 256 << 20
+	maxScryptP   = 16
+	maxPBKDF2Itr = 1 << 24
+)
+
+//checkKDFParams checks the params the keystore package asserts without checking, and their bounds
+func checkKDFParams(cj keystore.CryptoJSON) error {
+	if _, ok := cj.KDFParams["salt"].(string); !ok {
+		return errcode.New(errcode.CodeInvalidInput, "keystore without a kdf salt")
+	}
+	if _, err := kdfParam(cj, "dklen", minKDFDKLen, maxKDFDKLen); err != nil {
+		return err
+	}
+	switch cj.KDF {
+	case KDFScrypt:
+		n, err := kdfParam(cj, "n", 2, maxScryptN)
+		if err != nil {
+			return err
+		}
+		if n&(n-1) != 0 {
+			return errcode.Errorf(errcode.CodeInvalidInput, "scrypt n %d is not a power of 2", n)
+		}
+		//scrypt takes 128*n*r bytes, the bound of r is divided out so that the product does not overflow
+		if _, err := kdfParam(cj, "r", 1, maxScryptMem/(128*n)); err != nil {
+			return err
+		}
+		if _, err := kdfParam(cj, "p", 1, maxScryptP); err != nil {
+			return err
+		}
+	case KDFPBKDF2:
+		if _, ok := cj.KDFParams["prf"].(string); !ok {
+			return errcode.New(errcode.CodeInvalidInput, "keystore without a pbkdf2 prf")
+		}
+		if _, err := kdfParam(cj, "c", 1, maxPBKDF2Itr); err != nil {
+			return err
+		}
+	default:
+		return errcode.Errorf(errcode.CodeInvalidInput, "unsupported KDF %s", cj.KDF)
+	}
+	return nil
+}
+
+//kdfParam returns the integer kdf param name, the json numbers are float64
+func kdfParam(cj keystore.CryptoJSON, name string, min, max int) (int, error) {
+	f, ok := cj.KDFParams[name].(float64)
+	if !ok || f != math.Trunc(f) {
+		return 0, errcode.Errorf(errcode.CodeInvalidInput, "keystore without an integer kdf %s", name)
+	}
+	if f < float64(min) || f > float64(max) {
+		return 0, errcode.Errorf(errcode.CodeInvalidInput, "kdf %s %v out of [%d, %d]", name, f, min, max)
+	}
+	return int(f), nil
+}
+
+//encryptDataPBKDF2 is keystore.EncryptDataV3 with a pbkdf2-sha256 key instead of a scrypt one
+func encryptDataPBKDF2(data, auth []byte) (keystore.CryptoJSON, error) {
+	salt := make([]byte, 32)
+	iv := make([]byte, aes.BlockSize)
+	if _, err := rand.Read(salt); err != nil {
+		return keystore.CryptoJSON{}, err
+	}
+	if _, err := rand.Read(iv); err != nil {
+		return keystore.CryptoJSON{}, err
+	}
+	derivedKey := pbkdf2.Key(auth, salt, pbkdf2Iterations, pbkdf2DKLen, sha256.New)
+	block, err := aes.NewCipher(derivedKey[:16])
+	if err != nil {
+		return keystore.CryptoJSON{}, err
+	}
+	cipherText := make([]byte, len(data))
+	cipher.NewCTR(block, iv).XORKeyStream(cipherText, data)
+
+	cj := keystore.CryptoJSON{
+		Cipher:     "aes-128-ctr",
+		CipherText: hex.EncodeToString(cipherText),
+		KDF:        KDFPBKDF2,
+		KDFParams: map[string]interface{}{
+			"c":     pbkdf2Iterations,
+			"dklen": pbkdf2DKLen,
+			"prf":   "hmac-sha256",
+			"salt":  hex.EncodeToString(salt),
+		},
+		MAC: hex.EncodeToString(crypto.Keccak256(derivedKey[16:32], cipherText)),
+	}
+	cj.CipherParams.IV = hex.EncodeToString(iv)
+	return cj, nil
+}
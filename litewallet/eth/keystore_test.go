@@ -0,0 +1,126 @@
+package eth
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/QOSGroup/litewallet/litewallet/errcode"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+//the test vectors of the Web3 Secret Storage definition, the key is 7a28b5ba57c53603b0b07b56bba752f7784bf506fa95edc395f5cf6c7514fe9d
+const (
+	testKeystoreScrypt = `{"crypto":{"cipher":"aes-128-ctr","cipherparams":{"iv":"83dbcc02d8ccb40e466191a123791e0e"},"ciphertext":"d172bf743a674da9cdad04534d56926ef8358534d458fffccd4e6ad2fbde479c","kdf":"scrypt","kdfparams":{"dklen":32,"n":262144,"r":1,"p":8,"salt":"ab0c7876052600dd703518d6fc3fe8984592145b591fc8fb5c6d43190334ba19"},"mac":"2103ac29920d71da29f15d75b4a16dbe95cfd7ff8faea1056c33131d846e3097"},"id":"3198bc9c-6672-5ab3-d995-4942343ae5b6","version":3}`
+	testKeystorePBKDF2 = `{"crypto":{"cipher":"aes-128-ctr","cipherparams":{"iv":"6087dab2f9fdbbfaddc31a909735c1e6"},"ciphertext":"5318b4d5bcd28de64ee5559e671353e16f075ecae9f99c7a79a38af5f869aa46","kdf":"pbkdf2","kdfparams":{"c":262144,"dklen":32,"prf":"hmac-sha256","salt":"ae3cd4e7013836a3df6bd7241b12db061dbe2c6785853cce422d148a624ce0bd"},"mac":"517ead924a9d0dc3124507e3393d175ce3ff7c1e96529c6c555ce9e51205e9b2"},"id":"3198bc9c-6672-5ab3-d995-4942343ae5b6","version":3}`
+	testKeystoreKey    = "7a28b5ba57c53603b0b07b56bba752f7784bf506fa95edc395f5cf6c7514fe9d"
+)
+
+func TestImportKeystore(t *testing.T) {
+	rootDir, err := ioutil.TempDir("", "ethkeys")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(rootDir)
+	key, _ := crypto.HexToECDSA(testKeystoreKey)
+	address := crypto.PubkeyToAddress(key.PublicKey).Hex()
+
+	//an address is stored under one name, each file is imported into a store of its own
+	for name, ks := range map[string]string{"scrypt": testKeystoreScrypt, "pbkdf2": "\ufeff" + testKeystorePBKDF2} {
+		ko, err := ImportKeystore(filepath.Join(rootDir, name), name, "wm131421", ks, "testpassword")
+		if err != nil || ko.Address != address {
+			t.Fatalf("got %+v, %v importing the %s keystore, want %s", ko, err, name, address)
+		}
+		if _, err := ImportKeystore(rootDir, name, "wm131421", ks, "wrong"); errcode.Code(err) != errcode.CodeWrongPassword {
+			t.Errorf("got %v, want a wrong password error", err)
+		}
+	}
+	//the address of the file has to be the one of the key
+	other := strings.Replace(testKeystorePBKDF2, `"crypto"`, `"address":"7ef5a6135f1fd6a02593eedc869c6d41d934aef8","crypto"`, 1)
+	if _, err := ImportKeystore(rootDir, "other", "wm131421", other, "testpassword"); errcode.Code(err) != errcode.CodeInvalidInput {
+		t.Errorf("got %v for another address, want an invalid input error", err)
+	}
+	for _, ks := range []string{"not json", strings.Replace(testKeystorePBKDF2, `"kdf":"pbkdf2"`, `"kdf":"argon2"`, 1), strings.Replace(testKeystorePBKDF2, `"version":3`, `"version":2`, 1)} {
+		if _, err := ImportKeystore(rootDir, "bad", "wm131421", ks, "testpassword"); errcode.Code(err) != errcode.CodeInvalidInput {
+			t.Errorf("got %v for %s, want an invalid input error", err, ks)
+		}
+	}
+	//the kdf params are integers within bounds, a missing one is not a panic
+	for _, edit := range [][2]string{
+		{`"dklen":32,"n"`, `"n"`},
+		{`"dklen":32`, `"dklen":16`},
+		{`"n":262144`, `"n":"262144"`},
+		{`"n":262144`, `"n":262143`},
+		{`"n":262144`, `"n":2097152`},
+		{`"r":1`, `"r":1.5`},
+		{`"p":8`, `"p":0`},
+		{`"p":8`, `"p":17`},
+		{`"r":1`, `"r":9`},
+		{`"n":262144,"r":1`, `"n":1048576,"r":64`},
+	} {
+		ks := strings.Replace(testKeystoreScrypt, edit[0], edit[1], 1)
+		if _, err := ImportKeystore(rootDir, "bad", "wm131421", ks, "testpassword"); errcode.Code(err) != errcode.CodeInvalidInput {
+			t.Errorf("got %v for %s, want an invalid input error", err, edit[1])
+		}
+	}
+	for _, edit := range [][2]string{{`"c":262144,`, ``}, {`"c":262144`, `"c":-1`}, {`"c":262144`, `"c":1e9`}} {
+		ks := strings.Replace(testKeystorePBKDF2, edit[0], edit[1], 1)
+		if _, err := ImportKeystore(rootDir, "bad", "wm131421", ks, "testpassword"); errcode.Code(err) != errcode.CodeInvalidInput {
+			t.Errorf("got %v for %q, want an invalid input error", err, edit[1])
+		}
+	}
+
+	ko, err := ImportPrivateKey(rootDir, "hex", "wm131421", "0x"+testKeystoreKey)
+	if err != nil || ko.Address != address {
+		t.Fatalf("got %+v, %v, want %s", ko, err, address)
+	}
+	if _, err := ImportKeystore(rootDir, "scrypt", "wm131421", testKeystoreScrypt, "testpassword"); errcode.Code(err) != errcode.CodeInvalidInput {
+		t.Errorf("got %v for the address of hex, want an invalid input error", err)
+	}
+	ko, err = ImportPrivateKey(filepath.Join(rootDir, "hex"), "hex", "wm131421", "0x"+testKeystoreKey)
+	if err != nil || ko.Address != address {
+		t.Fatalf("got %+v, %v, want %s", ko, err, address)
+	}
+	if _, err := ImportPrivateKey(rootDir, "hex", "wm131421", "0x1234"); errcode.Code(err) != errcode.CodeInvalidInput {
+		t.Errorf("got %v for a short key, want an invalid input error", err)
+	}
+}
+
+func TestExportKeystore(t *testing.T) {
+	rootDir, err := ioutil.TempDir("", "ethkeys")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(rootDir)
+	defer func(n, p int) { ScryptN, ScryptP = n, p }(ScryptN, ScryptP)
+	ScryptN, ScryptP = keystore.LightScryptN, keystore.LightScryptP
+
+	ko, err := ImportPrivateKey(rootDir, "alice", "wm131421", testKeystoreKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ExportKeystore(rootDir, "alice", "wrong", "export", ""); errcode.Code(err) != errcode.CodeWrongPassword {
+		t.Errorf("got %v, want a wrong password error", err)
+	}
+	if _, err := ExportKeystore(rootDir, "alice", "wm131421", "export", "argon2"); errcode.Code(err) != errcode.CodeInvalidInput {
+		t.Errorf("got %v, want an invalid input error", err)
+	}
+	for _, kdf := range []string{KDFScrypt, KDFPBKDF2} {
+		ks, err := ExportKeystore(rootDir, "alice", "wm131421", "export", kdf)
+		if err != nil || !strings.Contains(ks, `"kdf":"`+kdf+`"`) {
+			t.Fatalf("got %s, %v, want a %s keystore", ks, err, kdf)
+		}
+		//geth reads it
+		key, err := keystore.DecryptKey([]byte(ks), "export")
+		if err != nil || key.Address.Hex() != ko.Address {
+			t.Fatalf("got %v decrypting the %s keystore, want %s", err, kdf, ko.Address)
+		}
+		imported, err := ImportKeystore(filepath.Join(rootDir, kdf), "alice", "newpass", ks, "export")
+		if err != nil || imported.Address != ko.Address {
+			t.Errorf("got %+v, %v importing the export, want %s", imported, err, ko.Address)
+		}
+	}
+}
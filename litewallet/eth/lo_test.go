@@ -1,14 +1,23 @@
 package eth
 
 import (
+	"context"
 	"crypto/ecdsa"
 	"fmt"
+	"github.com/QOSGroup/litewallet/litewallet/errcode"
+	"github.com/QOSGroup/litewallet/litewallet/keycrypt"
+	"github.com/QOSGroup/litewallet/litewallet/signer"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/crypto"
 	"golang.org/x/crypto/sha3"
+	"io/ioutil"
 	"log"
+	"os"
 	"os/user"
+	"path/filepath"
 	"testing"
+
+	dbm "github.com/tendermint/tendermint/libs/db"
 )
 
 func TestAddress(t *testing.T) {
@@ -80,4 +89,217 @@ func TestSigVerify(t *testing.T) {
 	password := "wm131421"
 	data2sign := []byte("hello")
 	SigVerify(rootDir,name,password,data2sign)
-}
\ No newline at end of file
+}
+func TestKeyErrorCodes(t *testing.T) {
+	rootDir, err := ioutil.TempDir("", "ethkeys")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(rootDir)
+	seed := "monster soap pipe grief tourist marine turkey scatter because fade actual robust"
+
+	if _, err := CreateKey(rootDir, "", "wm131421", seed); errcode.Code(err) != errcode.CodeInvalidInput {
+		t.Errorf("missing name: %v", err)
+	}
+	if _, err := CreateKey(rootDir, "eth5", "wm131421", "monster soap"); errcode.Code(err) != errcode.CodeInvalidInput {
+		t.Errorf("invalid mnemonic: %v", err)
+	}
+	if _, err := CreateKey(rootDir, "eth5", "wm131421", seed); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := FetchtoSign(rootDir, "eth6", "wm131421"); errcode.Code(err) != errcode.CodeKeyNotFound {
+		t.Errorf("unknown key: %v", err)
+	}
+	if _, err := FetchtoSign(rootDir, "eth5", "wrong"); errcode.Code(err) != errcode.CodeWrongPassword {
+		t.Errorf("wrong password: %v", err)
+	}
+	if _, err := SendETH(context.Background(), rootDir, "http://127.0.0.1:1", "eth5", "wm131421", "0x1B37", "20", "1", 21000); errcode.Code(err) != errcode.CodeInvalidInput {
+		t.Errorf("invalid address: %v", err)
+	}
+	if _, err := SendETH(context.Background(), rootDir, "http://127.0.0.1:1", "eth5", "wm131421", "0x1B37AB8d737B1776d3cC082D246Ee89Ed9693cD2", "20", "1", 21000); errcode.Code(err) != errcode.CodeNetwork {
+		t.Errorf("unreachable node: %v", err)
+	}
+}
+
+func TestDeleteKeyAndChangePassword(t *testing.T) {
+	rootDir, err := ioutil.TempDir("", "ethkeys")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(rootDir)
+	seed := "monster soap pipe grief tourist marine turkey scatter because fade actual robust"
+	if _, err := CreateKey(rootDir, "eth7", "wm131421", seed); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ChangePassword(rootDir, "eth7", "wrong", "wm131422"); errcode.Code(err) != errcode.CodeWrongPassword {
+		t.Errorf("wrong password: %v", err)
+	}
+	if err := ChangePassword(rootDir, "eth7", "wm131421", "wm131422"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := FetchtoSign(rootDir, "eth7", "wm131422"); err != nil {
+		t.Errorf("got %v, want the key with the new password", err)
+	}
+	if err := DeleteKey(rootDir, "eth7", "wm131421"); errcode.Code(err) != errcode.CodeWrongPassword {
+		t.Errorf("wrong password: %v", err)
+	}
+	if err := DeleteKey(rootDir, "eth7", "wm131422"); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := HasKey(rootDir, "eth7"); ok {
+		t.Error("the key eth7 should be deleted")
+	}
+	if err := DeleteKey(rootDir, "eth7", "wm131422"); errcode.Code(err) != errcode.CodeKeyNotFound {
+		t.Errorf("unknown key: %v", err)
+	}
+}
+
+func TestKeyConflicts(t *testing.T) {
+	rootDir, err := ioutil.TempDir("", "ethkeys")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(rootDir)
+	seed := "monster soap pipe grief tourist marine turkey scatter because fade actual robust"
+	first, err := CreateKey(rootDir, "eth8", "wm131421", seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := CreateKeyWithPath(rootDir, "eth8", "wm131421", seed, "1"); errcode.Code(err) != errcode.CodeInvalidInput {
+		t.Errorf("name conflict: %v", err)
+	}
+	if _, err := CreateKey(rootDir, "eth9", "wm131421", seed); errcode.Code(err) != errcode.CodeInvalidInput {
+		t.Errorf("address conflict: %v", err)
+	}
+	//the replaced key takes its address record along
+	second, err := ReplaceKeyWithPath(rootDir, "eth8", "wm131422", seed, "1")
+	if err != nil || second.Address == first.Address {
+		t.Fatalf("got %+v, %v, want the key at 1", second, err)
+	}
+	if _, err := FetchtoSign(rootDir, "eth8", "wm131422"); err != nil {
+		t.Errorf("got %v, want the replaced key", err)
+	}
+	if _, err := CreateKey(rootDir, "eth9", "wm131421", seed); err != nil {
+		t.Errorf("got %v, want the address of the replaced key free", err)
+	}
+	if issues, err := CheckStore(rootDir, false); err != nil || len(issues) != 0 {
+		t.Errorf("got %+v, %v, want a consistent store", issues, err)
+	}
+}
+
+func TestKeyUpgrade(t *testing.T) {
+	rootDir, err := ioutil.TempDir("", "ethkeys")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(rootDir)
+	defer keycrypt.SetCurrent(keycrypt.Current())
+	if err := keycrypt.SetCurrent(keycrypt.Params{KDF: keycrypt.KDFScrypt, N: 1 << 10, R: 8, P: 1}); err != nil {
+		t.Fatal(err)
+	}
+	seed := "monster soap pipe grief tourist marine turkey scatter because fade actual robust"
+	if _, err := CreateKey(rootDir, "eth10", "wm131421", seed); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := CreateKeyWithPath(rootDir, "eth11", "wm131421", seed, "1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := keycrypt.SetCurrent(keycrypt.Params{KDF: keycrypt.KDFArgon2id, Time: 1, Memory: 64, Threads: 1}); err != nil {
+		t.Fatal(err)
+	}
+	needsUpgrade := func(name string) bool {
+		db, _ := dbm.NewGoLevelDB("keys", filepath.Join(rootDir, "ethkeys"))
+		defer db.Close()
+		info, _ := getInfo(db, name)
+		upgrade, err := keycrypt.NeedsUpgrade(info.PrivKeyArmor)
+		return upgrade || err != nil
+	}
+
+	//the key is upgraded on its unlock
+	if _, err := FetchtoSign(rootDir, "eth10", "wm131421"); err != nil || needsUpgrade("eth10") {
+		t.Errorf("got %v, want the key eth10 in the current envelope", err)
+	}
+	if upgraded, err := UpgradeKey(rootDir, "eth10", "wm131421"); err != nil || upgraded {
+		t.Errorf("got %v, %v, want the key eth10 current", upgraded, err)
+	}
+	if _, err := UpgradeKey(rootDir, "eth11", "wrong"); errcode.Code(err) != errcode.CodeWrongPassword {
+		t.Errorf("wrong password: %v", err)
+	}
+	if upgraded, err := UpgradeKey(rootDir, "eth11", "wm131421"); err != nil || !upgraded || needsUpgrade("eth11") {
+		t.Errorf("got %v, %v, want the key eth11 upgraded", upgraded, err)
+	}
+	if _, err := FetchtoSign(rootDir, "eth11", "wm131421"); err != nil {
+		t.Errorf("got %v, want the upgraded key", err)
+	}
+}
+
+func TestSignMessage(t *testing.T) {
+	rootDir, err := ioutil.TempDir("", "ethkeys")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(rootDir)
+	seed := "monster soap pipe grief tourist marine turkey scatter because fade actual robust"
+	key, err := CreateKey(rootDir, "eth12", "wm131421", seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg := []byte("login to litewallet, nonce 42")
+	sig, err := SignMessage(rootDir, "eth12", "wm131421", msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := sig[64]; v != 27 && v != 28 {
+		t.Errorf("got V %d, want 27 or 28", v)
+	}
+	addr, err := RecoverMessageSigner(msg, sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if addr.Hex() != key.Address {
+		t.Errorf("recovered %s, want %s", addr.Hex(), key.Address)
+	}
+	if ok, err := VerifyMessage(key.Address, msg, sig); err != nil || !ok {
+		t.Errorf("got %v %v, want the signature verified", ok, err)
+	}
+	if ok, err := VerifyMessage(key.Address, []byte("login to litewallet, nonce 43"), sig); err != nil || ok {
+		t.Errorf("got %v %v, want another message rejected", ok, err)
+	}
+	//the signatures with V 0 or 1 are the same
+	sig[64] -= 27
+	if ok, err := VerifyMessage(key.Address, msg, sig); err != nil || !ok {
+		t.Errorf("got %v %v, want the signature with V %d verified", ok, err, sig[64])
+	}
+	if _, err := VerifyMessage(key.Address, msg, sig[:64]); errcode.Code(err) != errcode.CodeInvalidInput {
+		t.Errorf("short signature: %v", err)
+	}
+	if _, err := SignMessage(rootDir, "eth12", "wrong", msg); errcode.Code(err) != errcode.CodeWrongPassword {
+		t.Errorf("wrong password: %v", err)
+	}
+	if err := SigVerify(rootDir, "eth12", "wm131421", msg); err != nil {
+		t.Error(err)
+	}
+	//a signer returning a short signature is an error, not a panic
+	privateKey, err := FetchtoSign(rootDir, "eth12", "wm131421")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := SignMessageWithSigner(shortSigner{signer.NewSecp256k1(privateKey)}, msg); errcode.Code(err) != errcode.CodeInternal {
+		t.Errorf("got %v for a short signature, want an internal error", err)
+	}
+}
+
+//shortSigner drops the V of the signatures
+type shortSigner struct {
+	signer.Signer
+}
+
+func (s shortSigner) Sign(msg []byte) ([]byte, error) {
+	sig, err := s.Signer.Sign(msg)
+	if err != nil {
+		return nil, err
+	}
+	return sig[:64], nil
+}
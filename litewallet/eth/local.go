@@ -1,44 +1,38 @@
 package eth
 
 import (
-	"bytes"
 	"crypto/ecdsa"
-	"encoding/hex"
 	"encoding/json"
 	"fmt"
 
-	"github.com/btcsuite/btcd/chaincfg"
-	"github.com/btcsuite/btcutil/hdkeychain"
+	"github.com/QOSGroup/litewallet/litewallet/errcode"
 
-	"log"
 	"path/filepath"
 	"strings"
 
+	"github.com/QOSGroup/litewallet/litewallet/keycrypt"
 	"github.com/cosmos/cosmos-sdk/crypto/keys/keyerror"
-	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/tendermint/crypto/bcrypt"
-	tcrypto "github.com/tendermint/tendermint/crypto"
-	"github.com/tendermint/tendermint/crypto/armor"
-	"github.com/tendermint/tendermint/crypto/xsalsa20symmetric"
-	cmn "github.com/tendermint/tendermint/libs/common"
 	dbm "github.com/tendermint/tendermint/libs/db"
-	"github.com/tyler-smith/go-bip39"
 )
 
 const (
-	blockTypePrivKey        = "TENDERMINT PRIVATE KEY"
-	infoSuffix              = "info"
-	BcryptSecurityParameter = 12
+	blockTypePrivKey = "TENDERMINT PRIVATE KEY"
+	infoSuffix       = "info"
+	addrSuffix       = "addr"
+	//BcryptSecurityParameter is the bcrypt cost of the keys stored before the envelope of keycrypt
+	BcryptSecurityParameter = keycrypt.LegacyBcryptCost
 )
 
-// localInfo is the public information about a locally stored key
+// localInfo is the public information about a locally stored key, the path is the derivation path of
+// the key, empty for the imported keys and the keys stored before the paths were
 type LocalInfo struct {
 	Name         string `json:"name"`
 	PubKey       string `json:"pubkey"`
 	PrivKeyArmor string `json:"privkey"`
 	Address      string `json:"address"`
+	Path         string `json:"path,omitempty"`
 }
 
 type dbKeybase struct {
@@ -52,75 +46,75 @@ type KeyOutput struct {
 	PubKey   string `json:"pubkey"`
 	Mnemonic string `json:"mnemonic,omitempty"`
 	Denom    string `json:"denom"`
+	Path     string `json:"path,omitempty"`
 }
 
 //follow the cosmos hd implementation
 func CreateAccount(rootDir, name, password, mnemonic string) string {
-	if name == "" {
-		err := errMissingName()
-		return err.Error()
-	}
-	if password == "" {
-		err := errMissingPassword()
+	ko, err := CreateKey(rootDir, name, password, mnemonic)
+	if err != nil {
 		return err.Error()
 	}
-	//generate wallet with mnemonic
-	if mnemonic == "" {
-		return fmt.Sprintf("mnemonic is required")
-	}
+	respbyte, _ := json.Marshal(ko)
+	return string(respbyte)
+}
 
-	if !bip39.IsMnemonicValid(mnemonic) {
-		return fmt.Sprintf("mnemonic is invalid")
-	}
-	//convert mnemonic string to seed byte
-	seed := bip39.NewSeed(mnemonic, "")
+//CreateKey derives the key at DefaultHDPath from the mnemonic and stores it encrypted under name
+func CreateKey(rootDir, name, password, mnemonic string) (*KeyOutput, error) {
+	return CreateKeyWithPath(rootDir, name, password, mnemonic, DefaultHDPath)
+}
+
+//CreateKeyWithPath derives the key at hdPath from the mnemonic and stores it encrypted under name
+func CreateKeyWithPath(rootDir, name, password, mnemonic, hdPath string) (*KeyOutput, error) {
+	return createKeyWithPath(rootDir, name, password, mnemonic, hdPath, false)
+}
+
+//ReplaceKeyWithPath stores the key at hdPath of the mnemonic under name as CreateKeyWithPath, replacing the
+//key stored under name if any, such as the key of a forgotten password
+func ReplaceKeyWithPath(rootDir, name, password, mnemonic, hdPath string) (*KeyOutput, error) {
+	return createKeyWithPath(rootDir, name, password, mnemonic, hdPath, true)
+}
 
-	//dpath for the key base path derive:  m / purpose' / coin_type' / account' / change / address_index (m/44'/60'/0'/0/0)
-	dpath, err := accounts.ParseDerivationPath(`m/44'/60'/0'/0/0`)
+func createKeyWithPath(rootDir, name, password, mnemonic, hdPath string, overwrite bool) (*KeyOutput, error) {
+	if name == "" {
+		return nil, errMissingName()
+	}
+	if password == "" {
+		return nil, errMissingPassword()
+	}
+	privateKey, dpath, err := deriveKey(mnemonic, hdPath)
 	if err != nil {
-		return err.Error()
+		return nil, err
 	}
-
-	//fetch the masterKey for the wallet
-	masterKey, err := hdkeychain.NewMaster(seed, &chaincfg.MainNetParams)
-	//masterKey, err := bip32.NewMasterKey(seed)
+	ko, err := storeKey(rootDir, name, password, privateKey, dpath.String(), overwrite)
 	if err != nil {
-		return err.Error()
+		return nil, err
 	}
+	ko.Mnemonic = mnemonic
+	return ko, nil
+}
 
-	key := masterKey
-
-	for _, n := range dpath {
-		key, err = key.Child(n)
-		if err != nil {
-			return err.Error()
-		}
+//storeKey encrypts the private key with password and stores it under name, hdPath is the path of the
+//key in its mnemonic, empty for the imported keys. A key already stored under name is replaced only with
+//overwrite, and an address is stored under one name only.
+func storeKey(rootDir, name, password string, privateKeyECDSA *ecdsa.PrivateKey, hdPath string, overwrite bool) (*KeyOutput, error) {
+	if name == "" {
+		return nil, errMissingName()
 	}
-
-	//generate the privateKey and pubKey
-	privateKey, err := key.ECPrivKey()
-	privateKeyECDSA := privateKey.ToECDSA()
-	if err != nil {
-		return err.Error()
+	if password == "" {
+		return nil, errMissingPassword()
 	}
 	//then the pubKey
-	publicKey := privateKeyECDSA.Public()
-	publicKeyECDSA, ok := publicKey.(*ecdsa.PublicKey)
-	if !ok {
-		return err.Error()
-	}
+	publicKeyECDSA := &privateKeyECDSA.PublicKey
 	//the address, pubKey, PrivKey with hexString format
 	address := crypto.PubkeyToAddress(*publicKeyECDSA).Hex()
 	pubKeyHex := hexutil.Encode(crypto.FromECDSAPub(publicKeyECDSA))[4:]
 
 	//Armor and encrypt the privateKey
-	privateKeyByte := crypto.FromECDSA(privateKeyECDSA)
-	saltBytes, encBytes := encryptPrivKey(privateKeyByte, password)
-	header := map[string]string{
-		"kdf":  "bcrypt",
-		"salt": fmt.Sprintf("%X", saltBytes),
+	priKeyAmor, err := encryptArmorPrivKey(privateKeyECDSA, password)
+	if err != nil {
+		return nil, err
 	}
-	priKeyAmor := armor.EncodeArmor(blockTypePrivKey, header, encBytes)
 	//priKeyHex := hexutil.Encode(crypto.FromECDSA(privateKeyECDSA))[2:]
 
 	//gather the local info into struct
@@ -129,64 +123,133 @@ func CreateAccount(rootDir, name, password, mnemonic string) string {
 		PubKey:       pubKeyHex,
 		PrivKeyArmor: priKeyAmor,
 		Address:      address,
+		Path:         hdPath,
 	}
 
 	//write the local info by key
-	key1 := []byte(fmt.Sprintf("%s.%s", name, infoSuffix))
+	key1 := infoKey(name)
 	serializeInfo, err := json.Marshal(LInfo)
 	if err != nil {
-		return err.Error()
+		return nil, err
 	}
 
 	//init a go level DB to store the key and Info, specify the ethkeys
 	db, err := dbm.NewGoLevelDB("keys", filepath.Join(rootDir, "ethkeys"))
 	if err != nil {
-		return err.Error()
+		return nil, err
 	}
-	//Do not check whether the name conflict with existing names
-	// List returns the keys from storage in alphabetical order.
-	//var res []LocalInfo
-	//iter := db.Iterator(nil, nil)
-	//defer iter.Close()
-	//for ; iter.Valid(); iter.Next() {
-	//	key := string(iter.Key())
-	//	// need to include only keys in storage that have an info suffix
-	//	if strings.HasSuffix(key, infoSuffix) {
-	//		info, err := readInfo(iter.Value())
-	//		if err != nil {
-	//			return err.Error()
-	//		}
-	//		res = append(res, info)
-	//	}
-	//}
-	//// check if already exists
-	//for _, info := range res {
-	//	if info.Name == name {
-	//		err = errKeyNameConflict(name)
-	//		return err.Error()
-	//	}
-	//}
-
-	db.SetSync(key1, serializeInfo)
-	// store a pointer to the infokey by address for fast lookup
-	addrKey := []byte(fmt.Sprintf("%s.%s", address, "addr"))
-	db.SetSync(addrKey, key1)
 	//Close the db to release the lock
-	db.Close()
+	defer db.Close()
+	batch := db.NewBatch()
+	defer batch.Close()
+	if old := db.Get(key1); len(old) > 0 {
+		if !overwrite {
+			return nil, errKeyNameConflict(name)
+		}
+		//the address index of the replaced key goes with it
+		if oldInfo, err := readInfo(old); err == nil && oldInfo.Address != address {
+			batch.Delete(addrKey(oldInfo.Address))
+		}
+	}
+	if owner, ok := addressOwner(db, address); ok && owner != name {
+		return nil, errcode.Errorf(errcode.CodeInvalidInput, "the address %s is already stored as %s", address, owner)
+	}
+	//the info and the pointer to it by address for fast lookup are written together
+	batch.Set(key1, serializeInfo)
+	batch.Set(addrKey(address), key1)
+	batch.WriteSync()
 	//fetch the result
-	Ko := KeyOutput{LInfo.Name, "local", LInfo.Address, LInfo.PubKey, mnemonic, "ETH"}
-	respbyte, _ := json.Marshal(Ko)
-	return string(respbyte)
+	return &KeyOutput{LInfo.Name, "local", LInfo.Address, LInfo.PubKey, "", "ETH", LInfo.Path}, nil
+}
+
+//HasKey tells whether a key name is stored under rootDir
+func HasKey(rootDir, name string) (bool, error) {
+	db, err := dbm.NewGoLevelDB("keys", filepath.Join(rootDir, "ethkeys"))
+	if err != nil {
+		return false, err
+	}
+	defer db.Close()
+	return db.Has(infoKey(name)), nil
+}
+
+//DeleteKey removes the key name, the password has to match
+func DeleteKey(rootDir, name, password string) error {
+	db, err := dbm.NewGoLevelDB("keys", filepath.Join(rootDir, "ethkeys"))
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	info, err := getInfo(db, name)
+	if err != nil {
+		return err
+	}
+	if _, err := UnarmorDecryptPrivKey(info.PrivKeyArmor, password); err != nil {
+		return err
+	}
+	batch := db.NewBatch()
+	defer batch.Close()
+	batch.Delete(addrKey(info.Address))
+	batch.Delete(infoKey(name))
+	batch.WriteSync()
+	return nil
+}
 
+//ChangePassword encrypts the key name with newpass instead of oldpass
+func ChangePassword(rootDir, name, oldpass, newpass string) error {
+	if newpass == "" {
+		return errMissingPassword()
+	}
+	db, err := dbm.NewGoLevelDB("keys", filepath.Join(rootDir, "ethkeys"))
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	info, err := getInfo(db, name)
+	if err != nil {
+		return err
+	}
+	privKey, err := UnarmorDecryptPrivKey(info.PrivKeyArmor, oldpass)
+	if err != nil {
+		return err
+	}
+	if info.PrivKeyArmor, err = encryptArmorPrivKey(privKey, newpass); err != nil {
+		return err
+	}
+	bz, err := json.Marshal(info)
+	if err != nil {
+		return errcode.Internal(err)
+	}
+	db.SetSync(infoKey(name), bz)
+	return nil
+}
+
+//getInfo reads the info of the key name
+func getInfo(db dbm.DB, name string) (LocalInfo, error) {
+	bs := db.Get(infoKey(name))
+	if len(bs) == 0 {
+		return LocalInfo{}, errcode.KeyNotFound(keyerror.NewErrKeyNotFound(name))
+	}
+	return readInfo(bs)
 }
 
 //List local account
 func ListLocalAccount(rootDir string) string {
+	KoG, err := ListKeys(rootDir)
+	if err != nil {
+		return err.Error()
+	}
+	Kos, _ := json.Marshal(KoG)
+	return string(Kos)
+}
+
+//ListKeys returns the local accounts in alphabetical order of name
+func ListKeys(rootDir string) ([]KeyOutput, error) {
 	//init a go level DB to store the key and Info, specify the ethkeys
 	db, err := dbm.NewGoLevelDB("keys", filepath.Join(rootDir, "ethkeys"))
 	if err != nil {
-		return err.Error()
+		return nil, err
 	}
+	defer db.Close()
 	// List returns the keys from storage in alphabetical order.
 	var res []LocalInfo
 	iter := db.Iterator(nil, nil)
@@ -197,19 +260,17 @@ func ListLocalAccount(rootDir string) string {
 		if strings.HasSuffix(key, infoSuffix) {
 			info, err := readInfo(iter.Value())
 			if err != nil {
-				return err.Error()
+				return nil, err
 			}
 			res = append(res, info)
 		}
 	}
-	// check if already exists
 	var KoG []KeyOutput
 	for _, info := range res {
-		Ko := KeyOutput{info.Name, "local", info.Address, info.PubKey, "", "ETH"}
+		Ko := KeyOutput{info.Name, "local", info.Address, info.PubKey, "", "ETH", info.Path}
 		KoG = append(KoG, Ko)
 	}
-	Kos, _ := json.Marshal(KoG)
-	return string(Kos)
+	return KoG, nil
 }
 
 // decoding info
@@ -218,142 +279,148 @@ func readInfo(bz []byte) (info LocalInfo, err error) {
 	return
 }
 
-// encrypt the given privKey with the passphrase using a randomly
-// generated salt and the xsalsa20 cipher. returns the salt and the
-// encrypted priv key.
-func encryptPrivKey(privKey []byte, passphrase string) (saltBytes []byte, encBytes []byte) {
-	saltBytes = tcrypto.CRandBytes(16)
-	key, err := bcrypt.GenerateFromPassword(saltBytes, []byte(passphrase), BcryptSecurityParameter)
-	if err != nil {
-		cmn.Exit("Error generating bcrypt key from passphrase: " + err.Error())
-	}
-	key = tcrypto.Sha256(key) // get 32 bytes
-	privKeyBytes := privKey
-	return saltBytes, xsalsa20symmetric.EncryptSymmetric(privKeyBytes, key)
+//encryptArmorPrivKey encrypts the private key with passphrase in the current envelope of keycrypt
+func encryptArmorPrivKey(privateKeyECDSA *ecdsa.PrivateKey, passphrase string) (string, error) {
+	return keycrypt.Encrypt(blockTypePrivKey, crypto.FromECDSA(privateKeyECDSA), passphrase)
 }
 
 // Unarmor and decrypt the private key.
 func UnarmorDecryptPrivKey(armorStr string, passphrase string) (*ecdsa.PrivateKey, error) {
-	var privKey *ecdsa.PrivateKey
-	blockType, header, encBytes, err := armor.DecodeArmor(armorStr)
-	if err != nil {
-		return privKey, err
-	}
-	if blockType != blockTypePrivKey {
-		return privKey, fmt.Errorf("Unrecognized armor type: %v", blockType)
-	}
-	if header["kdf"] != "bcrypt" {
-		return privKey, fmt.Errorf("Unrecognized KDF type: %v", header["KDF"])
-	}
-	if header["salt"] == "" {
-		return privKey, fmt.Errorf("Missing salt bytes")
-	}
-	saltBytes, err := hex.DecodeString(header["salt"])
-	if err != nil {
-		return privKey, fmt.Errorf("Error decoding salt: %v", err.Error())
-	}
-	privKey, err = decryptPrivKey(saltBytes, encBytes, passphrase)
+	privKey, _, err := unarmorDecryptPrivKey(armorStr, passphrase)
 	return privKey, err
 }
 
-func decryptPrivKey(saltBytes []byte, encBytes []byte, passphrase string) (privKey *ecdsa.PrivateKey, err error) {
-	key, err := bcrypt.GenerateFromPassword(saltBytes, []byte(passphrase), BcryptSecurityParameter)
+//unarmorDecryptPrivKey decrypts the private key and tells whether its envelope is the current one
+func unarmorDecryptPrivKey(armorStr string, passphrase string) (*ecdsa.PrivateKey, bool, error) {
+	privKeyBytes, current, err := keycrypt.Decrypt(armorStr, blockTypePrivKey, passphrase)
 	if err != nil {
-		cmn.Exit("Error generating bcrypt key from passphrase: " + err.Error())
+		return nil, false, err
 	}
-	key = tcrypto.Sha256(key) // Get 32 bytes
-	privKeyBytes, err := xsalsa20symmetric.DecryptSymmetric(encBytes, key)
-	if err != nil && err.Error() == "Ciphertext decryption failed" {
-		return privKey, keyerror.NewErrWrongPassword()
-	} else if err != nil {
-		return privKey, err
+	privKey, err := crypto.ToECDSA(privKeyBytes)
+	if err != nil {
+		return nil, false, errcode.Internal(err)
 	}
-	privKey, err = crypto.ToECDSA(privKeyBytes)
-	return privKey, err
+	return privKey, current, nil
 }
 
-//Fetch private key for signning
+//Fetch private key for signning, a key of an outdated envelope is encrypted again with the current one
 func FetchtoSign(rootDir, name, password string) (privKey *ecdsa.PrivateKey, err error) {
 	//init a go level DB to store the key and Info, specify the ethkeys
 	db, err := dbm.NewGoLevelDB("keys", filepath.Join(rootDir, "ethkeys"))
 	if err != nil {
 		return nil, err
 	}
-	bs := db.Get(infoKey(name))
-	if len(bs) == 0 {
-		return nil, keyerror.NewErrKeyNotFound(name)
-	}
+	Li, err := getInfo(db, name)
 	//Close the db to release the lock
 	db.Close()
-	//get the LocalInfo by key
-	Li, err := readInfo(bs)
 	if err != nil {
 		return nil, err
 	}
-	armorStr := Li.PrivKeyArmor
-	//fmt.Println("PrivateKeyArmor",armorStr)
-	return UnarmorDecryptPrivKey(armorStr, password)
+	privKey, current, err := unarmorDecryptPrivKey(Li.PrivKeyArmor, password)
+	if err != nil {
+		return nil, err
+	}
+	if !current {
+		//the upgrade is done again on the next unlock if it fails
+		upgradeKey(rootDir, Li, privKey, password)
+	}
+	return privKey, nil
+}
+
+//UpgradeKey encrypts the key name again with the current envelope of keycrypt if it is another one, and
+//tells whether it did
+func UpgradeKey(rootDir, name, password string) (bool, error) {
+	db, err := dbm.NewGoLevelDB("keys", filepath.Join(rootDir, "ethkeys"))
+	if err != nil {
+		return false, err
+	}
+	info, err := getInfo(db, name)
+	db.Close()
+	if err != nil {
+		return false, err
+	}
+	if upgrade, err := keycrypt.NeedsUpgrade(info.PrivKeyArmor); err != nil || !upgrade {
+		return false, err
+	}
+	privKey, err := UnarmorDecryptPrivKey(info.PrivKeyArmor, password)
+	if err != nil {
+		return false, err
+	}
+	return true, upgradeKey(rootDir, info, privKey, password)
+}
+
+//upgradeKey writes the key of info encrypted with the current envelope, unless the key was changed since info
+//was read
+func upgradeKey(rootDir string, info LocalInfo, privKey *ecdsa.PrivateKey, password string) error {
+	armorStr := info.PrivKeyArmor
+	var err error
+	if info.PrivKeyArmor, err = encryptArmorPrivKey(privKey, password); err != nil {
+		return err
+	}
+	bz, err := json.Marshal(info)
+	if err != nil {
+		return errcode.Internal(err)
+	}
+	db, err := dbm.NewGoLevelDB("keys", filepath.Join(rootDir, "ethkeys"))
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	if stored, err := getInfo(db, info.Name); err != nil || stored.PrivKeyArmor != armorStr {
+		return errcode.Errorf(errcode.CodeInternal, "the key %s changed while it was upgraded", info.Name)
+	}
+	db.SetSync(infoKey(info.Name), bz)
+	return nil
 }
 
 func infoKey(name string) []byte {
 	return []byte(fmt.Sprintf("%s.%s", name, infoSuffix))
 }
 
+func addrKey(address string) []byte {
+	return []byte(fmt.Sprintf("%s.%s", address, addrSuffix))
+}
+
 func errMissingName() error {
-	return fmt.Errorf("you have to specify a name for the locally stored account")
+	return errcode.New(errcode.CodeInvalidInput, "you have to specify a name for the locally stored account")
 }
 
 func errMissingPassword() error {
-	return fmt.Errorf("you have to specify a password for the locally stored account")
+	return errcode.New(errcode.CodeInvalidInput, "you have to specify a password for the locally stored account")
+}
+
+func errMissingMnemonic() error {
+	return errcode.New(errcode.CodeInvalidInput, "mnemonic is required")
+}
+
+func errInvalidMnemonic() error {
+	return errcode.New(errcode.CodeInvalidInput, "mnemonic is invalid")
 }
 
 func errKeyNameConflict(name string) error {
-	return fmt.Errorf("acount with name %s already exists", name)
+	return errcode.Errorf(errcode.CodeInvalidInput, "acount with name %s already exists", name)
 }
 
-//verify the signature
-func SigVerify(rootDir, name, password string, data2sign []byte) {
-	//Fetch the privateKey
-	privateKey, err := FetchtoSign(rootDir, name, password)
+//SigVerify signs data2sign with the key name as SignMessage does and checks the signature recovers its address
+func SigVerify(rootDir, name, password string, data2sign []byte) error {
+	s, err := fetchSigner(rootDir, name, password)
 	if err != nil {
-		log.Fatal(err)
-	}
-
-	publicKey := privateKey.Public()
-	publicKeyECDSA, ok := publicKey.(*ecdsa.PublicKey)
-	if !ok {
-		log.Fatal("cannot assert type: publicKey is not of type *ecdsa.PublicKey")
+		return err
 	}
-	publicKeyBytes := crypto.FromECDSAPub(publicKeyECDSA)
-
-	//data to be signed
-	//data := []byte("hello")
-	hash := crypto.Keccak256Hash(data2sign)
-
-	//generate the signature
-	signature, err := crypto.Sign(hash.Bytes(), privateKey)
+	addr, err := SignerAddress(s)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
-
-	//recover the pubkey from signature
-	sigPublicKey, err := crypto.Ecrecover(hash.Bytes(), signature)
+	sig, err := SignMessageWithSigner(s, data2sign)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
-	matches := bytes.Equal(sigPublicKey, publicKeyBytes)
-	fmt.Println("sigPublicKey vs publicKeyBytes", matches) // true
-
-	sigPublicKeyECDSA, err := crypto.SigToPub(hash.Bytes(), signature)
+	ok, err := VerifyMessage(addr.Hex(), data2sign, sig)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
-	sigPublicKeyBytes := crypto.FromECDSAPub(sigPublicKeyECDSA)
-	matches = bytes.Equal(sigPublicKeyBytes, publicKeyBytes)
-	fmt.Println("sigPublicKeyBytes vs publicKeyBytes", matches) // true
-
-	signatureNoRecoverID := signature[:len(signature)-1] // remove recovery id
-	verified := crypto.VerifySignature(publicKeyBytes, hash.Bytes(), signatureNoRecoverID)
-	fmt.Println("no recover verify", verified) // true
-
+	if !ok {
+		return errcode.Errorf(errcode.CodeInternal, "the signature of %s does not recover its address", name)
+	}
+	return nil
 }
@@ -0,0 +1,80 @@
+package eth
+
+import (
+	"fmt"
+
+	"github.com/QOSGroup/litewallet/litewallet/errcode"
+	"github.com/QOSGroup/litewallet/litewallet/signer"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+//PersonalHash is the EIP-191 hash of msg signed by personal_sign, the message is prefixed so that it can not
+//be a tx
+func PersonalHash(msg []byte) []byte {
+	return crypto.Keccak256([]byte(fmt.Sprintf("\x19Ethereum Signed Message:\n%d", len(msg))), msg)
+}
+
+//SignMessage signs msg with the local key name as personal_sign does, the signature is R||S||V with V 27 or 28
+func SignMessage(rootDir, name, password string, msg []byte) ([]byte, error) {
+	s, err := fetchSigner(rootDir, name, password)
+	if err != nil {
+		return nil, err
+	}
+	return SignMessageWithSigner(s, msg)
+}
+
+//SignMessageWithSigner is SignMessage signed by s
+func SignMessageWithSigner(s signer.Signer, msg []byte) ([]byte, error) {
+	return signHash(s, PersonalHash(msg))
+}
+
+//signHash signs the hash of a message with s, V is 27 or 28 as the dApps check it
+func signHash(s signer.Signer, hash []byte) ([]byte, error) {
+	if _, err := SignerAddress(s); err != nil {
+		return nil, err
+	}
+	sig, err := s.Sign(hash)
+	if err != nil {
+		return nil, err
+	}
+	if len(sig) != signer.Secp256k1SignatureSize {
+		return nil, errcode.Errorf(errcode.CodeInternal, "invalid secp256k1 signature of %d bytes", len(sig))
+	}
+	sig[64] += 27
+	return sig, nil
+}
+
+//RecoverMessageSigner returns the address which signed msg with personal_sign, V is 27 or 28, or 0 or 1
+func RecoverMessageSigner(msg, sig []byte) (common.Address, error) {
+	return recoverSigner(PersonalHash(msg), sig)
+}
+
+//VerifyMessage tells whether sig is the personal_sign signature of msg by the address addr
+func VerifyMessage(addr string, msg, sig []byte) (bool, error) {
+	want, err := hexAddress(addr)
+	if err != nil {
+		return false, err
+	}
+	got, err := RecoverMessageSigner(msg, sig)
+	if err != nil {
+		return false, err
+	}
+	return got == want, nil
+}
+
+//recoverSigner returns the address of the R||S||V signature sig of hash
+func recoverSigner(hash, sig []byte) (common.Address, error) {
+	if len(sig) != signer.Secp256k1SignatureSize {
+		return common.Address{}, errcode.Errorf(errcode.CodeInvalidInput, "invalid signature of %d bytes", len(sig))
+	}
+	rsv := append([]byte(nil), sig...)
+	if rsv[64] >= 27 {
+		rsv[64] -= 27
+	}
+	pub, err := crypto.SigToPub(hash, rsv)
+	if err != nil {
+		return common.Address{}, errcode.InvalidInput(err)
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
@@ -0,0 +1,182 @@
+package eth
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+
+	"github.com/QOSGroup/litewallet/litewallet/errcode"
+	"github.com/QOSGroup/litewallet/litewallet/offline"
+	"github.com/QOSGroup/litewallet/litewallet/signer"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+//offlineTx is the unsigned tx of the offline documents in the JSON of the json-rpc, the nonce is the
+//Sequence of the document and the chain id its ChainID
+type offlineTx struct {
+	To       common.Address `json:"to"`
+	Value    *hexutil.Big   `json:"value"`
+	Gas      hexutil.Uint64 `json:"gas"`
+	GasPrice *hexutil.Big   `json:"gasPrice"`
+	Data     hexutil.Bytes  `json:"data"`
+}
+
+//BuildOfflineETH builds the unsigned ETH transfer of fromAddr with its pending nonce, the amount is in ETH
+//and the gasPrice in gwei
+func BuildOfflineETH(ctx context.Context, node, fromAddr, toAddr, gasPrice, amount string, gasLimit int64) (offline.Tx, error) {
+	from, err := hexAddress(fromAddr)
+	if err != nil {
+		return offline.Tx{}, err
+	}
+	to, err := hexAddress(toAddr)
+	if err != nil {
+		return offline.Tx{}, err
+	}
+	value, err := parseUnits(amount, 18)
+	if err != nil {
+		return offline.Tx{}, err
+	}
+
+	client, err := dial(ctx, node)
+	if err != nil {
+		return offline.Tx{}, err
+	}
+	defer client.Close()
+
+	tx, chainID, err := buildTx(ctx, client, from, to, value, gasPrice, gasLimit, nil, nil)
+	if err != nil {
+		return offline.Tx{}, err
+	}
+	return newOfflineTx(offline.TypeTransfer, from, tx, chainID)
+}
+
+//BuildOfflineERC20 builds the unsigned ERC20 transfer of fromAddr with its pending nonce, the tokenValue is
+//scaled by the decimals of the token contract
+func BuildOfflineERC20(ctx context.Context, node, fromAddr, toAddr, tokenAddr, tokenValue, gasPrice string, gasLimit int64) (offline.Tx, error) {
+	from, err := hexAddress(fromAddr)
+	if err != nil {
+		return offline.Tx{}, err
+	}
+	to, err := hexAddress(toAddr)
+	if err != nil {
+		return offline.Tx{}, err
+	}
+	tokenAddress, err := hexAddress(tokenAddr)
+	if err != nil {
+		return offline.Tx{}, err
+	}
+
+	client, err := dial(ctx, node)
+	if err != nil {
+		return offline.Tx{}, err
+	}
+	defer client.Close()
+
+	data, err := erc20Transfer(ctx, client, to, tokenAddress, tokenValue)
+	if err != nil {
+		return offline.Tx{}, err
+	}
+	tx, chainID, err := buildTx(ctx, client, from, tokenAddress, big.NewInt(0), gasPrice, gasLimit, nil, data)
+	if err != nil {
+		return offline.Tx{}, err
+	}
+	return newOfflineTx(offline.TypeERC20Transfer, from, tx, chainID)
+}
+
+//SignOffline signs the unsigned ETH tx with s, without any node
+func SignOffline(tx offline.Tx, s signer.Signer) (offline.Tx, error) {
+	if err := tx.CheckUnsigned(offline.ChainETH); err != nil {
+		return offline.Tx{}, err
+	}
+	from, err := SignerAddress(s)
+	if err != nil {
+		return offline.Tx{}, err
+	}
+	if signerAddr, err := hexAddress(tx.Signer); err != nil || signerAddr != from {
+		return offline.Tx{}, errcode.Errorf(errcode.CodeInvalidInput, "the offline tx is signed by %s, not %s", tx.Signer, from.Hex())
+	}
+	chainID, ok := new(big.Int).SetString(tx.ChainID, 10)
+	if !ok {
+		return offline.Tx{}, errcode.Errorf(errcode.CodeInvalidInput, "invalid chain id %q", tx.ChainID)
+	}
+
+	var otx offlineTx
+	if err := json.Unmarshal(tx.Tx, &otx); err != nil {
+		return offline.Tx{}, errcode.InvalidInput(err)
+	}
+	if otx.Value == nil || otx.GasPrice == nil {
+		return offline.Tx{}, errcode.New(errcode.CodeInvalidInput, "the offline tx misses its value or gasPrice")
+	}
+	unsigned := types.NewTransaction(tx.Sequence, otx.To, otx.Value.ToInt(), uint64(otx.Gas), otx.GasPrice.ToInt(), otx.Data)
+	signHash := types.NewEIP155Signer(chainID).Hash(unsigned).Hex()
+	if err := tx.CheckSignHash(signHash); err != nil {
+		return offline.Tx{}, err
+	}
+
+	signedTx, err := signTx(unsigned, chainID, s)
+	if err != nil {
+		return offline.Tx{}, err
+	}
+	raw, err := rlp.EncodeToBytes(signedTx)
+	if err != nil {
+		return offline.Tx{}, errcode.Internal(err)
+	}
+	tx.SignHash = signHash
+	tx.Signed = hexutil.Encode(raw)
+	tx.Hash = signedTx.Hash().Hex()
+	return tx, nil
+}
+
+//BroadcastOffline sends the signed ETH tx and returns its hash
+func BroadcastOffline(ctx context.Context, node string, tx offline.Tx) (common.Hash, error) {
+	if err := tx.CheckSigned(offline.ChainETH); err != nil {
+		return common.Hash{}, err
+	}
+	raw, err := hexutil.Decode(tx.Signed)
+	if err != nil {
+		return common.Hash{}, errcode.InvalidInput(err)
+	}
+	signedTx := new(types.Transaction)
+	if err := rlp.DecodeBytes(raw, signedTx); err != nil {
+		return common.Hash{}, errcode.InvalidInput(err)
+	}
+	if signedTx.Hash().Hex() != tx.Hash {
+		return common.Hash{}, errcode.Errorf(errcode.CodeInvalidInput, "the signed tx has the hash %s, not %s", signedTx.Hash().Hex(), tx.Hash)
+	}
+
+	client, err := dial(ctx, node)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	defer client.Close()
+	if err := client.SendTransaction(ctx, signedTx); err != nil {
+		return common.Hash{}, nodeError(err)
+	}
+	return signedTx.Hash(), nil
+}
+
+//newOfflineTx is the document of the unsigned tx of from on chainID
+func newOfflineTx(txType string, from common.Address, tx *types.Transaction, chainID *big.Int) (offline.Tx, error) {
+	bz, err := json.Marshal(offlineTx{
+		To:       *tx.To(),
+		Value:    (*hexutil.Big)(tx.Value()),
+		Gas:      hexutil.Uint64(tx.Gas()),
+		GasPrice: (*hexutil.Big)(tx.GasPrice()),
+		Data:     tx.Data(),
+	})
+	if err != nil {
+		return offline.Tx{}, errcode.Internal(err)
+	}
+	return offline.Tx{
+		Chain:    offline.ChainETH,
+		ChainID:  chainID.String(),
+		Type:     txType,
+		Signer:   from.Hex(),
+		Sequence: tx.Nonce(),
+		Tx:       bz,
+		SignHash: types.NewEIP155Signer(chainID).Hash(tx).Hex(),
+	}, nil
+}
@@ -1,27 +1,24 @@
 package eth
 
 import (
-	"fmt"
-	"github.com/tyler-smith/go-bip39"
+	"encoding/json"
 )
 
 func RecoverAccount(rootDir, name, password, mnemonic string) string {
-	if name == "" {
-		err := errMissingName()
+	ko, err := RestoreKey(rootDir, name, password, mnemonic)
+	if err != nil {
 		return err.Error()
 	}
-	if password == "" {
-		err := errMissingPassword()
-		return err.Error()
-	}
-	//generate wallet with mnemonic
-	if mnemonic == "" {
-		return fmt.Sprintf("mnemonic is required")
-	}
+	respbyte, _ := json.Marshal(ko)
+	return string(respbyte)
+}
 
-	if !bip39.IsMnemonicValid(mnemonic) {
-		return fmt.Sprintf("mnemonic is invalid")
-	}
-	output := CreateAccount(rootDir, name, password, mnemonic)
-	return output
+//RestoreKey restores the account of the mnemonic under name
+func RestoreKey(rootDir, name, password, mnemonic string) (*KeyOutput, error) {
+	return CreateKey(rootDir, name, password, mnemonic)
+}
+
+//RestoreKeyWithPath restores the account at hdPath of the mnemonic under name
+func RestoreKeyWithPath(rootDir, name, password, mnemonic, hdPath string) (*KeyOutput, error) {
+	return CreateKeyWithPath(rootDir, name, password, mnemonic, hdPath)
 }
@@ -1,91 +1,408 @@
 package eth
 
 import (
-	"os/user"
+	"context"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"os"
 	"testing"
+
+	"github.com/QOSGroup/litewallet/litewallet/errcode"
+	"github.com/QOSGroup/litewallet/litewallet/eth/contracts_erc20"
+	"github.com/QOSGroup/litewallet/litewallet/eth/ethtest"
+	"github.com/QOSGroup/litewallet/litewallet/offline"
+	"github.com/QOSGroup/litewallet/litewallet/signer/signertest"
+	"github.com/QOSGroup/litewallet/litewallet/slim/tendermint/crypto/funcInlocal/ed25519local"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	node     = "http://simulated"
+	name     = "eth5"
+	password = "wm131421"
+	seed     = "monster soap pipe grief tourist marine turkey scatter because fade actual robust"
+	toAddr   = "0x1B37AB8d737B1776d3cC082D246Ee89Ed9693cD2"
 )
 
+var ether = new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)
+
+//testChain is a simulated chain where the local key name holds 10ETH and 1000.5QT of a token,
+//the wallet functions dial it whatever the node
+type testChain struct {
+	*ethtest.Chain
+	rootDir string
+	from    common.Address
+	token   common.Address
+}
+
+func newTestChain(t *testing.T) *testChain {
+	rootDir, err := ioutil.TempDir("", "eth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := CreateKey(rootDir, name, password, seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := common.HexToAddress(key.Address)
+
+	//the token owner is a separate key, the deployment would use the nonces of from
+	owner, _ := crypto.GenerateKey()
+	chain := ethtest.NewChain(core.GenesisAlloc{
+		from:                                    {Balance: new(big.Int).Mul(big.NewInt(10), ether)},
+		crypto.PubkeyToAddress(owner.PublicKey): {Balance: ether},
+	})
+	token, err := chain.DeployToken(owner, big.NewInt(100050), "QT Token", 2, "QT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	instance, err := contracts_erc20.NewContractsErc20(token, chain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := instance.Transfer(bind.NewKeyedTransactor(owner), from, big.NewInt(100050)); err != nil {
+		t.Fatal(err)
+	}
+	chain.Commit()
+
+	SetDialer(func(ctx context.Context, node string) (Client, error) {
+		return chain, nil
+	})
+	return &testChain{Chain: chain, rootDir: rootDir, from: from, token: token}
+}
+
+func (c *testChain) Close() {
+	SetDialer(nil)
+	os.RemoveAll(c.rootDir)
+}
+
+func (c *testChain) balance(t *testing.T, addr string) string {
+	balance, err := GetBalance(context.Background(), node, addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return balance.Amount.String()
+}
+
+func (c *testChain) tokenBalance(t *testing.T, addr string) string {
+	balance, err := GetTokenBalance(context.Background(), node, addr, c.token.Hex())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return balance.Amount.String()
+}
+
 func TestGetAccount(t *testing.T) {
-	node := "https://kovan.infura.io/v3/ef4fee2bd9954c6c8303854e0dce1ffe"
-	addr := "0x189f91780c97ed13c242ce3f1568f97a446cab88"
-	output := GetAccount(node, addr)
-	t.Log(output)
+	chain := newTestChain(t)
+	defer chain.Close()
+
+	if output := GetAccount(node, chain.from.Hex()); output != "10ETH" {
+		t.Errorf("got %s, want 10ETH", output)
+	}
+	if output := GetAccount(node, toAddr); output != "0ETH" {
+		t.Errorf("got %s, want 0ETH", output)
+	}
+	if _, err := GetBalance(context.Background(), node, "0x1B37"); errcode.Code(err) != errcode.CodeInvalidInput {
+		t.Errorf("got %v for a bad address, want an invalid input error", err)
+	}
 }
 
 func TestGetAccountERC20(t *testing.T) {
-	node := "https://mainnet.infura.io/v3/ef4fee2bd9954c6c8303854e0dce1ffe"
-	addr := "0x1B37AB8d737B1776d3cC082D246Ee89Ed9693cD2"
-	tokenAddr := "0x86Fa049857E0209aa7D9e616F7eb3b3B78ECfdb0"
-	output := GetAccountERC20(node, addr, tokenAddr)
-	t.Log(output)
+	chain := newTestChain(t)
+	defer chain.Close()
+
+	if output := GetAccountERC20(node, chain.from.Hex(), chain.token.Hex()); output != "1000.5QT" {
+		t.Errorf("got %s, want 1000.5QT", output)
+	}
+	//an address without contract code is not a token
+	if _, err := GetTokenBalance(context.Background(), node, chain.from.Hex(), toAddr); errcode.Code(err) != errcode.CodeInvalidInput {
+		t.Errorf("got %v for an account, want an invalid input error", err)
+	}
 }
 
 func TestTransferETH(t *testing.T) {
-	usr, _ := user.Current()
-	rootDir := usr.HomeDir
-	node := "https://kovan.infura.io/v3/ef4fee2bd9954c6c8303854e0dce1ffe"
-	name := "easyzone"
-	password := "wm131421"
-	toAddr := "0x1B37AB8d737B1776d3cC082D246Ee89Ed9693cD2"
-	amount := "0.00002"
-	gasLimit := int64(21000)
-	gasPrice := "20"
-	output := TransferETH(rootDir, node, name, password, toAddr, gasPrice, amount, gasLimit)
-	t.Log(output)
+	chain := newTestChain(t)
+	defer chain.Close()
+
+	output := TransferETH(chain.rootDir, node, name, password, toAddr, "20", "0.00002", 21000)
+	pending := chain.Pending(chain.from)
+	if len(pending) != 1 || pending[0].Hash().Hex() != output {
+		t.Fatalf("got %s, want the hash of the pending tx", output)
+	}
+	if tx := pending[0]; tx.GasPrice().Int64() != 20000000000 || tx.Gas() != 21000 || tx.Value().Int64() != 20000000000000 {
+		t.Errorf("got tx %v", tx)
+	}
+	chain.Commit()
+
+	if got := chain.balance(t, toAddr); got != "20000000000000" {
+		t.Errorf("receiver has %s wei, want 20000000000000", got)
+	}
+	//10 ether minus the value and 21000 gas at 20 gwei
+	if got := chain.balance(t, chain.from.Hex()); got != "9999560000000000000" {
+		t.Errorf("sender has %s wei, want 9999560000000000000", got)
+	}
+}
+
+func TestTransferETHErrors(t *testing.T) {
+	chain := newTestChain(t)
+	defer chain.Close()
+
+	ctx := context.Background()
+	if _, err := SendETH(ctx, chain.rootDir, node, name, password, toAddr, "20", "11", 21000); errcode.Code(err) != errcode.CodeInsufficientFunds {
+		t.Errorf("got %v, want an insufficient funds error", err)
+	}
+	if _, err := SendETH(ctx, chain.rootDir, node, name, password, toAddr, "20", "1", 20000); errcode.Code(err) != errcode.CodeNodeRejected {
+		t.Errorf("got %v for a gas limit under 21000, want a node rejected error", err)
+	}
+	if _, err := SendETH(ctx, chain.rootDir, node, name, "wrong", toAddr, "20", "1", 21000); errcode.Code(err) != errcode.CodeWrongPassword {
+		t.Errorf("got %v, want a wrong password error", err)
+	}
+	if len(chain.Pending(chain.from)) != 0 {
+		t.Error("a failed transfer left a pending tx")
+	}
+}
+
+func TestTransferWithSigner(t *testing.T) {
+	chain := newTestChain(t)
+	defer chain.Close()
+
+	//the key of the wallet held by a device
+	key, err := FetchtoSign(chain.rootDir, name, password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	device := signertest.NewSecp256k1(key)
+	s := device.Signer()
+	if addr, err := SignerAddress(s); err != nil || addr != chain.from {
+		t.Fatalf("got %s, %v, want the address of the key", addr.Hex(), err)
+	}
+
+	ctx := context.Background()
+	hash, err := SendETHWithSigner(ctx, node, s, toAddr, "20", "0.00002", 21000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := SendERC20WithSigner(ctx, node, s, toAddr, chain.token.Hex(), "0.34", "3", 210000); err != nil {
+		t.Fatal(err)
+	}
+	pending := chain.Pending(chain.from)
+	if len(pending) != 2 || pending[0].Hash() != hash || device.Signs() != 2 {
+		t.Fatalf("got %d pending txs and %d signatures, want both txs signed by the device", len(pending), device.Signs())
+	}
+	chain.Commit()
+	if got := chain.balance(t, toAddr); got != "20000000000000" {
+		t.Errorf("receiver has %s wei, want 20000000000000", got)
+	}
+	if got := chain.tokenBalance(t, toAddr); got != "34" {
+		t.Errorf("receiver has %s, want 34 in the smallest unit", got)
+	}
+
+	device.Refuse(errors.New("cancelled"))
+	if _, err := SendETHWithSigner(ctx, node, s, toAddr, "20", "1", 21000); err == nil || len(chain.Pending(chain.from)) != 0 {
+		t.Errorf("got %v, want the error of the device and no pending tx", err)
+	}
+	ed := signertest.NewEd25519(ed25519local.GenPrivKeyFromSecret([]byte("qos"))).Signer()
+	if _, err := SendETHWithSigner(ctx, node, ed, toAddr, "20", "1", 21000); errcode.Code(err) != errcode.CodeInvalidInput {
+		t.Errorf("got %v for an ed25519 signer, want an invalid input error", err)
+	}
+}
+
+func TestOffline(t *testing.T) {
+	chain := newTestChain(t)
+	defer chain.Close()
+
+	key, err := FetchtoSign(chain.rootDir, name, password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	device := signertest.NewSecp256k1(key).Signer()
+
+	ctx := context.Background()
+	//build online -> sign offline -> broadcast, the document moving as JSON between the devices
+	unsigned, err := BuildOfflineETH(ctx, node, chain.from.Hex(), toAddr, "20", "0.00002", 21000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := offline.Parse(unsigned.String())
+	if err != nil || doc.Sequence != 0 || doc.SignHash == "" {
+		t.Fatalf("got %+v, %v, want the unsigned tx with the nonce 0", doc, err)
+	}
+	if _, err := BroadcastOffline(ctx, node, doc); errcode.Code(err) != errcode.CodeInvalidInput {
+		t.Errorf("got %v broadcasting the unsigned tx, want an invalid input error", err)
+	}
+	signed, err := SignOffline(doc, device)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := SignOffline(signed, device); errcode.Code(err) != errcode.CodeInvalidInput {
+		t.Errorf("got %v signing again, want an invalid input error", err)
+	}
+	hash, err := BroadcastOffline(ctx, node, signed)
+	if err != nil || hash.Hex() != signed.Hash {
+		t.Fatalf("got %s, %v, want the hash %s of the signature", hash.Hex(), err, signed.Hash)
+	}
+
+	unsigned, err = BuildOfflineERC20(ctx, node, chain.from.Hex(), toAddr, chain.token.Hex(), "0.34", "3", 210000)
+	if err != nil || unsigned.Sequence != 1 || unsigned.Type != offline.TypeERC20Transfer {
+		t.Fatalf("got %+v, %v, want the unsigned ERC20 transfer with the nonce 1", unsigned, err)
+	}
+	signed, err = SignOffline(unsigned, device)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := BroadcastOffline(ctx, node, signed); err != nil {
+		t.Fatal(err)
+	}
+	pending := chain.Pending(chain.from)
+	if len(pending) != 2 || pending[0].Hash() != hash || pending[1].Hash().Hex() != signed.Hash {
+		t.Fatalf("got %d pending txs, want the 2 offline txs", len(pending))
+	}
+	chain.Commit()
+	if got := chain.balance(t, toAddr); got != "20000000000000" {
+		t.Errorf("receiver has %s wei, want 20000000000000", got)
+	}
+	if got := chain.tokenBalance(t, toAddr); got != "34" {
+		t.Errorf("receiver has %s, want 34 in the smallest unit", got)
+	}
+
+	//the key of another account, a modified document
+	other, _ := crypto.GenerateKey()
+	if _, err := SignOffline(unsigned, signertest.NewSecp256k1(other).Signer()); errcode.Code(err) != errcode.CodeInvalidInput {
+		t.Errorf("got %v for another key, want an invalid input error", err)
+	}
+	unsigned.Sequence++
+	if _, err := SignOffline(unsigned, device); errcode.Code(err) != errcode.CodeInvalidInput {
+		t.Errorf("got %v for a modified tx, want an invalid input error", err)
+	}
 }
 
 func TestTransferERC20(t *testing.T) {
-	usr, _ := user.Current()
-	rootDir := usr.HomeDir
-	node := "https://kovan.infura.io/v3/ef4fee2bd9954c6c8303854e0dce1ffe"
-	name := "eth"
-	password := "wm131421"
-	toAddr := "0x1B37AB8d737B1776d3cC082D246Ee89Ed9693cD2"
-	tokenAddr := "0xc5d0ac103d253ca6fad4ec3170391ffab6fe5bb8"
-	//amount := int64(200000000000000000)
-	gasLimit := int64(210000)
-	tokenValue := "0.34"
-	gasPrice := "3"
-	output := TransferERC20(rootDir, node, name, password, toAddr, tokenAddr, tokenValue, gasPrice, gasLimit)
-	t.Log(output)
-}
-
-//func TestFormatFloat(t *testing.T) {
-//	tokenValue := "0.32"
-//	vamount, err := strconv.ParseFloat(tokenValue,32)
-//	if err != nil {
-//		log.Fatal(err)
-//	}
-//	vwei := vamount*1000000000000000000
-//	vstring := strconv.FormatFloat(vwei, 'f', -1, 32)
-//	//t.Log(vstring)
-//	Tamount := new(big.Int)
-//	//1000 token to transfer
-//	Tamount.SetString(vstring,10)
-//	t.Log(Tamount)
-//}
+	chain := newTestChain(t)
+	defer chain.Close()
+
+	output := TransferERC20(chain.rootDir, node, name, password, toAddr, chain.token.Hex(), "0.34", "3", 210000)
+	if !common.IsHexAddress(output[:42]) || len(chain.Pending(chain.from)) != 1 {
+		t.Fatalf("got %s, want the hash of the pending tx", output)
+	}
+	chain.Commit()
+
+	if got := chain.tokenBalance(t, toAddr); got != "34" {
+		t.Errorf("receiver has %s, want 34 in the smallest unit", got)
+	}
+	if got := chain.tokenBalance(t, chain.from.Hex()); got != "100016" {
+		t.Errorf("sender has %s, want 100016 in the smallest unit", got)
+	}
+
+	//the token has 2 decimals
+	_, err := SendERC20(context.Background(), chain.rootDir, node, name, password, toAddr, chain.token.Hex(), "0.345", "3", 210000)
+	if errcode.Code(err) != errcode.CodeInvalidInput {
+		t.Errorf("got %v for 3 decimals, want an invalid input error", err)
+	}
+}
+
+func TestParseUnits(t *testing.T) {
+	cases := []struct {
+		amount   string
+		decimals int
+		want     string
+	}{
+		{"0.00002", 18, "20000000000000"},
+		{"1.5", 18, "1500000000000000000"},
+		{"20", 9, "20000000000"},
+		{"0.34", 6, "340000"},
+	}
+	for _, c := range cases {
+		got, err := parseUnits(c.amount, c.decimals)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.String() != c.want {
+			t.Errorf("parseUnits(%q, %d) = %s, want %s", c.amount, c.decimals, got, c.want)
+		}
+	}
+	for _, bad := range []string{"", ".", "1.2.3", "-1", "1e18", "0.0000001"} {
+		if _, err := parseUnits(bad, 6); err == nil {
+			t.Errorf("parseUnits(%q) should fail", bad)
+		}
+	}
+}
 
 func TestGetPendingNonceAt(t *testing.T) {
-	usr, _ := user.Current()
-	rootDir := usr.HomeDir
-	node := "https://kovan.infura.io/v3/ef4fee2bd9954c6c8303854e0dce1ffe"
-	name := "easyzone"
-	password := "wm131421"
-	output := GetPendingNonceAt(rootDir, node, name, password)
-	t.Log(output)
+	chain := newTestChain(t)
+	defer chain.Close()
+
+	for i := 0; i < 2; i++ {
+		if _, err := SendETH(context.Background(), chain.rootDir, node, name, password, toAddr, "20", "1", 21000); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if output := GetPendingNonceAt(chain.rootDir, node, name, password); output != 2 {
+		t.Errorf("got pending nonce %d, want 2", output)
+	}
+	if output := GetNonceAt(chain.rootDir, node, name, password); output != 0 {
+		t.Errorf("got nonce %d before the block, want 0", output)
+	}
+
+	chain.Commit()
+	if output := GetNonceAt(chain.rootDir, node, name, password); output != 2 {
+		t.Errorf("got nonce %d after the block, want 2", output)
+	}
+	if output := GetPendingNonceAt(chain.rootDir, node, name, "wrong"); output != -1 {
+		t.Errorf("got pending nonce %d with a wrong password, want -1", output)
+	}
 }
 
 func TestSpeedTransferETH(t *testing.T) {
-	usr, _ := user.Current()
-	rootDir := usr.HomeDir
-	node := "https://kovan.infura.io/v3/ef4fee2bd9954c6c8303854e0dce1ffe"
-	name := "easyzone"
-	password := "wm131421"
-	toAddr := "0x1B37AB8d737B1776d3cC082D246Ee89Ed9693cD2"
-	amount := "0.000001"
-	gasLimit := int64(23000)
-	gasPrice := "200"
-	pendingNonce := int64(10)
-	output := SpeedTransferETH(rootDir, node, name, password, toAddr, gasPrice, amount, gasLimit, pendingNonce)
-	t.Log(output)
+	chain := newTestChain(t)
+	defer chain.Close()
+
+	ctx := context.Background()
+	slow, err := SendETH(ctx, chain.rootDir, node, name, password, toAddr, "2", "1", 21000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce, err := PendingNonce(ctx, chain.rootDir, node, name, password)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//the pending tx has nonce-1, the same gas price can not replace it
+	if _, err := ResendETH(ctx, chain.rootDir, node, name, password, toAddr, "2", "1", 21000, int64(nonce-1)); errcode.Code(err) != errcode.CodeNodeRejected {
+		t.Errorf("got %v, want an underpriced replacement rejected", err)
+	}
+	output := SpeedTransferETH(chain.rootDir, node, name, password, toAddr, "200", "1", 21000, int64(nonce-1))
+	pending := chain.Pending(chain.from)
+	if len(pending) != 1 || pending[0].Hash().Hex() != output || output == slow.Hex() {
+		t.Fatalf("got %s, want it to replace %s", output, slow.Hex())
+	}
+	chain.Commit()
+
+	if got := chain.balance(t, toAddr); got != ether.String() {
+		t.Errorf("receiver has %s wei, want 1 ether once", got)
+	}
+	if _, err := ResendETH(ctx, chain.rootDir, node, name, password, toAddr, "400", "1", 21000, int64(nonce-1)); errcode.Code(err) != errcode.CodeNodeRejected {
+		t.Errorf("got %v for a mined nonce, want a node rejected error", err)
+	}
+}
+
+func TestSpeedTransferERC20(t *testing.T) {
+	chain := newTestChain(t)
+	defer chain.Close()
+
+	if output := TransferERC20(chain.rootDir, node, name, password, toAddr, chain.token.Hex(), "1", "3", 210000); len(output) != 66 {
+		t.Fatal(output)
+	}
+	output := SpeedTransferERC20(chain.rootDir, node, name, password, toAddr, chain.token.Hex(), "2", "30", 210000, 0)
+	if pending := chain.Pending(chain.from); len(pending) != 1 || pending[0].Hash().Hex() != output {
+		t.Fatalf("got %s, want it to replace the pending transfer", output)
+	}
+	chain.Commit()
+
+	if got := chain.tokenBalance(t, toAddr); got != "200" {
+		t.Errorf("receiver has %s, want only the replacement of 200", got)
+	}
 }
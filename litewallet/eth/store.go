@@ -0,0 +1,121 @@
+package eth
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/QOSGroup/litewallet/litewallet/errcode"
+	dbm "github.com/tendermint/tendermint/libs/db"
+)
+
+//Each key is stored as two records: the info under name.info and the name of the info under address.addr.
+//The versions before the batch writes stored them one after the other without checking the names, so a
+//store may hold an info without its address record, address records of replaced or half written keys, or
+//an address under several names. CheckStore finds them and repairs what can be repaired without a password.
+
+//the kinds of the issues of a store
+const (
+	IssueMissingAddress   = "missing_address"   //an info has no address record
+	IssueStaleAddress     = "stale_address"     //an address record points to no info of that address
+	IssueDuplicateAddress = "duplicate_address" //an address is stored under several names
+	IssueUnreadableInfo   = "unreadable_info"   //an info record is not a key
+)
+
+//StoreIssue is an inconsistency of the ETH store, Repaired tells whether CheckStore fixed it
+type StoreIssue struct {
+	Kind     string   `json:"kind"`
+	Address  string   `json:"address,omitempty"`
+	Names    []string `json:"names,omitempty"`
+	Repaired bool     `json:"repaired"`
+}
+
+//CheckStore returns the inconsistencies of the ETH store under rootDir, and with repair writes the missing
+//address records and removes the stale ones. The duplicate addresses and the unreadable infos are left to
+//the user, who deletes the keys to drop with DeleteKey.
+func CheckStore(rootDir string, repair bool) ([]StoreIssue, error) {
+	db, err := dbm.NewGoLevelDB("keys", filepath.Join(rootDir, "ethkeys"))
+	if err != nil {
+		return nil, errcode.Internal(err)
+	}
+	defer db.Close()
+
+	infos := map[string]LocalInfo{}
+	//the names of each address and the address records, in the order of the store
+	names := map[string][]string{}
+	pointers := map[string]string{}
+	var addresses, pointerAddresses []string
+	issues := []StoreIssue{}
+	iter := db.Iterator(nil, nil)
+	for ; iter.Valid(); iter.Next() {
+		key := string(iter.Key())
+		switch {
+		case strings.HasSuffix(key, "."+infoSuffix):
+			name := strings.TrimSuffix(key, "."+infoSuffix)
+			info, err := readInfo(iter.Value())
+			if err != nil || info.Address == "" {
+				issues = append(issues, StoreIssue{Kind: IssueUnreadableInfo, Names: []string{name}})
+				continue
+			}
+			infos[name] = info
+			if len(names[info.Address]) == 0 {
+				addresses = append(addresses, info.Address)
+			}
+			names[info.Address] = append(names[info.Address], name)
+		case strings.HasSuffix(key, "."+addrSuffix):
+			address := strings.TrimSuffix(key, "."+addrSuffix)
+			pointers[address] = strings.TrimSuffix(string(iter.Value()), "."+infoSuffix)
+			pointerAddresses = append(pointerAddresses, address)
+		}
+	}
+	iter.Close()
+
+	batch := db.NewBatch()
+	defer batch.Close()
+	for _, address := range pointerAddresses {
+		name := pointers[address]
+		if info, ok := infos[name]; !ok || info.Address != address {
+			issues = append(issues, StoreIssue{Kind: IssueStaleAddress, Address: address, Names: []string{name}, Repaired: repair})
+			batch.Delete(addrKey(address))
+		}
+	}
+	for _, address := range addresses {
+		owners := names[address]
+		if len(owners) > 1 {
+			issues = append(issues, StoreIssue{Kind: IssueDuplicateAddress, Address: address, Names: owners})
+		}
+		//the record of an address under several names keeps the name it points to, or the first one
+		if name, ok := pointers[address]; ok && infos[name].Address == address {
+			continue
+		}
+		issues = append(issues, StoreIssue{Kind: IssueMissingAddress, Address: address, Names: owners[:1], Repaired: repair})
+		batch.Set(addrKey(address), infoKey(owners[0]))
+	}
+	if repair {
+		batch.WriteSync()
+	}
+	return issues, nil
+}
+
+//AddressName returns the name of the key of address stored under rootDir, empty when there is none
+func AddressName(rootDir, address string) (string, error) {
+	db, err := dbm.NewGoLevelDB("keys", filepath.Join(rootDir, "ethkeys"))
+	if err != nil {
+		return "", errcode.Internal(err)
+	}
+	defer db.Close()
+	name, _ := addressOwner(db, address)
+	return name, nil
+}
+
+//addressOwner returns the name of the key of address
+func addressOwner(db dbm.DB, address string) (string, bool) {
+	pointer := db.Get(addrKey(address))
+	if len(pointer) == 0 {
+		return "", false
+	}
+	info, err := readInfo(db.Get(pointer))
+	if err != nil || info.Address != address {
+		return "", false
+	}
+	return info.Name, true
+}
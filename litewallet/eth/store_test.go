@@ -0,0 +1,67 @@
+package eth
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	dbm "github.com/tendermint/tendermint/libs/db"
+)
+
+func TestCheckStore(t *testing.T) {
+	rootDir, err := ioutil.TempDir("", "ethkeys")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(rootDir)
+	seed := "monster soap pipe grief tourist marine turkey scatter because fade actual robust"
+	ko, err := CreateKey(rootDir, "alice", "wm131421", seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//the records the versions before the batch writes could leave
+	db, err := dbm.NewGoLevelDB("keys", filepath.Join(rootDir, "ethkeys"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, _ := getInfo(db, "alice")
+	info.Name = "bob"
+	bz, _ := json.Marshal(info)
+	db.SetSync(infoKey("bob"), bz)
+	db.SetSync(addrKey("0x1B37AB8d737B1776d3cC082D246Ee89Ed9693cD2"), infoKey("carol"))
+	db.SetSync(infoKey("dave"), []byte("{"))
+	db.Close()
+
+	issues, err := CheckStore(rootDir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kinds := map[string]StoreIssue{}
+	for _, issue := range issues {
+		kinds[issue.Kind] = issue
+	}
+	if len(issues) != 3 || kinds[IssueStaleAddress].Names[0] != "carol" || kinds[IssueUnreadableInfo].Names[0] != "dave" ||
+		len(kinds[IssueDuplicateAddress].Names) != 2 || kinds[IssueDuplicateAddress].Address != ko.Address {
+		t.Fatalf("got %+v", issues)
+	}
+
+	//the address records are repaired, the duplicate and the unreadable info are left
+	if _, err := CheckStore(rootDir, true); err != nil {
+		t.Fatal(err)
+	}
+	issues, _ = CheckStore(rootDir, false)
+	if len(issues) != 2 || issues[0].Kind != IssueUnreadableInfo || issues[1].Kind != IssueDuplicateAddress {
+		t.Errorf("got %+v, want the duplicate address and the unreadable info", issues)
+	}
+	if err := DeleteKey(rootDir, "bob", "wm131421"); err != nil {
+		t.Fatal(err)
+	}
+	//the record of the address went with bob, the repair points it to alice again
+	issues, _ = CheckStore(rootDir, true)
+	if len(issues) != 2 || issues[0].Kind != IssueUnreadableInfo || issues[1].Kind != IssueMissingAddress || issues[1].Names[0] != "alice" {
+		t.Errorf("got %+v, want the missing address of alice", issues)
+	}
+}
@@ -2,423 +2,373 @@ package eth
 
 import (
 	"context"
-	"crypto/ecdsa"
-	"fmt"
-	"log"
-	"math"
 	"math/big"
-	"strconv"
+	"strings"
 
+	"github.com/QOSGroup/litewallet/litewallet/errcode"
 	"github.com/QOSGroup/litewallet/litewallet/eth/contracts_erc20"
+	"github.com/QOSGroup/litewallet/litewallet/signer"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
 	"golang.org/x/crypto/sha3"
 )
 
 func TransferETH(rootDir, node, fromName, password, toAddr, gasPrice, amount string, GasLimit int64) string {
-	//fromName generated from keyspace locally
-	if fromName == "" {
-		fmt.Println("no fromName input!")
-	}
-	//Fetch the privateKey to sign
-	privateKey, err := FetchtoSign(rootDir, fromName, password)
-
-	publicKey := privateKey.Public()
-	publicKeyECDSA, ok := publicKey.(*ecdsa.PublicKey)
-	if !ok {
-		log.Fatal("cannot assert type: publicKey is not of type *ecdsa.PublicKey")
+	hash, err := SendETH(context.Background(), rootDir, node, fromName, password, toAddr, gasPrice, amount, GasLimit)
+	if err != nil {
+		return err.Error()
 	}
-	fromAddress := crypto.PubkeyToAddress(*publicKeyECDSA)
+	return hash.Hex()
+}
 
-	//setup the client, here use the infura own project "eth_wallet" node="https://kovan.infura.io/v3/ef4fee2bd9954c6c8303854e0dce1ffe"
-	client, err := ethclient.Dial(node)
+//SendETH signs a plain ETH transfer with the local key fromName and broadcasts it,
+//the amount is in ETH and the gasPrice in gwei
+func SendETH(ctx context.Context, rootDir, node, fromName, password, toAddr, gasPrice, amount string, gasLimit int64) (common.Hash, error) {
+	s, err := fetchSigner(rootDir, fromName, password)
 	if err != nil {
-		log.Fatal(err)
+		return common.Hash{}, err
 	}
+	return sendETH(ctx, node, s, toAddr, gasPrice, amount, gasLimit, nil)
+}
 
-	//get the nonce from the fromAddress to be dumped into tx
-	nonce, err := client.PendingNonceAt(context.Background(), fromAddress)
+//SendETHWithSigner is SendETH signed by s, a key which may live outside of the wallet
+func SendETHWithSigner(ctx context.Context, node string, s signer.Signer, toAddr, gasPrice, amount string, gasLimit int64) (common.Hash, error) {
+	return sendETH(ctx, node, s, toAddr, gasPrice, amount, gasLimit, nil)
+}
+
+//Transfer with ERC20 token
+func TransferERC20(rootDir, node, fromName, password, toAddr, tokenAddr, tokenValue, gasPrice string, GasLimit int64) string {
+	hash, err := SendERC20(context.Background(), rootDir, node, fromName, password, toAddr, tokenAddr, tokenValue, gasPrice, GasLimit)
 	if err != nil {
-		log.Fatal(err)
+		return err.Error()
 	}
+	return hash.Hex()
+}
 
-	//amount convertion to wei
-	Amount, err := strconv.ParseFloat(amount, 64)
+//SendERC20 signs an ERC20 transfer(address,uint256) call and broadcasts it,
+//the tokenValue is scaled by the decimals of the token contract
+func SendERC20(ctx context.Context, rootDir, node, fromName, password, toAddr, tokenAddr, tokenValue, gasPrice string, gasLimit int64) (common.Hash, error) {
+	s, err := fetchSigner(rootDir, fromName, password)
 	if err != nil {
-		log.Fatal(err)
+		return common.Hash{}, err
 	}
-	Amountwei := Amount * 1000000000000000000
-	value := big.NewInt(int64(Amountwei))
+	return sendERC20(ctx, node, s, toAddr, tokenAddr, tokenValue, gasPrice, gasLimit, nil)
+}
 
-	//value := big.NewInt(amount)
+//SendERC20WithSigner is SendERC20 signed by s, a key which may live outside of the wallet
+func SendERC20WithSigner(ctx context.Context, node string, s signer.Signer, toAddr, tokenAddr, tokenValue, gasPrice string, gasLimit int64) (common.Hash, error) {
+	return sendERC20(ctx, node, s, toAddr, tokenAddr, tokenValue, gasPrice, gasLimit, nil)
+}
 
-	//gasPrice fethced from ethgasstation then convert the gasPrice of string to gwei
-	gasAmount, err := strconv.ParseFloat(gasPrice, 64)
+//Deprecated in cshare for mobile!
+// PendingNonceAt returns the account nonce of the given account in the pending state.
+// This is the nonce that should be used for the next transaction.
+func GetPendingNonceAt(rootDir, node, fromName, password string) int64 {
+	nonce, err := PendingNonce(context.Background(), rootDir, node, fromName, password)
 	if err != nil {
-		log.Fatal(err)
+		return -1
 	}
-	gasgwei := gasAmount * 1000000000
-	bigGas := big.NewInt(int64(gasgwei))
-
-	//concert the to Address to byte format
-	toAddress := common.HexToAddress(toAddr)
-
-	gasLimit := uint64(GasLimit)
-	//Generate the Tx body, the data field is nil for just sending ETH
-	tx := types.NewTransaction(nonce, toAddress, value, gasLimit, bigGas, nil)
+	return int64(nonce)
+}
 
-	chainID, err := client.NetworkID(context.Background())
+//PendingNonce returns the nonce of the local key fromName in the pending state
+func PendingNonce(ctx context.Context, rootDir, node, fromName, password string) (uint64, error) {
+	fromAddress, err := keyAddress(rootDir, fromName, password)
 	if err != nil {
-		log.Fatal(err)
+		return 0, err
 	}
-
-	//sign the Tx
-	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(chainID), privateKey)
+	client, err := dial(ctx, node)
 	if err != nil {
-		log.Fatal(err)
+		return 0, err
 	}
+	defer client.Close()
+	nonce, err := client.PendingNonceAt(ctx, fromAddress)
+	return nonce, nodeError(err)
+}
 
-	//SendTransaction
-	err = client.SendTransaction(context.Background(), signedTx)
+//Speedup Tnx with Pending nonce
+func SpeedTransferETH(rootDir, node, fromName, password, toAddr, gasPrice, amount string, GasLimit, pendingNonce int64) string {
+	hash, err := ResendETH(context.Background(), rootDir, node, fromName, password, toAddr, gasPrice, amount, GasLimit, pendingNonce)
 	if err != nil {
-		log.Fatal(err)
+		return err.Error()
 	}
-
-	return signedTx.Hash().Hex()
-
+	return hash.Hex()
 }
 
-//Transfer with ERC20 token
-func TransferERC20(rootDir, node, fromName, password, toAddr, tokenAddr, tokenValue, gasPrice string, GasLimit int64) string {
-	//setup the client, here use the infura own project "eth_wallet" node="https://kovan.infura.io/v3/ef4fee2bd9954c6c8303854e0dce1ffe"
-	client, err := ethclient.Dial(node)
+//ResendETH is SendETH with an explicit nonce, used to replace a pending tx with a higher gasPrice
+func ResendETH(ctx context.Context, rootDir, node, fromName, password, toAddr, gasPrice, amount string, gasLimit, pendingNonce int64) (common.Hash, error) {
+	s, err := fetchSigner(rootDir, fromName, password)
 	if err != nil {
-		log.Fatal(err)
-	}
-	if fromName == "" {
-		fmt.Println("no fromName input!")
+		return common.Hash{}, err
 	}
-	//Fetch the privateKey to sign
-	privateKey, err := FetchtoSign(rootDir, fromName, password)
+	nonce := uint64(pendingNonce)
+	return sendETH(ctx, node, s, toAddr, gasPrice, amount, gasLimit, &nonce)
+}
+
+func SpeedTransferERC20(rootDir, node, fromName, password, toAddr, tokenAddr, tokenValue, gasPrice string, GasLimit, pendingNonce int64) string {
+	hash, err := ResendERC20(context.Background(), rootDir, node, fromName, password, toAddr, tokenAddr, tokenValue, gasPrice, GasLimit, pendingNonce)
 	if err != nil {
-		log.Fatal(err)
-	}
-	publicKey := privateKey.Public()
-	publicKeyECDSA, ok := publicKey.(*ecdsa.PublicKey)
-	if !ok {
-		log.Fatal("cannot assert type: publicKey is not of type *ecdsa.PublicKey")
+		return err.Error()
 	}
-	fromAddress := crypto.PubkeyToAddress(*publicKeyECDSA)
+	return hash.Hex()
+}
 
-	//get the nonce for this tx
-	nonce, err := client.PendingNonceAt(context.Background(), fromAddress)
+//ResendERC20 is SendERC20 with an explicit nonce, used to replace a pending tx with a higher gasPrice
+func ResendERC20(ctx context.Context, rootDir, node, fromName, password, toAddr, tokenAddr, tokenValue, gasPrice string, gasLimit, pendingNonce int64) (common.Hash, error) {
+	s, err := fetchSigner(rootDir, fromName, password)
 	if err != nil {
-		log.Fatal(err)
+		return common.Hash{}, err
 	}
+	nonce := uint64(pendingNonce)
+	return sendERC20(ctx, node, s, toAddr, tokenAddr, tokenValue, gasPrice, gasLimit, &nonce)
+}
 
-	//value is zero here for ERC20 tx
-	value := big.NewInt(0) // in wei (0 eth)
-	//set the amount and gasPrice for this Tx
-	//gasPrice, err := client.SuggestGasPrice(context.Background())
-	//if err != nil {
-	//	log.Fatal(err)
-	//}
-	//gasPrice fethced from ethgasstation then convert the gasPrice of string to gwei
-	gasAmount, err := strconv.ParseFloat(gasPrice, 64)
+//GetNonceAt return the nonce at latest block under the sepcific account.
+func GetNonceAt(rootDir, node, fromName, password string) int64 {
+	nonce, err := Nonce(context.Background(), rootDir, node, fromName, password)
 	if err != nil {
-		log.Fatal(err)
+		return -1
 	}
-	gasgwei := gasAmount * 1000000000
-	bigGas := big.NewInt(int64(gasgwei))
-
-	//the receiptant address
-	toAddress := common.HexToAddress(toAddr)
-
-	//begin smart contract part
-	tokenAddress := common.HexToAddress(tokenAddr)
-	transferFnSignature := []byte("transfer(address,uint256)")
-	hash := sha3.NewLegacyKeccak256()
-	hash.Write(transferFnSignature)
-	methodID := hash.Sum(nil)[:4]
-	paddedAddress := common.LeftPadBytes(toAddress.Bytes(), 32)
+	return int64(nonce)
+}
 
-	//fetch the decimals from the tokenAddress in smart contract
-	instance, err := contracts_erc20.NewContractsErc20(tokenAddress, client)
+//Nonce returns the nonce of the local key fromName at the latest block
+func Nonce(ctx context.Context, rootDir, node, fromName, password string) (uint64, error) {
+	fromAddress, err := keyAddress(rootDir, fromName, password)
 	if err != nil {
-		log.Fatal(err)
+		return 0, err
 	}
-	decimals, err := instance.Decimals(&bind.CallOpts{})
+	client, err := dial(ctx, node)
 	if err != nil {
-		log.Fatal(err)
+		return 0, err
 	}
-	digit := int(decimals)
-
-	//convert the tokenValue to decimals on corresponding ERC20
-	//vamount, err := strconv.ParseFloat(tokenValue,64)
-	//if err != nil {
-	//	log.Fatal(err)
-	//}
-	//
-	//vwei := vamount*1000000000000000000
-	//vstring := strconv.FormatFloat(vdigit.String(), 'f', -1, 32)
-
-	vbalance := new(big.Float)
-	vbalance.SetString(tokenValue)
-
-	vdigit := new(big.Float).Mul(vbalance, big.NewFloat(math.Pow10(digit)))
-	Tamount := new(big.Int)
-	//Tamount.SetString(vdigit.String(), 10)
-	f, _ := vdigit.Uint64()
-	Tamount.SetUint64(f)
-
-	paddedAmount := common.LeftPadBytes(Tamount.Bytes(), 32)
-
-	var data []byte
-	data = append(data, methodID...)
-	data = append(data, paddedAddress...)
-	data = append(data, paddedAmount...)
-
-	//gasLimit, err := client.EstimateGas(context.Background(), ethereum.CallMsg{
-	//	To: &tokenAddress,
-	//	Data: data,
-	//})
-	//if err != nil {
-	//	log.Fatal(err)
-	//}
+	defer client.Close()
+	nonce, err := client.NonceAt(ctx, fromAddress, nil)
+	return nonce, nodeError(err)
+}
 
-	//create a transaction
-	gasLimit := uint64(GasLimit)
-	tx := types.NewTransaction(nonce, tokenAddress, value, gasLimit, bigGas, data)
-	chainID, err := client.NetworkID(context.Background())
+func sendETH(ctx context.Context, node string, s signer.Signer, toAddr, gasPrice, amount string, gasLimit int64, nonce *uint64) (common.Hash, error) {
+	to, err := hexAddress(toAddr)
 	if err != nil {
-		log.Fatal(err)
+		return common.Hash{}, err
 	}
-	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(chainID), privateKey)
+	//amount convertion to wei
+	value, err := parseUnits(amount, 18)
 	if err != nil {
-		log.Fatal(err)
+		return common.Hash{}, err
 	}
-	err = client.SendTransaction(context.Background(), signedTx)
+
+	//setup the client, here use the infura own project "eth_wallet" node="https://kovan.infura.io/v3/ef4fee2bd9954c6c8303854e0dce1ffe"
+	client, err := dial(ctx, node)
 	if err != nil {
-		log.Fatal(err)
+		return common.Hash{}, err
 	}
+	defer client.Close()
 
-	return signedTx.Hash().Hex()
+	//the data field is nil for just sending ETH
+	return signAndSend(ctx, client, s, to, value, gasPrice, gasLimit, nonce, nil)
 }
 
-//Deprecated in cshare for mobile!
-// PendingNonceAt returns the account nonce of the given account in the pending state.
-// This is the nonce that should be used for the next transaction.
-func GetPendingNonceAt(rootDir, node, fromName, password string) int64 {
-	//fromName generated from keyspace locally
-	if fromName == "" {
-		fmt.Println("no fromName input!")
+func sendERC20(ctx context.Context, node string, s signer.Signer, toAddr, tokenAddr, tokenValue, gasPrice string, gasLimit int64, nonce *uint64) (common.Hash, error) {
+	to, err := hexAddress(toAddr)
+	if err != nil {
+		return common.Hash{}, err
 	}
-	//Fetch the privateKey to sign
-	privateKey, err := FetchtoSign(rootDir, fromName, password)
-
-	publicKey := privateKey.Public()
-	publicKeyECDSA, ok := publicKey.(*ecdsa.PublicKey)
-	if !ok {
-		log.Fatal("cannot assert type: publicKey is not of type *ecdsa.PublicKey")
+	tokenAddress, err := hexAddress(tokenAddr)
+	if err != nil {
+		return common.Hash{}, err
 	}
-	fromAddress := crypto.PubkeyToAddress(*publicKeyECDSA)
 
 	//setup the client, here use the infura own project "eth_wallet" node="https://kovan.infura.io/v3/ef4fee2bd9954c6c8303854e0dce1ffe"
-	client, err := ethclient.Dial(node)
+	client, err := dial(ctx, node)
 	if err != nil {
-		log.Fatal(err)
+		return common.Hash{}, err
 	}
+	defer client.Close()
 
-	//get the nonce from the fromAddress to be dumped into tx
-	nonce, err := client.PendingNonceAt(context.Background(), fromAddress)
+	data, err := erc20Transfer(ctx, client, to, tokenAddress, tokenValue)
 	if err != nil {
-		log.Fatal(err)
+		return common.Hash{}, err
 	}
-	nonceInt := int64(nonce)
-	//noncestr := strconv.FormatUint(nonce,10)
-	return nonceInt
+	//value is zero here for ERC20 tx
+	return signAndSend(ctx, client, s, tokenAddress, big.NewInt(0), gasPrice, gasLimit, nonce, data)
 }
 
-//Speedup Tnx with Pending nonce
-func SpeedTransferETH(rootDir, node, fromName, password, toAddr, gasPrice, amount string, GasLimit, pendingNonce int64) string {
-	//fromName generated from keyspace locally
-	if fromName == "" {
-		fmt.Println("no fromName input!")
-	}
-	//Fetch the privateKey to sign
-	privateKey, err := FetchtoSign(rootDir, fromName, password)
-	//setup the client, here use the infura own project "eth_wallet" node="https://kovan.infura.io/v3/ef4fee2bd9954c6c8303854e0dce1ffe"
-	client, err := ethclient.Dial(node)
+//erc20Transfer is the call data of the transfer of tokenValue to to, scaled by the decimals of the token
+func erc20Transfer(ctx context.Context, client Client, to, tokenAddress common.Address, tokenValue string) ([]byte, error) {
+	//fetch the decimals from the tokenAddress in smart contract
+	instance, err := contracts_erc20.NewContractsErc20(tokenAddress, client)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
-	//amount convertion to wei
-	Amount, err := strconv.ParseFloat(amount, 64)
+	decimals, err := instance.Decimals(&bind.CallOpts{Context: ctx})
 	if err != nil {
-		log.Fatal(err)
+		return nil, nodeError(err)
 	}
-	Amountwei := Amount * 1000000000000000000
-	value := big.NewInt(int64(Amountwei))
 
-	//value := big.NewInt(amount)
-
-	//gasPrice fethced from ethgasstation then convert the gasPrice of string to gwei
-	gasAmount, err := strconv.ParseFloat(gasPrice, 64)
+	//convert the tokenValue to decimals on corresponding ERC20
+	amount, err := parseUnits(tokenValue, int(decimals))
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
-	gasgwei := gasAmount * 1000000000
-	bigGas := big.NewInt(int64(gasgwei))
-
-	//concert the to Address to byte format
-	toAddress := common.HexToAddress(toAddr)
-
-	gasLimit := uint64(GasLimit)
-
-	//Generate the Tx body, the data field is nil for just sending ETH
-	nonce := uint64(pendingNonce)
-	tx := types.NewTransaction(nonce, toAddress, value, gasLimit, bigGas, nil)
+	return erc20TransferData(to, amount), nil
+}
 
-	chainID, err := client.NetworkID(context.Background())
+//signAndSend builds the tx, signs it with EIP155 on the network of the client and sends it,
+//a nil nonce means the pending nonce of the signer
+func signAndSend(ctx context.Context, client Client, s signer.Signer, to common.Address, value *big.Int, gasPrice string, gasLimit int64, nonce *uint64, data []byte) (common.Hash, error) {
+	from, err := SignerAddress(s)
 	if err != nil {
-		log.Fatal(err)
+		return common.Hash{}, err
+	}
+	tx, chainID, err := buildTx(ctx, client, from, to, value, gasPrice, gasLimit, nonce, data)
+	if err != nil {
+		return common.Hash{}, err
 	}
 
 	//sign the Tx
-	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(chainID), privateKey)
+	signedTx, err := signTx(tx, chainID, s)
 	if err != nil {
-		log.Fatal(err)
+		return common.Hash{}, err
 	}
 
 	//SendTransaction
-	err = client.SendTransaction(context.Background(), signedTx)
-	if err != nil {
-		log.Fatal(err)
+	if err := client.SendTransaction(ctx, signedTx); err != nil {
+		return common.Hash{}, nodeError(err)
 	}
-
-	return signedTx.Hash().Hex()
-
+	return signedTx.Hash(), nil
 }
 
-func SpeedTransferERC20(rootDir, node, fromName, password, toAddr, tokenAddr, tokenValue, gasPrice string, GasLimit, pendingNonce int64) string {
-	//setup the client, here use the infura own project "eth_wallet" node="https://kovan.infura.io/v3/ef4fee2bd9954c6c8303854e0dce1ffe"
-	client, err := ethclient.Dial(node)
-	if err != nil {
-		log.Fatal(err)
-	}
-	if fromName == "" {
-		fmt.Println("no fromName input!")
-	}
-	//Fetch the privateKey to sign
-	privateKey, err := FetchtoSign(rootDir, fromName, password)
-	if err != nil {
-		log.Fatal(err)
-	}
-	//value is zero here for ERC20 tx
-	value := big.NewInt(0) // in wei (0 eth)
-	//set the amount and gasPrice for this Tx
-	//gasPrice, err := client.SuggestGasPrice(context.Background())
-	//if err != nil {
-	//	log.Fatal(err)
-	//}
+//buildTx builds the unsigned tx of from and returns it with the chain id of the network of the client,
+//a nil nonce means the pending nonce of from
+func buildTx(ctx context.Context, client Client, from, to common.Address, value *big.Int, gasPrice string, gasLimit int64, nonce *uint64, data []byte) (*types.Transaction, *big.Int, error) {
 	//gasPrice fethced from ethgasstation then convert the gasPrice of string to gwei
-	gasAmount, err := strconv.ParseFloat(gasPrice, 64)
+	bigGas, err := parseUnits(gasPrice, 9)
 	if err != nil {
-		log.Fatal(err)
+		return nil, nil, err
 	}
-	gasgwei := gasAmount * 1000000000
-	bigGas := big.NewInt(int64(gasgwei))
-
-	//the receiptant address
-	toAddress := common.HexToAddress(toAddr)
 
-	//begin smart contract part
-	tokenAddress := common.HexToAddress(tokenAddr)
-	transferFnSignature := []byte("transfer(address,uint256)")
-	hash := sha3.NewLegacyKeccak256()
-	hash.Write(transferFnSignature)
-	methodID := hash.Sum(nil)[:4]
-	paddedAddress := common.LeftPadBytes(toAddress.Bytes(), 32)
+	//get the nonce from the fromAddress to be dumped into tx
+	if nonce == nil {
+		pending, err := client.PendingNonceAt(ctx, from)
+		if err != nil {
+			return nil, nil, nodeError(err)
+		}
+		nonce = &pending
+	}
 
-	//convert the tokenValue to wei in ERC20
-	vamount, err := strconv.ParseFloat(tokenValue, 64)
+	tx := types.NewTransaction(*nonce, to, value, uint64(gasLimit), bigGas, data)
+	chainID, err := client.NetworkID(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return nil, nil, nodeError(err)
 	}
-	vwei := vamount * 1000000000000000000
-	vstring := strconv.FormatFloat(vwei, 'f', -1, 32)
-
-	Tamount := new(big.Int)
-	//1000 token to transfer
-	Tamount.SetString(vstring, 10)
+	return tx, chainID, nil
+}
 
-	paddedAmount := common.LeftPadBytes(Tamount.Bytes(), 32)
+//erc20TransferData is the call data of transfer(address,uint256)
+func erc20TransferData(to common.Address, amount *big.Int) []byte {
+	hash := sha3.NewLegacyKeccak256()
+	hash.Write([]byte("transfer(address,uint256)"))
+	methodID := hash.Sum(nil)[:4]
 
 	var data []byte
 	data = append(data, methodID...)
-	data = append(data, paddedAddress...)
-	data = append(data, paddedAmount...)
-
-	//gasLimit, err := client.EstimateGas(context.Background(), ethereum.CallMsg{
-	//	To: &tokenAddress,
-	//	Data: data,
-	//})
-	//if err != nil {
-	//	log.Fatal(err)
-	//}
-
-	//create a transaction
-	gasLimit := uint64(GasLimit)
-	nonce := uint64(pendingNonce)
-	tx := types.NewTransaction(nonce, tokenAddress, value, gasLimit, bigGas, data)
-	chainID, err := client.NetworkID(context.Background())
+	data = append(data, common.LeftPadBytes(to.Bytes(), 32)...)
+	data = append(data, common.LeftPadBytes(amount.Bytes(), 32)...)
+	return data
+}
+
+//signTx signs tx with EIP155 on chainID, s signs the hash of the tx
+func signTx(tx *types.Transaction, chainID *big.Int, s signer.Signer) (*types.Transaction, error) {
+	txSigner := types.NewEIP155Signer(chainID)
+	sig, err := s.Sign(txSigner.Hash(tx).Bytes())
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
-	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(chainID), privateKey)
+	signedTx, err := tx.WithSignature(txSigner, sig)
 	if err != nil {
-		log.Fatal(err)
+		return nil, errcode.Internal(err)
 	}
-	err = client.SendTransaction(context.Background(), signedTx)
+	return signedTx, nil
+}
+
+//fetchSigner fetches the private key of the local account to sign
+func fetchSigner(rootDir, name, password string) (signer.Signer, error) {
+	//fromName generated from keyspace locally
+	if name == "" {
+		return nil, errMissingName()
+	}
+	privateKey, err := FetchtoSign(rootDir, name, password)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
+	return signer.NewSecp256k1(privateKey), nil
+}
 
-	return signedTx.Hash().Hex()
-
+func keyAddress(rootDir, name, password string) (common.Address, error) {
+	s, err := fetchSigner(rootDir, name, password)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return SignerAddress(s)
 }
 
-//GetNonceAt return the nonce at latest block under the sepcific account.
-func GetNonceAt(rootDir, node, fromName, password string) int64 {
-	//fromName generated from keyspace locally
-	if fromName == "" {
-		fmt.Println("no fromName input!")
+//SignerAddress returns the address of the secp256k1 signer s
+func SignerAddress(s signer.Signer) (common.Address, error) {
+	if s.Algo() != signer.AlgoSecp256k1 {
+		return common.Address{}, errcode.Errorf(errcode.CodeInvalidInput, "ETH signs with secp256k1, not %s", s.Algo())
 	}
-	//Fetch the privateKey to sign
-	privateKey, err := FetchtoSign(rootDir, fromName, password)
+	pub, err := crypto.DecompressPubkey(s.PubKey())
+	if err != nil {
+		return common.Address{}, errcode.InvalidInput(err)
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
 
-	publicKey := privateKey.Public()
-	publicKeyECDSA, ok := publicKey.(*ecdsa.PublicKey)
-	if !ok {
-		log.Fatal("cannot assert type: publicKey is not of type *ecdsa.PublicKey")
+//parseUnits converts a decimal string like "1.5" to an integer amount of the
+//smallest unit, e.g. wei for 18 decimals, without going through float64
+func parseUnits(amount string, decimals int) (*big.Int, error) {
+	amount = strings.TrimSpace(amount)
+	intPart, fracPart := amount, ""
+	if i := strings.IndexByte(amount, '.'); i >= 0 {
+		intPart, fracPart = amount[:i], amount[i+1:]
+	}
+	if len(fracPart) > decimals {
+		return nil, errcode.Errorf(errcode.CodeInvalidInput, "amount %q has more than %d decimal places", amount, decimals)
+	}
+	digits := intPart + fracPart + strings.Repeat("0", decimals-len(fracPart))
+	if intPart+fracPart == "" || strings.TrimLeft(digits, "0123456789") != "" {
+		return nil, errcode.Errorf(errcode.CodeInvalidInput, "invalid amount %q", amount)
 	}
-	fromAddress := crypto.PubkeyToAddress(*publicKeyECDSA)
+	value, _ := new(big.Int).SetString(digits, 10)
+	return value, nil
+}
 
-	//setup the client, here use the infura own project "eth_wallet" node="https://kovan.infura.io/v3/ef4fee2bd9954c6c8303854e0dce1ffe"
-	client, err := ethclient.Dial(node)
-	if err != nil {
-		log.Fatal(err)
+//hexAddress parses a 0x hex address, common.HexToAddress alone would accept any string
+func hexAddress(addr string) (common.Address, error) {
+	if !common.IsHexAddress(addr) {
+		return common.Address{}, errcode.Errorf(errcode.CodeInvalidInput, "invalid address %q", addr)
 	}
+	return common.HexToAddress(addr), nil
+}
 
-	//get the nonce from the fromAddress to be dumped into tx
-	nonce, err := client.NonceAt(context.Background(), fromAddress, nil)
-	if err != nil {
-		log.Fatal(err)
+//nodeError categorizes the error of a call to the node: the json-rpc errors are answers of the node,
+//the rest did not get an answer
+func nodeError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if err == bind.ErrNoCode {
+		return errcode.InvalidInput(err)
+	}
+	if _, ok := err.(rpc.Error); ok {
+		if strings.Contains(err.Error(), "insufficient funds") {
+			return errcode.Wrap(errcode.CodeInsufficientFunds, err)
+		}
+		return errcode.NodeRejected(err)
 	}
-	nonceInt := int64(nonce)
-	//noncestr := strconv.FormatUint(nonce,10)
-	return nonceInt
+	return errcode.Network(err)
 }
@@ -0,0 +1,525 @@
+package eth
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/QOSGroup/litewallet/litewallet/errcode"
+	"github.com/QOSGroup/litewallet/litewallet/signer"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+//domainType is the type of the domain of the typed data, the dApp and chain the signature is for
+const domainType = "EIP712Domain"
+
+//TypedData is an EIP-712 typed data document, the JSON eth_signTypedData_v4 takes. The numbers of the
+//domain and message are JSON numbers, decimal strings or 0x hex strings, the bytes are 0x hex strings.
+type TypedData struct {
+	Types       map[string][]TypedDataField `json:"types"`
+	PrimaryType string                      `json:"primaryType"`
+	Domain      map[string]interface{}      `json:"domain"`
+	Message     map[string]interface{}      `json:"message"`
+}
+
+//TypedDataField is a field of a type of the typed data
+type TypedDataField struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+//ParseTypedData parses the JSON typed data doc and checks its types and data
+func ParseTypedData(doc string) (*TypedData, error) {
+	dec := json.NewDecoder(strings.NewReader(doc))
+	//the numbers keep their digits instead of being rounded to float64
+	dec.UseNumber()
+	var td TypedData
+	if err := dec.Decode(&td); err != nil {
+		return nil, errcode.InvalidInput(err)
+	}
+	if err := td.checkTypes(); err != nil {
+		return nil, err
+	}
+	if _, err := td.Hash(); err != nil {
+		return nil, err
+	}
+	return &td, nil
+}
+
+//checkTypes checks the domain and primary types are declared and every field is of a known type
+func (td *TypedData) checkTypes() error {
+	if _, ok := td.Types[domainType]; !ok {
+		return errcode.Errorf(errcode.CodeInvalidInput, "the typed data has no %s type", domainType)
+	}
+	if _, ok := td.Types[td.PrimaryType]; !ok {
+		return errcode.Errorf(errcode.CodeInvalidInput, "unknown primary type %q", td.PrimaryType)
+	}
+	for name, fields := range td.Types {
+		if name == "" || strings.ContainsAny(name, "[](), ") {
+			return errcode.Errorf(errcode.CodeInvalidInput, "invalid type name %q", name)
+		}
+		names := make(map[string]bool, len(fields))
+		for _, f := range fields {
+			if f.Name == "" || names[f.Name] {
+				return errcode.Errorf(errcode.CodeInvalidInput, "invalid or duplicate field %q of %s", f.Name, name)
+			}
+			names[f.Name] = true
+			if !td.knownType(f.Type) {
+				return errcode.Errorf(errcode.CodeInvalidInput, "unknown type %q of %s.%s", f.Type, name, f.Name)
+			}
+		}
+	}
+	return nil
+}
+
+//knownType tells whether typ is an atomic, dynamic or declared struct type, or an array of them
+func (td *TypedData) knownType(typ string) bool {
+	if elemType, _, ok := arrayType(typ); ok {
+		return td.knownType(elemType)
+	}
+	if _, ok := td.Types[typ]; ok {
+		return true
+	}
+	switch typ {
+	case "address", "bool", "string", "bytes":
+		return true
+	}
+	if n, ok := sizedType(typ, "bytes"); ok {
+		return n >= 1 && n <= 32
+	}
+	if n, ok := intType(typ); ok {
+		return n >= 8 && n <= 256 && n%8 == 0
+	}
+	return false
+}
+
+//EncodeType returns the encoding of typ, followed by the struct types it references sorted by name
+func (td *TypedData) EncodeType(typ string) string {
+	deps := make(map[string]bool)
+	td.dependencies(typ, deps)
+	delete(deps, typ)
+	names := make([]string, 0, len(deps))
+	for name := range deps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range append([]string{typ}, names...) {
+		fields := make([]string, len(td.Types[name]))
+		for i, f := range td.Types[name] {
+			fields[i] = f.Type + " " + f.Name
+		}
+		fmt.Fprintf(&b, "%s(%s)", name, strings.Join(fields, ","))
+	}
+	return b.String()
+}
+
+//dependencies adds typ and the struct types it references to found
+func (td *TypedData) dependencies(typ string, found map[string]bool) {
+	for {
+		elemType, _, ok := arrayType(typ)
+		if !ok {
+			break
+		}
+		typ = elemType
+	}
+	if _, ok := td.Types[typ]; !ok || found[typ] {
+		return
+	}
+	found[typ] = true
+	for _, f := range td.Types[typ] {
+		td.dependencies(f.Type, found)
+	}
+}
+
+//TypeHash is the keccak256 of the encoding of typ
+func (td *TypedData) TypeHash(typ string) []byte {
+	return crypto.Keccak256([]byte(td.EncodeType(typ)))
+}
+
+//HashStruct is the keccak256 of the type hash of typ followed by the encoded fields of data
+func (td *TypedData) HashStruct(typ string, data map[string]interface{}) ([]byte, error) {
+	fields := td.Types[typ]
+	for name := range data {
+		if !hasField(fields, name) {
+			return nil, errcode.Errorf(errcode.CodeInvalidInput, "%s has no field %q", typ, name)
+		}
+	}
+	enc := td.TypeHash(typ)
+	for _, f := range fields {
+		v, ok := data[f.Name]
+		if !ok {
+			return nil, errcode.Errorf(errcode.CodeInvalidInput, "missing the field %s.%s", typ, f.Name)
+		}
+		value, err := td.encodeValue(f.Type, v)
+		if err != nil {
+			return nil, errcode.Errorf(errcode.CodeInvalidInput, "%s.%s: %v", typ, f.Name, err)
+		}
+		enc = append(enc, value...)
+	}
+	return crypto.Keccak256(enc), nil
+}
+
+//DomainSeparator is the hash of the domain
+func (td *TypedData) DomainSeparator() ([]byte, error) {
+	return td.HashStruct(domainType, td.Domain)
+}
+
+//Hash is the digest the key signs, the keccak256 of 0x1901, the domain separator and the hash of the message
+func (td *TypedData) Hash() ([]byte, error) {
+	domainSeparator, err := td.DomainSeparator()
+	if err != nil {
+		return nil, err
+	}
+	enc := append([]byte{0x19, 0x01}, domainSeparator...)
+	//a domain alone has no message
+	if td.PrimaryType != domainType {
+		msgHash, err := td.HashStruct(td.PrimaryType, td.Message)
+		if err != nil {
+			return nil, err
+		}
+		enc = append(enc, msgHash...)
+	}
+	return crypto.Keccak256(enc), nil
+}
+
+//encodeValue encodes v of typ in 32 bytes, the arrays, structs, strings and bytes by their hash
+func (td *TypedData) encodeValue(typ string, v interface{}) ([]byte, error) {
+	if elemType, size, ok := arrayType(typ); ok {
+		items, err := typedArray(v, size)
+		if err != nil {
+			return nil, err
+		}
+		var enc []byte
+		for i, item := range items {
+			value, err := td.encodeValue(elemType, item)
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %v", i, err)
+			}
+			enc = append(enc, value...)
+		}
+		return crypto.Keccak256(enc), nil
+	}
+	if _, ok := td.Types[typ]; ok {
+		data, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("got %v, want a %s object", v, typ)
+		}
+		return td.HashStruct(typ, data)
+	}
+
+	switch typ {
+	case "string":
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("got %v, want a string", v)
+		}
+		return crypto.Keccak256([]byte(s)), nil
+	case "bytes":
+		b, err := typedBytes(v)
+		if err != nil {
+			return nil, err
+		}
+		return crypto.Keccak256(b), nil
+	case "bool":
+		b, ok := v.(bool)
+		if !ok {
+			return nil, fmt.Errorf("got %v, want a bool", v)
+		}
+		if b {
+			return math.PaddedBigBytes(big.NewInt(1), 32), nil
+		}
+		return make([]byte, 32), nil
+	case "address":
+		addr, err := typedAddress(v)
+		if err != nil {
+			return nil, err
+		}
+		return common.LeftPadBytes(addr.Bytes(), 32), nil
+	}
+	if n, ok := sizedType(typ, "bytes"); ok {
+		b, err := typedBytes(v)
+		if err != nil {
+			return nil, err
+		}
+		if len(b) != n {
+			return nil, fmt.Errorf("got %d bytes, want %d", len(b), n)
+		}
+		return common.RightPadBytes(b, 32), nil
+	}
+	if _, ok := intType(typ); ok {
+		x, err := typedInt(typ, v)
+		if err != nil {
+			return nil, err
+		}
+		//the negative ints are in two's complement
+		return math.PaddedBigBytes(math.U256(new(big.Int).Set(x)), 32), nil
+	}
+	return nil, fmt.Errorf("unknown type %q", typ)
+}
+
+//Summary renders the domain and message as indented lines of the field names and values in the order of
+//their types, for the user to read before signing
+func (td *TypedData) Summary() (string, error) {
+	var b strings.Builder
+	if err := td.render(&b, 0, "Domain", domainType, td.Domain); err != nil {
+		return "", err
+	}
+	if td.PrimaryType != domainType {
+		if err := td.render(&b, 0, td.PrimaryType, td.PrimaryType, td.Message); err != nil {
+			return "", err
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+//render writes the value v of typ named name with the fields of the structs and the items of the arrays below
+func (td *TypedData) render(b *strings.Builder, depth int, name, typ string, v interface{}) error {
+	indent := strings.Repeat("  ", depth)
+	if elemType, size, ok := arrayType(typ); ok {
+		items, err := typedArray(v, size)
+		if err != nil {
+			return err
+		}
+		if len(items) == 1 {
+			fmt.Fprintf(b, "%s%s: 1 item\n", indent, name)
+		} else {
+			fmt.Fprintf(b, "%s%s: %d items\n", indent, name, len(items))
+		}
+		for i, item := range items {
+			if err := td.render(b, depth+1, fmt.Sprintf("[%d]", i), elemType, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if fields, ok := td.Types[typ]; ok {
+		data, ok := v.(map[string]interface{})
+		if !ok {
+			return errcode.Errorf(errcode.CodeInvalidInput, "%s: got %v, want a %s object", name, v, typ)
+		}
+		fmt.Fprintf(b, "%s%s:\n", indent, name)
+		for _, f := range fields {
+			//the domains only have some of their fields
+			fv, ok := data[f.Name]
+			if !ok {
+				continue
+			}
+			if err := td.render(b, depth+1, f.Name, f.Type, fv); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	value, err := formatValue(typ, v)
+	if err != nil {
+		return errcode.Errorf(errcode.CodeInvalidInput, "%s: %v", name, err)
+	}
+	fmt.Fprintf(b, "%s%s: %s\n", indent, name, value)
+	return nil
+}
+
+//formatValue formats the atomic or dynamic v of typ: the addresses checksummed, the numbers in decimal and
+//the bytes in 0x hex
+func formatValue(typ string, v interface{}) (string, error) {
+	switch typ {
+	case "string":
+		s, ok := v.(string)
+		if !ok {
+			return "", fmt.Errorf("got %v, want a string", v)
+		}
+		//a string can not fake the lines of other fields
+		if strings.IndexFunc(s, func(r rune) bool { return !unicode.IsGraphic(r) }) >= 0 {
+			return strconv.Quote(s), nil
+		}
+		return s, nil
+	case "bool":
+		b, ok := v.(bool)
+		if !ok {
+			return "", fmt.Errorf("got %v, want a bool", v)
+		}
+		return strconv.FormatBool(b), nil
+	case "address":
+		addr, err := typedAddress(v)
+		if err != nil {
+			return "", err
+		}
+		return addr.Hex(), nil
+	}
+	if _, ok := intType(typ); ok {
+		x, err := typedInt(typ, v)
+		if err != nil {
+			return "", err
+		}
+		return x.String(), nil
+	}
+	b, err := typedBytes(v)
+	if err != nil {
+		return "", err
+	}
+	return hexutil.Encode(b), nil
+}
+
+//SignTypedData signs the typed data with the local key name, the signature is R||S||V with V 27 or 28
+func SignTypedData(rootDir, name, password string, td *TypedData) ([]byte, error) {
+	s, err := fetchSigner(rootDir, name, password)
+	if err != nil {
+		return nil, err
+	}
+	return SignTypedDataWithSigner(s, td)
+}
+
+//SignTypedDataWithSigner is SignTypedData signed by s
+func SignTypedDataWithSigner(s signer.Signer, td *TypedData) ([]byte, error) {
+	hash, err := td.Hash()
+	if err != nil {
+		return nil, err
+	}
+	return signHash(s, hash)
+}
+
+//RecoverTypedDataSigner returns the address which signed the typed data, V is 27 or 28, or 0 or 1
+func RecoverTypedDataSigner(td *TypedData, sig []byte) (common.Address, error) {
+	hash, err := td.Hash()
+	if err != nil {
+		return common.Address{}, err
+	}
+	return recoverSigner(hash, sig)
+}
+
+//VerifyTypedData tells whether sig is the signature of the typed data by the address addr
+func VerifyTypedData(addr string, td *TypedData, sig []byte) (bool, error) {
+	want, err := hexAddress(addr)
+	if err != nil {
+		return false, err
+	}
+	got, err := RecoverTypedDataSigner(td, sig)
+	if err != nil {
+		return false, err
+	}
+	return got == want, nil
+}
+
+func hasField(fields []TypedDataField, name string) bool {
+	for _, f := range fields {
+		if f.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+//arrayType splits the array type typ in the type of its items and its size, -1 for the dynamic arrays
+func arrayType(typ string) (string, int, bool) {
+	if !strings.HasSuffix(typ, "]") {
+		return "", 0, false
+	}
+	i := strings.LastIndexByte(typ, '[')
+	if i <= 0 {
+		return "", 0, false
+	}
+	if typ[i+1:len(typ)-1] == "" {
+		return typ[:i], -1, true
+	}
+	size, err := strconv.Atoi(typ[i+1 : len(typ)-1])
+	if err != nil || size < 0 {
+		return "", 0, false
+	}
+	return typ[:i], size, true
+}
+
+//sizedType returns the size of the type typ named prefix followed by its size, like bytes32
+func sizedType(typ, prefix string) (int, bool) {
+	if !strings.HasPrefix(typ, prefix) || len(typ) == len(prefix) {
+		return 0, false
+	}
+	n, err := strconv.Atoi(typ[len(prefix):])
+	if err != nil || strconv.Itoa(n) != typ[len(prefix):] {
+		return 0, false
+	}
+	return n, true
+}
+
+//intType returns the bits of the uintN or intN type typ
+func intType(typ string) (int, bool) {
+	if n, ok := sizedType(typ, "uint"); ok {
+		return n, true
+	}
+	return sizedType(typ, "int")
+}
+
+func typedArray(v interface{}, size int) ([]interface{}, error) {
+	items, ok := v.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("got %v, want an array", v)
+	}
+	if size >= 0 && len(items) != size {
+		return nil, fmt.Errorf("got %d items, want %d", len(items), size)
+	}
+	return items, nil
+}
+
+func typedAddress(v interface{}) (common.Address, error) {
+	s, ok := v.(string)
+	if !ok || !common.IsHexAddress(s) {
+		return common.Address{}, fmt.Errorf("invalid address %v", v)
+	}
+	return common.HexToAddress(s), nil
+}
+
+func typedBytes(v interface{}) ([]byte, error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("got %v, want 0x hex bytes", v)
+	}
+	b, err := hexutil.Decode(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bytes %q: %v", s, err)
+	}
+	return b, nil
+}
+
+//typedInt parses the number v of the uintN or intN type typ and checks it fits in it
+func typedInt(typ string, v interface{}) (*big.Int, error) {
+	var s string
+	switch n := v.(type) {
+	case json.Number:
+		s = n.String()
+	case string:
+		s = n
+	case float64:
+		//the typed data built without ParseTypedData
+		s = strconv.FormatFloat(n, 'f', -1, 64)
+	default:
+		return nil, fmt.Errorf("got %v, want a number", v)
+	}
+	x, ok := new(big.Int), false
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		x, ok = x.SetString(s[2:], 16)
+	} else {
+		x, ok = x.SetString(s, 10)
+	}
+	if !ok {
+		return nil, fmt.Errorf("invalid number %q", s)
+	}
+
+	bits, _ := intType(typ)
+	min, max := new(big.Int), new(big.Int).Lsh(big.NewInt(1), uint(bits))
+	if strings.HasPrefix(typ, "int") {
+		max.Rsh(max, 1)
+		min.Neg(max)
+	}
+	//min <= x < max
+	if x.Cmp(min) < 0 || x.Cmp(max) >= 0 {
+		return nil, fmt.Errorf("%s out of the range of %s", s, typ)
+	}
+	return x, nil
+}
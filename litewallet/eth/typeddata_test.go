@@ -0,0 +1,237 @@
+package eth
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/QOSGroup/litewallet/litewallet/errcode"
+	"github.com/QOSGroup/litewallet/litewallet/signer"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+//mailTypedData is the example of EIP-712
+const mailTypedData = `{
+	"types": {
+		"EIP712Domain": [
+			{"name": "name", "type": "string"},
+			{"name": "version", "type": "string"},
+			{"name": "chainId", "type": "uint256"},
+			{"name": "verifyingContract", "type": "address"}
+		],
+		"Person": [
+			{"name": "name", "type": "string"},
+			{"name": "wallet", "type": "address"}
+		],
+		"Mail": [
+			{"name": "from", "type": "Person"},
+			{"name": "to", "type": "Person"},
+			{"name": "contents", "type": "string"}
+		]
+	},
+	"primaryType": "Mail",
+	"domain": {
+		"name": "Ether Mail",
+		"version": "1",
+		"chainId": 1,
+		"verifyingContract": "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"
+	},
+	"message": {
+		"from": {"name": "Cow", "wallet": "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"},
+		"to": {"name": "Bob", "wallet": "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"},
+		"contents": "Hello, Bob!"
+	}
+}`
+
+func TestTypedDataHash(t *testing.T) {
+	td, err := ParseTypedData(mailTypedData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := td.EncodeType("Mail"), "Mail(Person from,Person to,string contents)Person(string name,address wallet)"; got != want {
+		t.Errorf("got the type %s, want %s", got, want)
+	}
+	if got, want := hexutil.Encode(td.TypeHash("Mail")), "0xa0cedeb2dc280ba39b857546d74f5549c3a1d7bdc2dd96bf881f76108e23dac2"; got != want {
+		t.Errorf("got the type hash %s, want %s", got, want)
+	}
+	msgHash, err := td.HashStruct("Mail", td.Message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := hexutil.Encode(msgHash), "0xc52c0ee5d84264471806290a3f2c4cecfc5490626bf912d01f240d7a274b371e"; got != want {
+		t.Errorf("got the message hash %s, want %s", got, want)
+	}
+	domainSeparator, err := td.DomainSeparator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := hexutil.Encode(domainSeparator), "0xf2cee375fa42b42143804025fc449deafd50cc031ca257e0b194a650a912090f"; got != want {
+		t.Errorf("got the domain separator %s, want %s", got, want)
+	}
+	hash, err := td.Hash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := hexutil.Encode(hash), "0xbe609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2"; got != want {
+		t.Errorf("got the hash %s, want %s", got, want)
+	}
+
+	//the key of the example is the keccak256 of "cow"
+	key, err := crypto.ToECDSA(crypto.Keccak256([]byte("cow")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := SignTypedDataWithSigner(signer.NewSecp256k1(key), td)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "0x4355c47d63924e8a72e509b65029052eb6c299d53a04e167c5775fd466751c9d" +
+		"07299936d304c153f6443dfa05f40ff007d72911b6f72307f996231605b91562" + "1c"
+	if got := hexutil.Encode(sig); got != want {
+		t.Errorf("got the signature %s, want %s", got, want)
+	}
+	if ok, err := VerifyTypedData("0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826", td, sig); err != nil || !ok {
+		t.Errorf("got %v %v, want the signature verified", ok, err)
+	}
+}
+
+func TestParseTypedData(t *testing.T) {
+	for name, edit := range map[string]func(string) string{
+		"no domain type":  func(doc string) string { return strings.Replace(doc, `"EIP712Domain"`, `"Domain"`, 1) },
+		"unknown primary": func(doc string) string { return strings.Replace(doc, `"primaryType": "Mail"`, `"primaryType": "Letter"`, 1) },
+		"unknown type":    func(doc string) string { return strings.Replace(doc, `"type": "Person"`, `"type": "Persona"`, 1) },
+		"invalid size":    func(doc string) string { return strings.Replace(doc, `"uint256"`, `"uint257"`, 1) },
+		"missing field":   func(doc string) string { return strings.Replace(doc, `"contents": "Hello, Bob!"`, `"content": "Hello, Bob!"`, 1) },
+		"invalid address": func(doc string) string { return strings.Replace(doc, `"0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"`, `"Bob"`, 1) },
+		"negative uint":   func(doc string) string { return strings.Replace(doc, `"chainId": 1`, `"chainId": -1`, 1) },
+		"not a number":    func(doc string) string { return strings.Replace(doc, `"chainId": 1`, `"chainId": 1.5`, 1) },
+		"not JSON":        func(doc string) string { return doc[1:] },
+	} {
+		if _, err := ParseTypedData(edit(mailTypedData)); errcode.Code(err) != errcode.CodeInvalidInput {
+			t.Errorf("%s: got %v, want an invalid input error", name, err)
+		}
+	}
+
+	//the chain id as a decimal or hex string is the same number
+	for _, chainID := range []string{`"1"`, `"0x1"`} {
+		td, err := ParseTypedData(strings.Replace(mailTypedData, `"chainId": 1`, `"chainId": `+chainID, 1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hash, _ := td.Hash(); hexutil.Encode(hash) != "0xbe609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2" {
+			t.Errorf("got the hash %x for the chain id %s", hash, chainID)
+		}
+	}
+}
+
+func TestTypedDataArrays(t *testing.T) {
+	td, err := ParseTypedData(`{
+		"types": {
+			"EIP712Domain": [{"name": "name", "type": "string"}, {"name": "chainId", "type": "uint256"}],
+			"Order": [
+				{"name": "maker", "type": "address"},
+				{"name": "amounts", "type": "int64[2]"},
+				{"name": "salt", "type": "bytes32"},
+				{"name": "data", "type": "bytes"},
+				{"name": "fills", "type": "Fill[]"},
+				{"name": "partial", "type": "bool"}
+			],
+			"Fill": [{"name": "taker", "type": "address"}, {"name": "note", "type": "string"}]
+		},
+		"primaryType": "Order",
+		"domain": {"name": "Exchange", "chainId": "0x3"},
+		"message": {
+			"maker": "0xcd2a3d9f938e13cd947ec05abc7fe734df8dd826",
+			"amounts": [-5, "1000"],
+			"salt": "0x000000000000000000000000000000000000000000000000000000000000002a",
+			"data": "0xdeadbeef",
+			"fills": [{"taker": "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB", "note": "first\nApprove all"}],
+			"partial": true
+		}
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := td.EncodeType("Order"), "Order(address maker,int64[2] amounts,bytes32 salt,bytes data,Fill[] fills,bool partial)Fill(address taker,string note)"; got != want {
+		t.Errorf("got the type %s, want %s", got, want)
+	}
+	//the negative ints are in two's complement
+	enc, err := td.encodeValue("int64", float64(-5))
+	if err != nil || hexutil.Encode(enc) != "0xfffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffb" {
+		t.Errorf("got %x %v, want -5 in two's complement", enc, err)
+	}
+	if _, err := td.encodeValue("int64[2]", []interface{}{float64(1)}); err == nil {
+		t.Error("got no error for a short fixed array")
+	}
+	if _, err := td.encodeValue("bytes32", "0xdeadbeef"); err == nil {
+		t.Error("got no error for a short bytes32")
+	}
+
+	summary, err := td.Summary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `Domain:
+  name: Exchange
+  chainId: 3
+Order:
+  maker: 0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826
+  amounts: 2 items
+    [0]: -5
+    [1]: 1000
+  salt: 0x000000000000000000000000000000000000000000000000000000000000002a
+  data: 0xdeadbeef
+  fills: 1 item
+    [0]:
+      taker: 0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB
+      note: "first\nApprove all"
+  partial: true`
+	if summary != want {
+		t.Errorf("got the summary\n%s\nwant\n%s", summary, want)
+	}
+}
+
+func TestSignTypedData(t *testing.T) {
+	rootDir, err := ioutil.TempDir("", "ethkeys")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(rootDir)
+	seed := "monster soap pipe grief tourist marine turkey scatter because fade actual robust"
+	key, err := CreateKey(rootDir, "eth13", "wm131421", seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	td, err := ParseTypedData(mailTypedData)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sig, err := SignTypedData(rootDir, "eth13", "wm131421", td)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if addr, err := RecoverTypedDataSigner(td, sig); err != nil || addr.Hex() != key.Address {
+		t.Errorf("recovered %s %v, want %s", addr.Hex(), err, key.Address)
+	}
+	if ok, err := VerifyTypedData(key.Address, td, sig); err != nil || !ok {
+		t.Errorf("got %v %v, want the signature verified", ok, err)
+	}
+	//a signature of the message is not one of the typed data
+	td.Message["contents"] = "Hello, Alice!"
+	if ok, err := VerifyTypedData(key.Address, td, sig); err != nil || ok {
+		t.Errorf("got %v %v, want another message rejected", ok, err)
+	}
+	if _, err := SignTypedData(rootDir, "eth13", "wrong", td); errcode.Code(err) != errcode.CodeWrongPassword {
+		t.Errorf("wrong password: %v", err)
+	}
+	privateKey, err := FetchtoSign(rootDir, "eth13", "wm131421")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := SignTypedDataWithSigner(shortSigner{signer.NewSecp256k1(privateKey)}, td); errcode.Code(err) != errcode.CodeInternal {
+		t.Errorf("got %v for a short signature, want an internal error", err)
+	}
+}
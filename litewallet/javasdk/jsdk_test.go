@@ -1,30 +1,83 @@
 package javasdk
 
 import (
-	"os/user"
+	"encoding/json"
+	"io/ioutil"
+	"os"
 	"testing"
+
+	"github.com/QOSGroup/litewallet/litewallet/rpctest/cosmos"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+)
+
+const (
+	chainId  = "test4matt"
+	fromName = "local"
+	password = "wm131421"
+	seed     = "tomorrow room limit true galaxy dove chicken fine resemble tonight record yellow"
+	toStr    = "cosmos1mrf49r22adtd8juv6kvg8dxly32qlj7rg47644"
 )
 
+//newChain starts a chain where the local key fromName holds 1000stake and 1000token
+func newChain(t *testing.T) (*cosmos.Chain, string) {
+	rootDir, err := ioutil.TempDir("", "javasdk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := RecoverKey(rootDir, fromName, password, seed)
+	var key struct {
+		Address string `json:"address"`
+	}
+	if err := json.Unmarshal([]byte(out), &key); err != nil {
+		t.Fatal(out)
+	}
+	addr, err := sdk.AccAddressFromBech32(key.Address)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chain := cosmos.NewChain(chainId)
+	chain.SetAccount(addr, sdk.NewCoins(sdk.NewInt64Coin("stake", 1000), sdk.NewInt64Coin("token", 1000)))
+	chain.SetValidator(sdk.ValAddress(ed25519.GenPrivKeyFromSecret([]byte("validator")).PubKey().Address()),
+		ed25519.GenPrivKeyFromSecret([]byte("consensus")).PubKey(), 10000000)
+	return chain, rootDir
+}
+
+func transfer(t *testing.T, chain *cosmos.Chain, rootDir string) string {
+	transout := TransferAsync(rootDir, chain.URI(), chainId, fromName, password, toStr, "1stake", "1token")
+	var res sdk.TxResponse
+	if err := cdc.UnmarshalJSON([]byte(transout), &res); err != nil || res.TxHash == "" {
+		t.Fatal(transout)
+	}
+	return res.TxHash
+}
+
 func TestTransferAsync(t *testing.T) {
-	usr, _ := user.Current()
-	rootDir := usr.HomeDir
-	node := "tcp://localhost:26657"
-	chainId := "test4matt"
-	fromName := "local"
-	password := "wm131421"
-	toStr := "cosmos1mrf49r22adtd8juv6kvg8dxly32qlj7rg47644"
-	coinStr := "1stake"
-	feeStr := "1token"
-	transout := TransferAsync(rootDir,node,chainId,fromName,password,toStr,coinStr,feeStr)
-	t.Log(transout)
+	chain, rootDir := newChain(t)
+	defer os.RemoveAll(rootDir)
+	defer chain.Close()
+
+	transfer(t, chain, rootDir)
+
+	to, _ := sdk.AccAddressFromBech32(toStr)
+	if acc := chain.Account(to); acc == nil || acc.GetCoins().AmountOf("stake").Int64() != 1 {
+		t.Errorf("receiver got %v, want 1stake", acc)
+	}
 }
 
 func TestQueryTx(t *testing.T) {
-	usr, _ := user.Current()
-	rootDir := usr.HomeDir
-	node := "tcp://localhost:26657"
-	chainId := "test4matt"
-	Txhash := "0BA029449967228DB14E7ECCFF9B97C5963807DB07D32CF180CBD545BBE59CFC"
-	qout := QueryTx(rootDir, node, chainId, Txhash)
-	t.Log(qout)
-}
\ No newline at end of file
+	chain, rootDir := newChain(t)
+	defer os.RemoveAll(rootDir)
+	defer chain.Close()
+
+	hash := transfer(t, chain, rootDir)
+	qout := QueryTx(rootDir, chain.URI(), chainId, hash)
+	var res sdk.TxResponse
+	if err := cdc.UnmarshalJSON([]byte(qout), &res); err != nil {
+		t.Fatal(qout)
+	}
+	if res.TxHash != hash || res.Height != chain.Height() {
+		t.Errorf("got %s", qout)
+	}
+}
@@ -2,19 +2,18 @@ package main
 
 import "C"
 import (
-	"github.com/cosmos/cosmos-sdk/client/lcd/cosmoswallet/javasdk"
-	"github.com/cosmos/cosmos-sdk/client/lcd/cosmoswallet/sdksource"
+	"github.com/QOSGroup/litewallet/litewallet/javasdk"
 )
 
 //export GetAccount
 func GetAccount(rootDir,node,chainId,addr *C.char) *C.char {
-	output := sdksource.GetAccount(C.GoString(rootDir),C.GoString(node), C.GoString(chainId), C.GoString(addr))
+	output := javasdk.GetAccount(C.GoString(rootDir),C.GoString(node), C.GoString(chainId), C.GoString(addr))
 	return C.CString(output)
 }
 
 //export RecoverKey
 func RecoverKey(rootDir,name,password,seed *C.char) *C.char {
-	output := sdksource.RecoverKey(C.GoString(rootDir),C.GoString(name), C.GoString(password), C.GoString(seed))
+	output := javasdk.RecoverKey(C.GoString(rootDir),C.GoString(name), C.GoString(password), C.GoString(seed))
 	return C.CString(output)
 }
 
@@ -0,0 +1,267 @@
+//Package keycrypt encrypts the private keys the keystores of litewallet keep under rootDir.
+//
+//A private key is encrypted with xsalsa20 under a key derived from the password and armored, the armor
+//headers are the envelope: its version, the KDF with all its parameters and the salt. A key is so always
+//decrypted with the parameters it was encrypted with, whatever the current ones are. The armors written
+//before the envelope carry the bcrypt headers of tendermint only, they are read as version 1 with the cost
+//of 12 the keystores used. Decrypt tells whether the envelope of a key is the current one, the keystores
+//encrypt the key again with the current parameters on the unlock it is not.
+package keycrypt
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/QOSGroup/litewallet/litewallet/errcode"
+	"github.com/tendermint/crypto/bcrypt"
+	tcrypto "github.com/tendermint/tendermint/crypto"
+	"github.com/tendermint/tendermint/crypto/armor"
+	"github.com/tendermint/tendermint/crypto/xsalsa20symmetric"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/scrypt"
+)
+
+//the KDFs of the envelope, bcrypt is only read for the legacy keys
+const (
+	KDFBcrypt   = "bcrypt"
+	KDFScrypt   = "scrypt"
+	KDFArgon2id = "argon2id"
+)
+
+const (
+	//Version is the version of the envelope Encrypt writes
+	Version = 2
+	//legacyVersion is the version of the armors without a version header
+	legacyVersion = 1
+	//LegacyBcryptCost is the bcrypt cost of the legacy keys
+	LegacyBcryptCost = 12
+
+	keySize  = 32
+	saltSize = 16
+
+	//the limits of the parameters of an armor, a tampered armor should not take the memory or the time of
+	//the device
+	maxBcryptCost    = 16
+	maxScryptMemory  = 1 << 30
+	maxArgon2Memory  = 1 << 20
+	maxArgon2Time    = 64
+	maxArgon2Threads = 64
+)
+
+//Params are a KDF with its parameters, the ones of the other KDFs are zero
+type Params struct {
+	KDF string
+	//Cost is the bcrypt cost
+	Cost int
+	//N, R and P are the scrypt parameters
+	N, R, P int
+	//Time, Memory in KiB and Threads are the argon2id parameters
+	Time    uint32
+	Memory  uint32
+	Threads uint8
+}
+
+var (
+	//Argon2id are the default parameters, the second recommended option of RFC 9106
+	Argon2id = Params{KDF: KDFArgon2id, Time: 3, Memory: 64 * 1024, Threads: 4}
+	//Scrypt are the scrypt parameters of the interactive logins
+	Scrypt = Params{KDF: KDFScrypt, N: 1 << 15, R: 8, P: 1}
+
+	currentMu sync.RWMutex
+	current   = Argon2id
+)
+
+//Current returns the parameters the keys are encrypted with
+func Current() Params {
+	currentMu.RLock()
+	defer currentMu.RUnlock()
+	return current
+}
+
+//SetCurrent sets the parameters the keys are encrypted with, scrypt or argon2id ones. The keys encrypted
+//with other parameters are encrypted again with them on their next unlock.
+func SetCurrent(p Params) error {
+	if p.KDF == KDFBcrypt {
+		return errcode.New(errcode.CodeInvalidInput, "bcrypt is only read for the legacy keys")
+	}
+	if err := p.validate(); err != nil {
+		return err
+	}
+	currentMu.Lock()
+	defer currentMu.Unlock()
+	current = p
+	return nil
+}
+
+func (p Params) validate() error {
+	switch p.KDF {
+	case KDFBcrypt:
+		if p.Cost < bcrypt.MinCost || p.Cost > maxBcryptCost {
+			return errcode.Errorf(errcode.CodeInvalidInput, "invalid bcrypt cost %d", p.Cost)
+		}
+	case KDFScrypt:
+		//the memory bound is divided instead of multiplying the params, which overflows an int of 32 bits
+		if p.N <= 1 || p.N&(p.N-1) != 0 || p.R <= 0 || p.P <= 0 || p.R > maxScryptMemory/128/p.N/p.P {
+			return errcode.Errorf(errcode.CodeInvalidInput, "invalid scrypt parameters N=%d r=%d p=%d", p.N, p.R, p.P)
+		}
+	case KDFArgon2id:
+		if p.Time == 0 || p.Time > maxArgon2Time || p.Threads == 0 || p.Threads > maxArgon2Threads ||
+			p.Memory < 8*uint32(p.Threads) || p.Memory > maxArgon2Memory {
+			return errcode.Errorf(errcode.CodeInvalidInput, "invalid argon2id parameters t=%d m=%d p=%d", p.Time, p.Memory, p.Threads)
+		}
+	default:
+		return errcode.Errorf(errcode.CodeInvalidInput, "unknown KDF %q", p.KDF)
+	}
+	return nil
+}
+
+//deriveKey returns the xsalsa20 key of passphrase
+func (p Params) deriveKey(passphrase string, salt []byte) ([]byte, error) {
+	switch p.KDF {
+	case KDFBcrypt:
+		key, err := bcrypt.GenerateFromPassword(salt, []byte(passphrase), p.Cost)
+		if err != nil {
+			return nil, errcode.Errorf(errcode.CodeInternal, "Error generating bcrypt key from passphrase: %v", err)
+		}
+		return tcrypto.Sha256(key), nil
+	case KDFScrypt:
+		key, err := scrypt.Key([]byte(passphrase), salt, p.N, p.R, p.P, keySize)
+		if err != nil {
+			return nil, errcode.Errorf(errcode.CodeInternal, "Error generating scrypt key from passphrase: %v", err)
+		}
+		return key, nil
+	default:
+		return argon2.IDKey([]byte(passphrase), salt, p.Time, p.Memory, p.Threads, keySize), nil
+	}
+}
+
+//header returns the envelope of the parameters
+func (p Params) header(salt []byte) map[string]string {
+	header := map[string]string{
+		"version": strconv.Itoa(Version),
+		"kdf":     p.KDF,
+		"salt":    fmt.Sprintf("%X", salt),
+	}
+	switch p.KDF {
+	case KDFBcrypt:
+		header["cost"] = strconv.Itoa(p.Cost)
+	case KDFScrypt:
+		header["n"] = strconv.Itoa(p.N)
+		header["r"] = strconv.Itoa(p.R)
+		header["p"] = strconv.Itoa(p.P)
+	case KDFArgon2id:
+		header["t"] = strconv.FormatUint(uint64(p.Time), 10)
+		header["m"] = strconv.FormatUint(uint64(p.Memory), 10)
+		header["p"] = strconv.FormatUint(uint64(p.Threads), 10)
+	}
+	return header
+}
+
+//envelope reads the version and the parameters of header
+func envelope(header map[string]string) (version int, p Params, err error) {
+	version = legacyVersion
+	if v, ok := header["version"]; ok {
+		if version, err = strconv.Atoi(v); err != nil || version < legacyVersion || version > Version {
+			return 0, p, errcode.Errorf(errcode.CodeInternal, "Unrecognized envelope version: %v", v)
+		}
+	}
+	p.KDF = header["kdf"]
+	if version == legacyVersion {
+		if p.KDF != KDFBcrypt {
+			return 0, p, errcode.Errorf(errcode.CodeInternal, "Unrecognized KDF type: %v", p.KDF)
+		}
+		p.Cost = LegacyBcryptCost
+		return version, p, nil
+	}
+
+	var n [3]uint64
+	var names []string
+	switch p.KDF {
+	case KDFBcrypt:
+		names = []string{"cost"}
+	case KDFScrypt:
+		names = []string{"n", "r", "p"}
+	case KDFArgon2id:
+		names = []string{"t", "m", "p"}
+	default:
+		return 0, p, errcode.Errorf(errcode.CodeInternal, "Unrecognized KDF type: %v", p.KDF)
+	}
+	for i, name := range names {
+		if n[i], err = strconv.ParseUint(header[name], 10, 32); err != nil {
+			return 0, p, errcode.Errorf(errcode.CodeInternal, "Error decoding the %s parameter %s: %v", p.KDF, name, err)
+		}
+	}
+	switch p.KDF {
+	case KDFBcrypt:
+		p.Cost = int(n[0])
+	case KDFScrypt:
+		p.N, p.R, p.P = int(n[0]), int(n[1]), int(n[2])
+	case KDFArgon2id:
+		if n[2] > maxArgon2Threads {
+			return 0, p, errcode.Errorf(errcode.CodeInternal, "Unsupported envelope: %d argon2id threads", n[2])
+		}
+		p.Time, p.Memory, p.Threads = uint32(n[0]), uint32(n[1]), uint8(n[2])
+	}
+	if err := p.validate(); err != nil {
+		return 0, p, errcode.Errorf(errcode.CodeInternal, "Unsupported envelope: %v", err)
+	}
+	return version, p, nil
+}
+
+//Encrypt encrypts secret with passphrase and the current parameters and armors it as blockType
+func Encrypt(blockType string, secret []byte, passphrase string) (string, error) {
+	return encrypt(Current(), blockType, secret, passphrase)
+}
+
+func encrypt(p Params, blockType string, secret []byte, passphrase string) (string, error) {
+	salt := tcrypto.CRandBytes(saltSize)
+	key, err := p.deriveKey(passphrase, salt)
+	if err != nil {
+		return "", err
+	}
+	return armor.EncodeArmor(blockType, p.header(salt), xsalsa20symmetric.EncryptSymmetric(secret, key)), nil
+}
+
+//Decrypt returns the secret of the armor of blockType, and whether the armor is of the current version and
+//parameters
+func Decrypt(armorStr, blockType, passphrase string) (secret []byte, current bool, err error) {
+	armorType, header, encBytes, err := armor.DecodeArmor(armorStr)
+	if err != nil {
+		return nil, false, errcode.Internal(err)
+	}
+	if armorType != blockType {
+		return nil, false, errcode.Errorf(errcode.CodeInternal, "Unrecognized armor type: %v", armorType)
+	}
+	version, p, err := envelope(header)
+	if err != nil {
+		return nil, false, err
+	}
+	salt, err := hex.DecodeString(header["salt"])
+	if err != nil || len(salt) == 0 {
+		return nil, false, errcode.Errorf(errcode.CodeInternal, "Error decoding salt: %v", err)
+	}
+	key, err := p.deriveKey(passphrase, salt)
+	if err != nil {
+		return nil, false, err
+	}
+	secret, err = xsalsa20symmetric.DecryptSymmetric(encBytes, key)
+	if err != nil {
+		return nil, false, errcode.New(errcode.CodeWrongPassword, "invalid account password")
+	}
+	return secret, version == Version && p == Current(), nil
+}
+
+//NeedsUpgrade tells whether the armor is not of the current version and parameters, the password is not checked
+func NeedsUpgrade(armorStr string) (bool, error) {
+	_, header, _, err := armor.DecodeArmor(armorStr)
+	if err != nil {
+		return false, errcode.Internal(err)
+	}
+	version, p, err := envelope(header)
+	if err != nil {
+		return false, err
+	}
+	return version != Version || p != Current(), nil
+}
@@ -0,0 +1,113 @@
+package keycrypt
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/QOSGroup/litewallet/litewallet/errcode"
+	"github.com/tendermint/crypto/bcrypt"
+	tcrypto "github.com/tendermint/tendermint/crypto"
+	"github.com/tendermint/tendermint/crypto/armor"
+	"github.com/tendermint/tendermint/crypto/xsalsa20symmetric"
+)
+
+const blockType = "TENDERMINT PRIVATE KEY"
+
+var (
+	cheapScrypt   = Params{KDF: KDFScrypt, N: 1 << 10, R: 8, P: 1}
+	cheapArgon2id = Params{KDF: KDFArgon2id, Time: 1, Memory: 64, Threads: 1}
+)
+
+//legacyArmor is the armor the keystores wrote before the envelope
+func legacyArmor(secret []byte, passphrase string) string {
+	salt := tcrypto.CRandBytes(16)
+	key, _ := bcrypt.GenerateFromPassword(salt, []byte(passphrase), LegacyBcryptCost)
+	header := map[string]string{"kdf": "bcrypt", "salt": fmt.Sprintf("%X", salt)}
+	return armor.EncodeArmor(blockType, header, xsalsa20symmetric.EncryptSymmetric(secret, tcrypto.Sha256(key)))
+}
+
+func TestEnvelope(t *testing.T) {
+	defer SetCurrent(Current())
+	secret := []byte("a private key")
+	if err := SetCurrent(cheapScrypt); err != nil {
+		t.Fatal(err)
+	}
+	scryptArmor, err := Encrypt(blockType, secret, "pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(scryptArmor, "kdf: scrypt") || !strings.Contains(scryptArmor, "n: 1024") {
+		t.Errorf("got %s, want the scrypt parameters in the headers", scryptArmor)
+	}
+	got, current, err := Decrypt(scryptArmor, blockType, "pass")
+	if err != nil || !current || !bytes.Equal(got, secret) {
+		t.Fatalf("got %q, %v, %v, want the secret of a current armor", got, current, err)
+	}
+	if _, _, err := Decrypt(scryptArmor, blockType, "wrong"); errcode.Code(err) != errcode.CodeWrongPassword {
+		t.Errorf("got %v, want a wrong password error", err)
+	}
+	if _, _, err := Decrypt(scryptArmor, "ETH PRIVATE KEY", "pass"); err == nil {
+		t.Error("want an error for another block type")
+	}
+
+	//the keys keep their parameters when the current ones change
+	if err := SetCurrent(cheapArgon2id); err != nil {
+		t.Fatal(err)
+	}
+	if upgrade, err := NeedsUpgrade(scryptArmor); err != nil || !upgrade {
+		t.Errorf("got %v, %v, want the scrypt armor to need an upgrade", upgrade, err)
+	}
+	got, current, err = Decrypt(scryptArmor, blockType, "pass")
+	if err != nil || current || !bytes.Equal(got, secret) {
+		t.Fatalf("got %q, %v, %v, want the secret of an outdated armor", got, current, err)
+	}
+	argonArmor, _ := Encrypt(blockType, secret, "pass")
+	if upgrade, err := NeedsUpgrade(argonArmor); err != nil || upgrade {
+		t.Errorf("got %v, %v, want the argon2id armor to be current", upgrade, err)
+	}
+	if got, current, err := Decrypt(argonArmor, blockType, "pass"); err != nil || !current || !bytes.Equal(got, secret) {
+		t.Errorf("got %q, %v, %v, want the secret of a current armor", got, current, err)
+	}
+
+	//the armors without a version are the bcrypt ones of the keystores
+	legacy := legacyArmor(secret, "pass")
+	if got, current, err := Decrypt(legacy, blockType, "pass"); err != nil || current || !bytes.Equal(got, secret) {
+		t.Errorf("got %q, %v, %v, want the secret of the legacy armor", got, current, err)
+	}
+	if _, _, err := Decrypt(legacy, blockType, "wrong"); errcode.Code(err) != errcode.CodeWrongPassword {
+		t.Errorf("got %v, want a wrong password error", err)
+	}
+}
+
+func TestEnvelopeLimits(t *testing.T) {
+	_, header, encBytes, _ := armor.DecodeArmor(legacyArmor([]byte("secret"), "pass"))
+	header["version"] = "2"
+	header["kdf"] = KDFArgon2id
+	for _, m := range []string{"4194304", "x", ""} {
+		header["t"], header["m"], header["p"] = "1", m, "1"
+		if _, _, err := Decrypt(armor.EncodeArmor(blockType, header, encBytes), blockType, "pass"); err == nil {
+			t.Errorf("want an error for the argon2id memory %q", m)
+		}
+	}
+	header["version"] = "3"
+	if _, err := NeedsUpgrade(armor.EncodeArmor(blockType, header, encBytes)); err == nil {
+		t.Error("want an error for an unknown version")
+	}
+
+	for _, p := range []Params{
+		{KDF: KDFBcrypt, Cost: 12},
+		{KDF: KDFScrypt, N: 1000, R: 8, P: 1},
+		{KDF: KDFScrypt, N: 1 << 30, R: 1 << 20, P: 1 << 20},
+		{KDF: KDFArgon2id, Time: 1, Memory: 4, Threads: 1},
+		{KDF: "pbkdf2"},
+	} {
+		if err := SetCurrent(p); errcode.Code(err) != errcode.CodeInvalidInput {
+			t.Errorf("got %v for %+v, want an invalid input error", err, p)
+		}
+	}
+	if Current() != Argon2id {
+		t.Errorf("got %+v, want the default parameters", Current())
+	}
+}
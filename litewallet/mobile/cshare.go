@@ -1,147 +1,217 @@
 package litewallet
 
 import (
+	"context"
+	"encoding/hex"
 	"encoding/json"
-	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/QOSGroup/litewallet/litewallet/api"
+	"github.com/QOSGroup/litewallet/litewallet/errcode"
 	"github.com/QOSGroup/litewallet/litewallet/eth"
+	"github.com/QOSGroup/litewallet/litewallet/offline"
 	"github.com/QOSGroup/litewallet/litewallet/sdksource"
-	"github.com/QOSGroup/litewallet/litewallet/slim"
-	"github.com/QOSGroup/litewallet/litewallet/slim/base/types"
-	"github.com/QOSGroup/litewallet/litewallet/slim/module"
-	"github.com/QOSGroup/litewallet/litewallet/slim/txs"
+	"github.com/QOSGroup/litewallet/litewallet/signer"
+	"github.com/QOSGroup/litewallet/litewallet/slim/tendermint/crypto/funcInlocal/bip39local"
+	"github.com/QOSGroup/litewallet/litewallet/slim/tendermint/crypto/funcInlocal/slip39local"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 )
 
 //create the seed(mnemonic) for the account generation
 func CreateSeed() string {
-	output := sdksource.CreateSeed()
-	return output
+	mnemonic, err := api.CreateSeed(context.Background())
+	return plainResponse(sdksource.SeedOutput{Seed: mnemonic}, err)
+}
+
+//CreateSeedWithLanguage creates the seed in one of the languages of MnemonicLanguages
+func CreateSeedWithLanguage(language string) string {
+	mnemonic, err := api.CreateSeedIn(context.Background(), language)
+	return plainResponse(sdksource.SeedOutput{Seed: mnemonic}, err)
+}
+
+//EntropyProvider is a source of entropy of the app, such as the secure RNG of the platform, Entropy returns
+//n random bytes
+type EntropyProvider interface {
+	Entropy(n int) ([]byte, error)
+}
+
+//CreateSeedWithStrength creates a seed of 12, 15, 18, 21 or 24 words in language, English when empty.
+//The entropy of provider, which may be nil, and the dice rolls, digits 1 to 6 which may be empty, are mixed
+//into the system entropy.
+func CreateSeedWithStrength(words int, language, diceRolls string, provider EntropyProvider) string {
+	if language == "" {
+		language = bip39local.English
+	}
+	sources := []bip39local.EntropySource{bip39local.SystemEntropy}
+	if provider != nil {
+		sources = append(sources, provider)
+	}
+	if diceRolls != "" {
+		sources = append(sources, bip39local.DiceEntropy(diceRolls))
+	}
+	mnemonic, err := api.CreateMnemonic(context.Background(), words, language, bip39local.Mix(sources...))
+	return plainResponse(sdksource.SeedOutput{Seed: mnemonic}, err)
+}
+
+//MnemonicLanguages lists the languages of the seeds
+func MnemonicLanguages() string {
+	return plainResponse(api.MnemonicLanguages(), nil)
+}
+
+//RegisterMnemonicLanguage adds a bip39 wordlist, words is the list with one word per line
+func RegisterMnemonicLanguage(language, words string) string {
+	err := api.RegisterMnemonicLanguage(language, strings.Split(strings.TrimSpace(words), "\n"))
+	return plainResponse(language, err)
+}
+
+//MnemonicComplete lists at most limit words of the wordlist starting with prefix for a recovery screen,
+//language is one of MnemonicLanguages, English when empty
+func MnemonicComplete(prefix, language string, limit int) string {
+	return plainResponse(api.MnemonicComplete(prefix, language, limit))
+}
+
+//MnemonicDiagnose returns the positions of the unknown words of mnemonic, whether its checksum is valid and
+//the replacements of a single wrong word
+func MnemonicDiagnose(mnemonic, language string) string {
+	return plainResponse(api.MnemonicDiagnose(mnemonic, language))
 }
 
 //WalletAddressCheck for different chains
 func WalletAddressCheck(addr string) string {
-	output := sdksource.WalletAddressCheck(addr)
-	return output
+	return plainResponse(api.WalletAddressCheck(addr), nil)
 }
 
 //create account
 func CosmosCreateAccount(rootDir, name, password, seed string) string {
-	output := sdksource.CreateAccount(rootDir, name, password, seed)
-	return output
+	return cosmosResponse(api.CosmosCreateAccount(context.Background(), rootDir, name, password, seed))
 }
 
 //recover key
 func CosmosRecoverKey(rootDir, name, password, seed string) string {
-	output := sdksource.RecoverKey(rootDir, name, password, seed)
-	return output
+	return cosmosResponse(api.CosmosRecoverKey(context.Background(), rootDir, name, password, seed))
 }
 
 //update password
 func CosmosUpdateKey(rootDir, name, oldpass, newpass string) string {
-	output := sdksource.UpdateKey(rootDir, name, oldpass, newpass)
-	return output
+	err := api.CosmosUpdateKey(context.Background(), rootDir, name, oldpass, newpass)
+	return cosmosResponse(sdksource.UpdateKeyOutput{PasswordUpdate: "Password is successfully updated!"}, err)
+}
+
+//list the keys stored under rootDir
+func CosmosListKeys(rootDir string) string {
+	return cosmosResponse(api.CosmosListKeys(context.Background(), rootDir))
+}
+
+//delete the key name, the password has to match
+func CosmosDeleteKey(rootDir, name, password string) string {
+	err := api.CosmosDeleteKey(context.Background(), rootDir, name, password)
+	return cosmosResponse("Key is successfully deleted!", err)
+}
+
+//export the private key armored and encrypted with exportPass
+func CosmosExportKey(rootDir, name, password, exportPass string) string {
+	return cosmosResponse(api.CosmosExportKey(context.Background(), rootDir, name, password, exportPass))
+}
+
+//import the armored private key of CosmosExportKey, armorPass decrypts the armor and password the local key
+func CosmosImportKey(rootDir, name, password, armor, armorPass string) string {
+	return cosmosResponse(api.CosmosImportKey(context.Background(), rootDir, name, password, armor, armorPass))
 }
 
 //get account info
 func CosmosGetAccount(rootDir, node, chainID, addr string) string {
-	output := sdksource.GetAccount(rootDir, node, chainID, addr)
-	return output
+	return cosmosResponse(api.CosmosGetAccount(context.Background(), rootDir, node, chainID, addr))
 }
 
 //transfer
 func CosmosTransfer(rootDir, node, chainId, fromName, password, toStr, coinStr, feeStr, broadcastMode string) string {
-	output := sdksource.Transfer(rootDir, node, chainId, fromName, password, toStr, coinStr, feeStr, broadcastMode)
-	return output
+	return cosmosResponse(api.CosmosTransfer(context.Background(), rootDir, node, chainId, fromName, password, toStr, coinStr, feeStr, broadcastMode))
 }
 
 //delegate
 func CosmosDelegate(rootDir, node, chainID, delegatorName, password, delegatorAddr, validatorAddr, delegationCoinStr, feeStr, broadcastMode string) string {
-	output := sdksource.Delegate(rootDir, node, chainID, delegatorName, password, delegatorAddr, validatorAddr, delegationCoinStr, feeStr, broadcastMode)
-	return output
+	return cosmosResponse(api.CosmosDelegate(context.Background(), rootDir, node, chainID, delegatorName, password, delegatorAddr, validatorAddr, delegationCoinStr, feeStr, broadcastMode))
 }
 
 //get a specific delegation shares
 func CosmosGetDelegationShares(rootDir, node, chainID, delegatorAddr, validatorAddr string) string {
-	output := sdksource.GetDelegationShares(rootDir, node, chainID, delegatorAddr, validatorAddr)
-	return output
+	return cosmosResponse(api.CosmosGetDelegationShares(context.Background(), rootDir, node, chainID, delegatorAddr, validatorAddr))
 }
 
 //for unbond delegation shares from specific validator
 func CosmosUnbondingDelegation(rootDir, node, chainID, delegatorName, password, delegatorAddr, validatorAddr, Ubdshares, feeStr, broadcastMode string) string {
-	output := sdksource.UnbondingDelegation(rootDir, node, chainID, delegatorName, password, delegatorAddr, validatorAddr, Ubdshares, feeStr, broadcastMode)
-	return output
+	return cosmosResponse(api.CosmosUnbondingDelegation(context.Background(), rootDir, node, chainID, delegatorName, password, delegatorAddr, validatorAddr, Ubdshares, feeStr, broadcastMode))
 }
 
 //get all unbonding delegations from a specific delegator
 func CosmosGetAllUnbondingDelegations(rootDir, node, chainID, delegatorAddr string) string {
-	output := sdksource.GetAllUnbondingDelegations(rootDir, node, chainID, delegatorAddr)
-	return output
+	return cosmosResponse(api.CosmosGetAllUnbondingDelegations(context.Background(), rootDir, node, chainID, delegatorAddr))
 }
 
-//Get bonded validators
+//Get bonded validators, the result is an empty list if none is delegated
 func CosmosGetBondValidators(rootDir, node, chainID, delegatorAddr string) string {
-	output := sdksource.GetBondValidators(rootDir, node, chainID, delegatorAddr)
-	return output
+	validplus, err := api.CosmosGetBondValidators(context.Background(), rootDir, node, chainID, delegatorAddr)
+	if err == nil && validplus == nil {
+		validplus = []sdksource.ValidPlus{}
+	}
+	return cosmosResponse(validplus, err)
 }
 
 //get all the validators
 func CosmosGetAllValidators(rootDir, node, chainID string) string {
-	output := sdksource.GetAllValidators(rootDir, node, chainID)
-	return output
+	return cosmosResponse(api.CosmosGetAllValidators(context.Background(), rootDir, node, chainID))
 }
 
 //get all delegations from the delegator
 func CosmosGetAllDelegations(rootDir, node, chainID, delegatorAddr string) string {
-	output := sdksource.GetAllDelegations(rootDir, node, chainID, delegatorAddr)
-	return output
+	return cosmosResponse(api.CosmosGetAllDelegations(context.Background(), rootDir, node, chainID, delegatorAddr))
 }
 
 //Withdraw rewards from a specific validator
 func CosmosWithdrawDelegationReward(rootDir, node, chainID, delegatorName, password, delegatorAddr, validatorAddr, feeStr, broadcastMode string) string {
-	output := sdksource.WithdrawDelegationReward(rootDir, node, chainID, delegatorName, password, delegatorAddr, validatorAddr, feeStr, broadcastMode)
-	return output
+	return cosmosResponse(api.CosmosWithdrawDelegationReward(context.Background(), rootDir, node, chainID, delegatorName, password, delegatorAddr, validatorAddr, feeStr, broadcastMode))
 }
 
 //get a delegation reward between delegator and validator
 func CosmosGetDelegationRewards(rootDir, node, chainID, delegatorAddr, validatorAddr string) string {
-	output := sdksource.GetDelegationRewards(rootDir, node, chainID, delegatorAddr, validatorAddr)
-	return output
+	return cosmosResponse(api.CosmosGetDelegationRewards(context.Background(), rootDir, node, chainID, delegatorAddr, validatorAddr))
+}
+
+//verify the Cosmos query results against the headers signed by the validators instead of trusting the node,
+//the trusted headers are kept under the rootDir of the queries
+func CosmosSetVerified(on bool) {
+	api.CosmosSetVerified(on)
 }
 
 //query the tx result by txHash generated via async broadcast
 func CosmosQueryTx(rootDir, node, chainId, txHash string) string {
-	output := sdksource.QueryTx(rootDir, node, chainId, txHash)
-	return output
+	return cosmosResponse(api.CosmosQueryTx(context.Background(), rootDir, node, chainId, txHash))
 }
 
 func CosmosGetValSelfBondShares(rootDir, node, chainID, validatorAddr string) string {
-	output := sdksource.GetValSelfBondShares(rootDir, node, chainID, validatorAddr)
-	return output
+	return cosmosResponse(api.CosmosGetValSelfBondShares(context.Background(), rootDir, node, chainID, validatorAddr))
 }
 
 func CosmosGetDelegtorRewardsShares(rootDir, node, chainId, delegatorAddr string) string {
-	output := sdksource.GetDelegtorRewardsShares(rootDir, node, chainId, delegatorAddr)
-	return output
+	return cosmosResponse(api.CosmosGetDelegtorRewardsShares(context.Background(), rootDir, node, chainId, delegatorAddr))
 }
 
 func CosmosWithdrawDelegatorAllRewards(rootDir, node, chainID, delegatorName, password, delegatorAddr, feeStr, broadcastMode string) string {
-	output := sdksource.WithdrawDelegatorAllRewards(rootDir, node, chainID, delegatorName, password, delegatorAddr, feeStr, broadcastMode)
-	return output
+	return cosmosResponse(api.CosmosWithdrawDelegatorAllRewards(context.Background(), rootDir, node, chainID, delegatorName, password, delegatorAddr, feeStr, broadcastMode))
 }
 
 func CosmosQueryQueryTxsWithTags(rootDir, node, chainID, addr string, page, limit int) string {
-	output := sdksource.QueryTxsWithTags(rootDir, node, chainID, addr, page, limit)
-	return output
+	return cosmosResponse(api.CosmosQueryTxsWithTags(context.Background(), rootDir, node, chainID, addr, page, limit))
 }
 
 //QOS wallet part begin from here
 func QOSAccountCreate(password string) string {
-	output := slim.AccountCreateStr(password)
-	return output
+	return qosResponse(api.QOSAccountCreate(context.Background(), password))
 }
 
 func QOSAccountCreateFromSeed(mncode string) string {
-	output := slim.AccountCreateFromSeed(mncode)
-	return output
+	return qosResponse(api.QOSAccountCreateFromSeed(context.Background(), mncode))
 }
 
 ////for QSCKVStoreset
@@ -158,11 +228,7 @@ func QOSAccountCreateFromSeed(mncode string) string {
 
 //for QOSQueryAccount
 func QOSQueryAccount(remote, addr string) string {
-	output, err := slim.QueryAccount(remote, addr)
-	if (err != nil) {
-		return err.Error()
-	}
-	return string(output)
+	return qosResponse(api.QOSQueryAccount(context.Background(), remote, addr))
 }
 
 ////for QOSQueryAccount
@@ -173,47 +239,32 @@ func QOSQueryAccount(remote, addr string) string {
 
 //for AccountRecovery
 func QOSAccountRecover(mncode, password string) string {
-	output := slim.AccountRecoverStr(mncode, password)
-	return output
+	return qosResponse(api.QOSAccountRecover(context.Background(), mncode, password))
 }
 
 //for IP input
 func QOSSetBlockchainEntrance(sh, mh string) {
-	txs.SetBlockchainEntrance(sh, mh)
+	api.QOSSetBlockchainEntrance(sh, mh)
 }
 
 //for PubAddrRetrieval
 func QOSPubAddrRetrieval(priv string) string {
-	//	fmt.Println("Please input host including IP and port for initialization on Qstar deamon:")
-	output := slim.PubAddrRetrievalStr(priv)
-	return output
+	return qosResponse(api.QOSPubAddrRetrieval(context.Background(), priv))
 }
 
 //for QSCtransferSend
 func QOSTransferSend(remote, addrto, coinstr, privkey, chainid string) string {
-	output, err := slim.Transfer(remote, addrto, coinstr, privkey, chainid)
-	if err != nil {
-		return err.Error()
-	}
-	return string(output)
+	return qosResponse(api.QOSTransferSend(context.Background(), remote, addrto, coinstr, privkey, chainid))
 }
 
 //for QOSDelegationSend
 func QOSDelegationSend(remote, validatorAddr string, coins int64, privkey, chainid string) string {
-	output, err := slim.Delegation(remote, validatorAddr, coins, privkey, chainid)
-	if err != nil {
-		return err.Error()
-	}
-	return string(output)
+	return qosResponse(api.QOSDelegationSend(context.Background(), remote, validatorAddr, coins, privkey, chainid))
 }
 
 //for QOSDelegationSend
 func QOSUnbondDelegationSend(remote, validatorAddr string, coins int64, privkey, chainid string) string {
-	output, err := slim.UnbondDelegation(remote, validatorAddr, coins, privkey, chainid)
-	if err != nil {
-		return err.Error()
-	}
-	return string(output)
+	return qosResponse(api.QOSUnbondDelegationSend(context.Background(), remote, validatorAddr, coins, privkey, chainid))
 }
 
 ////for QOSCommitResultCheck
@@ -228,13 +279,11 @@ func QOSUnbondDelegationSend(remote, validatorAddr string, coins int64, privkey,
 //}
 
 func QOSAesEncrypt(key, plainText string) string {
-	output := slim.AesEncrypt(key, plainText)
-	return output
+	return qosResponse(api.QOSAesEncrypt(context.Background(), key, plainText))
 }
 
 func QOSAesDecrypt(key, cipherText string) string {
-	output := slim.AesDecrypt(key, cipherText)
-	return output
+	return qosResponse(api.QOSAesDecrypt(context.Background(), key, cipherText))
 }
 
 //func QOSTransferRecordsQuery(chainid, addr, cointype, offset, limit string) string {
@@ -243,44 +292,37 @@ func QOSAesDecrypt(key, cipherText string) string {
 //}
 
 func CosmosTransferB4send(rootDir, node, chainID, fromName, password, toStr, coinStr, feeStr string) string {
-	output := sdksource.TransferB4send(rootDir, node, chainID, fromName, password, toStr, coinStr, feeStr)
-	return output
+	txBytes, err := api.CosmosTransferB4send(context.Background(), rootDir, node, chainID, fromName, password, toStr, coinStr, feeStr)
+	return cosmosResponse(hex.EncodeToString(txBytes), err)
 }
 
 func CosmosBroadcastTransferTx(rootDir, node, chainID, txString, broadcastMode string) string {
-	output := sdksource.BroadcastTransferTx(rootDir, node, chainID, txString, broadcastMode)
-	return output
+	txBytes, err := decodeHex(txString)
+	if err != nil {
+		return cosmosResponse(nil, err)
+	}
+	return cosmosResponse(api.CosmosBroadcastTransferTx(context.Background(), rootDir, node, chainID, txBytes, broadcastMode))
 }
 
 //for AdvertisersTrue
 func QOSAdvertisersTrue(privatekey, coinsType, coinAmount, qscchainid string) string {
-	output := module.AdvertisersTrue(privatekey, coinsType, coinAmount, qscchainid)
-	return output
+	tx, err := api.QOSAdvertisers(context.Background(), privatekey, coinsType, coinAmount, "2", qscchainid)
+	return qscTxResponse(tx, err)
 }
 
 //for AdvertisersFalse
 func QOSAdvertisersFalse(privatekey, coinsType, coinAmount, qscchainid string) string {
-	output := module.AdvertisersFalse(privatekey, coinsType, coinAmount, qscchainid)
-	return output
+	tx, err := api.QOSAdvertisers(context.Background(), privatekey, coinsType, coinAmount, "1", qscchainid)
+	return qscTxResponse(tx, err)
 }
 
 //for GetTx
 func QOSGetTx(remote, tx string) string {
-	output, err := slim.QueryTx(remote, tx)
-	if err != nil {
-		return err.Error()
-	}
-	return string(output)
+	return qosResponse(api.QOSGetTx(context.Background(), remote, tx))
 }
 
 func QOSGetBlance(addrs string) string {
-	path := fmt.Sprintf("/store/%s/%s", "aoeaccount", "key")
-	output, _ := txs.Query(path, []byte(addrs))
-	var basecoin *types.BaseCoins
-	//err=json.Unmarshal(resp.Value,&basecoin)
-	txs.Cdc.UnmarshalBinaryBare(output, &basecoin)
-	result, _ := json.Marshal(basecoin)
-	return string(result)
+	return qosResponse(api.QOSGetBlance(context.Background(), addrs))
 }
 
 //func QOSGetBlanceByCointype(addrs, cointype string) string {
@@ -302,55 +344,234 @@ func QOSGetBlance(addrs string) string {
 //coinAmount             //竞拍数量
 //qscchainid             //chainid
 func QOSAcutionAd(articleHash, privatekey, coinsType, coinAmount, qscchainid string) string {
-	output := module.AcutionAd(articleHash, privatekey, coinsType, coinAmount, qscchainid)
-	return output
+	amount, err := strconv.Atoi(coinAmount)
+	if err != nil {
+		return qosResponse(nil, errcode.Errorf(errcode.CodeInvalidInput, "AcutionAd invalid amount %s", coinAmount))
+	}
+	tx, err := api.QOSAcutionAd(context.Background(), articleHash, privatekey, coinsType, amount, qscchainid)
+	return qscTxResponse(tx, err)
 }
 
 //for Extract
 func QOSExtract(privatekey, coinsType, coinAmount, qscchainid string) string {
-	output := module.Extract(privatekey, coinsType, coinAmount, qscchainid)
-	return output
+	tx, err := api.QOSExtract(context.Background(), privatekey, coinsType, coinAmount, qscchainid)
+	return qscTxResponse(tx, err)
 }
 
 // 提交到联盟链上
 func QOSBroadcastTransferTxToQSC(txstring, broadcastModes string) string {
-	return txs.BroadcastTransferTxToQSC(txstring, broadcastModes)
+	txBytes, err := decodeHex(txstring)
+	if err != nil {
+		return qosResponse(nil, err)
+	}
+	return qosResponse(api.QOSBroadcastTransferTxToQSC(context.Background(), txBytes, broadcastModes))
 }
 
 func QOSCommHandler(funcName, privatekey, args, qscchainid string) string {
-	output := module.CommHandler(funcName, privatekey, args, qscchainid)
-	return output
+	var argList []string
+	if err := json.Unmarshal([]byte(args), &argList); err != nil {
+		return qosResponse(nil, errcode.InvalidInput(err))
+	}
+	tx, err := api.QOSCommHandler(context.Background(), funcName, privatekey, argList, qscchainid)
+	return qscTxResponse(tx, err)
+}
+
+//QOS keystore part, the keys are stored encrypted under rootDir and the functions ending with ByName sign with them
+func QOSCreateKey(rootDir, name, password, seed string) string {
+	return qosResponse(api.QOSCreateKey(context.Background(), rootDir, name, password, seed))
+}
+
+func QOSRecoverKey(rootDir, name, password, seed string) string {
+	return qosResponse(api.QOSRecoverKey(context.Background(), rootDir, name, password, seed))
+}
+
+//create or recover the key at a SLIP-0010 path, all its levels are hardened such as m/44'/118'/0'/0'/0'
+func QOSCreateKeyWithPath(rootDir, name, password, seed, hdPath string) string {
+	return qosResponse(api.QOSCreateKeyWithPath(context.Background(), rootDir, name, password, seed, hdPath))
+}
+
+//create or recover the account index of the seed, at m/44'/118'/0'/0'/index'
+func QOSCreateKeyAt(rootDir, name, password, seed string, index int) string {
+	if index < 0 || int64(index) >= 1<<31 {
+		return qosResponse(nil, errcode.Errorf(errcode.CodeInvalidInput, "invalid account index %d", index))
+	}
+	return qosResponse(api.QOSCreateKeyAt(context.Background(), rootDir, name, password, seed, uint32(index)))
+}
+
+//recover the account of QOSAccountCreate or QOSAccountRecover, passphrase is its password or "DNWTTY" if it had none,
+//an empty passphrase recovers the account of QOSAccountCreateFromSeed
+func QOSRecoverLegacyKey(rootDir, name, password, seed, passphrase string) string {
+	return qosResponse(api.QOSRecoverLegacyKey(context.Background(), rootDir, name, password, seed, passphrase))
+}
+
+//list count accounts of the seed from the index start
+func QOSDeriveAccounts(seed string, start, count int) string {
+	if start < 0 || int64(start) >= 1<<31 {
+		return qosResponse(nil, errcode.Errorf(errcode.CodeInvalidInput, "invalid account index %d", start))
+	}
+	return qosResponse(api.QOSDeriveAccounts(context.Background(), seed, uint32(start), count))
+}
+
+//import the base64 private key of QOSAccountCreate
+func QOSImportKey(rootDir, name, password, privkey string) string {
+	return qosResponse(api.QOSImportKey(context.Background(), rootDir, name, password, privkey))
+}
+
+func QOSListKeys(rootDir string) string {
+	return qosResponse(api.QOSListKeys(context.Background(), rootDir))
+}
+
+func QOSDeleteKey(rootDir, name, password string) string {
+	err := api.QOSDeleteKey(context.Background(), rootDir, name, password)
+	return qosResponse("Key is successfully deleted!", err)
+}
+
+func QOSUpdateKey(rootDir, name, oldpass, newpass string) string {
+	err := api.QOSUpdateKey(context.Background(), rootDir, name, oldpass, newpass)
+	return qosResponse("Password is successfully updated!", err)
+}
+
+func QOSExportKey(rootDir, name, password string) string {
+	return qosResponse(api.QOSExportKey(context.Background(), rootDir, name, password))
+}
+
+func QOSTransferSendByName(rootDir, remote, name, password, addrto, coinstr, chainid string) string {
+	return qosResponse(api.QOSTransferSendByName(context.Background(), rootDir, remote, name, password, addrto, coinstr, chainid))
+}
+
+func QOSDelegationSendByName(rootDir, remote, name, password, validatorAddr string, coins int64, chainid string) string {
+	return qosResponse(api.QOSDelegationSendByName(context.Background(), rootDir, remote, name, password, validatorAddr, coins, chainid))
+}
+
+func QOSUnbondDelegationSendByName(rootDir, remote, name, password, validatorAddr string, coins int64, chainid string) string {
+	return qosResponse(api.QOSUnbondDelegationSendByName(context.Background(), rootDir, remote, name, password, validatorAddr, coins, chainid))
+}
+
+func QOSAdvertisersTrueByName(rootDir, name, password, coinsType, coinAmount, qscchainid string) string {
+	tx, err := api.QOSAdvertisersByName(context.Background(), rootDir, name, password, coinsType, coinAmount, "2", qscchainid)
+	return qscTxResponse(tx, err)
+}
+
+func QOSAdvertisersFalseByName(rootDir, name, password, coinsType, coinAmount, qscchainid string) string {
+	tx, err := api.QOSAdvertisersByName(context.Background(), rootDir, name, password, coinsType, coinAmount, "1", qscchainid)
+	return qscTxResponse(tx, err)
+}
+
+func QOSAcutionAdByName(rootDir, articleHash, name, password, coinsType, coinAmount, qscchainid string) string {
+	amount, err := strconv.Atoi(coinAmount)
+	if err != nil {
+		return qosResponse(nil, errcode.Errorf(errcode.CodeInvalidInput, "AcutionAd invalid amount %s", coinAmount))
+	}
+	tx, err := api.QOSAcutionAdByName(context.Background(), rootDir, articleHash, name, password, coinsType, amount, qscchainid)
+	return qscTxResponse(tx, err)
+}
+
+func QOSExtractByName(rootDir, name, password, coinsType, coinAmount, qscchainid string) string {
+	tx, err := api.QOSExtractByName(context.Background(), rootDir, name, password, coinsType, coinAmount, qscchainid)
+	return qscTxResponse(tx, err)
+}
+
+func QOSCommHandlerByName(rootDir, funcName, name, password, args, qscchainid string) string {
+	var argList []string
+	if err := json.Unmarshal([]byte(args), &argList); err != nil {
+		return qosResponse(nil, errcode.InvalidInput(err))
+	}
+	tx, err := api.QOSCommHandlerByName(context.Background(), rootDir, funcName, name, password, argList, qscchainid)
+	return qscTxResponse(tx, err)
+}
+
+func QOSInvestAdByName(rootDir, QOSchainId, QSCchainId, articleHash, coins, name, password string) string {
+	tx, err := api.QOSInvestAdByName(context.Background(), rootDir, QOSchainId, QSCchainId, articleHash, coins, name, password)
+	return qscTxResponse(tx, err)
 }
 
 //From here, Eth wallet part start
 func EthCreateAccount(rootDir, name, password, seed string) string {
-	output := eth.CreateAccount(rootDir, name, password, seed)
-	return output
+	return plainResponse(api.EthCreateAccount(context.Background(), rootDir, name, password, seed))
 }
 
 func EthRecoverAccount(rootDir, name, password, seed string) string {
-	output := eth.RecoverAccount(rootDir, name, password, seed)
-	return output
+	return plainResponse(api.EthRecoverAccount(context.Background(), rootDir, name, password, seed))
+}
+
+//list the keys stored under rootDir
+func EthListAccounts(rootDir string) string {
+	return plainResponse(api.EthListAccounts(context.Background(), rootDir))
+}
+
+//recover the key at hdPath under name replacing the key stored there, when its password is forgotten
+func EthReplaceAccount(rootDir, name, password, seed, hdPath string) string {
+	return plainResponse(api.EthReplaceAccount(context.Background(), rootDir, name, password, seed, hdPath))
+}
+
+//check the key store, with repair the address records of the stores of the earlier versions are fixed
+func EthCheckStore(rootDir string, repair bool) string {
+	return plainResponse(api.EthCheckStore(context.Background(), rootDir, repair))
+}
+
+//delete the key name, the password has to match
+func EthDeleteAccount(rootDir, name, password string) string {
+	err := api.EthDeleteAccount(context.Background(), rootDir, name, password)
+	return plainResponse("Key is successfully deleted!", err)
+}
+
+//update the password of the key name
+func EthUpdateKey(rootDir, name, oldpass, newpass string) string {
+	err := api.EthUpdateKey(context.Background(), rootDir, name, oldpass, newpass)
+	return plainResponse("Password is successfully updated!", err)
+}
+
+//import a keystore v3 json file of geth or MetaMask, keystorePassword decrypts the file and password the local key
+func EthImportKeystore(rootDir, name, password, keystoreJSON, keystorePassword string) string {
+	return plainResponse(api.EthImportKeystore(context.Background(), rootDir, name, password, keystoreJSON, keystorePassword))
+}
+
+func EthImportPrivateKey(rootDir, name, password, privKeyHex string) string {
+	return plainResponse(api.EthImportPrivateKey(context.Background(), rootDir, name, password, privKeyHex))
+}
+
+//export the key as keystore v3 json, kdf is "scrypt", the default, or "pbkdf2"
+func EthExportKeystore(rootDir, name, password, keystorePassword, kdf string) string {
+	return plainResponse(api.EthExportKeystore(context.Background(), rootDir, name, password, keystorePassword, kdf))
+}
+
+//create or recover the key at hdPath, an absolute path or one relative to m/44'/60'/0'/0 such as "1"
+func EthCreateAccountWithPath(rootDir, name, password, seed, hdPath string) string {
+	return plainResponse(api.EthCreateAccountWithPath(context.Background(), rootDir, name, password, seed, hdPath))
+}
+
+//create or recover the account index of the seed, at m/44'/60'/0'/0/index
+func EthCreateAccountAt(rootDir, name, password, seed string, index int) string {
+	if index < 0 || int64(index) >= 1<<31 {
+		return plainResponse(nil, errcode.Errorf(errcode.CodeInvalidInput, "invalid account index %d", index))
+	}
+	return plainResponse(api.EthCreateAccountAt(context.Background(), rootDir, name, password, seed, uint32(index)))
+}
+
+//list count accounts of the seed from the index start, with the balances when node is not empty
+func EthDeriveAccounts(node, seed string, start, count int) string {
+	if start < 0 || int64(start) >= 1<<31 {
+		return plainResponse(nil, errcode.Errorf(errcode.CodeInvalidInput, "invalid account index %d", start))
+	}
+	return derivedAccountsResponse(api.EthDeriveAccounts(context.Background(), node, seed, uint32(start), count))
 }
 
 func EthGetAccount(node, addr string) string {
-	output := eth.GetAccount(node, addr)
-	return output
+	return balanceResponse(api.EthGetAccount(context.Background(), node, addr))
 }
 
 func EthGetErc20Account(node, addr, tokenAddr string) string {
-	output := eth.GetAccountERC20(node, addr, tokenAddr)
-	return output
+	return balanceResponse(api.EthGetErc20Account(context.Background(), node, addr, tokenAddr))
 }
 
 func EthTransferETH(rootDir, node, name, password, toAddr, gasPrice, amount string, gasLimit int64) string {
-	output := eth.TransferETH(rootDir, node, name, password, toAddr, gasPrice, amount, gasLimit)
-	return output
+	hash, err := api.EthTransferETH(context.Background(), rootDir, node, name, password, toAddr, gasPrice, amount, gasLimit)
+	return plainResponse(hash.Hex(), err)
 }
 
 func EthTransferErc20(rootDir, node, name, password, toAddr, tokenAddr, tokenValue, gasPrice string, gasLimit int64) string {
-	output := eth.TransferERC20(rootDir, node, name, password, toAddr, tokenAddr, tokenValue, gasPrice, gasLimit)
-	return output
+	hash, err := api.EthTransferErc20(context.Background(), rootDir, node, name, password, toAddr, tokenAddr, tokenValue, gasPrice, gasLimit)
+	return plainResponse(hash.Hex(), err)
 }
 
 //Deprecated!
@@ -360,17 +581,478 @@ func EthTransferErc20(rootDir, node, name, password, toAddr, tokenAddr, tokenVal
 //}
 
 func EthSpeedTransferETH(rootDir, node, fromName, password, toAddr, gasPrice, amount string, GasLimit, pendingNonce int64) string {
-	output := eth.SpeedTransferETH(rootDir, node, fromName, password, toAddr, gasPrice, amount, GasLimit, pendingNonce)
-	return output
+	hash, err := api.EthSpeedTransferETH(context.Background(), rootDir, node, fromName, password, toAddr, gasPrice, amount, GasLimit, pendingNonce)
+	return plainResponse(hash.Hex(), err)
 }
 
 func EthSpeedTransferERC20(rootDir, node, fromName, password, toAddr, tokenAddr, tokenValue, gasPrice string, GasLimit, pendingNonce int64) string {
-	output := eth.SpeedTransferERC20(rootDir, node, fromName, password, toAddr, tokenAddr, tokenValue, gasPrice, GasLimit, pendingNonce)
-	return output
+	hash, err := api.EthSpeedTransferERC20(context.Background(), rootDir, node, fromName, password, toAddr, tokenAddr, tokenValue, gasPrice, GasLimit, pendingNonce)
+	return plainResponse(hash.Hex(), err)
+}
+
+//EthGetNonceAt provide the nonce at the latest block, the result is a decimal string
+func EthGetNonceAt(rootDir, node, fromName, password string) string {
+	return plainResponse(api.EthGetNonceAt(context.Background(), rootDir, node, fromName, password))
+}
+
+//Network profiles part, the functions ending with On take the name of a loaded network instead of the node and chain id
+
+//LoadNetworks registers the networks of a json or toml file and returns all registered ones
+func LoadNetworks(path string) string {
+	if err := api.LoadNetworks(path); err != nil {
+		return plainResponse(nil, err)
+	}
+	return plainResponse(api.Networks(), nil)
+}
+
+//ListNetworks returns the registered networks
+func ListNetworks() string {
+	return plainResponse(api.Networks(), nil)
+}
+
+func CosmosGetAccountOn(network, rootDir, addr string) string {
+	return cosmosResponse(api.CosmosGetAccountOn(context.Background(), network, rootDir, addr))
+}
+
+func CosmosTransferOn(network, rootDir, fromName, password, toStr, coinStr, feeStr, broadcastMode string) string {
+	return cosmosResponse(api.CosmosTransferOn(context.Background(), network, rootDir, fromName, password, toStr, coinStr, feeStr, broadcastMode))
+}
+
+func CosmosQueryTxOn(network, rootDir, txHash string) string {
+	return cosmosResponse(api.CosmosQueryTxOn(context.Background(), network, rootDir, txHash))
+}
+
+func QOSQueryAccountOn(network, addr string) string {
+	return qosResponse(api.QOSQueryAccountOn(context.Background(), network, addr))
+}
+
+func QOSTransferSendOn(network, addrto, coinstr, privkey string) string {
+	return qosResponse(api.QOSTransferSendOn(context.Background(), network, addrto, coinstr, privkey))
+}
+
+func QOSTransferSendOnByName(network, rootDir, name, password, addrto, coinstr string) string {
+	return qosResponse(api.QOSTransferSendOnByName(context.Background(), network, rootDir, name, password, addrto, coinstr))
+}
+
+func QOSGetTxOn(network, tx string) string {
+	return qosResponse(api.QOSGetTxOn(context.Background(), network, tx))
+}
+
+func EthGetAccountOn(network, addr string) string {
+	return balanceResponse(api.EthGetAccountOn(context.Background(), network, addr))
+}
+
+func EthGetErc20AccountOn(network, addr, tokenAddr string) string {
+	return balanceResponse(api.EthGetErc20AccountOn(context.Background(), network, addr, tokenAddr))
+}
+
+func EthTransferETHOn(network, rootDir, name, password, toAddr, gasPrice, amount string, gasLimit int64) string {
+	hash, err := api.EthTransferETHOn(context.Background(), network, rootDir, name, password, toAddr, gasPrice, amount, gasLimit)
+	return plainResponse(hash.Hex(), err)
+}
+
+func EthTransferErc20On(network, rootDir, name, password, toAddr, tokenAddr, tokenValue, gasPrice string, gasLimit int64) string {
+	hash, err := api.EthTransferErc20On(context.Background(), network, rootDir, name, password, toAddr, tokenAddr, tokenValue, gasPrice, gasLimit)
+	return plainResponse(hash.Hex(), err)
+}
+
+//Multi-chain wallet part, one mnemonic and one password for the Cosmos, ETH and QOS keys of the same name
+
+//WalletCreate creates the keys of every chain from the seed, a new seed is generated when it is empty
+func WalletCreate(rootDir, name, password, seed string) string {
+	return plainResponse(api.WalletCreate(context.Background(), rootDir, name, password, seed))
+}
+
+func WalletRecover(rootDir, name, password, seed string) string {
+	return plainResponse(api.WalletRecover(context.Background(), rootDir, name, password, seed))
+}
+
+//WalletGet returns the addresses of the wallet name on every chain
+func WalletGet(rootDir, name string) string {
+	return plainResponse(api.WalletGet(context.Background(), rootDir, name))
+}
+
+func WalletList(rootDir string) string {
+	return plainResponse(api.WalletList(context.Background(), rootDir))
+}
+
+//WalletBackupShares splits the seed into SLIP-0039 shares, groups is a JSON array such as
+//[{"member_threshold":2,"member_count":3}] and the shares are returned as an array per group
+func WalletBackupShares(seed, passphrase string, groupThreshold int, groups string) string {
+	var groupList []slip39local.Group
+	if err := json.Unmarshal([]byte(groups), &groupList); err != nil {
+		return plainResponse(nil, errcode.InvalidInput(err))
+	}
+	return plainResponse(api.WalletBackupShares(context.Background(), seed, passphrase, groupThreshold, groupList))
+}
+
+//WalletRestoreShares creates the wallet of the shares, a JSON array of share mnemonics, the seed is in
+//language, English when empty
+func WalletRestoreShares(rootDir, name, password, shares, passphrase, language string) string {
+	var shareList []string
+	if err := json.Unmarshal([]byte(shares), &shareList); err != nil {
+		return plainResponse(nil, errcode.InvalidInput(err))
+	}
+	if language == "" {
+		language = bip39local.English
+	}
+	return plainResponse(api.WalletRestoreShares(context.Background(), rootDir, name, password, shareList, passphrase, language))
+}
+
+//WalletMigrateKeys encrypts the keys under rootDir again in the current envelope, passwords is a JSON object of
+//the passwords by key name such as {"alice":"alicepass"}, the keys without a password are skipped
+func WalletMigrateKeys(rootDir, passwords string) string {
+	var passwordMap map[string]string
+	if err := json.Unmarshal([]byte(passwords), &passwordMap); err != nil {
+		return plainResponse(nil, errcode.InvalidInput(err))
+	}
+	return plainResponse(api.WalletMigrateKeys(context.Background(), rootDir, passwordMap))
+}
+
+//SetKeyEncryption sets the KDF the keys are encrypted with, argon2id, the default, or scrypt
+func SetKeyEncryption(kdf string) string {
+	return plainResponse(kdf, api.SetKeyEncryption(kdf))
 }
 
-//EthGetNonceAt provide the nonce at the latest block
-func EthGetNonceAt(rootDir, node, fromName, password string) int64 {
-	output := eth.GetNonceAt(rootDir, node, fromName, password)
-	return output
+//Signer part, the functions ending with WithSigner sign with a key the app keeps instead of a local key
+
+//DeviceSigner is a key of the app, such as a key of the Android Keystore, the iOS Secure Enclave or an external
+//device. Algo is "secp256k1" for Cosmos and ETH or "ed25519" for QOS, PubKey the compressed or uncompressed
+//secp256k1 public key or the ed25519 one. Sign signs the 32 bytes digest with secp256k1, returning the DER or
+//r||s signature, or the message itself with ed25519.
+type DeviceSigner interface {
+	Algo() string
+	PubKey() []byte
+	Sign(msg []byte) ([]byte, error)
+}
+
+func deviceSigner(ds DeviceSigner) (signer.Signer, error) {
+	if ds == nil {
+		return nil, errcode.New(errcode.CodeInvalidInput, "missing the signer")
+	}
+	return signer.NewCallback(ds.Algo(), ds.PubKey(), ds.Sign)
+}
+
+func CosmosTransferWithSigner(rootDir, node, chainID string, ds DeviceSigner, toStr, coinStr, feeStr, broadcastMode string) string {
+	s, err := deviceSigner(ds)
+	if err != nil {
+		return cosmosResponse(nil, err)
+	}
+	return cosmosResponse(api.CosmosTransferWithSigner(context.Background(), rootDir, node, chainID, s, toStr, coinStr, feeStr, broadcastMode))
+}
+
+func CosmosDelegateWithSigner(rootDir, node, chainID string, ds DeviceSigner, validatorAddr, delegationCoinStr, feeStr, broadcastMode string) string {
+	s, err := deviceSigner(ds)
+	if err != nil {
+		return cosmosResponse(nil, err)
+	}
+	return cosmosResponse(api.CosmosDelegateWithSigner(context.Background(), rootDir, node, chainID, s, validatorAddr, delegationCoinStr, feeStr, broadcastMode))
+}
+
+func CosmosUnbondingDelegationWithSigner(rootDir, node, chainID string, ds DeviceSigner, validatorAddr, Ubdshares, feeStr, broadcastMode string) string {
+	s, err := deviceSigner(ds)
+	if err != nil {
+		return cosmosResponse(nil, err)
+	}
+	return cosmosResponse(api.CosmosUnbondingDelegationWithSigner(context.Background(), rootDir, node, chainID, s, validatorAddr, Ubdshares, feeStr, broadcastMode))
+}
+
+func CosmosWithdrawDelegationRewardWithSigner(rootDir, node, chainID string, ds DeviceSigner, validatorAddr, feeStr, broadcastMode string) string {
+	s, err := deviceSigner(ds)
+	if err != nil {
+		return cosmosResponse(nil, err)
+	}
+	return cosmosResponse(api.CosmosWithdrawDelegationRewardWithSigner(context.Background(), rootDir, node, chainID, s, validatorAddr, feeStr, broadcastMode))
+}
+
+func CosmosWithdrawDelegatorAllRewardsWithSigner(rootDir, node, chainID string, ds DeviceSigner, feeStr, broadcastMode string) string {
+	s, err := deviceSigner(ds)
+	if err != nil {
+		return cosmosResponse(nil, err)
+	}
+	return cosmosResponse(api.CosmosWithdrawDelegatorAllRewardsWithSigner(context.Background(), rootDir, node, chainID, s, feeStr, broadcastMode))
+}
+
+func QOSTransferSendWithSigner(remote, addrto, coinstr string, ds DeviceSigner, chainid string) string {
+	s, err := deviceSigner(ds)
+	if err != nil {
+		return qosResponse(nil, err)
+	}
+	return qosResponse(api.QOSTransferSendWithSigner(context.Background(), remote, addrto, coinstr, s, chainid))
+}
+
+func QOSDelegationSendWithSigner(remote, validatorAddr string, coins int64, ds DeviceSigner, chainid string) string {
+	s, err := deviceSigner(ds)
+	if err != nil {
+		return qosResponse(nil, err)
+	}
+	return qosResponse(api.QOSDelegationSendWithSigner(context.Background(), remote, validatorAddr, coins, s, chainid))
+}
+
+func QOSUnbondDelegationSendWithSigner(remote, validatorAddr string, coins int64, ds DeviceSigner, chainid string) string {
+	s, err := deviceSigner(ds)
+	if err != nil {
+		return qosResponse(nil, err)
+	}
+	return qosResponse(api.QOSUnbondDelegationSendWithSigner(context.Background(), remote, validatorAddr, coins, s, chainid))
+}
+
+func EthTransferETHWithSigner(node string, ds DeviceSigner, toAddr, gasPrice, amount string, gasLimit int64) string {
+	s, err := deviceSigner(ds)
+	if err != nil {
+		return plainResponse(nil, err)
+	}
+	hash, err := api.EthTransferETHWithSigner(context.Background(), node, s, toAddr, gasPrice, amount, gasLimit)
+	return plainResponse(hash.Hex(), err)
+}
+
+func EthTransferErc20WithSigner(node string, ds DeviceSigner, toAddr, tokenAddr, tokenValue, gasPrice string, gasLimit int64) string {
+	s, err := deviceSigner(ds)
+	if err != nil {
+		return plainResponse(nil, err)
+	}
+	hash, err := api.EthTransferErc20WithSigner(context.Background(), node, s, toAddr, tokenAddr, tokenValue, gasPrice, gasLimit)
+	return plainResponse(hash.Hex(), err)
+}
+
+//Offline signing part, the txs are built online from the address of the sender, signed on an air-gapped device
+//and broadcast from any online device. The result of each step is the JSON document of the tx to pass to the
+//next step: the build sets its sign_hash, the signature its hash, the one the chain knows the tx by.
+
+func CosmosBuildOfflineTransfer(rootDir, node, chainID, fromAddr, toStr, coinStr, feeStr string) string {
+	return offlineTxResponse(api.CosmosBuildOfflineTransfer(context.Background(), rootDir, node, chainID, fromAddr, toStr, coinStr, feeStr))
+}
+
+func CosmosBuildOfflineDelegate(rootDir, node, chainID, delegatorAddr, validatorAddr, delegationCoinStr, feeStr string) string {
+	return offlineTxResponse(api.CosmosBuildOfflineDelegate(context.Background(), rootDir, node, chainID, delegatorAddr, validatorAddr, delegationCoinStr, feeStr))
+}
+
+func CosmosBuildOfflineUnbondingDelegation(rootDir, node, chainID, delegatorAddr, validatorAddr, Ubdshares, feeStr string) string {
+	return offlineTxResponse(api.CosmosBuildOfflineUnbondingDelegation(context.Background(), rootDir, node, chainID, delegatorAddr, validatorAddr, Ubdshares, feeStr))
+}
+
+func CosmosBuildOfflineWithdrawDelegationReward(rootDir, node, chainID, delegatorAddr, validatorAddr, feeStr string) string {
+	return offlineTxResponse(api.CosmosBuildOfflineWithdrawDelegationReward(context.Background(), rootDir, node, chainID, delegatorAddr, validatorAddr, feeStr))
+}
+
+func CosmosBuildOfflineWithdrawDelegatorAllRewards(rootDir, node, chainID, delegatorAddr, feeStr string) string {
+	return offlineTxResponse(api.CosmosBuildOfflineWithdrawDelegatorAllRewards(context.Background(), rootDir, node, chainID, delegatorAddr, feeStr))
+}
+
+func EthBuildOfflineETH(node, fromAddr, toAddr, gasPrice, amount string, gasLimit int64) string {
+	return offlineTxResponse(api.EthBuildOfflineETH(context.Background(), node, fromAddr, toAddr, gasPrice, amount, gasLimit))
+}
+
+func EthBuildOfflineErc20(node, fromAddr, toAddr, tokenAddr, tokenValue, gasPrice string, gasLimit int64) string {
+	return offlineTxResponse(api.EthBuildOfflineErc20(context.Background(), node, fromAddr, toAddr, tokenAddr, tokenValue, gasPrice, gasLimit))
+}
+
+func QOSBuildOfflineTransfer(remote, fromAddr, addrto, coinstr, chainid string) string {
+	return offlineTxResponse(api.QOSBuildOfflineTransfer(context.Background(), remote, fromAddr, addrto, coinstr, chainid))
+}
+
+func QOSBuildOfflineDelegation(remote, fromAddr, validatorAddr string, coins int64, chainid string) string {
+	return offlineTxResponse(api.QOSBuildOfflineDelegation(context.Background(), remote, fromAddr, validatorAddr, coins, chainid))
+}
+
+func QOSBuildOfflineUnbondDelegation(remote, fromAddr, validatorAddr string, coins int64, chainid string) string {
+	return offlineTxResponse(api.QOSBuildOfflineUnbondDelegation(context.Background(), remote, fromAddr, validatorAddr, coins, chainid))
+}
+
+func QOSBuildOfflineReDelegation(remote, fromAddr, fromValidatorAddr, toValidatorAddr string, coins int64, chainid string) string {
+	return offlineTxResponse(api.QOSBuildOfflineReDelegation(context.Background(), remote, fromAddr, fromValidatorAddr, toValidatorAddr, coins, chainid))
+}
+
+//OfflineSign signs the document of an unsigned tx of any chain with the key of the app
+func OfflineSign(doc string, ds DeviceSigner) string {
+	tx, err := offline.Parse(doc)
+	if err != nil {
+		return plainResponse(nil, err)
+	}
+	s, err := deviceSigner(ds)
+	if err != nil {
+		return plainResponse(nil, err)
+	}
+	return offlineTxResponse(api.OfflineSign(context.Background(), tx, s))
+}
+
+//OfflineSignByName signs the document of an unsigned tx with the local key name of its chain
+func OfflineSignByName(rootDir, name, password, doc string) string {
+	tx, err := offline.Parse(doc)
+	if err != nil {
+		return plainResponse(nil, err)
+	}
+	return offlineTxResponse(api.OfflineSignByName(context.Background(), rootDir, name, password, tx))
+}
+
+//OfflineBroadcast broadcasts the document of a signed tx to node, the node of its chain, the result is its hash.
+//rootDir and broadcastMode are the ones of the Cosmos txs.
+func OfflineBroadcast(rootDir, node, doc, broadcastMode string) string {
+	tx, err := offline.Parse(doc)
+	if err != nil {
+		return plainResponse(nil, err)
+	}
+	return plainResponse(api.OfflineBroadcast(context.Background(), rootDir, node, tx, broadcastMode))
+}
+
+//Message signing part, the messages prove the ownership of an address to a service such as a login: ETH signs
+//as personal_sign, Cosmos an ADR-036 doc and QOS an ed25519 message. The ETH signatures are 0x hex, the Cosmos
+//and QOS ones the JSON of the signature with its public key, to pass as is to the Verify functions.
+
+func EthSignMessage(rootDir, name, password, message string) string {
+	sig, err := api.EthSignMessage(context.Background(), rootDir, name, password, []byte(message))
+	if err != nil {
+		return plainResponse(nil, err)
+	}
+	return plainResponse(hexutil.Encode(sig), nil)
+}
+
+func EthSignMessageWithSigner(ds DeviceSigner, message string) string {
+	s, err := deviceSigner(ds)
+	if err != nil {
+		return plainResponse(nil, err)
+	}
+	sig, err := api.EthSignMessageWithSigner(context.Background(), s, []byte(message))
+	if err != nil {
+		return plainResponse(nil, err)
+	}
+	return plainResponse(hexutil.Encode(sig), nil)
+}
+
+//EthRecoverMessageSigner returns the address which signed message, signature is hex with or without 0x
+func EthRecoverMessageSigner(message, signature string) string {
+	sig, err := decodeHex(strings.TrimPrefix(signature, "0x"))
+	if err != nil {
+		return plainResponse(nil, err)
+	}
+	addr, err := api.EthRecoverMessageSigner(context.Background(), []byte(message), sig)
+	if err != nil {
+		return plainResponse(nil, err)
+	}
+	return plainResponse(addr.Hex(), nil)
+}
+
+//EthVerifyMessage tells whether signature is the one of message by address
+func EthVerifyMessage(address, message, signature string) string {
+	sig, err := decodeHex(strings.TrimPrefix(signature, "0x"))
+	if err != nil {
+		return plainResponse(nil, err)
+	}
+	return plainResponse(api.EthVerifyMessage(context.Background(), address, []byte(message), sig))
+}
+
+func CosmosSignMessage(rootDir, name, password, message string) string {
+	return messageSignatureResponse(api.CosmosSignMessage(context.Background(), rootDir, name, password, []byte(message)))
+}
+
+func CosmosSignMessageWithSigner(ds DeviceSigner, message string) string {
+	s, err := deviceSigner(ds)
+	if err != nil {
+		return plainResponse(nil, err)
+	}
+	return messageSignatureResponse(api.CosmosSignMessageWithSigner(context.Background(), s, []byte(message)))
+}
+
+//CosmosVerifyMessage tells whether signature is the one of message by address
+func CosmosVerifyMessage(address, message, signature string) string {
+	sig, err := parseMessageSignature(signature)
+	if err != nil {
+		return plainResponse(nil, err)
+	}
+	return plainResponse(api.CosmosVerifyMessage(context.Background(), address, []byte(message), sig))
+}
+
+func QOSSignMessage(privkey, message string) string {
+	return messageSignatureResponse(api.QOSSignMessage(context.Background(), privkey, []byte(message)))
+}
+
+func QOSSignMessageByName(rootDir, name, password, message string) string {
+	return messageSignatureResponse(api.QOSSignMessageByName(context.Background(), rootDir, name, password, []byte(message)))
+}
+
+func QOSSignMessageWithSigner(ds DeviceSigner, message string) string {
+	s, err := deviceSigner(ds)
+	if err != nil {
+		return plainResponse(nil, err)
+	}
+	return messageSignatureResponse(api.QOSSignMessageWithSigner(context.Background(), s, []byte(message)))
+}
+
+//QOSVerifyMessage tells whether signature is the one of message by address
+func QOSVerifyMessage(address, message, signature string) string {
+	sig, err := parseMessageSignature(signature)
+	if err != nil {
+		return plainResponse(nil, err)
+	}
+	return plainResponse(api.QOSVerifyMessage(context.Background(), address, []byte(message), sig))
+}
+
+//The typed data are the EIP-712 JSON documents eth_signTypedData_v4 takes, EthTypedDataSummary renders one for
+//the confirmation screen. The signatures are 0x hex.
+
+func EthSignTypedData(rootDir, name, password, typedData string) string {
+	td, err := eth.ParseTypedData(typedData)
+	if err != nil {
+		return plainResponse(nil, err)
+	}
+	sig, err := api.EthSignTypedData(context.Background(), rootDir, name, password, td)
+	if err != nil {
+		return plainResponse(nil, err)
+	}
+	return plainResponse(hexutil.Encode(sig), nil)
+}
+
+func EthSignTypedDataWithSigner(ds DeviceSigner, typedData string) string {
+	td, err := eth.ParseTypedData(typedData)
+	if err != nil {
+		return plainResponse(nil, err)
+	}
+	s, err := deviceSigner(ds)
+	if err != nil {
+		return plainResponse(nil, err)
+	}
+	sig, err := api.EthSignTypedDataWithSigner(context.Background(), s, td)
+	if err != nil {
+		return plainResponse(nil, err)
+	}
+	return plainResponse(hexutil.Encode(sig), nil)
+}
+
+//EthRecoverTypedDataSigner returns the address which signed the typed data, signature is hex with or without 0x
+func EthRecoverTypedDataSigner(typedData, signature string) string {
+	td, err := eth.ParseTypedData(typedData)
+	if err != nil {
+		return plainResponse(nil, err)
+	}
+	sig, err := decodeHex(strings.TrimPrefix(signature, "0x"))
+	if err != nil {
+		return plainResponse(nil, err)
+	}
+	addr, err := api.EthRecoverTypedDataSigner(context.Background(), td, sig)
+	if err != nil {
+		return plainResponse(nil, err)
+	}
+	return plainResponse(addr.Hex(), nil)
+}
+
+//EthVerifyTypedData tells whether signature is the one of the typed data by address
+func EthVerifyTypedData(address, typedData, signature string) string {
+	td, err := eth.ParseTypedData(typedData)
+	if err != nil {
+		return plainResponse(nil, err)
+	}
+	sig, err := decodeHex(strings.TrimPrefix(signature, "0x"))
+	if err != nil {
+		return plainResponse(nil, err)
+	}
+	return plainResponse(api.EthVerifyTypedData(context.Background(), address, td, sig))
+}
+
+//EthTypedDataSummary returns the lines of the domain and message of the typed data to show before signing
+func EthTypedDataSummary(typedData string) string {
+	td, err := eth.ParseTypedData(typedData)
+	if err != nil {
+		return plainResponse(nil, err)
+	}
+	return plainResponse(api.EthTypedDataSummary(context.Background(), td))
 }
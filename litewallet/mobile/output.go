@@ -0,0 +1,147 @@
+package litewallet
+
+import (
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/QOSGroup/litewallet/litewallet/errcode"
+	"github.com/QOSGroup/litewallet/litewallet/eth"
+	"github.com/QOSGroup/litewallet/litewallet/offline"
+	"github.com/QOSGroup/litewallet/litewallet/signer"
+	qosapp "github.com/QOSGroup/litewallet/litewallet/slim/app"
+	"github.com/QOSGroup/litewallet/litewallet/slim/base/txs"
+	"github.com/QOSGroup/litewallet/litewallet/slim/tendermint/crypto/funcInlocal/respwrap"
+	qostxs "github.com/QOSGroup/litewallet/litewallet/slim/txs"
+	"github.com/cosmos/cosmos-sdk/cmd/gaia/app"
+	amino "github.com/tendermint/go-amino"
+)
+
+var cdc = app.MakeCodec()
+
+//plainCdc marshals the results which hold no amino interface, the ETH ones and the plain strings
+var plainCdc = amino.NewCodec()
+
+//Every export of the package returns the same JSON envelope, the rpc response of respwrap:
+//
+//	{"jsonrpc": "2.0", "id": "", "result": ...}
+//	{"jsonrpc": "2.0", "id": "", "error": {"code": 3, "message": "..."}}
+//
+//The result is the amino JSON of the typed result of the api package, 64-bit integers are strings.
+//The error code is one of the errcode catalog, the same for the QOS, Cosmos and ETH wallets.
+
+func response(codec *amino.Codec, v interface{}, err error) string {
+	if err != nil {
+		resp, _ := respwrap.ResponseWrapper(codec, nil, err)
+		return string(resp)
+	}
+	resp, _ := respwrap.ResponseWrapper(codec, v, nil)
+	return string(resp)
+}
+
+func cosmosResponse(v interface{}, err error) string {
+	return response(cdc, v, err)
+}
+
+func qosResponse(v interface{}, err error) string {
+	return response(qosapp.Cdc, v, err)
+}
+
+func plainResponse(v interface{}, err error) string {
+	return response(plainCdc, v, err)
+}
+
+//balanceResult is the ETH or ERC20 balance returned to mobile, the amount is in the smallest unit
+type balanceResult struct {
+	Amount   string `json:"amount"`
+	Decimals int    `json:"decimals"`
+	Symbol   string `json:"symbol"`
+	Display  string `json:"display"` //in whole units, e.g. 1.5ETH
+}
+
+func newBalanceResult(balance *eth.Balance) *balanceResult {
+	return &balanceResult{
+		Amount:   balance.Amount.String(),
+		Decimals: balance.Decimals,
+		Symbol:   balance.Symbol,
+		Display:  balance.String(),
+	}
+}
+
+func balanceResponse(balance *eth.Balance, err error) string {
+	if err != nil {
+		return plainResponse(nil, err)
+	}
+	return plainResponse(newBalanceResult(balance), nil)
+}
+
+//derivedAccountResult is an account of a mnemonic, the balance is only set when a node is given
+type derivedAccountResult struct {
+	Index   uint32         `json:"index"`
+	Path    string         `json:"path"`
+	Address string         `json:"address"`
+	Balance *balanceResult `json:"balance,omitempty"`
+}
+
+func derivedAccountsResponse(accounts []eth.DerivedAccount, err error) string {
+	if err != nil {
+		return plainResponse(nil, err)
+	}
+	out := make([]derivedAccountResult, len(accounts))
+	for i, account := range accounts {
+		out[i] = derivedAccountResult{Index: account.Index, Path: account.Path, Address: account.Address}
+		if account.Balance != nil {
+			out[i].Balance = newBalanceResult(account.Balance)
+		}
+	}
+	return plainResponse(out, nil)
+}
+
+//qscTxResponse returns the hex of the signed qsc tx, to broadcast with QOSBroadcastTransferTxToQSC
+func qscTxResponse(tx *txs.TxStd, err error) string {
+	if err != nil {
+		return qosResponse(nil, err)
+	}
+	bz, err := qostxs.Cdc.MarshalBinaryBare(tx)
+	if err != nil {
+		return qosResponse(nil, errcode.Internal(err))
+	}
+	return qosResponse(hex.EncodeToString(bz), nil)
+}
+
+//offlineTxResponse returns the JSON document of the offline tx as a string, to pass as is to the next step
+func offlineTxResponse(tx offline.Tx, err error) string {
+	if err != nil {
+		return plainResponse(nil, err)
+	}
+	return plainResponse(tx.String(), nil)
+}
+
+//messageSignatureResponse returns the JSON of the message signature as a string, to pass as is to the verifier
+func messageSignatureResponse(sig signer.MessageSignature, err error) string {
+	if err != nil {
+		return plainResponse(nil, err)
+	}
+	bz, err := json.Marshal(sig)
+	if err != nil {
+		return plainResponse(nil, errcode.Internal(err))
+	}
+	return plainResponse(string(bz), nil)
+}
+
+//parseMessageSignature parses the JSON of a message signature, a malformed one is an invalid input
+func parseMessageSignature(s string) (signer.MessageSignature, error) {
+	var sig signer.MessageSignature
+	if err := json.Unmarshal([]byte(s), &sig); err != nil {
+		return signer.MessageSignature{}, errcode.InvalidInput(err)
+	}
+	return sig, nil
+}
+
+//decodeHex decodes a hex argument, a malformed one is an invalid input
+func decodeHex(s string) ([]byte, error) {
+	bz, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, errcode.InvalidInput(err)
+	}
+	return bz, nil
+}
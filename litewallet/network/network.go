@@ -0,0 +1,235 @@
+//Package network is the registry of the networks the wallet talks to. A profile describes one network,
+//its chain family, chain id, rpc endpoints, fee denom and address prefixes, so the apps pick a network
+//by name and switch between mainnet and testnet by loading another file instead of rebuilding.
+package network
+
+import (
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/QOSGroup/litewallet/litewallet/errcode"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/viper"
+	"github.com/tendermint/tendermint/libs/bech32"
+)
+
+//Family is the chain family of a network, it selects the wallet functions used on it
+type Family string
+
+const (
+	FamilyCosmos Family = "cosmos"
+	FamilyQOS    Family = "qos"
+	FamilyETH    Family = "eth"
+)
+
+//Profile describes a network. The endpoints are in the form taken by the functions of the family:
+//tcp://host:port for Cosmos, host:port for QOS and the json-rpc url for ETH.
+type Profile struct {
+	Name            string   `json:"name" mapstructure:"name"`
+	Family          Family   `json:"family" mapstructure:"family"`
+	ChainID         string   `json:"chain_id" mapstructure:"chain_id"`
+	Endpoints       []string `json:"endpoints" mapstructure:"endpoints"`
+	FeeDenom        string   `json:"fee_denom" mapstructure:"fee_denom"`
+	Decimals        int      `json:"decimals" mapstructure:"decimals"`
+	AccountPrefix   string   `json:"account_prefix" mapstructure:"account_prefix"`
+	ValidatorPrefix string   `json:"validator_prefix" mapstructure:"validator_prefix"`
+	Explorer        string   `json:"explorer" mapstructure:"explorer"`
+}
+
+//Validate checks that the profile can be used by the functions of its family
+func (p Profile) Validate() error {
+	if p.Name == "" {
+		return errcode.New(errcode.CodeInvalidInput, "network name is required")
+	}
+	switch p.Family {
+	case FamilyCosmos, FamilyQOS:
+		if p.ChainID == "" {
+			return errcode.Errorf(errcode.CodeInvalidInput, "network %s: chain id is required", p.Name)
+		}
+	case FamilyETH:
+	default:
+		return errcode.Errorf(errcode.CodeInvalidInput, "network %s: unknown chain family %q", p.Name, p.Family)
+	}
+	if len(p.Endpoints) == 0 {
+		return errcode.Errorf(errcode.CodeInvalidInput, "network %s: no rpc endpoint", p.Name)
+	}
+	if p.Decimals < 0 {
+		return errcode.Errorf(errcode.CodeInvalidInput, "network %s: negative decimals", p.Name)
+	}
+	return nil
+}
+
+//Coins appends the fee denom to a bare amount like "100", a coin string with a denom is returned as is
+func (p Profile) Coins(amount string) string {
+	amount = strings.TrimSpace(amount)
+	if amount == "" || strings.TrimLeft(amount, "0123456789.") != "" {
+		return amount
+	}
+	return amount + p.FeeDenom
+}
+
+//CheckAddress checks that addr is an account address of the network:
+//a hex address on ETH, a bech32 address with the account prefix on Cosmos and QOS
+func (p Profile) CheckAddress(addr string) error {
+	if p.Family == FamilyETH {
+		if !common.IsHexAddress(addr) {
+			return errcode.Errorf(errcode.CodeInvalidInput, "invalid address %q", addr)
+		}
+		return nil
+	}
+	hrp, _, err := bech32.DecodeAndConvert(addr)
+	if err != nil {
+		return errcode.Errorf(errcode.CodeInvalidInput, "invalid address %q: %v", addr, err)
+	}
+	if p.AccountPrefix != "" && hrp != p.AccountPrefix {
+		return errcode.Errorf(errcode.CodeInvalidInput, "address %s is not on network %s, want prefix %s", addr, p.Name, p.AccountPrefix)
+	}
+	return nil
+}
+
+//Registry holds the profiles by name and the pools of their endpoints, it is safe for concurrent use
+type Registry struct {
+	mtx      sync.RWMutex
+	profiles map[string]Profile
+	pools    map[string]*Pool
+}
+
+//NewRegistry returns an empty registry
+func NewRegistry() *Registry {
+	return &Registry{profiles: make(map[string]Profile), pools: make(map[string]*Pool)}
+}
+
+//Register validates p and stores it, replacing the profile of the same name
+func (r *Registry) Register(p Profile) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	p.Endpoints = append([]string(nil), p.Endpoints...)
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.profiles[p.Name] = p
+	delete(r.pools, p.Name)
+	return nil
+}
+
+//Get returns the profile name
+func (r *Registry) Get(name string) (Profile, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	p, ok := r.profiles[name]
+	if !ok {
+		return Profile{}, errcode.Errorf(errcode.CodeInvalidInput, "unknown network %q", name)
+	}
+	return p, nil
+}
+
+//Pool returns the pool of the endpoints of the profile name, the same one until the profile is replaced
+func (r *Registry) Pool(name string) (*Pool, error) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if pool, ok := r.pools[name]; ok {
+		return pool, nil
+	}
+	p, ok := r.profiles[name]
+	if !ok {
+		return nil, errcode.Errorf(errcode.CodeInvalidInput, "unknown network %q", name)
+	}
+	pool := NewPool(p)
+	r.pools[name] = pool
+	return pool, nil
+}
+
+//Profiles returns all profiles sorted by name
+func (r *Registry) Profiles() []Profile {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	profiles := make([]Profile, 0, len(r.profiles))
+	for _, p := range r.profiles {
+		profiles = append(profiles, p)
+	}
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].Name < profiles[j].Name })
+	return profiles
+}
+
+//LoadFile registers the networks of a json or toml file, the format is taken from the extension.
+//The file has a list of profiles under networks:
+//
+//	[[networks]]
+//	name = "cosmoshub"
+//	family = "cosmos"
+//	chain_id = "cosmoshub-2"
+//	endpoints = ["tcp://localhost:26657"]
+//	fee_denom = "uatom"
+//	decimals = 6
+//	account_prefix = "cosmos"
+//	validator_prefix = "cosmosvaloper"
+//
+//Nothing is registered if one of the profiles is invalid.
+func (r *Registry) LoadFile(path string) error {
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return errcode.Errorf(errcode.CodeInvalidInput, "read networks %s: %v", path, err)
+	}
+	return r.load(v)
+}
+
+//Load registers the networks read from rd in format, json or toml, as for LoadFile
+func (r *Registry) Load(rd io.Reader, format string) error {
+	v := viper.New()
+	v.SetConfigType(format)
+	if err := v.ReadConfig(rd); err != nil {
+		return errcode.Errorf(errcode.CodeInvalidInput, "read networks: %v", err)
+	}
+	return r.load(v)
+}
+
+func (r *Registry) load(v *viper.Viper) error {
+	var profiles []Profile
+	if err := v.UnmarshalKey("networks", &profiles); err != nil {
+		return errcode.Errorf(errcode.CodeInvalidInput, "read networks: %v", err)
+	}
+	seen := make(map[string]bool)
+	for _, p := range profiles {
+		if err := p.Validate(); err != nil {
+			return err
+		}
+		if seen[p.Name] {
+			return errcode.Errorf(errcode.CodeInvalidInput, "network %s is defined twice", p.Name)
+		}
+		seen[p.Name] = true
+	}
+	for _, p := range profiles {
+		r.Register(p)
+	}
+	return nil
+}
+
+var registry = NewRegistry()
+
+//Register stores p in the registry used by the api functions taking a network name
+func Register(p Profile) error {
+	return registry.Register(p)
+}
+
+//Get returns the profile name of the registry used by the api functions
+func Get(name string) (Profile, error) {
+	return registry.Get(name)
+}
+
+//GetPool returns the pool of the endpoints of the profile name in the registry used by the api functions
+func GetPool(name string) (*Pool, error) {
+	return registry.Pool(name)
+}
+
+//Profiles returns the profiles of the registry used by the api functions
+func Profiles() []Profile {
+	return registry.Profiles()
+}
+
+//LoadFile registers the networks of a json or toml file in the registry used by the api functions
+func LoadFile(path string) error {
+	return registry.LoadFile(path)
+}
@@ -0,0 +1,165 @@
+package network
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/QOSGroup/litewallet/litewallet/errcode"
+)
+
+const networksTOML = `
+[[networks]]
+name = "cosmoshub"
+family = "cosmos"
+chain_id = "cosmoshub-2"
+endpoints = ["tcp://node-1:26657", "tcp://node-2:26657"]
+fee_denom = "uatom"
+decimals = 6
+account_prefix = "cosmos"
+validator_prefix = "cosmosvaloper"
+explorer = "https://explorer.example/cosmos"
+
+[[networks]]
+name = "kovan"
+family = "eth"
+endpoints = ["https://kovan.example/rpc"]
+fee_denom = "ETH"
+decimals = 18
+`
+
+const networksJSON = `{"networks": [
+	{"name": "qos-test", "family": "qos", "chain_id": "aquarius-1000", "endpoints": ["127.0.0.1:26657"],
+	 "fee_denom": "qos", "decimals": 0, "account_prefix": "qosacc", "validator_prefix": "qosval"}
+]}`
+
+var cosmoshub = Profile{
+	Name:            "cosmoshub",
+	Family:          FamilyCosmos,
+	ChainID:         "cosmoshub-2",
+	Endpoints:       []string{"tcp://node-1:26657", "tcp://node-2:26657"},
+	FeeDenom:        "uatom",
+	Decimals:        6,
+	AccountPrefix:   "cosmos",
+	ValidatorPrefix: "cosmosvaloper",
+	Explorer:        "https://explorer.example/cosmos",
+}
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "network")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	r := NewRegistry()
+	if err := r.LoadFile(writeFile(t, dir, "networks.toml", networksTOML)); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.LoadFile(writeFile(t, dir, "networks.json", networksJSON)); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := r.Get("cosmoshub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(p, cosmoshub) {
+		t.Errorf("got %+v, want %+v", p, cosmoshub)
+	}
+	if p, _ := r.Get("qos-test"); p.Family != FamilyQOS || p.ChainID != "aquarius-1000" || p.Endpoints[0] != "127.0.0.1:26657" {
+		t.Errorf("got %+v", p)
+	}
+
+	var names []string
+	for _, p := range r.Profiles() {
+		names = append(names, p.Name)
+	}
+	if want := []string{"cosmoshub", "kovan", "qos-test"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("got networks %v, want %v", names, want)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	cases := map[string]string{
+		"no chain id":    `{"networks": [{"name": "a", "family": "cosmos", "endpoints": ["tcp://a:1"]}]}`,
+		"no endpoint":    `{"networks": [{"name": "a", "family": "eth"}]}`,
+		"unknown family": `{"networks": [{"name": "a", "family": "btc", "endpoints": ["a"]}]}`,
+		"no name":        `{"networks": [{"family": "eth", "endpoints": ["a"]}]}`,
+		"twice":          `{"networks": [{"name": "a", "family": "eth", "endpoints": ["a"]}, {"name": "a", "family": "eth", "endpoints": ["b"]}]}`,
+		"not json":       `{"networks": [`,
+	}
+	for name, data := range cases {
+		r := NewRegistry()
+		r.Register(Profile{Name: "kept", Family: FamilyETH, Endpoints: []string{"a"}})
+		err := r.Load(strings.NewReader(data), "json")
+		if errcode.Code(err) != errcode.CodeInvalidInput {
+			t.Errorf("%s: got %v, want an invalid input error", name, err)
+		}
+		if len(r.Profiles()) != 1 {
+			t.Errorf("%s: the registry changed", name)
+		}
+	}
+}
+
+func TestRegister(t *testing.T) {
+	r := NewRegistry()
+	if _, err := r.Get("cosmoshub"); errcode.Code(err) != errcode.CodeInvalidInput {
+		t.Errorf("got %v for an unknown network, want an invalid input error", err)
+	}
+	if err := r.Register(cosmoshub); err != nil {
+		t.Fatal(err)
+	}
+	testnet := cosmoshub
+	testnet.ChainID = "gaia-13003"
+	if err := r.Register(testnet); err != nil {
+		t.Fatal(err)
+	}
+	if p, _ := r.Get("cosmoshub"); p.ChainID != "gaia-13003" {
+		t.Errorf("got chain id %s, want the replacement", p.ChainID)
+	}
+}
+
+func TestCoins(t *testing.T) {
+	cases := map[string]string{
+		"100":      "100uatom",
+		" 1.5 ":    "1.5uatom",
+		"100stake": "100stake",
+		"":         "",
+	}
+	for amount, want := range cases {
+		if got := cosmoshub.Coins(amount); got != want {
+			t.Errorf("Coins(%q) = %q, want %q", amount, got, want)
+		}
+	}
+}
+
+func TestCheckAddress(t *testing.T) {
+	if err := cosmoshub.CheckAddress("cosmos1nelm60csnn6204tav8s5ypkvevm6k2xsch8x5r"); err != nil {
+		t.Error(err)
+	}
+	for _, addr := range []string{"qosacc1saaz7w30ke89gqceqq2rfzvadzevf6sxauycja", "cosmos1nelm", "0x1B37AB8d737B1776d3cC082D246Ee89Ed9693cD2"} {
+		if err := cosmoshub.CheckAddress(addr); errcode.Code(err) != errcode.CodeInvalidInput {
+			t.Errorf("%s: got %v, want an invalid input error", addr, err)
+		}
+	}
+
+	kovan := Profile{Name: "kovan", Family: FamilyETH, Endpoints: []string{"a"}}
+	if err := kovan.CheckAddress("0x1B37AB8d737B1776d3cC082D246Ee89Ed9693cD2"); err != nil {
+		t.Error(err)
+	}
+	if err := kovan.CheckAddress("cosmos1nelm60csnn6204tav8s5ypkvevm6k2xsch8x5r"); errcode.Code(err) != errcode.CodeInvalidInput {
+		t.Errorf("got %v, want an invalid input error", err)
+	}
+}
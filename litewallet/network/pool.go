@@ -0,0 +1,294 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/QOSGroup/litewallet/litewallet/errcode"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+const (
+	//DefaultMaxLag is how many blocks an endpoint may be behind the highest one before it is stale
+	DefaultMaxLag = 5
+	//DefaultCheckInterval is how long the health of the endpoints is trusted
+	DefaultCheckInterval = 30 * time.Second
+	//DefaultProbeTimeout bounds a health check
+	DefaultProbeTimeout = 5 * time.Second
+)
+
+//Probe returns the latest height and the chain id reported by an endpoint
+type Probe func(ctx context.Context, endpoint string) (height int64, chainID string, err error)
+
+//ProbeTendermint probes a Cosmos or QOS node with the status rpc, within the deadline of ctx or
+//DefaultProbeTimeout
+func ProbeTendermint(ctx context.Context, endpoint string) (int64, string, error) {
+	timeout := DefaultProbeTimeout
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
+	req, err := http.NewRequest(http.MethodGet, statusURL(endpoint), nil)
+	if err != nil {
+		return 0, "", errcode.InvalidInput(err)
+	}
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return 0, "", errcode.Network(err)
+	}
+	defer resp.Body.Close()
+	//the fields of the status the probe needs, the heights are strings in the amino json
+	var status struct {
+		Result struct {
+			NodeInfo struct {
+				Network string `json:"network"`
+			} `json:"node_info"`
+			SyncInfo struct {
+				LatestBlockHeight int64 `json:"latest_block_height,string"`
+			} `json:"sync_info"`
+		} `json:"result"`
+		Error *struct {
+			Message string `json:"message"`
+			Data    string `json:"data"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return 0, "", errcode.Network(err)
+	}
+	if status.Error != nil {
+		return 0, "", errcode.Errorf(errcode.CodeNetwork, "status of %s: %s %s", endpoint, status.Error.Message, status.Error.Data)
+	}
+	return status.Result.SyncInfo.LatestBlockHeight, status.Result.NodeInfo.Network, nil
+}
+
+//statusURL is the url of the status rpc of a tcp://host:port, host:port or http url endpoint
+func statusURL(endpoint string) string {
+	switch {
+	case strings.HasPrefix(endpoint, "tcp://"):
+		endpoint = "http://" + strings.TrimPrefix(endpoint, "tcp://")
+	case !strings.Contains(endpoint, "://"):
+		endpoint = "http://" + endpoint
+	}
+	return strings.TrimSuffix(endpoint, "/") + "/status"
+}
+
+//ProbeETH probes an ETH node, the chain id is the network id in decimal
+func ProbeETH(ctx context.Context, endpoint string) (int64, string, error) {
+	client, err := ethclient.DialContext(ctx, endpoint)
+	if err != nil {
+		return 0, "", errcode.Network(err)
+	}
+	defer client.Close()
+	header, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return 0, "", errcode.Network(err)
+	}
+	id, err := client.NetworkID(ctx)
+	if err != nil {
+		return 0, "", errcode.Network(err)
+	}
+	return header.Number.Int64(), id.String(), nil
+}
+
+//Health is the state of an endpoint at its last check or call
+type Health struct {
+	Endpoint string        `json:"endpoint"`
+	Height   int64         `json:"height"`
+	ChainID  string        `json:"chain_id"`
+	Latency  time.Duration `json:"latency"`
+	Err      error         `json:"-"`
+}
+
+//Pool spreads the calls to a network over its endpoints. The endpoints are probed at most every
+//CheckInterval, the calls go round robin to the ones on the chain of the profile and at most MaxLag
+//blocks behind, and fail over to the next endpoint on a network error or an answer failing the
+//verification. The stale and failed endpoints are still tried last, the endpoints of another chain never.
+type Pool struct {
+	Profile       Profile
+	Probe         Probe
+	MaxLag        int64
+	CheckInterval time.Duration
+	ProbeTimeout  time.Duration
+
+	mtx      sync.Mutex
+	health   map[string]Health
+	checked  time.Time
+	checking chan struct{}
+	next     int
+}
+
+//NewPool returns a pool over the endpoints of p, probed according to its family
+func NewPool(p Profile) *Pool {
+	probe := ProbeTendermint
+	if p.Family == FamilyETH {
+		probe = ProbeETH
+	}
+	return &Pool{
+		Profile:       p,
+		Probe:         probe,
+		MaxLag:        DefaultMaxLag,
+		CheckInterval: DefaultCheckInterval,
+		ProbeTimeout:  DefaultProbeTimeout,
+		health:        make(map[string]Health),
+	}
+}
+
+//Check probes all endpoints now, concurrently, and returns their health in the order of the profile.
+//A check already running is waited for instead of starting another one.
+func (pl *Pool) Check(ctx context.Context) []Health {
+	pl.check(ctx, false)
+	return pl.Health()
+}
+
+//check runs a check of the endpoints, when due is set only if the last one is older than CheckInterval.
+//One check runs at a time, the callers meanwhile wait for its result.
+func (pl *Pool) check(ctx context.Context, due bool) {
+	pl.mtx.Lock()
+	if running := pl.checking; running != nil {
+		pl.mtx.Unlock()
+		select {
+		case <-running:
+		case <-ctx.Done():
+		}
+		return
+	}
+	if due && time.Since(pl.checked) < pl.CheckInterval {
+		pl.mtx.Unlock()
+		return
+	}
+	running := make(chan struct{})
+	pl.checking = running
+	pl.mtx.Unlock()
+
+	health := pl.probe(ctx)
+
+	pl.mtx.Lock()
+	pl.health = health
+	pl.checked = time.Now()
+	pl.checking = nil
+	pl.mtx.Unlock()
+	close(running)
+}
+
+//probe probes all endpoints concurrently within ProbeTimeout
+func (pl *Pool) probe(ctx context.Context) map[string]Health {
+	ctx, cancel := context.WithTimeout(ctx, pl.ProbeTimeout)
+	defer cancel()
+
+	results := make(chan Health, len(pl.Profile.Endpoints))
+	for _, endpoint := range pl.Profile.Endpoints {
+		go func(endpoint string) {
+			start := time.Now()
+			height, chainID, err := pl.Probe(ctx, endpoint)
+			results <- Health{Endpoint: endpoint, Height: height, ChainID: chainID, Latency: time.Since(start), Err: err}
+		}(endpoint)
+	}
+	//the probes not done in time are timeouts, their result is dropped
+	health := make(map[string]Health)
+	for range pl.Profile.Endpoints {
+		select {
+		case h := <-results:
+			health[h.Endpoint] = h
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	for _, endpoint := range pl.Profile.Endpoints {
+		if _, ok := health[endpoint]; !ok {
+			health[endpoint] = Health{Endpoint: endpoint, Latency: pl.ProbeTimeout, Err: errcode.Network(ctx.Err())}
+		}
+	}
+	return health
+}
+
+//Health returns the health of the endpoints at their last check or call, in the order of the profile
+func (pl *Pool) Health() []Health {
+	pl.mtx.Lock()
+	defer pl.mtx.Unlock()
+	var health []Health
+	for _, endpoint := range pl.Profile.Endpoints {
+		h, ok := pl.health[endpoint]
+		if !ok {
+			h = Health{Endpoint: endpoint}
+		}
+		health = append(health, h)
+	}
+	return health
+}
+
+//Endpoints returns the endpoints in the order to try them, after a check if the last one is too old
+func (pl *Pool) Endpoints(ctx context.Context) []string {
+	pl.check(ctx, true)
+
+	pl.mtx.Lock()
+	defer pl.mtx.Unlock()
+	var best int64
+	for _, h := range pl.health {
+		if h.Err == nil && pl.onChain(h) && h.Height > best {
+			best = h.Height
+		}
+	}
+	var healthy, fallback []Health
+	for _, endpoint := range pl.Profile.Endpoints {
+		h := pl.health[endpoint]
+		switch {
+		case h.Err == nil && pl.onChain(h) && h.Height >= best-pl.MaxLag:
+			healthy = append(healthy, h)
+		case h.ChainID == "" || pl.onChain(h):
+			fallback = append(fallback, h)
+		}
+	}
+	sort.SliceStable(healthy, func(i, j int) bool { return healthy[i].Latency < healthy[j].Latency })
+	sort.SliceStable(fallback, func(i, j int) bool { return fallback[i].Height > fallback[j].Height })
+
+	var endpoints []string
+	if n := len(healthy); n > 0 {
+		start := pl.next % n
+		pl.next++
+		for i := 0; i < n; i++ {
+			endpoints = append(endpoints, healthy[(start+i)%n].Endpoint)
+		}
+	}
+	for _, h := range fallback {
+		endpoints = append(endpoints, h.Endpoint)
+	}
+	return endpoints
+}
+
+func (pl *Pool) onChain(h Health) bool {
+	return pl.Profile.ChainID == "" || h.ChainID == pl.Profile.ChainID
+}
+
+//Do calls fn with the endpoints in turn until one does not fail with a network or verification error.
+//The error of fn is returned as is when it is not a network error, e.g. a tx rejected by the node.
+//A broadcast failing with a network error may have reached the node before the next endpoint gets the same tx,
+//which the node then refuses as a duplicate.
+func (pl *Pool) Do(ctx context.Context, fn func(endpoint string) error) error {
+	endpoints := pl.Endpoints(ctx)
+	if len(endpoints) == 0 {
+		return errcode.Errorf(errcode.CodeNetwork, "network %s: no endpoint on chain %s", pl.Profile.Name, pl.Profile.ChainID)
+	}
+	var err error
+	for _, endpoint := range endpoints {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		err = fn(endpoint)
+		if code := errcode.Code(err); code != errcode.CodeNetwork && code != errcode.CodeUnverified {
+			return err
+		}
+		pl.mtx.Lock()
+		h := pl.health[endpoint]
+		h.Endpoint, h.Err = endpoint, err
+		pl.health[endpoint] = h
+		pl.mtx.Unlock()
+	}
+	return err
+}
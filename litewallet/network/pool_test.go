@@ -0,0 +1,234 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/QOSGroup/litewallet/litewallet/errcode"
+	"github.com/QOSGroup/litewallet/litewallet/rpctest"
+)
+
+//fakeNodes answers the probes with the height and chain id set per endpoint, the endpoints without one are down
+type fakeNodes struct {
+	mtx    sync.Mutex
+	height map[string]int64
+	chain  map[string]string
+	delay  map[string]time.Duration
+	probes int
+}
+
+func newFakeNodes() *fakeNodes {
+	return &fakeNodes{height: make(map[string]int64), chain: make(map[string]string), delay: make(map[string]time.Duration)}
+}
+
+func (f *fakeNodes) set(endpoint string, height int64, chainID string) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.height[endpoint], f.chain[endpoint] = height, chainID
+}
+
+func (f *fakeNodes) probe(ctx context.Context, endpoint string) (int64, string, error) {
+	f.mtx.Lock()
+	f.probes++
+	height, ok := f.height[endpoint]
+	chainID, delay := f.chain[endpoint], f.delay[endpoint]
+	f.mtx.Unlock()
+	select {
+	case <-time.After(delay):
+	case <-ctx.Done():
+		return 0, "", errcode.Network(ctx.Err())
+	}
+	if !ok {
+		return 0, "", errcode.Errorf(errcode.CodeNetwork, "%s is down", endpoint)
+	}
+	return height, chainID, nil
+}
+
+func newTestPool(nodes *fakeNodes, endpoints ...string) *Pool {
+	pool := NewPool(Profile{Name: "test", Family: FamilyQOS, ChainID: "test-1", Endpoints: endpoints})
+	pool.Probe = nodes.probe
+	return pool
+}
+
+func TestPoolEndpoints(t *testing.T) {
+	nodes := newFakeNodes()
+	nodes.set("a", 100, "test-1")
+	nodes.set("b", 90, "test-1")
+	nodes.set("c", 200, "other-1")
+	nodes.set("d", 99, "test-1")
+	pool := newTestPool(nodes, "a", "b", "c", "d", "e")
+
+	//b is stale and e down, they come last by height, c is on another chain
+	ctx := context.Background()
+	first := pool.Endpoints(ctx)
+	if len(first) != 4 || first[2] != "b" || first[3] != "e" {
+		t.Fatalf("got %v, want a and d, then b and e", first)
+	}
+	second := pool.Endpoints(ctx)
+	if !reflect.DeepEqual(second[:2], []string{first[1], first[0]}) {
+		t.Errorf("got %v after %v, want the healthy endpoints in turn", second, first)
+	}
+	if nodes.probes != 5 {
+		t.Errorf("probed %d times, want one check within the interval", nodes.probes)
+	}
+
+	for _, h := range pool.Health() {
+		if (h.Err != nil) != (h.Endpoint == "e") {
+			t.Errorf("got health %+v", h)
+		}
+	}
+}
+
+func TestPoolCheckTimeout(t *testing.T) {
+	nodes := newFakeNodes()
+	nodes.set("a", 100, "test-1")
+	nodes.set("slow", 100, "test-1")
+	nodes.delay["slow"] = time.Second
+	pool := newTestPool(nodes, "slow", "a")
+	pool.ProbeTimeout = 50 * time.Millisecond
+
+	health := pool.Check(context.Background())
+	if health[0].Endpoint != "slow" || errcode.Code(health[0].Err) != errcode.CodeNetwork {
+		t.Errorf("got %+v, want a timeout", health[0])
+	}
+	if health[1].Err != nil {
+		t.Errorf("got %+v, want a healthy endpoint", health[1])
+	}
+}
+
+func TestPoolSingleCheck(t *testing.T) {
+	nodes := newFakeNodes()
+	nodes.set("a", 100, "test-1")
+	nodes.set("b", 100, "test-1")
+	nodes.delay["a"] = 20 * time.Millisecond
+	pool := newTestPool(nodes, "a", "b")
+
+	//the callers arriving while the interval is over share one check
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if endpoints := pool.Endpoints(context.Background()); len(endpoints) != 2 {
+				t.Errorf("got %v, want a and b", endpoints)
+			}
+		}()
+	}
+	wg.Wait()
+	if nodes.probes != 2 {
+		t.Errorf("probed %d times, want one check", nodes.probes)
+	}
+}
+
+func TestPoolDo(t *testing.T) {
+	nodes := newFakeNodes()
+	nodes.set("a", 100, "test-1")
+	nodes.set("b", 100, "test-1")
+	pool := newTestPool(nodes, "a", "b")
+	ctx := context.Background()
+
+	//whichever endpoint comes first fails, the call goes to the other one
+	var called []string
+	err := pool.Do(ctx, func(endpoint string) error {
+		called = append(called, endpoint)
+		if len(called) == 1 {
+			return errcode.Network(errors.New("connection refused"))
+		}
+		return nil
+	})
+	if err != nil || len(called) != 2 || called[0] == called[1] {
+		t.Fatalf("got %v calling %v, want a failover", err, called)
+	}
+	//the failed endpoint is only tried after the other one until the next check
+	for i := 0; i < 2; i++ {
+		if endpoints := pool.Endpoints(ctx); endpoints[0] != called[1] {
+			t.Errorf("got %v, want %s first", endpoints, called[1])
+		}
+	}
+
+	//a rejected tx is not sent again to the next endpoint
+	called = nil
+	err = pool.Do(ctx, func(endpoint string) error {
+		called = append(called, endpoint)
+		return errcode.New(errcode.CodeNodeRejected, "invalid nonce")
+	})
+	if errcode.Code(err) != errcode.CodeNodeRejected || len(called) != 1 {
+		t.Errorf("got %v calling %v, want the rejection of the first endpoint", err, called)
+	}
+
+	//a node failing the verification is skipped like an unreachable one
+	called = nil
+	err = pool.Do(ctx, func(endpoint string) error {
+		called = append(called, endpoint)
+		if len(called) == 1 {
+			return errcode.Unverified(errors.New("failed to prove merkle proof"))
+		}
+		return nil
+	})
+	if err != nil || len(called) != 2 {
+		t.Errorf("got %v calling %v, want a failover", err, called)
+	}
+
+	err = pool.Do(ctx, func(endpoint string) error {
+		return errcode.Network(errors.New("connection refused"))
+	})
+	if errcode.Code(err) != errcode.CodeNetwork {
+		t.Errorf("got %v, want a network error once all endpoints failed", err)
+	}
+}
+
+func TestPoolWrongChain(t *testing.T) {
+	nodes := newFakeNodes()
+	nodes.set("a", 100, "other-1")
+	pool := newTestPool(nodes, "a")
+
+	err := pool.Do(context.Background(), func(endpoint string) error {
+		t.Errorf("called %s on another chain", endpoint)
+		return nil
+	})
+	if errcode.Code(err) != errcode.CodeNetwork {
+		t.Errorf("got %v, want a network error", err)
+	}
+}
+
+func TestProbeTendermint(t *testing.T) {
+	node := rpctest.NewNode("test-1", rpctest.NewApp())
+	down := rpctest.NewNode("test-1", rpctest.NewApp())
+	down.Close()
+	defer node.Close()
+
+	pool := NewPool(Profile{Name: "test", Family: FamilyQOS, ChainID: "test-1", Endpoints: []string{down.Remote(), node.Remote()}})
+	health := pool.Check(context.Background())
+	if errcode.Code(health[0].Err) != errcode.CodeNetwork {
+		t.Errorf("got %+v for a stopped node, want a network error", health[0])
+	}
+	if h := health[1]; h.Err != nil || h.Height != node.Height() || h.ChainID != "test-1" {
+		t.Errorf("got %+v, want the status of the node", h)
+	}
+	if endpoints := pool.Endpoints(context.Background()); endpoints[0] != node.Remote() {
+		t.Errorf("got %v, want the running node first", endpoints)
+	}
+}
+
+func TestProbeTendermintTimeout(t *testing.T) {
+	hung := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(time.Second)
+	}))
+	defer hung.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, _, err := ProbeTendermint(ctx, hung.URL); errcode.Code(err) != errcode.CodeNetwork {
+		t.Errorf("got %v for a hung node, want a network error", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("the probe took %v, want it bounded by the context", elapsed)
+	}
+}
@@ -0,0 +1,106 @@
+//Package offline is the portable document of a tx signed on an air-gapped device. The tx is built online
+//from the address of the sender, with the account number, sequence or nonce read from the node, signed on
+//the offline device which holds the key and broadcast from any online device:
+//
+//	build (online)  -> Tx with SignHash
+//	sign (offline)  -> Tx with Signed and Hash
+//	broadcast       -> Hash
+//
+//The document is JSON so it can be moved as a file or a QR code. The chain packages build, sign and
+//broadcast the Tx of their chain.
+package offline
+
+import (
+	"encoding/json"
+
+	"github.com/QOSGroup/litewallet/litewallet/errcode"
+)
+
+//the chains of the documents
+const (
+	ChainCosmos = "cosmos"
+	ChainETH    = "eth"
+	ChainQOS    = "qos"
+)
+
+//the types of the txs, informative for the offline device showing what it signs
+const (
+	TypeTransfer          = "transfer"
+	TypeERC20Transfer     = "erc20_transfer"
+	TypeDelegate          = "delegate"
+	TypeUndelegate        = "undelegate"
+	TypeRedelegate        = "redelegate"
+	TypeWithdrawReward    = "withdraw_reward"
+	TypeWithdrawAllReward = "withdraw_all_rewards"
+)
+
+//Tx is an unsigned or signed tx. Tx is the unsigned tx in the JSON of its chain, AccountNumber and
+//Sequence are the ones it is signed with, Sequence being the nonce of ETH and QOS. SignHash is the hex
+//hash of the bytes the key signs, Signed the hex of the signed tx and Hash the hash the chain knows it by,
+//both set by the signature.
+type Tx struct {
+	Chain         string          `json:"chain"`
+	ChainID       string          `json:"chain_id"`
+	Type          string          `json:"type"`
+	Signer        string          `json:"signer"`
+	AccountNumber uint64          `json:"account_number"`
+	Sequence      uint64          `json:"sequence"`
+	Tx            json.RawMessage `json:"tx"`
+	SignHash      string          `json:"sign_hash"`
+	Signed        string          `json:"signed,omitempty"`
+	Hash          string          `json:"hash,omitempty"`
+}
+
+//Parse reads the document doc
+func Parse(doc string) (Tx, error) {
+	var tx Tx
+	if err := json.Unmarshal([]byte(doc), &tx); err != nil {
+		return Tx{}, errcode.InvalidInput(err)
+	}
+	switch tx.Chain {
+	case ChainCosmos, ChainETH, ChainQOS:
+	default:
+		return Tx{}, errcode.Errorf(errcode.CodeInvalidInput, "unknown chain %q of the offline tx", tx.Chain)
+	}
+	if tx.Signer == "" || len(tx.Tx) == 0 {
+		return Tx{}, errcode.New(errcode.CodeInvalidInput, "the offline tx misses its signer or tx")
+	}
+	return tx, nil
+}
+
+//String is the JSON of the document
+func (tx Tx) String() string {
+	bz, _ := json.Marshal(tx)
+	return string(bz)
+}
+
+//CheckUnsigned checks tx is an unsigned tx of chain, to sign
+func (tx Tx) CheckUnsigned(chain string) error {
+	if tx.Chain != chain {
+		return errcode.Errorf(errcode.CodeInvalidInput, "the offline tx is a %s tx, not %s", tx.Chain, chain)
+	}
+	if tx.Signed != "" {
+		return errcode.New(errcode.CodeInvalidInput, "the offline tx is already signed")
+	}
+	return nil
+}
+
+//CheckSigned checks tx is a signed tx of chain, to broadcast
+func (tx Tx) CheckSigned(chain string) error {
+	if tx.Chain != chain {
+		return errcode.Errorf(errcode.CodeInvalidInput, "the offline tx is a %s tx, not %s", tx.Chain, chain)
+	}
+	if tx.Signed == "" {
+		return errcode.New(errcode.CodeInvalidInput, "the offline tx is not signed")
+	}
+	return nil
+}
+
+//CheckSignHash checks signHash, the hash computed again from the document by the signature, is the one of
+//the build, a mismatch means the document was modified
+func (tx Tx) CheckSignHash(signHash string) error {
+	if tx.SignHash != "" && tx.SignHash != signHash {
+		return errcode.Errorf(errcode.CodeInvalidInput, "the sign hash %s of the offline tx does not match its content %s", tx.SignHash, signHash)
+	}
+	return nil
+}
@@ -0,0 +1,249 @@
+package rpctest
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/QOSGroup/litewallet/litewallet/trust"
+	amino "github.com/tendermint/go-amino"
+	"github.com/tendermint/iavl"
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/crypto/merkle"
+	cmn "github.com/tendermint/tendermint/libs/common"
+	dbm "github.com/tendermint/tendermint/libs/db"
+)
+
+//QueryFunc answers the custom queries under the path it is registered for
+type QueryFunc func(path string, data []byte) ([]byte, error)
+
+//TxFunc checks tx and, when deliver is set, applies it to the stores. A non zero code rejects tx.
+type TxFunc func(tx []byte, deliver bool) abci.ResponseDeliverTx
+
+//App is an abci.Application over named in-memory IAVL stores under a multistore, hashed and proved
+//as the stores of the QOS and Cosmos apps. It answers the key and subspace queries of the stores
+//itself and leaves the custom queries and the txs to the chain.
+//The QueryFunc and TxFunc run under the lock of the App, the tests change the state with Do.
+type App struct {
+	abci.BaseApplication
+
+	mtx     sync.Mutex
+	cdc     *amino.Codec
+	stores  map[string]*Store
+	queries map[string]QueryFunc
+	txFunc  TxFunc
+	height  int64
+	infos   map[int64][]trust.StoreInfo
+}
+
+//NewApp returns an App with no store, accepting every tx
+func NewApp() *App {
+	return &App{
+		cdc:     amino.NewCodec(),
+		stores:  make(map[string]*Store),
+		queries: make(map[string]QueryFunc),
+		infos:   make(map[int64][]trust.StoreInfo),
+	}
+}
+
+//Store returns the store name, it is created on first use. A store created after the first block
+//is empty at the heights before, so its versions follow the heights of the App.
+func (app *App) Store(name string) *Store {
+	s, ok := app.stores[name]
+	if !ok {
+		s = &Store{tree: iavl.NewMutableTree(dbm.NewMemDB(), 0)}
+		for i := int64(0); i < app.height; i++ {
+			if _, _, err := s.tree.SaveVersion(); err != nil {
+				panic(err)
+			}
+		}
+		app.stores[name] = s
+	}
+	return s
+}
+
+//Do runs fn under the lock of the App, to set up the state while the node is serving.
+//The queries see the changes once the node has committed a block.
+func (app *App) Do(fn func()) {
+	app.mtx.Lock()
+	defer app.mtx.Unlock()
+	fn()
+}
+
+//LastHeight is the height of the last committed block, the txs being delivered go in the next one.
+//It is for the QueryFunc and TxFunc, which already hold the lock.
+func (app *App) LastHeight() int64 {
+	return app.height
+}
+
+//HandleQuery routes the queries starting with prefix to fn, the longest prefix wins
+func (app *App) HandleQuery(prefix string, fn QueryFunc) {
+	app.queries[prefix] = fn
+}
+
+//HandleTx sets the function checking and applying the txs
+func (app *App) HandleTx(fn TxFunc) {
+	app.txFunc = fn
+}
+
+//Query answers the key and subspace queries from the stores at the height asked for, the latest
+//one by default, with the proofs of the key queries when asked for. The custom queries go to the chain.
+func (app *App) Query(req abci.RequestQuery) abci.ResponseQuery {
+	app.mtx.Lock()
+	defer app.mtx.Unlock()
+	//the QOS clients query both /store/... and store/...
+	parts := strings.Split(strings.TrimPrefix(req.Path, "/"), "/")
+	if len(parts) == 3 && parts[0] == "store" {
+		return app.queryStore(parts[1], parts[2], req)
+	}
+
+	var route string
+	for prefix := range app.queries {
+		if strings.HasPrefix(req.Path, prefix) && len(prefix) > len(route) {
+			route = prefix
+		}
+	}
+	if route == "" {
+		return abci.ResponseQuery{Code: 1, Log: "unknown query path " + req.Path}
+	}
+	bz, err := app.queries[route](req.Path, req.Data)
+	if err != nil {
+		return abci.ResponseQuery{Code: 1, Log: err.Error()}
+	}
+	return abci.ResponseQuery{Value: bz}
+}
+
+func (app *App) queryStore(name, kind string, req abci.RequestQuery) abci.ResponseQuery {
+	height := req.Height
+	if height == 0 {
+		height = app.height
+	}
+	infos, ok := app.infos[height]
+	if !ok {
+		return abci.ResponseQuery{Code: 1, Log: fmt.Sprintf("no state at height %d", height)}
+	}
+	res := abci.ResponseQuery{Key: req.Data, Height: height}
+	store, ok := app.stores[name]
+	if !ok || !store.tree.VersionExists(height) {
+		//the store did not exist yet, its keys have no proof
+		return res
+	}
+	tree, err := store.tree.GetImmutable(height)
+	if err != nil {
+		return abci.ResponseQuery{Code: 1, Log: err.Error()}
+	}
+
+	switch kind {
+	case "key":
+		if !req.Prove || tree.Size() == 0 {
+			//an empty tree has no proof, as in the IAVL stores of a real chain
+			_, res.Value = tree.Get(req.Data)
+			return res
+		}
+		value, proof, err := tree.GetWithProof(req.Data)
+		if err != nil {
+			return abci.ResponseQuery{Code: 1, Log: err.Error()}
+		}
+		op := iavl.NewIAVLAbsenceOp(req.Data, proof).ProofOp()
+		if value != nil {
+			op = iavl.NewIAVLValueOp(req.Data, proof).ProofOp()
+		}
+		res.Value = value
+		res.Proof = &merkle.Proof{Ops: []merkle.ProofOp{
+			op,
+			trust.NewMultiStoreProofOp([]byte(name), &trust.MultiStoreProof{StoreInfos: infos}).ProofOp(),
+		}}
+	case "subspace":
+		var kvs []cmn.KVPair
+		tree.IterateRange(req.Data, prefixEnd(req.Data), true, func(key, value []byte) bool {
+			kvs = append(kvs, cmn.KVPair{Key: key, Value: value})
+			return false
+		})
+		bz, err := app.cdc.MarshalBinaryLengthPrefixed(kvs)
+		if err != nil {
+			return abci.ResponseQuery{Code: 1, Log: err.Error()}
+		}
+		res.Value = bz
+	default:
+		return abci.ResponseQuery{Code: 1, Log: "unknown query path " + req.Path}
+	}
+	return res
+}
+
+func (app *App) CheckTx(req abci.RequestCheckTx) abci.ResponseCheckTx {
+	app.mtx.Lock()
+	defer app.mtx.Unlock()
+	if app.txFunc == nil {
+		return abci.ResponseCheckTx{}
+	}
+	res := app.txFunc(req.Tx, false)
+	return abci.ResponseCheckTx{Code: res.Code, Data: res.Data, Log: res.Log, Codespace: res.Codespace}
+}
+
+func (app *App) DeliverTx(req abci.RequestDeliverTx) abci.ResponseDeliverTx {
+	app.mtx.Lock()
+	defer app.mtx.Unlock()
+	if app.txFunc == nil {
+		return abci.ResponseDeliverTx{}
+	}
+	return app.txFunc(req.Tx, true)
+}
+
+//Commit saves a version of every store and returns the root of the multistore as the app hash
+func (app *App) Commit() abci.ResponseCommit {
+	app.mtx.Lock()
+	defer app.mtx.Unlock()
+	app.height++
+	infos := make([]trust.StoreInfo, 0, len(app.stores))
+	for name, store := range app.stores {
+		hash, version, err := store.tree.SaveVersion()
+		if err != nil {
+			panic(err)
+		}
+		infos = append(infos, trust.NewStoreInfo(name, version, hash))
+	}
+	app.infos[app.height] = infos
+	proof := trust.MultiStoreProof{StoreInfos: infos}
+	return abci.ResponseCommit{Data: proof.ComputeRootHash()}
+}
+
+//Store is an IAVL tree of the App, the chain works on its latest state and the queries
+//are answered from the versions committed
+type Store struct {
+	tree *iavl.MutableTree
+}
+
+func (s *Store) Get(key []byte) []byte {
+	_, value := s.tree.Get(key)
+	return value
+}
+
+func (s *Store) Set(key, value []byte) {
+	s.tree.Set(key, value)
+}
+
+func (s *Store) Delete(key []byte) {
+	s.tree.Remove(key)
+}
+
+//Subspace returns the pairs whose key starts with prefix, sorted by key
+func (s *Store) Subspace(prefix []byte) []cmn.KVPair {
+	var kvs []cmn.KVPair
+	s.tree.IterateRange(prefix, prefixEnd(prefix), true, func(key, value []byte) bool {
+		kvs = append(kvs, cmn.KVPair{Key: key, Value: value})
+		return false
+	})
+	return kvs
+}
+
+//prefixEnd returns the first key after all keys starting with prefix, nil if there is none
+func prefixEnd(prefix []byte) []byte {
+	end := append([]byte(nil), prefix...)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	return nil
+}
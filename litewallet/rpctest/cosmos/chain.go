@@ -0,0 +1,424 @@
+//Package cosmos runs a Cosmos hub chain on an rpctest.Node. It keeps the accounts, the validators,
+//the delegations, the unbondings and the rewards in memory and applies the send, delegate,
+//undelegate and withdraw txs signed by the sdksource package.
+package cosmos
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/QOSGroup/litewallet/litewallet/rpctest"
+	"github.com/cosmos/cosmos-sdk/cmd/gaia/app"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/auth"
+	"github.com/cosmos/cosmos-sdk/x/bank"
+	distr "github.com/cosmos/cosmos-sdk/x/distribution"
+	distrkeeper "github.com/cosmos/cosmos-sdk/x/distribution/keeper"
+	"github.com/cosmos/cosmos-sdk/x/staking"
+	"github.com/cosmos/cosmos-sdk/x/staking/types"
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/crypto"
+	cmn "github.com/tendermint/tendermint/libs/common"
+)
+
+const (
+	storeStake = "staking"
+
+	//UnbondingTime is how long an unbonding takes to complete
+	UnbondingTime = 21 * 24 * time.Hour
+)
+
+var cdc = app.MakeCodec()
+
+//Chain is a Cosmos chain with a single node
+type Chain struct {
+	*rpctest.Node
+	app *rpctest.App
+
+	accountNumber uint64
+	rewards       map[string]sdk.DecCoins
+}
+
+//NewChain starts a chain of chainID with no account, Close stops it
+func NewChain(chainID string) *Chain {
+	c := &Chain{app: rpctest.NewApp(), rewards: make(map[string]sdk.DecCoins)}
+	c.app.HandleTx(c.runTx)
+	c.app.HandleQuery(fmt.Sprintf("custom/%s/%s", auth.StoreKey, auth.QueryAccount), c.queryAccount)
+	c.app.HandleQuery("custom/staking/delegatorValidators", c.queryDelegatorValidators)
+	c.app.HandleQuery("custom/distr/delegation_rewards", c.queryDelegationRewards)
+	c.app.HandleQuery("custom/distr/delegator_validators", c.queryRewardValidators)
+	c.Node = rpctest.NewNode(chainID, c.app)
+	return c
+}
+
+//SetAccount creates the account addr holding coins, or replaces its coins
+func (c *Chain) SetAccount(addr sdk.AccAddress, coins sdk.Coins) {
+	c.app.Do(func() {
+		st := c.newState()
+		acc := st.account(addr)
+		if acc == nil {
+			acc = c.newAccount(addr)
+		}
+		if err := acc.SetCoins(coins); err != nil {
+			panic(err)
+		}
+		st.setAccount(acc)
+		st.write()
+	})
+	c.Commit()
+}
+
+//Account returns the account addr, nil if there is none
+func (c *Chain) Account(addr sdk.AccAddress) auth.Account {
+	var acc auth.Account
+	c.app.Do(func() {
+		acc = c.newState().account(addr)
+	})
+	return acc
+}
+
+//SetValidator creates a bonded validator of operator, self delegating tokens
+func (c *Chain) SetValidator(operator sdk.ValAddress, consPubKey crypto.PubKey, tokens int64) {
+	c.app.Do(func() {
+		validator := staking.NewValidator(operator, consPubKey, staking.Description{Moniker: operator.String()})
+		validator.Status = sdk.Bonded
+		st := c.newState()
+		st.setValidator(validator)
+		st.delegate(sdk.AccAddress(operator), operator, sdk.NewInt(tokens))
+		st.write()
+	})
+	c.Commit()
+}
+
+//Validator returns the validator of operator, false if there is none
+func (c *Chain) Validator(operator sdk.ValAddress) (staking.Validator, bool) {
+	var (
+		validator staking.Validator
+		ok        bool
+	)
+	c.app.Do(func() {
+		validator, ok = c.newState().validator(operator)
+	})
+	return validator, ok
+}
+
+//SetRewards sets the outstanding rewards of delegator from validator
+func (c *Chain) SetRewards(delegator sdk.AccAddress, validator sdk.ValAddress, rewards sdk.DecCoins) {
+	c.app.Do(func() {
+		c.rewards[rewardKey(delegator, validator)] = rewards
+	})
+}
+
+func rewardKey(delegator sdk.AccAddress, validator sdk.ValAddress) string {
+	return delegator.String() + "/" + validator.String()
+}
+
+func (c *Chain) newAccount(addr sdk.AccAddress) auth.Account {
+	acc := auth.NewBaseAccountWithAddress(addr)
+	if err := acc.SetAccountNumber(c.accountNumber); err != nil {
+		panic(err)
+	}
+	c.accountNumber++
+	return &acc
+}
+
+//state buffers the writes of a tx, they reach the stores only when the whole tx succeeds
+type state struct {
+	c      *Chain
+	writes map[string]map[string][]byte
+}
+
+func (c *Chain) newState() *state {
+	return &state{c: c, writes: make(map[string]map[string][]byte)}
+}
+
+func (st *state) get(store string, key []byte) []byte {
+	if bz, ok := st.writes[store][string(key)]; ok {
+		return bz
+	}
+	return st.c.app.Store(store).Get(key)
+}
+
+func (st *state) set(store string, key, value []byte) {
+	if st.writes[store] == nil {
+		st.writes[store] = make(map[string][]byte)
+	}
+	st.writes[store][string(key)] = value
+}
+
+func (st *state) write() {
+	for store, kvs := range st.writes {
+		for k, v := range kvs {
+			if v == nil {
+				st.c.app.Store(store).Delete([]byte(k))
+			} else {
+				st.c.app.Store(store).Set([]byte(k), v)
+			}
+		}
+	}
+}
+
+func (st *state) account(addr sdk.AccAddress) auth.Account {
+	bz := st.get(auth.StoreKey, auth.AddressStoreKey(addr))
+	if bz == nil {
+		return nil
+	}
+	var acc auth.Account
+	cdc.MustUnmarshalBinaryBare(bz, &acc)
+	return acc
+}
+
+func (st *state) setAccount(acc auth.Account) {
+	st.set(auth.StoreKey, auth.AddressStoreKey(acc.GetAddress()), cdc.MustMarshalBinaryBare(acc))
+}
+
+func (st *state) validator(operator sdk.ValAddress) (staking.Validator, bool) {
+	bz := st.get(storeStake, staking.GetValidatorKey(operator))
+	if bz == nil {
+		return staking.Validator{}, false
+	}
+	return types.MustUnmarshalValidator(cdc, bz), true
+}
+
+func (st *state) setValidator(validator staking.Validator) {
+	st.set(storeStake, staking.GetValidatorKey(validator.OperatorAddress), types.MustMarshalValidator(cdc, validator))
+}
+
+func (st *state) delegation(delegator sdk.AccAddress, validator sdk.ValAddress) (staking.Delegation, bool) {
+	bz := st.get(storeStake, staking.GetDelegationKey(delegator, validator))
+	if bz == nil {
+		return staking.Delegation{}, false
+	}
+	return types.MustUnmarshalDelegation(cdc, bz), true
+}
+
+//delegate bonds amount to the validator, one share for one token
+func (st *state) delegate(delegator sdk.AccAddress, operator sdk.ValAddress, amount sdk.Int) error {
+	validator, ok := st.validator(operator)
+	if !ok {
+		return fmt.Errorf("validator %s does not exist", operator)
+	}
+	shares := sdk.NewDecFromInt(amount)
+	validator.Tokens = validator.Tokens.Add(amount)
+	validator.DelegatorShares = validator.DelegatorShares.Add(shares)
+	st.setValidator(validator)
+
+	delegation, ok := st.delegation(delegator, operator)
+	if !ok {
+		delegation = types.NewDelegation(delegator, operator, sdk.ZeroDec())
+	}
+	delegation.Shares = delegation.Shares.Add(shares)
+	st.set(storeStake, staking.GetDelegationKey(delegator, operator), types.MustMarshalDelegation(cdc, delegation))
+	return nil
+}
+
+func (st *state) undelegate(delegator sdk.AccAddress, operator sdk.ValAddress, amount sdk.Int, height int64) error {
+	delegation, ok := st.delegation(delegator, operator)
+	shares := sdk.NewDecFromInt(amount)
+	if !ok || delegation.Shares.LT(shares) {
+		return fmt.Errorf("delegation of %s to %s is less than %s", delegator, operator, amount)
+	}
+	validator, _ := st.validator(operator)
+	validator.Tokens = validator.Tokens.Sub(amount)
+	validator.DelegatorShares = validator.DelegatorShares.Sub(shares)
+	st.setValidator(validator)
+
+	delegation.Shares = delegation.Shares.Sub(shares)
+	key := staking.GetDelegationKey(delegator, operator)
+	if delegation.Shares.IsZero() {
+		st.set(storeStake, key, nil)
+	} else {
+		st.set(storeStake, key, types.MustMarshalDelegation(cdc, delegation))
+	}
+
+	completion := rpctest.BlockTime(height).Add(UnbondingTime)
+	ubdKey := staking.GetUBDKey(delegator, operator)
+	if bz := st.get(storeStake, ubdKey); bz != nil {
+		ubd := types.MustUnmarshalUBD(cdc, bz)
+		ubd.AddEntry(height, completion, amount)
+		st.set(storeStake, ubdKey, types.MustMarshalUBD(cdc, ubd))
+	} else {
+		st.set(storeStake, ubdKey, types.MustMarshalUBD(cdc, types.NewUnbondingDelegation(delegator, operator, height, completion, amount)))
+	}
+	return nil
+}
+
+//runTx checks the signatures, the account numbers and the sequences of the signers, takes the fee
+//and applies the msgs in order. The state is only changed when deliver is set.
+func (c *Chain) runTx(bz []byte, deliver bool) abci.ResponseDeliverTx {
+	var tx auth.StdTx
+	if err := cdc.UnmarshalBinaryLengthPrefixed(bz, &tx); err != nil {
+		return reject(sdk.ErrTxDecode(err.Error()))
+	}
+	if err := tx.ValidateBasic(); err != nil {
+		return reject(err)
+	}
+
+	st := c.newState()
+	signers := tx.GetSigners()
+	for i, sig := range tx.GetSignatures() {
+		acc := st.account(signers[i])
+		if acc == nil {
+			return reject(sdk.ErrUnknownAddress(fmt.Sprintf("account %s does not exist", signers[i])))
+		}
+		if !bytes.Equal(sig.PubKey.Address(), signers[i]) {
+			return reject(sdk.ErrInvalidPubKey(fmt.Sprintf("pubkey does not match the signer %s", signers[i])))
+		}
+		signBytes := auth.StdSignBytes(c.ChainID, acc.GetAccountNumber(), acc.GetSequence(), tx.Fee, tx.Msgs, tx.Memo)
+		if !sig.PubKey.VerifyBytes(signBytes, sig.Signature) {
+			return reject(sdk.ErrUnauthorized(fmt.Sprintf("signature verification failed, invalid chain-id, account number %d or sequence %d", acc.GetAccountNumber(), acc.GetSequence())))
+		}
+		if err := acc.SetSequence(acc.GetSequence() + 1); err != nil {
+			return reject(sdk.ErrInternal(err.Error()))
+		}
+		if err := acc.SetPubKey(sig.PubKey); err != nil {
+			return reject(sdk.ErrInternal(err.Error()))
+		}
+		if i == 0 {
+			if err := subCoins(acc, tx.Fee.Amount); err != nil {
+				return reject(err)
+			}
+		}
+		st.setAccount(acc)
+	}
+
+	height := c.app.LastHeight() + 1
+	var tags []cmn.KVPair
+	tag := func(key string, addr fmt.Stringer) {
+		tags = append(tags, cmn.KVPair{Key: []byte(key), Value: []byte(addr.String())})
+	}
+	for _, msg := range tx.Msgs {
+		switch msg := msg.(type) {
+		case bank.MsgSend:
+			if err := c.send(st, msg.FromAddress, msg.ToAddress, msg.Amount); err != nil {
+				return reject(err)
+			}
+			tag("sender", msg.FromAddress)
+			tag("recipient", msg.ToAddress)
+		case staking.MsgDelegate:
+			if err := subCoins(st.account(msg.DelegatorAddress), sdk.Coins{msg.Amount}); err != nil {
+				return reject(err)
+			}
+			if err := st.delegate(msg.DelegatorAddress, msg.ValidatorAddress, msg.Amount.Amount); err != nil {
+				return reject(staking.ErrNoValidatorFound(staking.DefaultCodespace))
+			}
+			tag("delegator", msg.DelegatorAddress)
+		case staking.MsgUndelegate:
+			if err := st.undelegate(msg.DelegatorAddress, msg.ValidatorAddress, msg.Amount.Amount, height); err != nil {
+				return reject(staking.ErrNotEnoughDelegationShares(staking.DefaultCodespace, msg.Amount.String()))
+			}
+			tag("delegator", msg.DelegatorAddress)
+		case distr.MsgWithdrawDelegatorReward:
+			key := rewardKey(msg.DelegatorAddress, msg.ValidatorAddress)
+			coins, _ := c.rewards[key].TruncateDecimal()
+			acc := st.account(msg.DelegatorAddress)
+			if err := acc.SetCoins(acc.GetCoins().Add(coins)); err != nil {
+				return reject(sdk.ErrInternal(err.Error()))
+			}
+			st.setAccount(acc)
+			if deliver {
+				delete(c.rewards, key)
+			}
+			tag("delegator", msg.DelegatorAddress)
+		default:
+			return reject(sdk.ErrUnknownRequest(fmt.Sprintf("unsupported msg %T", msg)))
+		}
+	}
+
+	if deliver {
+		st.write()
+	}
+	return abci.ResponseDeliverTx{Events: []abci.Event{{Type: "message", Attributes: tags}}}
+}
+
+func (c *Chain) send(st *state, from, to sdk.AccAddress, amount sdk.Coins) sdk.Error {
+	sender := st.account(from)
+	if err := subCoins(sender, amount); err != nil {
+		return err
+	}
+	st.setAccount(sender)
+
+	receiver := st.account(to)
+	if receiver == nil {
+		receiver = c.newAccount(to)
+	}
+	if err := receiver.SetCoins(receiver.GetCoins().Add(amount)); err != nil {
+		return sdk.ErrInternal(err.Error())
+	}
+	st.setAccount(receiver)
+	return nil
+}
+
+func subCoins(acc auth.Account, amount sdk.Coins) sdk.Error {
+	coins, negative := acc.GetCoins().SafeSub(amount)
+	if negative {
+		return sdk.ErrInsufficientCoins(fmt.Sprintf("insufficient account funds; %s < %s", acc.GetCoins(), amount))
+	}
+	if err := acc.SetCoins(coins); err != nil {
+		return sdk.ErrInternal(err.Error())
+	}
+	return nil
+}
+
+func (c *Chain) queryAccount(path string, data []byte) ([]byte, error) {
+	var params auth.QueryAccountParams
+	if err := cdc.UnmarshalJSON(data, &params); err != nil {
+		return nil, err
+	}
+	acc := c.newState().account(params.Address)
+	if acc == nil {
+		return nil, fmt.Errorf("account %s does not exist", params.Address)
+	}
+	return cdc.MarshalJSON(acc)
+}
+
+func (c *Chain) queryDelegatorValidators(path string, data []byte) ([]byte, error) {
+	var params staking.QueryDelegatorParams
+	if err := cdc.UnmarshalJSON(data, &params); err != nil {
+		return nil, err
+	}
+	validators := []staking.Validator{}
+	st := c.newState()
+	for _, kv := range c.app.Store(storeStake).Subspace(staking.GetDelegationsKey(params.DelegatorAddr)) {
+		delegation := types.MustUnmarshalDelegation(cdc, kv.Value)
+		validator, _ := st.validator(delegation.ValidatorAddress)
+		validators = append(validators, validator)
+	}
+	return cdc.MarshalJSON(validators)
+}
+
+func (c *Chain) queryDelegationRewards(path string, data []byte) ([]byte, error) {
+	var params distr.QueryDelegationRewardsParams
+	if err := cdc.UnmarshalJSON(data, &params); err != nil {
+		return nil, err
+	}
+	if _, ok := c.newState().delegation(params.DelegatorAddress, params.ValidatorAddress); !ok {
+		return nil, fmt.Errorf("delegation of %s to %s does not exist", params.DelegatorAddress, params.ValidatorAddress)
+	}
+	rewards := c.rewards[rewardKey(params.DelegatorAddress, params.ValidatorAddress)]
+	if rewards == nil {
+		rewards = sdk.DecCoins{}
+	}
+	return cdc.MarshalJSON(rewards)
+}
+
+func (c *Chain) queryRewardValidators(path string, data []byte) ([]byte, error) {
+	var params distrkeeper.QueryDelegatorParams
+	if err := cdc.UnmarshalJSON(data, &params); err != nil {
+		return nil, err
+	}
+	validators := []sdk.ValAddress{}
+	for _, kv := range c.app.Store(storeStake).Subspace(staking.GetDelegationsKey(params.DelegatorAddress)) {
+		validators = append(validators, types.MustUnmarshalDelegation(cdc, kv.Value).ValidatorAddress)
+	}
+	return cdc.MarshalJSON(validators)
+}
+
+func reject(err sdk.Error) abci.ResponseDeliverTx {
+	return abci.ResponseDeliverTx{
+		Code:      uint32(err.Code()),
+		Codespace: string(err.Codespace()),
+		Log:       err.ABCILog(),
+	}
+}
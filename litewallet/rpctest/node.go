@@ -0,0 +1,418 @@
+//Package rpctest is an in-process stand-in of a Tendermint node for the tests of the wallets.
+//It serves abci_query, broadcast_tx_sync/async/commit, tx, block, commit, validators, tx_search
+//and status over the Tendermint JSON-RPC, backed by an abci.Application holding the chain state
+//in memory, so the QOS and Cosmos clients run against it without any network. The blocks are
+//signed by the validators of the node, so the light clients verify them as on a real chain.
+package rpctest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	amino "github.com/tendermint/go-amino"
+	abci "github.com/tendermint/tendermint/abci/types"
+	cmn "github.com/tendermint/tendermint/libs/common"
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/p2p"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	rpcserver "github.com/tendermint/tendermint/rpc/lib/server"
+	rpctypes "github.com/tendermint/tendermint/rpc/lib/types"
+	"github.com/tendermint/tendermint/types"
+)
+
+//GenesisTime is the time of the first block, every new block is one second later
+var GenesisTime = time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)
+
+//BlockTime is the time of the block at height
+func BlockTime(height int64) time.Time {
+	return GenesisTime.Add(time.Duration(height-1) * time.Second)
+}
+
+//Node is a chain which commits every broadcast tx in a block of its own. By default it starts
+//with one validator of its own, so two nodes of the same chain id are forks of each other.
+type Node struct {
+	ChainID string
+
+	mtx        sync.Mutex
+	app        abci.Application
+	blocks     []*types.Block
+	commits    []*types.Commit
+	valSets    []*types.ValidatorSet
+	validators []types.PrivValidator
+	next       []types.PrivValidator
+	appHash    []byte
+	forge      func(*abci.ResponseQuery)
+	unproved   bool
+	lagging    bool
+	txs        []*ctypes.ResultTx
+	server     *httptest.Server
+}
+
+//NewNode starts a node of chainID in front of app, Close stops it. The blocks are signed by
+//validators, or by a new validator of the node when there is none.
+func NewNode(chainID string, app abci.Application, validators ...types.PrivValidator) *Node {
+	if len(validators) == 0 {
+		validators = []types.PrivValidator{types.NewMockPV()}
+	}
+	n := &Node{ChainID: chainID, app: app, validators: validators, next: validators}
+	n.commitBlock(nil)
+
+	cdc := amino.NewCodec()
+	ctypes.RegisterAmino(cdc)
+	mux := http.NewServeMux()
+	rpcserver.RegisterRPCFuncs(mux, n.routes(), cdc, log.NewNopLogger())
+	n.server = httptest.NewServer(mux)
+	return n
+}
+
+//Remote is the host:port of the node, as taken by the QOS functions
+func (n *Node) Remote() string {
+	return strings.TrimPrefix(n.server.URL, "http://")
+}
+
+//URI is the tcp://host:port of the node, as taken by the Cosmos functions
+func (n *Node) URI() string {
+	return "tcp://" + n.Remote()
+}
+
+//Close stops the server of the node
+func (n *Node) Close() {
+	n.server.Close()
+}
+
+//Height is the height of the latest block
+func (n *Node) Height() int64 {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	return int64(len(n.blocks))
+}
+
+//SetValidators replaces the validators, each with the voting power 10. The next block announces
+//the new set in its header and the blocks after it are signed by the new validators.
+func (n *Node) SetValidators(validators ...types.PrivValidator) {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	n.next = validators
+}
+
+//Forge makes the node tamper with its answers to the queries by fn, as a dishonest node would
+func (n *Node) Forge(fn func(res *abci.ResponseQuery)) {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	n.forge = fn
+}
+
+//Commit commits an empty block, taking in the state set up with App.Do. The state is proved
+//once the node has made the next block, which it does on the next status or query.
+func (n *Node) Commit() int64 {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	n.unproved = true
+	return n.commitBlock(nil)
+}
+
+//Lag makes the node make the block proving its latest state only on a query of that state, not on
+//the next status, as a live chain between two blocks. The state at the height before the latest is
+//then the one before the last tx.
+func (n *Node) Lag() {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	n.lagging = true
+}
+
+func (n *Node) routes() map[string]*rpcserver.RPCFunc {
+	return map[string]*rpcserver.RPCFunc{
+		"status":              rpcserver.NewRPCFunc(n.status, ""),
+		"abci_query":          rpcserver.NewRPCFunc(n.abciQuery, "path,data,height,prove"),
+		"broadcast_tx_async":  rpcserver.NewRPCFunc(n.broadcastTxAsync, "tx"),
+		"broadcast_tx_sync":   rpcserver.NewRPCFunc(n.broadcastTxSync, "tx"),
+		"broadcast_tx_commit": rpcserver.NewRPCFunc(n.broadcastTxCommit, "tx"),
+		"tx":                  rpcserver.NewRPCFunc(n.tx, "hash,prove"),
+		"block":               rpcserver.NewRPCFunc(n.block, "height"),
+		"commit":              rpcserver.NewRPCFunc(n.commit, "height"),
+		"validators":          rpcserver.NewRPCFunc(n.validatorSet, "height"),
+		"tx_search":           rpcserver.NewRPCFunc(n.txSearch, "query,prove,page,per_page"),
+	}
+}
+
+func (n *Node) status(ctx *rpctypes.Context) (*ctypes.ResultStatus, error) {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	//the state of the latest block is proved by the app hash in the next header, which a live chain
+	//makes within seconds, the node makes it at once so the state is proved at the height before the latest
+	if n.unproved && !n.lagging {
+		n.commitBlock(nil)
+		n.unproved = false
+	}
+	latest := n.blocks[len(n.blocks)-1]
+	return &ctypes.ResultStatus{
+		NodeInfo: p2p.DefaultNodeInfo{Network: n.ChainID, Other: p2p.DefaultNodeInfoOther{TxIndex: "on"}},
+		SyncInfo: ctypes.SyncInfo{
+			LatestBlockHash:   latest.Hash(),
+			LatestAppHash:     latest.AppHash,
+			LatestBlockHeight: latest.Height,
+			LatestBlockTime:   latest.Time,
+		},
+	}, nil
+}
+
+func (n *Node) abciQuery(ctx *rpctypes.Context, path string, data cmn.HexBytes, height int64, prove bool) (*ctypes.ResultABCIQuery, error) {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	res := n.app.Query(abci.RequestQuery{Path: path, Data: data, Height: height, Prove: prove})
+	if res.Height == 0 {
+		res.Height = int64(len(n.blocks))
+	}
+	//the state of the latest block is proved by the app hash in the next header, which a live
+	//chain makes within seconds, the node makes it at once so the light clients need not wait
+	if res.Proof != nil && res.Height == int64(len(n.blocks)) {
+		n.commitBlock(nil)
+		n.unproved = false
+	}
+	if n.forge != nil {
+		n.forge(&res)
+	}
+	return &ctypes.ResultABCIQuery{Response: res}, nil
+}
+
+func (n *Node) broadcastTxAsync(ctx *rpctypes.Context, tx types.Tx) (*ctypes.ResultBroadcastTx, error) {
+	res := n.broadcast(tx)
+	return &ctypes.ResultBroadcastTx{Hash: tx.Hash(), Code: res.CheckTx.Code, Log: res.CheckTx.Log}, nil
+}
+
+func (n *Node) broadcastTxSync(ctx *rpctypes.Context, tx types.Tx) (*ctypes.ResultBroadcastTx, error) {
+	res := n.broadcast(tx)
+	return &ctypes.ResultBroadcastTx{Hash: tx.Hash(), Code: res.CheckTx.Code, Data: res.CheckTx.Data, Log: res.CheckTx.Log}, nil
+}
+
+func (n *Node) broadcastTxCommit(ctx *rpctypes.Context, tx types.Tx) (*ctypes.ResultBroadcastTxCommit, error) {
+	return n.broadcast(tx), nil
+}
+
+//broadcast runs CheckTx and, if it passes, commits tx in a new block
+func (n *Node) broadcast(tx types.Tx) *ctypes.ResultBroadcastTxCommit {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	res := &ctypes.ResultBroadcastTxCommit{Hash: tx.Hash()}
+	res.CheckTx = n.app.CheckTx(abci.RequestCheckTx{Tx: tx})
+	if res.CheckTx.IsErr() {
+		return res
+	}
+	res.DeliverTx = n.app.DeliverTx(abci.RequestDeliverTx{Tx: tx})
+	res.Height = n.commitBlock(types.Txs{tx})
+	n.unproved = true
+	n.txs = append(n.txs, &ctypes.ResultTx{
+		Hash:     tx.Hash(),
+		Height:   res.Height,
+		TxResult: res.DeliverTx,
+		Tx:       tx,
+	})
+	return res
+}
+
+//commitBlock makes the next block of txs and signs it by the validators. As on a real chain,
+//the app hash of a block is the one of the state after the block before.
+func (n *Node) commitBlock(txs types.Txs) int64 {
+	height := int64(len(n.blocks) + 1)
+	lastCommit := &types.Commit{}
+	if height > 1 {
+		lastCommit = n.commits[height-2]
+	}
+	valSet, nextValSet := validatorSet(n.validators), validatorSet(n.next)
+	block := types.MakeBlock(height, txs, lastCommit, nil)
+	block.ChainID = n.ChainID
+	block.Time = BlockTime(height)
+	block.AppHash = n.appHash
+	block.ValidatorsHash = valSet.Hash()
+	block.NextValidatorsHash = nextValSet.Hash()
+	if height > 1 {
+		block.LastBlockID = n.commits[height-2].BlockID
+	}
+	blockID := types.BlockID{Hash: block.Hash(), PartsHeader: block.MakePartSet(types.BlockPartSizeBytes).Header()}
+
+	precommits := make([]*types.CommitSig, valSet.Size())
+	for _, pv := range n.validators {
+		idx, _ := valSet.GetByAddress(pv.GetPubKey().Address())
+		vote := &types.Vote{
+			ValidatorAddress: pv.GetPubKey().Address(),
+			ValidatorIndex:   idx,
+			Height:           height,
+			Timestamp:        block.Time,
+			Type:             types.PrecommitType,
+			BlockID:          blockID,
+		}
+		if err := pv.SignVote(n.ChainID, vote); err != nil {
+			panic(err)
+		}
+		precommits[idx] = vote.CommitSig()
+	}
+
+	n.blocks = append(n.blocks, block)
+	n.commits = append(n.commits, types.NewCommit(blockID, precommits))
+	n.valSets = append(n.valSets, valSet)
+	n.validators = n.next
+	n.appHash = n.app.Commit().Data
+	return height
+}
+
+func validatorSet(validators []types.PrivValidator) *types.ValidatorSet {
+	vals := make([]*types.Validator, len(validators))
+	for i, pv := range validators {
+		vals[i] = types.NewValidator(pv.GetPubKey(), 10)
+	}
+	return types.NewValidatorSet(vals)
+}
+
+func (n *Node) tx(ctx *rpctypes.Context, hash []byte, prove bool) (*ctypes.ResultTx, error) {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	for _, tx := range n.txs {
+		if tx.Hash.String() == cmn.HexBytes(hash).String() {
+			return n.proveTx(tx, prove), nil
+		}
+	}
+	return nil, cmn.NewError("Tx (%X) not found", hash)
+}
+
+//proveTx returns tx with the proof of its inclusion in its block when prove is set
+func (n *Node) proveTx(tx *ctypes.ResultTx, prove bool) *ctypes.ResultTx {
+	if !prove {
+		return tx
+	}
+	proved := *tx
+	proved.Proof = n.blocks[tx.Height-1].Data.Txs.Proof(int(tx.Index))
+	return &proved
+}
+
+func (n *Node) block(ctx *rpctypes.Context, heightPtr *int64) (*ctypes.ResultBlock, error) {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	height, err := n.height(heightPtr, 0)
+	if err != nil {
+		return nil, err
+	}
+	block := n.blocks[height-1]
+	meta := types.NewBlockMeta(block, block.MakePartSet(types.BlockPartSizeBytes))
+	return &ctypes.ResultBlock{BlockMeta: meta, Block: block}, nil
+}
+
+func (n *Node) commit(ctx *rpctypes.Context, heightPtr *int64) (*ctypes.ResultCommit, error) {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	height, err := n.height(heightPtr, 0)
+	if err != nil {
+		return nil, err
+	}
+	return ctypes.NewResultCommit(&n.blocks[height-1].Header, n.commits[height-1], true), nil
+}
+
+//validatorSet returns the validators of the block at height, up to the block after the latest one
+func (n *Node) validatorSet(ctx *rpctypes.Context, heightPtr *int64) (*ctypes.ResultValidators, error) {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	height, err := n.height(heightPtr, 1)
+	if err != nil {
+		return nil, err
+	}
+	valSet := validatorSet(n.validators)
+	if height <= int64(len(n.valSets)) {
+		valSet = n.valSets[height-1]
+	}
+	return &ctypes.ResultValidators{BlockHeight: height, Validators: valSet.Validators}, nil
+}
+
+//height returns the height asked for, the latest one for nil, which may be ahead of the latest block
+func (n *Node) height(heightPtr *int64, ahead int64) (int64, error) {
+	height := int64(len(n.blocks))
+	if heightPtr != nil {
+		height = *heightPtr
+	}
+	if height <= 0 || height > int64(len(n.blocks))+ahead {
+		return 0, cmn.NewError("Height must be less than or equal to the current blockchain height")
+	}
+	return height, nil
+}
+
+//txSearch supports the conditions key=value joined with AND, where the key is tx.hash, tx.height
+//or the type.key of an event attribute of the DeliverTx, e.g. message.sender='cosmos1...'.
+//A key without the type matches the attribute in any event, as the tags of the Cosmos txs.
+func (n *Node) txSearch(ctx *rpctypes.Context, query string, prove bool, page, perPage int) (*ctypes.ResultTxSearch, error) {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	conds, err := parseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	var found []*ctypes.ResultTx
+	for _, tx := range n.txs {
+		if matchTx(tx, conds) {
+			found = append(found, n.proveTx(tx, prove))
+		}
+	}
+
+	if perPage <= 0 {
+		perPage = 30
+	}
+	if page <= 0 {
+		page = 1
+	}
+	total := len(found)
+	start := (page - 1) * perPage
+	if start > total {
+		start = total
+	}
+	end := start + perPage
+	if end > total {
+		end = total
+	}
+	return &ctypes.ResultTxSearch{Txs: found[start:end], TotalCount: total}, nil
+}
+
+func parseQuery(query string) (map[string]string, error) {
+	conds := make(map[string]string)
+	for _, cond := range strings.Split(query, " AND ") {
+		kv := strings.SplitN(cond, "=", 2)
+		if len(kv) != 2 {
+			return nil, cmn.NewError("unsupported query condition %q", cond)
+		}
+		conds[strings.TrimSpace(kv[0])] = strings.Trim(strings.TrimSpace(kv[1]), "'")
+	}
+	return conds, nil
+}
+
+func matchTx(tx *ctypes.ResultTx, conds map[string]string) bool {
+	for key, value := range conds {
+		switch key {
+		case "tx.hash":
+			if !strings.EqualFold(tx.Hash.String(), value) {
+				return false
+			}
+		case "tx.height":
+			if strconv.FormatInt(tx.Height, 10) != value {
+				return false
+			}
+		default:
+			if !hasAttribute(tx.TxResult.Events, key, value) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func hasAttribute(events []abci.Event, key, value string) bool {
+	for _, event := range events {
+		for _, attr := range event.Attributes {
+			k := string(attr.Key)
+			if (key == k || key == event.Type+"."+k) && string(attr.Value) == value {
+				return true
+			}
+		}
+	}
+	return false
+}
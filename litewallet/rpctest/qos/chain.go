@@ -0,0 +1,357 @@
+//Package qos runs a QOS chain on an rpctest.Node. It keeps the accounts, the validators, the
+//delegations and the approves in memory and applies the transfer and stake txs signed by the slim package.
+//The setters of the state commit a block of their own, as the txs do.
+package qos
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/QOSGroup/litewallet/litewallet/rpctest"
+	"github.com/QOSGroup/litewallet/litewallet/slim/app"
+	"github.com/QOSGroup/litewallet/litewallet/slim/base/account"
+	btxs "github.com/QOSGroup/litewallet/litewallet/slim/base/txs"
+	btypes "github.com/QOSGroup/litewallet/litewallet/slim/base/types"
+	approvetypes "github.com/QOSGroup/litewallet/litewallet/slim/module/approve/types"
+	bank_txs "github.com/QOSGroup/litewallet/litewallet/slim/module/bank/txs"
+	"github.com/QOSGroup/litewallet/litewallet/slim/module/stake/mapper"
+	stake_txs "github.com/QOSGroup/litewallet/litewallet/slim/module/stake/txs"
+	staketypes "github.com/QOSGroup/litewallet/litewallet/slim/module/stake/types"
+	"github.com/QOSGroup/litewallet/litewallet/slim/types"
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	cmn "github.com/tendermint/tendermint/libs/common"
+	tmtypes "github.com/tendermint/tendermint/types"
+)
+
+//UnbondPeriod is the number of blocks an unbonding or a redelegation takes to complete
+const UnbondPeriod = 10
+
+//Chain is a QOS chain with a single node
+type Chain struct {
+	*rpctest.Node
+	app *rpctest.App
+
+	delegations   []mapper.DelegationQueryResult
+	unbondings    []staketypes.UnbondingDelegationInfo
+	redelegations []staketypes.RedelegationInfo
+}
+
+//NewChain starts a chain of chainID with no account, Close stops it
+func NewChain(chainID string) *Chain {
+	c := &Chain{app: rpctest.NewApp()}
+	c.app.HandleTx(c.runTx)
+	c.app.HandleQuery(fmt.Sprintf("custom/%s/%s/", staketypes.Stake, staketypes.Delegation), c.queryDelegation)
+	c.app.HandleQuery(fmt.Sprintf("custom/%s/%s/%s/", staketypes.Stake, staketypes.Delegations, staketypes.Delegator), c.queryDelegations)
+	c.app.HandleQuery(fmt.Sprintf("custom/%s/%s/", staketypes.Stake, staketypes.Unbondings), c.queryUnbondings)
+	c.app.HandleQuery(fmt.Sprintf("custom/%s/%s/", staketypes.Stake, staketypes.Redelegations), c.queryRedelegations)
+	//the chains of a chain id share their validator, the light client takes them for the same chain
+	validator := tmtypes.NewMockPVWithParams(ed25519.GenPrivKeyFromSecret([]byte(chainID)), false, false)
+	c.Node = rpctest.NewNode(chainID, c.app, validator)
+	return c
+}
+
+//SetAccount creates or replaces the account addr, holding qos
+func (c *Chain) SetAccount(addr btypes.AccAddress, qos int64) {
+	c.app.Do(func() {
+		c.setAccount(types.NewQOSAccount(addr, btypes.NewInt(qos), nil))
+	})
+	c.Commit()
+}
+
+//Account returns the account addr, nil if there is none
+func (c *Chain) Account(addr btypes.AccAddress) *types.QOSAccount {
+	var acc *types.QOSAccount
+	c.app.Do(func() {
+		acc = c.account(addr)
+	})
+	return acc
+}
+
+//SetValidator creates or replaces the validator
+func (c *Chain) SetValidator(validator staketypes.Validator) {
+	c.app.Do(func() {
+		key := staketypes.BuildValidatorKey(validator.OperatorAddress)
+		c.app.Store(staketypes.MapperName).Set(key, app.Cdc.MustMarshalBinaryBare(validator))
+	})
+	c.Commit()
+}
+
+//SetApprove creates or replaces the approve of approve.From to approve.To
+func (c *Chain) SetApprove(approve approvetypes.Approve) {
+	c.app.Do(func() {
+		key := approvetypes.BuildApproveKey(approve.From, approve.To)
+		c.app.Store(approvetypes.MapperName).Set(key, app.Cdc.MustMarshalBinaryBare(approve))
+	})
+	c.Commit()
+}
+
+//Delegation returns the amount delegated by delegator to validator, 0 if there is none
+func (c *Chain) Delegation(delegator btypes.AccAddress, validator btypes.ValAddress) int64 {
+	var amount int64
+	c.app.Do(func() {
+		if d := c.delegation(delegator, validator); d != nil {
+			amount = d.Amount.Int64()
+		}
+	})
+	return amount
+}
+
+func (c *Chain) account(addr btypes.AccAddress) *types.QOSAccount {
+	bz := c.app.Store(account.AccountMapperName).Get(account.AddressStoreKey(addr))
+	if bz == nil {
+		return nil
+	}
+	var acc account.Account
+	app.Cdc.MustUnmarshalBinaryBare(bz, &acc)
+	return acc.(*types.QOSAccount)
+}
+
+func (c *Chain) setAccount(acc *types.QOSAccount) {
+	var iacc account.Account = acc
+	c.app.Store(account.AccountMapperName).Set(account.AddressStoreKey(acc.AccountAddress), app.Cdc.MustMarshalBinaryBare(iacc))
+}
+
+func (c *Chain) validator(addr btypes.ValAddress) *staketypes.Validator {
+	bz := c.app.Store(staketypes.MapperName).Get(staketypes.BuildValidatorKey(addr))
+	if bz == nil {
+		return nil
+	}
+	var validator staketypes.Validator
+	app.Cdc.MustUnmarshalBinaryBare(bz, &validator)
+	return &validator
+}
+
+func (c *Chain) delegation(delegator btypes.AccAddress, validator btypes.ValAddress) *mapper.DelegationQueryResult {
+	for i, d := range c.delegations {
+		if d.DelegatorAddr.Equals(delegator) && d.ValidatorAddr.Equals(validator) {
+			return &c.delegations[i]
+		}
+	}
+	return nil
+}
+
+//runTx checks the signatures and the nonces of the signers, then applies the itxs in order.
+//The state is only changed when deliver is set.
+func (c *Chain) runTx(bz []byte, deliver bool) abci.ResponseDeliverTx {
+	var tx btxs.TxStd
+	if err := app.Cdc.UnmarshalBinaryBare(bz, &tx); err != nil {
+		return reject("decode tx: %v", err)
+	}
+	if tx.ChainID != c.ChainID {
+		return reject("wrong chain id %s, the chain is %s", tx.ChainID, c.ChainID)
+	}
+	if len(tx.ITxs) == 0 || len(tx.Signature) == 0 {
+		return reject("tx without itx or signature")
+	}
+
+	//work on copies, so a failing itx leaves nothing behind
+	accounts := make(map[string]*types.QOSAccount)
+	get := func(addr btypes.AccAddress) *types.QOSAccount {
+		if acc, ok := accounts[addr.String()]; ok {
+			return acc
+		}
+		acc := c.account(addr)
+		if acc == nil {
+			acc = types.NewQOSAccount(addr, btypes.ZeroInt(), nil)
+		}
+		accounts[addr.String()] = acc
+		return acc
+	}
+
+	for _, sig := range tx.Signature {
+		signer := get(btypes.AccAddress(sig.Pubkey.Address()))
+		if sig.Nonce != signer.Nonce+1 {
+			return reject("invalid nonce %d of %s, expected %d", sig.Nonce, signer.AccountAddress, signer.Nonce+1)
+		}
+		if !sig.Pubkey.VerifyBytes(tx.BuildSignatureBytes(sig.Nonce, ""), sig.Signature) {
+			return reject("invalid signature of %s", signer.AccountAddress)
+		}
+		signer.Nonce = sig.Nonce
+		signer.Publickey = sig.Pubkey
+	}
+
+	delegations := append([]mapper.DelegationQueryResult(nil), c.delegations...)
+	unbondings := append([]staketypes.UnbondingDelegationInfo(nil), c.unbondings...)
+	redelegations := append([]staketypes.RedelegationInfo(nil), c.redelegations...)
+	height := c.app.LastHeight() + 1
+	findDelegation := func(delegator btypes.AccAddress, validator btypes.ValAddress) *mapper.DelegationQueryResult {
+		for i, d := range delegations {
+			if d.DelegatorAddr.Equals(delegator) && d.ValidatorAddr.Equals(validator) {
+				return &delegations[i]
+			}
+		}
+		return nil
+	}
+
+	for _, itx := range tx.ITxs {
+		switch itx := itx.(type) {
+		case bank_txs.TxTransfer:
+			if err := c.transfer(itx, get); err != nil {
+				return reject("%v", err)
+			}
+		case *bank_txs.TxTransfer:
+			if err := c.transfer(*itx, get); err != nil {
+				return reject("%v", err)
+			}
+		case *stake_txs.TxCreateDelegation:
+			if c.validator(itx.ValidatorAddr) == nil {
+				return reject("validator %s not exists", itx.ValidatorAddr)
+			}
+			if err := get(itx.Delegator).MinusQOS(itx.Amount); err != nil {
+				return reject("delegator %s does not have enough qos", itx.Delegator)
+			}
+			if d := findDelegation(itx.Delegator, itx.ValidatorAddr); d != nil {
+				d.Amount = d.Amount.Add(itx.Amount)
+				d.IsCompound = itx.IsCompound
+			} else {
+				delegations = append(delegations, mapper.DelegationQueryResult{
+					DelegatorAddr: itx.Delegator,
+					ValidatorAddr: itx.ValidatorAddr,
+					Amount:        itx.Amount,
+					IsCompound:    itx.IsCompound,
+				})
+			}
+		case *stake_txs.TxUnbondDelegation:
+			d := findDelegation(itx.Delegator, itx.ValidatorAddr)
+			if d == nil {
+				return reject("delegation of %s to %s not exists", itx.Delegator, itx.ValidatorAddr)
+			}
+			amount := itx.UnbondAmount
+			if itx.IsUnbondAll {
+				amount = d.Amount
+			}
+			if d.Amount.LT(amount) {
+				return reject("unbond amount %s is more than the delegation %s", amount, d.Amount)
+			}
+			d.Amount = d.Amount.Sub(amount)
+			unbondings = append(unbondings, staketypes.NewUnbondingDelegationInfo(itx.Delegator, itx.ValidatorAddr, height, height+UnbondPeriod, amount))
+		case *stake_txs.TxCreateReDelegation:
+			if c.validator(itx.ToValidatorAddr) == nil {
+				return reject("validator %s not exists", itx.ToValidatorAddr)
+			}
+			from := findDelegation(itx.Delegator, itx.FromValidatorAddr)
+			if from == nil {
+				return reject("delegation of %s to %s not exists", itx.Delegator, itx.FromValidatorAddr)
+			}
+			amount := itx.Amount
+			if itx.IsRedelegateAll {
+				amount = from.Amount
+			}
+			if from.Amount.LT(amount) {
+				return reject("redelegate amount %s is more than the delegation %s", amount, from.Amount)
+			}
+			from.Amount = from.Amount.Sub(amount)
+			redelegations = append(redelegations, staketypes.NewRedelegateInfo(itx.Delegator, itx.FromValidatorAddr, itx.ToValidatorAddr, amount, height, height+UnbondPeriod, itx.IsCompound))
+		default:
+			return reject("unsupported itx %T", itx)
+		}
+	}
+
+	if deliver {
+		for _, acc := range accounts {
+			c.setAccount(acc)
+		}
+		c.delegations = delegations
+		c.unbondings = unbondings
+		c.redelegations = redelegations
+	}
+	return abci.ResponseDeliverTx{Events: []abci.Event{{
+		Type: "message",
+		Attributes: []cmn.KVPair{
+			{Key: []byte("sender"), Value: []byte(btypes.AccAddress(tx.Signature[0].Pubkey.Address()).String())},
+		},
+	}}}
+}
+
+func (c *Chain) transfer(tx bank_txs.TxTransfer, get func(btypes.AccAddress) *types.QOSAccount) error {
+	for _, sender := range tx.Senders {
+		if err := get(sender.Address).Minus(sender.QOS, sender.QSCs); err != nil {
+			return fmt.Errorf("sender %s does not have enough coins", sender.Address)
+		}
+	}
+	for _, receiver := range tx.Receivers {
+		if err := get(receiver.Address).Plus(receiver.QOS, receiver.QSCs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Chain) queryDelegation(path string, data []byte) ([]byte, error) {
+	parts := strings.Split(path, "/")
+	delegator, validator, err := delegatorValidator(parts[len(parts)-2], parts[len(parts)-1])
+	if err != nil {
+		return nil, err
+	}
+	d := c.delegation(delegator, validator)
+	if d == nil {
+		return nil, fmt.Errorf("delegation of %s to %s not exists", delegator, validator)
+	}
+	return app.Cdc.MarshalJSON(c.withConsPubKey(*d))
+}
+
+func (c *Chain) queryDelegations(path string, data []byte) ([]byte, error) {
+	delegator, err := btypes.AccAddressFromBech32(path[strings.LastIndex(path, "/")+1:])
+	if err != nil {
+		return nil, err
+	}
+	result := []mapper.DelegationQueryResult{}
+	for _, d := range c.delegations {
+		if d.DelegatorAddr.Equals(delegator) {
+			result = append(result, c.withConsPubKey(d))
+		}
+	}
+	return app.Cdc.MarshalJSON(result)
+}
+
+func (c *Chain) queryUnbondings(path string, data []byte) ([]byte, error) {
+	delegator, err := btypes.AccAddressFromBech32(path[strings.LastIndex(path, "/")+1:])
+	if err != nil {
+		return nil, err
+	}
+	result := []staketypes.UnbondingDelegationInfo{}
+	for _, u := range c.unbondings {
+		if u.DelegatorAddr.Equals(delegator) {
+			result = append(result, u)
+		}
+	}
+	return app.Cdc.MarshalJSON(result)
+}
+
+func (c *Chain) queryRedelegations(path string, data []byte) ([]byte, error) {
+	delegator, err := btypes.AccAddressFromBech32(path[strings.LastIndex(path, "/")+1:])
+	if err != nil {
+		return nil, err
+	}
+	result := []staketypes.RedelegationInfo{}
+	for _, r := range c.redelegations {
+		if r.DelegatorAddr.Equals(delegator) {
+			result = append(result, r)
+		}
+	}
+	return app.Cdc.MarshalJSON(result)
+}
+
+func (c *Chain) withConsPubKey(d mapper.DelegationQueryResult) mapper.DelegationQueryResult {
+	if v := c.validator(d.ValidatorAddr); v != nil {
+		d.ValidatorConsensusPubKey, _ = btypes.ConsensusPubKeyString(v.ConsPubKey)
+	}
+	return d
+}
+
+func delegatorValidator(delegatorStr, validatorStr string) (btypes.AccAddress, btypes.ValAddress, error) {
+	delegator, err := btypes.AccAddressFromBech32(delegatorStr)
+	if err != nil {
+		return nil, nil, err
+	}
+	validator, err := btypes.ValAddressFromBech32(validatorStr)
+	if err != nil {
+		return nil, nil, err
+	}
+	return delegator, validator, nil
+}
+
+func reject(format string, args ...interface{}) abci.ResponseDeliverTx {
+	return abci.ResponseDeliverTx{Code: 1, Log: fmt.Sprintf(format, args...)}
+}
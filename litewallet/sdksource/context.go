@@ -24,33 +24,12 @@ func newCLIContext(rootDir, node, chainID string) context.CLIContext {
 	}
 	rpc = rpcclient.NewHTTP(nodeURI, "/websocket")
 
-	//create the verifier for the LCD verification
-	//var trustNode bool
-	//trustNode = false
-	//if trustNode {
-	//	fmt.Printf("The default value for the trustNode is false!")
-	//}
-	//chainID := ChainID
-	//home := rootDir
-
-	//cacheSize := 10 // TODO: determine appropriate cache size
-	//verifier, err := tmliteProxy.NewVerifier(
-	//	chainID, filepath.Join(rootDir, ".gaiacli", ".gaialite"),
-	//	rpc, log.NewNopLogger(), cacheSize,
-	//)
-
-	//if err != nil {
-	//	fmt.Printf("Create verifier failed: %s\n", err.Error())
-	//	fmt.Printf("Please check network connection and verify the address of the node to connect to\n")
-	//	os.Exit(1)
-	//}
-
 	CliContext := context.CLIContext{
 		Client:       rpc,
 		Output:       os.Stdout,
 		NodeURI:      nodeURI,
 		AccountStore: auth.StoreKey,
-		//Verifier:    verifier,
+		//the verifier is set by queryContext in the verified mode
 		//BroadcastMode: broadcastMode,
 	}
 	return CliContext
@@ -80,29 +59,3 @@ func newCLIContext(rootDir, node, chainID string) context.CLIContext {
 //	var txBldr authtxb.TxBuilder
 //	return txBldr
 //}
-
-//node discovery with round-robin algorithm
-//var i = 0
-//var servers = []string{"127.0.0.1:8000", "127.0.0.1:8001", "127.0.0.1:8003"}
-//
-//// Balance returns one of the servers based using round-robin algorithm
-//func Balance() string {
-//	server := servers[i]
-//	i++
-//
-//	// it means that we reached the end of servers
-//	// and we need to reset the counter and start
-//	// from the beginning
-//	if i >= len(servers) {
-//		i = 0
-//	}
-//	return server
-//}
-//
-//
-//func main() {
-//	// requests loop
-//	for i := 0; i < 20; i++ {
-//		fmt.Println(Balance())
-//	}
-//}
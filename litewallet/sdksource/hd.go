@@ -0,0 +1,44 @@
+package sdksource
+
+import (
+	"github.com/QOSGroup/litewallet/litewallet/errcode"
+	"github.com/QOSGroup/litewallet/litewallet/slim/tendermint/crypto/funcInlocal/bip39local"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/hd"
+	amino "github.com/tendermint/go-amino"
+	"github.com/tendermint/tendermint/crypto"
+	cryptoAmino "github.com/tendermint/tendermint/crypto/encoding/amino"
+	"github.com/tendermint/tendermint/crypto/secp256k1"
+)
+
+//The keybase only derives the keys of English mnemonics. The keys are derived here for every bip39 language
+//at the paths of the keybase, from the NFKD seed of the specification, which is the seed of the keybase for
+//the English mnemonics.
+
+//localInfo is the info the keybase stores for a local key, registered under the same name for the keybase
+//to read it back
+type localInfo struct {
+	Name         string        `json:"name"`
+	PubKey       crypto.PubKey `json:"pubkey"`
+	PrivKeyArmor string        `json:"privkey.armor"`
+}
+
+var infoCdc = amino.NewCodec()
+
+func init() {
+	cryptoAmino.RegisterAmino(infoCdc)
+	infoCdc.RegisterConcrete(localInfo{}, "crypto/keys/localInfo", nil)
+}
+
+//derivePrivKey returns the key at params of mnemonic
+func derivePrivKey(mnemonic, bip39Passphrase string, params hd.BIP44Params) (crypto.PrivKey, error) {
+	bip39Seed, err := bip39local.MnemonicToSeed(mnemonic, bip39Passphrase)
+	if err != nil {
+		return nil, errInvalidSeed()
+	}
+	masterPriv, ch := hd.ComputeMastersFromSeed(bip39Seed)
+	derivedPriv, err := hd.DerivePrivateKeyForPath(masterPriv, ch, params.String())
+	if err != nil {
+		return nil, errcode.Internal(err)
+	}
+	return secp256k1.PrivKeySecp256k1(derivedPriv), nil
+}
@@ -0,0 +1,266 @@
+package sdksource
+
+import (
+	"errors"
+	"path/filepath"
+
+	"github.com/QOSGroup/litewallet/litewallet/errcode"
+	"github.com/QOSGroup/litewallet/litewallet/keycrypt"
+	"github.com/cosmos/cosmos-sdk/client/keys"
+	crkeys "github.com/cosmos/cosmos-sdk/crypto/keys"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/hd"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/keyerror"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/spf13/viper"
+	"github.com/tendermint/tendermint/crypto"
+	cryptoAmino "github.com/tendermint/tendermint/crypto/encoding/amino"
+	"github.com/tendermint/tendermint/libs/cli"
+	dbm "github.com/tendermint/tendermint/libs/db"
+)
+
+//The keybase encrypts the local keys with the bcrypt of mintkey. keybase stores them in the envelope of
+//keycrypt instead, as the infos the keybase writes itself with another armor of the private key, and
+//decrypts them to sign, export, update and delete. The keys the keybase encrypted are read as well and
+//encrypted again in the current envelope on their next unlock. The ledger and offline keys and the other
+//operations are left to the keybase. keybase opens the db for each operation as the lazy keybase does, so
+//the two never hold it at once.
+
+const (
+	blockTypePrivKey = "TENDERMINT PRIVATE KEY"
+	infoSuffix       = "info"
+	addressSuffix    = "address"
+)
+
+//keybase is the keybase under rootDir
+type keybase struct {
+	crkeys.Keybase
+	rootDir string
+}
+
+//keyBase opens the keybase under rootDir
+func keyBase(rootDir string) (keybase, error) {
+	viper.Set(cli.HomeFlag, rootDir)
+	kb, err := keys.NewKeyBaseFromHomeFlag()
+	if err != nil {
+		return keybase{}, err
+	}
+	return keybase{kb, rootDir}, nil
+}
+
+func (kb keybase) openDB() (dbm.DB, error) {
+	db, err := dbm.NewGoLevelDB("keys", filepath.Join(kb.rootDir, "keys"))
+	if err != nil {
+		return nil, errcode.Internal(err)
+	}
+	return db, nil
+}
+
+//readLocal reads the info of the local key name, ok is false when name is not a local key
+func (kb keybase) readLocal(name string) (info localInfo, ok bool, err error) {
+	db, err := kb.openDB()
+	if err != nil {
+		return info, false, err
+	}
+	bz := db.Get(infoKey(name))
+	db.Close()
+	if len(bz) == 0 || infoCdc.UnmarshalBinaryLengthPrefixed(bz, &info) != nil {
+		return info, false, nil
+	}
+	return info, true, nil
+}
+
+//writeLocal stores the key priv under name encrypted with passphrase, with the address index of the keybase.
+//With armor, the key stored under name is replaced only if it is still the key of armor, else a key stored
+//under name is replaced only with overwrite.
+func (kb keybase) writeLocal(name string, priv crypto.PrivKey, passphrase, armor string, overwrite bool) (crkeys.Info, error) {
+	privArmor, err := keycrypt.Encrypt(blockTypePrivKey, priv.Bytes(), passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if err := kb.putLocal(localInfo{Name: name, PubKey: priv.PubKey(), PrivKeyArmor: privArmor}, armor, overwrite); err != nil {
+		return nil, err
+	}
+	return kb.Get(name)
+}
+
+func (kb keybase) putLocal(info localInfo, armor string, overwrite bool) error {
+	name := info.Name
+	bz, err := infoCdc.MarshalBinaryLengthPrefixed(info)
+	if err != nil {
+		return errcode.Internal(err)
+	}
+	db, err := kb.openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	batch := db.NewBatch()
+	defer batch.Close()
+	var stored localInfo
+	old := db.Get(infoKey(name))
+	isLocal := len(old) > 0 && infoCdc.UnmarshalBinaryLengthPrefixed(old, &stored) == nil
+	switch {
+	case armor != "" && (!isLocal || stored.PrivKeyArmor != armor):
+		return errcode.Errorf(errcode.CodeInternal, "the key %s changed while it was encrypted again", name)
+	case armor == "" && len(old) > 0 && !overwrite:
+		return errKeyNameConflict(name)
+	case isLocal && !stored.PubKey.Equals(info.PubKey):
+		//the address index of the replaced key goes with it
+		batch.Delete(addrKey(sdk.AccAddress(stored.PubKey.Address())))
+	}
+	batch.Set(infoKey(name), bz)
+	batch.Set(addrKey(sdk.AccAddress(info.PubKey.Address())), infoKey(name))
+	batch.WriteSync()
+	return nil
+}
+
+//decrypt decrypts the local key of info and tells whether it is in the current envelope
+func (kb keybase) decrypt(info localInfo, passphrase string) (crypto.PrivKey, bool, error) {
+	if info.PrivKeyArmor == "" {
+		return nil, false, errors.New("private key not available")
+	}
+	bz, current, err := keycrypt.Decrypt(info.PrivKeyArmor, blockTypePrivKey, passphrase)
+	if errcode.Code(err) == errcode.CodeWrongPassword {
+		return nil, false, keyerror.NewErrWrongPassword()
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	priv, err := cryptoAmino.PrivKeyFromBytes(bz)
+	if err != nil {
+		return nil, false, errcode.Internal(err)
+	}
+	return priv, current, nil
+}
+
+//unlock decrypts the local key of info, and encrypts it again in the current envelope if it is in another one
+func (kb keybase) unlock(info localInfo, passphrase string) (crypto.PrivKey, error) {
+	priv, current, err := kb.decrypt(info, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if !current {
+		//the upgrade is done again on the next unlock if it fails
+		kb.writeLocal(info.Name, priv, passphrase, info.PrivKeyArmor, false)
+	}
+	return priv, nil
+}
+
+//CreateAccount derives the key of account and index of mnemonic, in any bip39 language
+func (kb keybase) CreateAccount(name, mnemonic, bip39Passwd, encryptPasswd string, account uint32, index uint32) (crkeys.Info, error) {
+	return kb.Derive(name, mnemonic, bip39Passwd, encryptPasswd, *hd.NewFundraiserParams(account, index))
+}
+
+//Derive stores the key at params of mnemonic under name, replacing the key stored under name as the keybase does
+func (kb keybase) Derive(name, mnemonic, bip39Passphrase, encryptPasswd string, params hd.BIP44Params) (crkeys.Info, error) {
+	priv, err := derivePrivKey(mnemonic, bip39Passphrase, params)
+	if err != nil {
+		return nil, err
+	}
+	return kb.writeLocal(name, priv, encryptPasswd, "", true)
+}
+
+func (kb keybase) Sign(name, passphrase string, msg []byte) ([]byte, crypto.PubKey, error) {
+	info, ok, err := kb.readLocal(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !ok {
+		return kb.Keybase.Sign(name, passphrase, msg)
+	}
+	priv, err := kb.unlock(info, passphrase)
+	if err != nil {
+		return nil, nil, err
+	}
+	sig, err := priv.Sign(msg)
+	if err != nil {
+		return nil, nil, err
+	}
+	return sig, priv.PubKey(), nil
+}
+
+func (kb keybase) ExportPrivateKeyObject(name string, passphrase string) (crypto.PrivKey, error) {
+	info, ok, err := kb.readLocal(name)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return kb.Keybase.ExportPrivateKeyObject(name, passphrase)
+	}
+	return kb.unlock(info, passphrase)
+}
+
+func (kb keybase) Delete(name, passphrase string, skipPass bool) error {
+	info, ok, err := kb.readLocal(name)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return kb.Keybase.Delete(name, passphrase, skipPass)
+	}
+	if !skipPass {
+		if _, _, err := kb.decrypt(info, passphrase); err != nil {
+			return err
+		}
+	}
+	return kb.Keybase.Delete(name, "", true)
+}
+
+func (kb keybase) Update(name, oldpass string, getNewpass func() (string, error)) error {
+	info, ok, err := kb.readLocal(name)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return kb.Keybase.Update(name, oldpass, getNewpass)
+	}
+	priv, _, err := kb.decrypt(info, oldpass)
+	if err != nil {
+		return err
+	}
+	newpass, err := getNewpass()
+	if err != nil {
+		return err
+	}
+	_, err = kb.writeLocal(name, priv, newpass, "", true)
+	return err
+}
+
+//UpgradeKey encrypts the local key name again in the current envelope of keycrypt if it is in another one, and
+//tells whether it did
+func UpgradeKey(rootDir, name, password string) (bool, error) {
+	kb, err := keyBase(rootDir)
+	if err != nil {
+		return false, err
+	}
+	info, ok, err := kb.readLocal(name)
+	if err != nil {
+		return false, err
+	}
+	if !ok {
+		if _, err := kb.Get(name); err != nil {
+			return false, keyError(err)
+		}
+		//the ledger and offline keys have no private key to encrypt
+		return false, nil
+	}
+	if upgrade, err := keycrypt.NeedsUpgrade(info.PrivKeyArmor); err != nil || !upgrade {
+		return false, err
+	}
+	priv, _, err := kb.decrypt(info, password)
+	if err != nil {
+		return false, keyError(err)
+	}
+	if _, err := kb.writeLocal(name, priv, password, info.PrivKeyArmor, false); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func infoKey(name string) []byte {
+	return []byte(name + "." + infoSuffix)
+}
+
+func addrKey(address sdk.AccAddress) []byte {
+	return []byte(address.String() + "." + addressSuffix)
+}
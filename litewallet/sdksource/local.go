@@ -5,11 +5,12 @@ import (
 	"fmt"
 	"regexp"
 
-	"github.com/cosmos/cosmos-sdk/client/keys"
+	"github.com/QOSGroup/litewallet/litewallet/errcode"
+	"github.com/QOSGroup/litewallet/litewallet/slim/tendermint/crypto/funcInlocal/bip39local"
 	crkeys "github.com/cosmos/cosmos-sdk/crypto/keys"
-	bip39 "github.com/cosmos/go-bip39"
-	"github.com/spf13/viper"
-	"github.com/tendermint/tendermint/libs/cli"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/keyerror"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/mintkey"
+	"github.com/tendermint/tendermint/crypto/secp256k1"
 )
 
 // keybase is used to make GetKeyBase a singleton
@@ -66,14 +67,7 @@ type SeedOutput struct {
 
 //create mnemonics with bip39 to output 12-word list
 func CreateSeed() string {
-	// default number of words (12):
-	// this generates a mnemonic directly from the number of words by reading system entropy.
-	defaultEntropySize := 128
-	entropy, err := bip39.NewEntropy(defaultEntropySize)
-	if err != nil {
-		return err.Error()
-	}
-	mnemonic, err := bip39.NewMnemonic(entropy)
+	mnemonic, err := GenerateSeed()
 	if err != nil {
 		return err.Error()
 	}
@@ -84,126 +78,157 @@ func CreateSeed() string {
 	return string(respbyte)
 }
 
+//GenerateSeed returns a new 12-word bip39 mnemonic
+func GenerateSeed() (string, error) {
+	return GenerateSeedIn(bip39local.English)
+}
+
+//GenerateSeedIn returns a new 12-word bip39 mnemonic in language, one of bip39local.Languages
+func GenerateSeedIn(language string) (string, error) {
+	return GenerateMnemonic(12, language, nil)
+}
+
+//GenerateMnemonic returns a new bip39 mnemonic of 12, 15, 18, 21 or 24 words in language with the entropy of
+//source, the system entropy when it is nil
+func GenerateMnemonic(words int, language string, source bip39local.EntropySource) (string, error) {
+	mnemonic, err := bip39local.GenerateMnemonic(words, language, source)
+	if err != nil {
+		return "", errcode.InvalidInput(err)
+	}
+	return mnemonic, nil
+}
+
 //errors on account creation
 func errKeyNameConflict(name string) error {
-	return fmt.Errorf("acount with name %s already exists", name)
+	return errcode.Errorf(errcode.CodeInvalidInput, "acount with name %s already exists", name)
 }
 
 func errMissingName() error {
-	return fmt.Errorf("you have to specify a name for the locally stored account")
+	return errcode.New(errcode.CodeInvalidInput, "you have to specify a name for the locally stored account")
 }
 
 func errMissingPassword() error {
-	return fmt.Errorf("you have to specify a password for the locally stored account")
+	return errcode.New(errcode.CodeInvalidInput, "you have to specify a password for the locally stored account")
 }
 
 func errMissingSeed() error {
-	return fmt.Errorf("you have to specify seed for key recover")
+	return errcode.New(errcode.CodeInvalidInput, "you have to specify seed for key recover")
+}
+
+func errInvalidSeed() error {
+	return errcode.New(errcode.CodeInvalidInput, "the seed is not a valid bip39 mnemonic")
 }
 
 func CreateAccount(rootDir, name, password, seed string) string {
-	var (
-		err  error
-		info crkeys.Info
-	)
-	//initialize keybase
-	//SetKeyBase(rootDir)
-	viper.Set(cli.HomeFlag, rootDir)
-	kb, errz := keys.NewKeyBaseFromHomeFlag()
-	if errz != nil {
-		fmt.Println(errz)
+	Ko, err := CreateKey(rootDir, name, password, seed)
+	if err != nil {
+		return err.Error()
 	}
+	respbyte, _ := json.Marshal(Ko)
+	return string(respbyte)
+}
 
+//CreateKey stores a new key under name, a new mnemonic is generated if seed is empty
+func CreateKey(rootDir, name, password, seed string) (*KeyOutput, error) {
 	//check out the input
 	if name == "" {
-		err = errMissingName()
-		return err.Error()
+		return nil, errMissingName()
 	}
 	if password == "" {
-		err = errMissingPassword()
-		return err.Error()
+		return nil, errMissingPassword()
+	}
+	//initialize keybase
+	kb, err := keyBase(rootDir)
+	if err != nil {
+		return nil, err
 	}
 	// check if already exists
 	infos, err := kb.List()
+	if err != nil {
+		return nil, err
+	}
 	for _, info := range infos {
 		if info.GetName() == name {
-			err = errKeyNameConflict(name)
-			return err.Error()
+			return nil, errKeyNameConflict(name)
 		}
 	}
 
 	//create account
-	if seed == "" {
-		algo := crkeys.SigningAlgo("secp256k1")
-		pass := defaultBIP39pass
-		name := "inmemorykey"
-		_, Seed, _ := kb.CreateMnemonic(name, crkeys.English, pass, algo)
-		seed = Seed
-	}
-
-	info, err1 := kb.CreateAccount(name, seed, defaultBIP39pass, password, 0, 0)
-	if err1 != nil {
-		return err1.Error()
+	if seed != "" && !isSeedValid(seed) {
+		return nil, errInvalidSeed()
 	}
-
-	keyOutput, err2 := crkeys.Bech32KeyOutput(info)
-	if err2 != nil {
-		return err2.Error()
+	if seed == "" {
+		if seed, err = GenerateSeed(); err != nil {
+			return nil, err
+		}
 	}
-
-	keyOutput.Mnemonic = seed
-	//add new field denom for the coin name
-	var Ko KeyOutput
-	Ko = KeyOutput{keyOutput.Name, keyOutput.Type, keyOutput.Address, keyOutput.PubKey, keyOutput.Mnemonic, DenomName}
-	respbyte, _ := json.Marshal(Ko)
-	return string(respbyte)
+	return createKey(kb, name, password, seed)
 }
 
 //for recover key with name, password and seed input
 func RecoverKey(rootDir, name, password, seed string) string {
-	var (
-		err  error
-		info crkeys.Info
-	)
-	//initialize keybase
-	//SetKeyBase(rootDir)
-	viper.Set(cli.HomeFlag, rootDir)
-	kb, errz := keys.NewKeyBaseFromHomeFlag()
-	if errz != nil {
-		fmt.Println(errz)
+	Ko, err := RestoreKey(rootDir, name, password, seed)
+	if err != nil {
+		return err.Error()
 	}
+	respbyte, _ := json.Marshal(Ko)
+	return string(respbyte)
+}
 
+//RestoreKey stores the key of the seed under name
+func RestoreKey(rootDir, name, password, seed string) (*KeyOutput, error) {
 	if name == "" {
-		err = errMissingName()
-		return err.Error()
+		return nil, errMissingName()
 	}
 	if password == "" {
-		err = errMissingPassword()
-		return err.Error()
+		return nil, errMissingPassword()
 	}
 	if seed == "" {
-		err = errMissingSeed()
-		return err.Error()
+		return nil, errMissingSeed()
+	}
+	if !isSeedValid(seed) {
+		return nil, errInvalidSeed()
 	}
+	//initialize keybase
+	kb, err := keyBase(rootDir)
 	if err != nil {
-		return err.Error()
+		return nil, err
 	}
-	info, err1 := kb.CreateAccount(name, seed, defaultBIP39pass, password, 0, 0)
-	if err1 != nil {
-		return err1.Error()
+	return createKey(kb, name, password, seed)
+}
+
+//isSeedValid checks the words and the checksum of a mnemonic of any bip39 language
+func isSeedValid(seed string) bool {
+	_, _, err := bip39local.MnemonicToEntropy(seed)
+	return err == nil
+}
+
+func createKey(kb keybase, name, password, seed string) (*KeyOutput, error) {
+	info, err := kb.CreateAccount(name, seed, defaultBIP39pass, password, 0, 0)
+	if err != nil {
+		return nil, err
 	}
 
-	keyOutput, err2 := crkeys.Bech32KeyOutput(info)
-	if err2 != nil {
-		return err2.Error()
+	keyOutput, err := crkeys.Bech32KeyOutput(info)
+	if err != nil {
+		return nil, err
 	}
 
-	keyOutput.Mnemonic = seed
 	//add new field denom for the coin name
-	var Ko KeyOutput
-	Ko = KeyOutput{keyOutput.Name, keyOutput.Type, keyOutput.Address, keyOutput.PubKey, keyOutput.Mnemonic, DenomName}
-	respbyte, _ := json.Marshal(Ko)
-	return string(respbyte)
+	return &KeyOutput{keyOutput.Name, keyOutput.Type, keyOutput.Address, keyOutput.PubKey, seed, DenomName}, nil
+}
+
+//HasKey tells whether the keybase under rootDir holds a key name
+func HasKey(rootDir, name string) (bool, error) {
+	kb, err := keyBase(rootDir)
+	if err != nil {
+		return false, err
+	}
+	_, err = kb.Get(name)
+	if keyerror.IsErrKeyNotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
 }
 
 type UpdateKeyOutput struct {
@@ -212,19 +237,8 @@ type UpdateKeyOutput struct {
 
 //for update the password of the name key stored in level db
 func UpdateKey(rootDir, name, oldpass, newpass string) string {
-	//SetKeyBase(rootDir)
-	viper.Set(cli.HomeFlag, rootDir)
-	kb, errz := keys.NewKeyBaseFromHomeFlag()
-	if errz != nil {
-		fmt.Println(errz)
-	}
-	getNewpass := func() (string, error) {
-		return newpass, nil
-	}
-
-	err2 := kb.Update(name, oldpass, getNewpass)
-	if err2 != nil {
-		return err2.Error()
+	if err := ChangePassword(rootDir, name, oldpass, newpass); err != nil {
+		return err.Error()
 	}
 	res := fmt.Sprintf("Password is successfully updated!")
 
@@ -236,6 +250,97 @@ func UpdateKey(rootDir, name, oldpass, newpass string) string {
 
 }
 
+//ChangePassword re-encrypts the key of name with newpass
+func ChangePassword(rootDir, name, oldpass, newpass string) error {
+	kb, err := keyBase(rootDir)
+	if err != nil {
+		return err
+	}
+	getNewpass := func() (string, error) {
+		return newpass, nil
+	}
+	return keyError(kb.Update(name, oldpass, getNewpass))
+}
+
+//ListKeys returns the keys of the keybase under rootDir in alphabetical order of name
+func ListKeys(rootDir string) ([]KeyOutput, error) {
+	kb, err := keyBase(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	infos, err := kb.List()
+	if err != nil {
+		return nil, err
+	}
+	kos := make([]KeyOutput, 0, len(infos))
+	for _, info := range infos {
+		ko, err := crkeys.Bech32KeyOutput(info)
+		if err != nil {
+			return nil, err
+		}
+		kos = append(kos, KeyOutput{ko.Name, ko.Type, ko.Address, ko.PubKey, "", DenomName})
+	}
+	return kos, nil
+}
+
+//DeleteKey removes the key name, the password has to match
+func DeleteKey(rootDir, name, password string) error {
+	kb, err := keyBase(rootDir)
+	if err != nil {
+		return err
+	}
+	return keyError(kb.Delete(name, password, false))
+}
+
+//ExportKey returns the private key of name armored and encrypted with exportPass, the armor ImportKey reads
+func ExportKey(rootDir, name, password, exportPass string) (string, error) {
+	if exportPass == "" {
+		return "", errcode.New(errcode.CodeInvalidInput, "you have to specify a password for the exported key")
+	}
+	kb, err := keyBase(rootDir)
+	if err != nil {
+		return "", err
+	}
+	priv, err := kb.ExportPrivateKeyObject(name, password)
+	if err != nil {
+		return "", keyError(err)
+	}
+	return mintkey.EncryptArmorPrivKey(priv, exportPass), nil
+}
+
+//ImportKey decrypts the armored private key of ExportKey with armorPass and stores it under name
+func ImportKey(rootDir, name, password, armor, armorPass string) (*KeyOutput, error) {
+	if name == "" {
+		return nil, errMissingName()
+	}
+	if password == "" {
+		return nil, errMissingPassword()
+	}
+	priv, err := mintkey.UnarmorDecryptPrivKey(armor, armorPass)
+	if keyerror.IsErrWrongPassword(err) {
+		return nil, errcode.WrongPassword(err)
+	}
+	if err != nil {
+		return nil, errcode.Errorf(errcode.CodeInvalidInput, "invalid armored private key: %v", err)
+	}
+	if _, ok := priv.(secp256k1.PrivKeySecp256k1); !ok {
+		return nil, errcode.New(errcode.CodeInvalidInput, "the armored private key is not a secp256k1 key")
+	}
+	kb, err := keyBase(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	info, err := kb.writeLocal(name, priv, password, "", false)
+	if err != nil {
+		return nil, err
+	}
+	ko, err := crkeys.Bech32KeyOutput(info)
+	if err != nil {
+		return nil, err
+	}
+	return &KeyOutput{ko.Name, ko.Type, ko.Address, ko.PubKey, "", DenomName}, nil
+}
+
 //To differentiate the addresses from various wallets, e.g. cosmos,ETH,qos, .etc
 func WalletAddressCheck(addr string) string {
 	//split the address with prefix, e.g. "0x", "cosmos", "address" for ETH, cosmos, qos respectively
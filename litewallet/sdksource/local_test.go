@@ -1,9 +1,18 @@
 package sdksource
 
 import (
+	"io/ioutil"
+	"os"
 	"os/user"
+	"strings"
 	"testing"
+
+	"github.com/QOSGroup/litewallet/litewallet/errcode"
+	"github.com/QOSGroup/litewallet/litewallet/signer/signertest"
+	"github.com/QOSGroup/litewallet/litewallet/slim/tendermint/crypto/funcInlocal/bip39local"
+	"github.com/QOSGroup/litewallet/litewallet/slim/tendermint/crypto/funcInlocal/ed25519local"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
 )
 
 func TestCreateSeed(t *testing.T) {
@@ -53,4 +62,153 @@ func TestWalletAddressCheck(t *testing.T) {
 	address := "0x1uyh63ddjrv944prku8sfn8vmmxluktl46dmy2e"
 	output := WalletAddressCheck(address)
 	t.Log(output)
-}
\ No newline at end of file
+}
+func TestImportDerivedKey(t *testing.T) {
+	rootDir, err := ioutil.TempDir("", "keys")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(rootDir)
+	kb, err := keyBase(rootDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	//the derived key of an English mnemonic is the one of the keybase
+	seed := "tomorrow room limit true galaxy dove chicken fine resemble tonight record yellow"
+	created, err := kb.Keybase.CreateAccount("created", seed, defaultBIP39pass, "wm131421", 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	imported, err := kb.CreateAccount("imported", seed, defaultBIP39pass, "wm131421", 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !imported.GetPubKey().Equals(created.GetPubKey()) {
+		t.Fatalf("got %s, want %s", imported.GetAddress(), created.GetAddress())
+	}
+	if _, _, err := kb.Sign("imported", "wm131421", []byte("msg")); err != nil {
+		t.Errorf("got %v, want a signature of the imported key", err)
+	}
+	//the key of the keybase is encrypted again in the envelope of keycrypt on its unlock
+	if info, _, _ := kb.readLocal("created"); !strings.Contains(info.PrivKeyArmor, "kdf: bcrypt") {
+		t.Errorf("got %s, want the armor of the keybase", info.PrivKeyArmor)
+	}
+	if _, _, err := kb.Sign("created", "wm131421", []byte("msg")); err != nil {
+		t.Errorf("got %v, want a signature of the created key", err)
+	}
+	if upgraded, err := UpgradeKey(rootDir, "created", "wm131421"); err != nil || upgraded {
+		t.Errorf("got %v, %v, want the created key upgraded on its unlock", upgraded, err)
+	}
+	if _, err := UpgradeKey(rootDir, "unknown", "wm131421"); errcode.Code(err) != errcode.CodeKeyNotFound {
+		t.Errorf("unknown key: %v", err)
+	}
+
+	chinese, _ := bip39local.NewMnemonicIn(make([]byte, 16), bip39local.ChineseSimplified)
+	ko, err := RestoreKey(rootDir, "chinese", "wm131421", chinese)
+	if err != nil || ko.Address == created.GetAddress().String() {
+		t.Fatalf("got %+v, %v, want the key of the Chinese mnemonic", ko, err)
+	}
+	if err := ChangePassword(rootDir, "chinese", "wm131421", "wm131422"); err != nil {
+		t.Errorf("got %v, want the password of the Chinese key changed", err)
+	}
+}
+
+func TestKeyLifecycle(t *testing.T) {
+	rootDir, err := ioutil.TempDir("", "keys")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(rootDir)
+	seed := "tomorrow room limit true galaxy dove chicken fine resemble tonight record yellow"
+	created, err := CreateKey(rootDir, "cm", "wm131421", seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	armor, err := ExportKey(rootDir, "cm", "wm131421", "exportpass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ExportKey(rootDir, "cm", "wrong", "exportpass"); errcode.Code(err) != errcode.CodeWrongPassword {
+		t.Errorf("wrong password: %v", err)
+	}
+	if err := DeleteKey(rootDir, "cm", "wrong"); errcode.Code(err) != errcode.CodeWrongPassword {
+		t.Errorf("wrong password: %v", err)
+	}
+	if err := DeleteKey(rootDir, "cm", "wm131421"); err != nil {
+		t.Fatal(err)
+	}
+	if err := DeleteKey(rootDir, "cm", "wm131421"); errcode.Code(err) != errcode.CodeKeyNotFound {
+		t.Errorf("unknown key: %v", err)
+	}
+
+	if _, err := ImportKey(rootDir, "cm2", "wm131422", armor, "wrong"); errcode.Code(err) != errcode.CodeWrongPassword {
+		t.Errorf("wrong armor password: %v", err)
+	}
+	imported, err := ImportKey(rootDir, "cm2", "wm131422", armor, "exportpass")
+	if err != nil || imported.Address != created.Address {
+		t.Fatalf("got %+v, %v, want the address %s", imported, err, created.Address)
+	}
+	if _, err := ImportKey(rootDir, "cm2", "wm131422", armor, "exportpass"); errcode.Code(err) != errcode.CodeInvalidInput {
+		t.Errorf("name conflict: %v", err)
+	}
+	kos, err := ListKeys(rootDir)
+	if err != nil || len(kos) != 1 || kos[0].Name != "cm2" || kos[0].Address != created.Address {
+		t.Errorf("got %+v, %v, want the key cm2", kos, err)
+	}
+}
+
+func TestSignMessage(t *testing.T) {
+	rootDir, err := ioutil.TempDir("", "keys")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(rootDir)
+	seed := "tomorrow room limit true galaxy dove chicken fine resemble tonight record yellow"
+	created, err := CreateKey(rootDir, "cm", "wm131421", seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr, _ := sdk.AccAddressFromBech32(created.Address)
+
+	//the doc is the one of the Cosmos wallets
+	want := `{"account_number":"0","chain_id":"","fee":{"amount":[],"gas":"0"},"memo":"",` +
+		`"msgs":[{"type":"sign/MsgSignData","value":{"data":"aGVsbG8=","signer":"` + created.Address + `"}}],"sequence":"0"}`
+	if got, err := MessageSignBytes(addr, []byte("hello")); err != nil || string(got) != want {
+		t.Errorf("got the sign doc %s, %v, want %s", got, err, want)
+	}
+
+	msg := []byte("login to litewallet, nonce 42")
+	sig, err := SignMessage(rootDir, "cm", "wm131421", msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sig.Signature) != 64 {
+		t.Errorf("got a signature of %d bytes, want r||s", len(sig.Signature))
+	}
+	if ok, err := VerifyMessage(created.Address, msg, sig); err != nil || !ok {
+		t.Errorf("got %v %v, want the signature verified", ok, err)
+	}
+	if ok, err := VerifyMessage(created.Address, []byte("login to litewallet, nonce 43"), sig); err != nil || ok {
+		t.Errorf("got %v %v, want another message rejected", ok, err)
+	}
+	if _, err := SignMessage(rootDir, "cm", "wrong", msg); errcode.Code(err) != errcode.CodeWrongPassword {
+		t.Errorf("wrong password: %v", err)
+	}
+
+	//another key signing for the address is rejected
+	key, err := ethcrypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := SignMessageWithSigner(signertest.NewSecp256k1(key).Signer(), msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := VerifyMessage(created.Address, msg, other); err != nil || ok {
+		t.Errorf("got %v %v, want the signature of another key rejected", ok, err)
+	}
+	if _, err := SignMessageWithSigner(signertest.NewEd25519(ed25519local.GenPrivKeyFromSecret([]byte("device"))).Signer(), msg); errcode.Code(err) != errcode.CodeInvalidInput {
+		t.Errorf("ed25519 signer: %v", err)
+	}
+}
@@ -0,0 +1,114 @@
+package sdksource
+
+import (
+	"encoding/json"
+
+	"github.com/QOSGroup/litewallet/litewallet/errcode"
+	"github.com/QOSGroup/litewallet/litewallet/signer"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/tendermint/tendermint/crypto"
+	"github.com/tendermint/tendermint/crypto/secp256k1"
+)
+
+//The messages are signed as ADR-036 offline sign docs, the sign doc of a tx with an empty chain id, a zero
+//account number, sequence and fee, and a single MsgSignData of the signer carrying the message. No node
+//accepts such a tx, so the signature can not be replayed on chain, and the Cosmos wallets sign the same doc.
+
+//adr036Doc is the StdSignDoc of an ADR-036 message, its fields in the order of the sorted JSON
+type adr036Doc struct {
+	AccountNumber string      `json:"account_number"`
+	ChainID       string      `json:"chain_id"`
+	Fee           adr036Fee   `json:"fee"`
+	Memo          string      `json:"memo"`
+	Msgs          []adr036Msg `json:"msgs"`
+	Sequence      string      `json:"sequence"`
+}
+
+type adr036Fee struct {
+	Amount []sdk.Coin `json:"amount"`
+	Gas    string     `json:"gas"`
+}
+
+type adr036Msg struct {
+	Type  string         `json:"type"`
+	Value adr036SignData `json:"value"`
+}
+
+//adr036SignData is the MsgSignData, the data is base64 in the JSON
+type adr036SignData struct {
+	Data   []byte `json:"data"`
+	Signer string `json:"signer"`
+}
+
+//MessageSignBytes returns the sign bytes of the ADR-036 doc of msg signed by signerAddr
+func MessageSignBytes(signerAddr sdk.AccAddress, msg []byte) ([]byte, error) {
+	bz, err := json.Marshal(adr036Doc{
+		AccountNumber: "0",
+		Fee:           adr036Fee{Amount: []sdk.Coin{}, Gas: "0"},
+		Msgs:          []adr036Msg{{Type: "sign/MsgSignData", Value: adr036SignData{Data: msg, Signer: signerAddr.String()}}},
+		Sequence:      "0",
+	})
+	if err != nil {
+		return nil, errcode.Internal(err)
+	}
+	bz, err = sdk.SortJSON(bz)
+	if err != nil {
+		return nil, errcode.Internal(err)
+	}
+	return bz, nil
+}
+
+//SignMessage signs msg with the local key name as an ADR-036 doc
+func SignMessage(rootDir, name, password string, msg []byte) (signer.MessageSignature, error) {
+	s, err := KeySigner(rootDir, name, password)
+	if err != nil {
+		return signer.MessageSignature{}, err
+	}
+	return SignMessageWithSigner(s, msg)
+}
+
+//SignMessageWithSigner is SignMessage signed by s
+func SignMessageWithSigner(s signer.Signer, msg []byte) (signer.MessageSignature, error) {
+	addr, err := SignerAddress(s)
+	if err != nil {
+		return signer.MessageSignature{}, err
+	}
+	signBytes, err := MessageSignBytes(addr, msg)
+	if err != nil {
+		return signer.MessageSignature{}, err
+	}
+	sig, err := s.Sign(crypto.Sha256(signBytes))
+	if err != nil {
+		return signer.MessageSignature{}, err
+	}
+	if len(sig) != signer.Secp256k1SignatureSize {
+		return signer.MessageSignature{}, errcode.Errorf(errcode.CodeInternal, "invalid secp256k1 signature of %d bytes", len(sig))
+	}
+	//Cosmos takes r||s without the recovery id
+	return signer.NewMessageSignature(s, sig[:64]), nil
+}
+
+//VerifyMessage tells whether sig is the ADR-036 signature of msg by the account address addr, the public key
+//of sig has to be the one of addr
+func VerifyMessage(addr string, msg []byte, sig signer.MessageSignature) (bool, error) {
+	accAddr, err := sdk.AccAddressFromBech32(addr)
+	if err != nil {
+		return false, errcode.InvalidInput(err)
+	}
+	if err := sig.CheckType(signer.PubKeySecp256k1Type); err != nil {
+		return false, err
+	}
+	var pubKey secp256k1.PubKeySecp256k1
+	if len(sig.PubKey.Value) != len(pubKey) {
+		return false, errcode.Errorf(errcode.CodeInvalidInput, "invalid secp256k1 public key of %d bytes", len(sig.PubKey.Value))
+	}
+	copy(pubKey[:], sig.PubKey.Value)
+	if !accAddr.Equals(sdk.AccAddress(pubKey.Address())) {
+		return false, nil
+	}
+	signBytes, err := MessageSignBytes(accAddr, msg)
+	if err != nil {
+		return false, err
+	}
+	return pubKey.VerifyBytes(signBytes, sig.Signature), nil
+}
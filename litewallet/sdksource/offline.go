@@ -0,0 +1,193 @@
+package sdksource
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/QOSGroup/litewallet/litewallet/errcode"
+	"github.com/QOSGroup/litewallet/litewallet/offline"
+	"github.com/QOSGroup/litewallet/litewallet/signer"
+	"github.com/cosmos/cosmos-sdk/client/context"
+	"github.com/cosmos/cosmos-sdk/client/utils"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/auth"
+	authtxb "github.com/cosmos/cosmos-sdk/x/auth/client/txbuilder"
+	"github.com/tendermint/tendermint/crypto"
+	tmtypes "github.com/tendermint/tendermint/types"
+)
+
+//The offline txs are built from the address of the sender with its account number and sequence, signed by
+//SignOffline on a device without network and broadcast by BroadcastOffline, see the offline package.
+
+//BuildOfflineTransfer builds the unsigned transfer of coinStr from fromStr to toStr
+func BuildOfflineTransfer(rootDir, node, chainID, fromStr, toStr, coinStr, feeStr string) (offline.Tx, error) {
+	fromAddr, err := sdk.AccAddressFromBech32(fromStr)
+	if err != nil {
+		return offline.Tx{}, errcode.InvalidInput(err)
+	}
+	txBldr, msg, err := prepareTransfer(rootDir, node, chainID, fromAddr, toStr, coinStr, feeStr)
+	if err != nil {
+		return offline.Tx{}, err
+	}
+	return offlineTx(txBldr, offline.TypeTransfer, fromAddr, []sdk.Msg{msg})
+}
+
+//BuildOfflineDelegate builds the unsigned delegation of delegationCoinStr of delegatorAddr to validatorAddr
+func BuildOfflineDelegate(rootDir, node, chainID, delegatorAddr, validatorAddr, delegationCoinStr, feeStr string) (offline.Tx, error) {
+	DelegatorAddr, err := sdk.AccAddressFromBech32(delegatorAddr)
+	if err != nil {
+		return offline.Tx{}, errcode.InvalidInput(err)
+	}
+	cliCtx := queryContext(rootDir, node, chainID)
+	msgs, err := delegateMsgs(cliCtx, DelegatorAddr, validatorAddr, delegationCoinStr)
+	if err != nil {
+		return offline.Tx{}, err
+	}
+	return buildOffline(cliCtx, chainID, feeStr, offline.TypeDelegate, DelegatorAddr, msgs)
+}
+
+//BuildOfflineUndelegate builds the unsigned unbonding of Ubdshares of delegatorAddr from validatorAddr
+func BuildOfflineUndelegate(rootDir, node, chainID, delegatorAddr, validatorAddr, Ubdshares, feeStr string) (offline.Tx, error) {
+	DelegatorAddr, err := sdk.AccAddressFromBech32(delegatorAddr)
+	if err != nil {
+		return offline.Tx{}, errcode.InvalidInput(err)
+	}
+	cliCtx := queryContext(rootDir, node, chainID)
+	msgs, err := undelegateMsgs(cliCtx, DelegatorAddr, validatorAddr, Ubdshares)
+	if err != nil {
+		return offline.Tx{}, err
+	}
+	return buildOffline(cliCtx, chainID, feeStr, offline.TypeUndelegate, DelegatorAddr, msgs)
+}
+
+//BuildOfflineWithdrawReward builds the unsigned withdrawal of the rewards of delegatorAddr from validatorAddr
+func BuildOfflineWithdrawReward(rootDir, node, chainID, delegatorAddr, validatorAddr, feeStr string) (offline.Tx, error) {
+	DelegatorAddr, err := sdk.AccAddressFromBech32(delegatorAddr)
+	if err != nil {
+		return offline.Tx{}, errcode.InvalidInput(err)
+	}
+	cliCtx := queryContext(rootDir, node, chainID)
+	msgs, err := withdrawRewardMsgs(cliCtx, DelegatorAddr, validatorAddr)
+	if err != nil {
+		return offline.Tx{}, err
+	}
+	return buildOffline(cliCtx, chainID, feeStr, offline.TypeWithdrawReward, DelegatorAddr, msgs)
+}
+
+//BuildOfflineWithdrawAllRewards builds the unsigned withdrawal of the rewards of delegatorAddr from all its
+//validators
+func BuildOfflineWithdrawAllRewards(rootDir, node, chainID, delegatorAddr, feeStr string) (offline.Tx, error) {
+	DelAddr, err := sdk.AccAddressFromBech32(delegatorAddr)
+	if err != nil {
+		return offline.Tx{}, errcode.InvalidInput(err)
+	}
+	cliCtx := queryContext(rootDir, node, chainID)
+	msgs, err := withdrawAllRewardsMsgs(cliCtx, DelAddr)
+	if err != nil {
+		return offline.Tx{}, err
+	}
+	return buildOffline(cliCtx, chainID, feeStr, offline.TypeWithdrawAllReward, DelAddr, msgs)
+}
+
+//SignOffline signs the unsigned Cosmos tx with s, without any node
+func SignOffline(tx offline.Tx, s signer.Signer) (offline.Tx, error) {
+	if err := tx.CheckUnsigned(offline.ChainCosmos); err != nil {
+		return offline.Tx{}, err
+	}
+	addr, err := SignerAddress(s)
+	if err != nil {
+		return offline.Tx{}, err
+	}
+	if addr.String() != tx.Signer {
+		return offline.Tx{}, errcode.Errorf(errcode.CodeInvalidInput, "the offline tx is signed by %s, not %s", tx.Signer, addr)
+	}
+
+	var stdTx auth.StdTx
+	if err := cdc.UnmarshalJSON(tx.Tx, &stdTx); err != nil {
+		return offline.Tx{}, errcode.InvalidInput(err)
+	}
+	//the signer of the document has to be the one of the messages
+	for _, msgSigner := range stdTx.GetSigners() {
+		if !msgSigner.Equals(addr) {
+			return offline.Tx{}, errcode.Errorf(errcode.CodeInvalidInput, "the offline tx has a message of %s", msgSigner)
+		}
+	}
+
+	signMsg := authtxb.StdSignMsg{
+		ChainID:       tx.ChainID,
+		AccountNumber: tx.AccountNumber,
+		Sequence:      tx.Sequence,
+		Fee:           stdTx.Fee,
+		Msgs:          stdTx.Msgs,
+		Memo:          stdTx.Memo,
+	}
+	if err := tx.CheckSignHash(signHash(signMsg)); err != nil {
+		return offline.Tx{}, err
+	}
+	signed, err := signStdSignMsg(signMsg, s)
+	if err != nil {
+		return offline.Tx{}, err
+	}
+	txBytes, err := utils.GetTxEncoder(cdc)(signed)
+	if err != nil {
+		return offline.Tx{}, errcode.Internal(err)
+	}
+	tx.SignHash = signHash(signMsg)
+	tx.Signed = hex.EncodeToString(txBytes)
+	tx.Hash = fmt.Sprintf("%X", tmtypes.Tx(txBytes).Hash())
+	return tx, nil
+}
+
+//BroadcastOffline broadcasts the signed Cosmos tx in broadcastMode
+func BroadcastOffline(rootDir, node string, tx offline.Tx, broadcastMode string) (sdk.TxResponse, error) {
+	if err := tx.CheckSigned(offline.ChainCosmos); err != nil {
+		return sdk.TxResponse{}, err
+	}
+	txBytes, err := hex.DecodeString(tx.Signed)
+	if err != nil {
+		return sdk.TxResponse{}, errcode.InvalidInput(err)
+	}
+	if hash := fmt.Sprintf("%X", tmtypes.Tx(txBytes).Hash()); hash != tx.Hash {
+		return sdk.TxResponse{}, errcode.Errorf(errcode.CodeInvalidInput, "the signed tx has the hash %s, not %s", hash, tx.Hash)
+	}
+	return BroadcastTxBytes(rootDir, node, tx.ChainID, txBytes, broadcastMode)
+}
+
+//buildOffline builds msgs with the account number and sequence of addr
+func buildOffline(cliCtx context.CLIContext, chainID, feeStr, txType string, addr sdk.AccAddress, msgs []sdk.Msg) (offline.Tx, error) {
+	txBldr, err := txBuilder(cliCtx, chainID, feeStr, addr)
+	if err != nil {
+		return offline.Tx{}, err
+	}
+	return offlineTx(txBldr, txType, addr, msgs)
+}
+
+//offlineTx is the document of msgs unsigned, the tx is a StdTx without signatures
+func offlineTx(txBldr authtxb.TxBuilder, txType string, addr sdk.AccAddress, msgs []sdk.Msg) (offline.Tx, error) {
+	if len(msgs) == 0 {
+		return offline.Tx{}, errcode.New(errcode.CodeInvalidInput, "the tx has no message")
+	}
+	signMsg, err := txBldr.BuildSignMsg(msgs)
+	if err != nil {
+		return offline.Tx{}, err
+	}
+	bz, err := cdc.MarshalJSON(auth.NewStdTx(signMsg.Msgs, signMsg.Fee, nil, signMsg.Memo))
+	if err != nil {
+		return offline.Tx{}, errcode.Internal(err)
+	}
+	return offline.Tx{
+		Chain:         offline.ChainCosmos,
+		ChainID:       signMsg.ChainID,
+		Type:          txType,
+		Signer:        addr.String(),
+		AccountNumber: signMsg.AccountNumber,
+		Sequence:      signMsg.Sequence,
+		Tx:            bz,
+		SignHash:      signHash(signMsg),
+	}, nil
+}
+
+//signHash is the hex of the sha256 of the sign bytes, the digest the key signs
+func signHash(signMsg authtxb.StdSignMsg) string {
+	return fmt.Sprintf("%X", crypto.Sha256(signMsg.Bytes()))
+}
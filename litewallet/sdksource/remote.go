@@ -3,18 +3,21 @@ package sdksource
 import (
 	"bytes"
 	"encoding/hex"
-	"errors"
 	"fmt"
-	"os"
+	"net"
 	"strings"
 	"time"
 
-	"github.com/cosmos/cosmos-sdk/client/keys"
+	"github.com/QOSGroup/litewallet/litewallet/errcode"
+	"github.com/QOSGroup/litewallet/litewallet/signer"
+	"github.com/QOSGroup/litewallet/litewallet/trust"
+
+	"github.com/cosmos/cosmos-sdk/client/context"
 	"github.com/cosmos/cosmos-sdk/client/tx"
 	"github.com/cosmos/cosmos-sdk/client/utils"
 	"github.com/cosmos/cosmos-sdk/cmd/gaia/app"
 	"github.com/cosmos/cosmos-sdk/codec"
-	cskeys "github.com/cosmos/cosmos-sdk/crypto/keys"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/keyerror"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/x/auth"
 	authtxb "github.com/cosmos/cosmos-sdk/x/auth/client/txbuilder"
@@ -23,10 +26,8 @@ import (
 	distritypes "github.com/cosmos/cosmos-sdk/x/distribution/types"
 	"github.com/cosmos/cosmos-sdk/x/staking"
 	"github.com/cosmos/cosmos-sdk/x/staking/types"
-	"github.com/spf13/viper"
+	pkgerrors "github.com/pkg/errors"
 	"github.com/tendermint/tendermint/libs/bech32"
-	"github.com/tendermint/tendermint/libs/cli"
-	ctypes "github.com/tendermint/tendermint/rpc/core/types"
 	tmtypes "github.com/tendermint/tendermint/types"
 )
 
@@ -40,479 +41,333 @@ const (
 
 //get account from /auth/accounts/{address}
 func GetAccount(rootDir, node, chainID, addr string) string {
-	key, err := sdk.AccAddressFromBech32(addr)
+	acc, err := QueryAccount(rootDir, node, chainID, addr)
 	if err != nil {
 		return err.Error()
 	}
-
-	//to be fixed, the trust-node was set true to passby the verifier function, need improvement
-	cliCtx := newCLIContext(rootDir, node, chainID).
-		WithCodec(cdc).
-		WithAccountDecoder(cdc).WithTrustNode(true)
-	//cliCtx := context.NewCLIContext().
-	//	WithCodec(cdc).WithAccountDecoder(cdc)
-
-	if err = cliCtx.EnsureAccountExistsFromAddr(key); err != nil {
-		return err.Error()
-	}
-
-	acc, err := cliCtx.GetAccount(key)
+	output, err := cdc.MarshalJSON(acc)
 	if err != nil {
 		return err.Error()
 	}
+	return string(output)
+}
 
-	var output []byte
-	if cliCtx.Indent {
-		output, err = cdc.MarshalJSONIndent(acc, "", "  ")
-	} else {
-		output, err = cdc.MarshalJSON(acc)
-	}
+//QueryAccount returns the account of addr
+func QueryAccount(rootDir, node, chainID, addr string) (auth.Account, error) {
+	key, err := sdk.AccAddressFromBech32(addr)
 	if err != nil {
-		return err.Error()
+		return nil, errcode.InvalidInput(err)
 	}
 
-	return string(output)
-
+	cliCtx := queryContext(rootDir, node, chainID)
+	if err = cliCtx.EnsureAccountExistsFromAddr(key); err != nil {
+		return nil, nodeError(err)
+	}
+	acc, err := cliCtx.GetAccount(key)
+	return acc, nodeError(err)
 }
 
 //complete the whole process with following sequence {Send coins (build -> sign -> send)}
 func Transfer(rootDir, node, chainID, fromName, password, toStr, coinStr, feeStr, broadcastMode string) string {
-	//get the Keybase
-	viper.Set(cli.HomeFlag, rootDir)
-	kb, err1 := keys.NewKeyBaseFromHomeFlag()
-	if err1 != nil {
-		fmt.Println(err1)
-	}
-	//SetKeyBase(rootDir)
-	//fromName generated from keyspace locally
-	if fromName == "" {
-		fmt.Println("no fromName input!")
-	}
-	var info cskeys.Info
-	var err error
-	info, err = kb.Get(fromName)
-	if err != nil {
-		fmt.Printf("could not find key %s\n", fromName)
-		os.Exit(1)
-	}
-
-	fromAddr := info.GetAddress()
-	cliCtx := newCLIContext(rootDir, node, chainID).
-		WithCodec(cdc).
-		WithAccountDecoder(cdc).WithTrustNode(true).WithBroadcastMode(broadcastMode)
-	if err := cliCtx.EnsureAccountExistsFromAddr(fromAddr); err != nil {
-		return err.Error()
-	}
+	res, err := SendCoins(rootDir, node, chainID, fromName, password, toStr, coinStr, feeStr, broadcastMode)
+	return txResponseOutput(res, err)
+}
 
-	to, err := sdk.AccAddressFromBech32(toStr)
+//SendCoins sends coinStr from the local key fromName to toStr and broadcasts it in broadcastMode
+func SendCoins(rootDir, node, chainID, fromName, password, toStr, coinStr, feeStr, broadcastMode string) (sdk.TxResponse, error) {
+	s, err := KeySigner(rootDir, fromName, password)
 	if err != nil {
-		return err.Error()
+		return sdk.TxResponse{}, err
 	}
+	return SendCoinsWithSigner(rootDir, node, chainID, s, toStr, coinStr, feeStr, broadcastMode)
+}
 
-	// parse coins trying to be sent
-	coins, err := sdk.ParseCoins(coinStr)
+//SendCoinsWithSigner is SendCoins signed by s, a key which may live outside of the wallet
+func SendCoinsWithSigner(rootDir, node, chainID string, s signer.Signer, toStr, coinStr, feeStr, broadcastMode string) (sdk.TxResponse, error) {
+	txBytes, err := SignTransferWithSigner(rootDir, node, chainID, s, toStr, coinStr, feeStr)
 	if err != nil {
-		return err.Error()
+		return sdk.TxResponse{}, err
 	}
+	// broadcast to a Tendermint node
+	return BroadcastTxBytes(rootDir, node, chainID, txBytes, broadcastMode)
+}
 
-	account, err := cliCtx.GetAccount(fromAddr)
-	if err != nil {
-		return err.Error()
-	}
+//do Delegate operation
+func Delegate(rootDir, node, chainID, delegatorName, password, delegatorAddr, validatorAddr, delegationCoinStr, feeStr, broadcastMode string) string {
+	res, err := DelegateCoins(rootDir, node, chainID, delegatorName, password, delegatorAddr, validatorAddr, delegationCoinStr, feeStr, broadcastMode)
+	return txResponseOutput(res, err)
+}
 
-	// ensure account has enough coins
-	if !account.GetCoins().IsAllGTE(coins) {
-		return fmt.Sprintf("Address %s doesn't have enough coins to pay for this transaction.", fromAddr)
+//DelegateCoins delegates delegationCoinStr of the local key delegatorName to validatorAddr
+func DelegateCoins(rootDir, node, chainID, delegatorName, password, delegatorAddr, validatorAddr, delegationCoinStr, feeStr, broadcastMode string) (sdk.TxResponse, error) {
+	s, err := ownDelegator(rootDir, delegatorName, password, delegatorAddr)
+	if err != nil {
+		return sdk.TxResponse{}, err
 	}
+	return DelegateCoinsWithSigner(rootDir, node, chainID, s, validatorAddr, delegationCoinStr, feeStr, broadcastMode)
+}
 
-	// build and sign the transaction, then broadcast to Tendermint
-	msg := bank.NewMsgSend(fromAddr, to, coins)
-
-	//init a txBuilder for the transaction with fee
-	txBldr := authtxb.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc)).WithFees(feeStr).WithChainID(chainID)
-	//txBldr := authtxb.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc)).WithGasPrices(feeStr).WithChainID(chainID)
-
-	//accNum added to txBldr
-	accNum, err := cliCtx.GetAccountNumber(fromAddr)
+//DelegateCoinsWithSigner is DelegateCoins signed by s, a key which may live outside of the wallet
+func DelegateCoinsWithSigner(rootDir, node, chainID string, s signer.Signer, validatorAddr, delegationCoinStr, feeStr, broadcastMode string) (sdk.TxResponse, error) {
+	DelegatorAddr, err := SignerAddress(s)
 	if err != nil {
-		return err.Error()
+		return sdk.TxResponse{}, err
 	}
-	txBldr = txBldr.WithAccountNumber(accNum)
 
-	//accSequence added
-	accSeq, err := cliCtx.GetAccountSequence(fromAddr)
+	//init a context for this delegate tx
+	cliCtx := broadcastContext(rootDir, node, chainID, broadcastMode)
+	msgs, err := delegateMsgs(cliCtx, DelegatorAddr, validatorAddr, delegationCoinStr)
 	if err != nil {
-		return err.Error()
+		return sdk.TxResponse{}, err
 	}
-	txBldr = txBldr.WithSequence(accSeq)
 
 	// build and sign the transaction
-	txBytes, err := txBldr.BuildAndSign(fromName, password, []sdk.Msg{msg})
+	txBytes, err := signTx(cliCtx, s, chainID, feeStr, DelegatorAddr, msgs)
 	if err != nil {
-		return err.Error()
+		return sdk.TxResponse{}, err
 	}
 	// broadcast to a Tendermint node
-	res, err := cliCtx.BroadcastTx(txBytes)
-	//tmhash to fetch the txhash before broadcast to chain
-	//txhash := tmhash.Sum(txBytes)
-	//fmt.Println(strings.ToUpper(hex.EncodeToString(txhash)))
-	if err != nil {
-		return err.Error()
-	}
-	resbyte, err := cdc.MarshalJSON(res)
-	if err != nil {
-		return err.Error()
-	}
-	return string(resbyte)
+	return broadcast(cliCtx, txBytes)
 }
 
-//do Delegate operation
-func Delegate(rootDir, node, chainID, delegatorName, password, delegatorAddr, validatorAddr, delegationCoinStr, feeStr, broadcastMode string) string {
-	//get the Keybase
-	viper.Set(cli.HomeFlag, rootDir)
-	kb, err1 := keys.NewKeyBaseFromHomeFlag()
-	if err1 != nil {
-		fmt.Println(err1)
-	}
-	//delegatorName generated from keyspace locally
-	if delegatorName == "" {
-		fmt.Println("no delegatorName input!")
-	}
-	info, err := kb.Get(delegatorName)
-	if err != nil {
-		return err.Error()
-	}
-	//checkout with rule of own deligation
-	DelegatorAddr, err := sdk.AccAddressFromBech32(delegatorAddr)
-	if err != nil {
-		return err.Error()
-	}
-	if !bytes.Equal(info.GetPubKey().Address(), DelegatorAddr) {
-		return fmt.Sprintf("Must use own delegator address")
-	}
-
-	//init a context for this delegate tx
-	cliCtx := newCLIContext(rootDir, node, chainID).
-		WithCodec(cdc).
-		WithAccountDecoder(cdc).WithTrustNode(true).WithBroadcastMode(broadcastMode)
+//delegateMsgs checks the delegation of DelegatorAddr and returns its messages
+func delegateMsgs(cliCtx context.CLIContext, DelegatorAddr sdk.AccAddress, validatorAddr, delegationCoinStr string) ([]sdk.Msg, error) {
 	if err := cliCtx.EnsureAccountExistsFromAddr(DelegatorAddr); err != nil {
-		return err.Error()
+		return nil, nodeError(err)
 	}
 
 	//validator to address type []byte
 	ValidatorAddr, err := sdk.ValAddressFromBech32(validatorAddr)
 	if err != nil {
-		return err.Error()
+		return nil, errcode.InvalidInput(err)
 	}
 
 	// parse coin from the delegation
 	Delegation, err := sdk.ParseCoin(delegationCoinStr)
 	if err != nil {
-		return err.Error()
+		return nil, errcode.InvalidInput(err)
 	}
 
 	//check out the account enough money for the delegation
 	account, err := cliCtx.GetAccount(DelegatorAddr)
 	if err != nil {
-		return err.Error()
+		return nil, nodeError(err)
 	}
 
 	DelegationToS := sdk.Coins{Delegation}
 	if !account.GetCoins().IsAllGTE(DelegationToS) {
-		return fmt.Sprintf("Delegator address %s doesn't have enough coins to perform this transaction.", delegatorAddr)
+		return nil, errcode.Errorf(errcode.CodeInsufficientFunds, "Delegator address %s doesn't have enough coins to perform this transaction.", DelegatorAddr)
 	}
 
 	//build the stake message
 	msg := staking.NewMsgDelegate(DelegatorAddr, ValidatorAddr, Delegation)
-	err = msg.ValidateBasic()
-	if err != nil {
-		return err.Error()
-	}
-
-	//sign the stake message
-	//init the txbldr
-	txBldr := authtxb.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc)).WithFees(feeStr).WithChainID(chainID)
-
-	//accNum added to txBldr
-	accNum, err := cliCtx.GetAccountNumber(DelegatorAddr)
-	if err != nil {
-		return err.Error()
+	if err := msg.ValidateBasic(); err != nil {
+		return nil, errcode.InvalidInput(err)
 	}
-	txBldr = txBldr.WithAccountNumber(accNum)
+	return []sdk.Msg{msg}, nil
+}
 
-	//accSequence added
-	accSeq, err := cliCtx.GetAccountSequence(DelegatorAddr)
+//get the delegation share under a specific validator
+func GetDelegationShares(rootDir, node, chainID, delegatorAddr, validatorAddr string) string {
+	delegation, err := QueryDelegation(rootDir, node, chainID, delegatorAddr, validatorAddr)
 	if err != nil {
 		return err.Error()
 	}
-	txBldr = txBldr.WithSequence(accSeq)
 
-	// build and sign the transaction
-	txBytes, err := txBldr.BuildAndSign(delegatorName, password, []sdk.Msg{msg})
-	if err != nil {
-		return err.Error()
-	}
-	// broadcast to a Tendermint node
-	res, err := cliCtx.BroadcastTx(txBytes)
-	if err != nil {
-		return err.Error()
-	}
-	resbyte, err := cdc.MarshalJSON(res)
+	//json output the result
+	output, err := codec.MarshalJSONIndent(cdc, delegation)
 	if err != nil {
 		return err.Error()
 	}
-	return string(resbyte)
-
+	return string(output)
 }
 
-//get the delegation share under a specific validator
-func GetDelegationShares(rootDir, node, chainID, delegatorAddr, validatorAddr string) string {
+//QueryDelegation returns the delegation of delegatorAddr to validatorAddr
+func QueryDelegation(rootDir, node, chainID, delegatorAddr, validatorAddr string) (staking.Delegation, error) {
 	//convert the delegator string address to sdk form
 	DelAddr, err := sdk.AccAddressFromBech32(delegatorAddr)
 	if err != nil {
-		return err.Error()
+		return staking.Delegation{}, errcode.InvalidInput(err)
 	}
 
 	//convert the validator string address to sdk form
 	ValAddr, err := sdk.ValAddressFromBech32(validatorAddr)
 	if err != nil {
-		return err.Error()
+		return staking.Delegation{}, errcode.InvalidInput(err)
 	}
 
-	//to be fixed, the trust-node was set true to passby the verifier function, need improvement
-	cliCtx := newCLIContext(rootDir, node, chainID).
-		WithCodec(cdc).
-		WithAccountDecoder(cdc).WithTrustNode(true)
+	cliCtx := queryContext(rootDir, node, chainID)
 	if err := cliCtx.EnsureAccountExistsFromAddr(DelAddr); err != nil {
-		return err.Error()
+		return staking.Delegation{}, nodeError(err)
 	}
 
 	// make a query to get the existing delegation shares
 	key := staking.GetDelegationKey(DelAddr, ValAddr)
 	res, err := cliCtx.QueryStore(key, storeStake)
 	if err != nil {
-		return err.Error()
+		return staking.Delegation{}, nodeError(err)
 	}
 
 	// parse out the delegation
-	delegation, err := types.UnmarshalDelegation(cdc, res)
-	if err != nil {
-		return err.Error()
-	}
-
-	//json output the result
-	output, err := codec.MarshalJSONIndent(cdc, delegation)
-	if err != nil {
-		return err.Error()
-	}
-
-	return string(output)
-
+	return types.UnmarshalDelegation(cdc, res)
 }
 
 //for unbond some of delegation shares from specific validator
 func UnbondingDelegation(rootDir, node, chainID, delegatorName, password, delegatorAddr, validatorAddr, Ubdshares, feeStr, broadcastMode string) string {
-	//build procedure
-	//get the Keybase
-	viper.Set(cli.HomeFlag, rootDir)
-	kb, err1 := keys.NewKeyBaseFromHomeFlag()
-	if err1 != nil {
-		fmt.Println(err1)
-	}
+	res, err := UndelegateCoins(rootDir, node, chainID, delegatorName, password, delegatorAddr, validatorAddr, Ubdshares, feeStr, broadcastMode)
+	return txResponseOutput(res, err)
+}
 
-	//delegatorName generated from keyspace locally
-	if delegatorName == "" {
-		fmt.Println("no delegatorName input!")
+//UndelegateCoins unbonds Ubdshares of the local key delegatorName from validatorAddr
+func UndelegateCoins(rootDir, node, chainID, delegatorName, password, delegatorAddr, validatorAddr, Ubdshares, feeStr, broadcastMode string) (sdk.TxResponse, error) {
+	s, err := ownDelegator(rootDir, delegatorName, password, delegatorAddr)
+	if err != nil {
+		return sdk.TxResponse{}, err
 	}
-	info, err := kb.Get(delegatorName)
+	return UndelegateCoinsWithSigner(rootDir, node, chainID, s, validatorAddr, Ubdshares, feeStr, broadcastMode)
+}
+
+//UndelegateCoinsWithSigner is UndelegateCoins signed by s, a key which may live outside of the wallet
+func UndelegateCoinsWithSigner(rootDir, node, chainID string, s signer.Signer, validatorAddr, Ubdshares, feeStr, broadcastMode string) (sdk.TxResponse, error) {
+	DelegatorAddr, err := SignerAddress(s)
 	if err != nil {
-		return err.Error()
+		return sdk.TxResponse{}, err
 	}
-	//checkout with rule of own deligation
-	DelegatorAddr, err := sdk.AccAddressFromBech32(delegatorAddr)
+
+	cliCtx := broadcastContext(rootDir, node, chainID, broadcastMode)
+	msgs, err := undelegateMsgs(cliCtx, DelegatorAddr, validatorAddr, Ubdshares)
 	if err != nil {
-		return err.Error()
+		return sdk.TxResponse{}, err
 	}
-	if !bytes.Equal(info.GetPubKey().Address(), DelegatorAddr) {
-		return fmt.Sprintf("Must use own delegator address")
+
+	//build-->sign-->broadcast
+	txBytes, err := signTx(cliCtx, s, chainID, feeStr, DelegatorAddr, msgs)
+	if err != nil {
+		return sdk.TxResponse{}, err
 	}
+	// broadcast to a Tendermint node
+	return broadcast(cliCtx, txBytes)
+}
 
-	////to be fixed, the trust-node was set true to passby the verifier function, need improvement
-	cliCtx := newCLIContext(rootDir, node, chainID).
-		WithCodec(cdc).
-		WithAccountDecoder(cdc).WithTrustNode(true).WithBroadcastMode(broadcastMode)
+//undelegateMsgs checks the unbonding of DelegatorAddr and returns its messages
+func undelegateMsgs(cliCtx context.CLIContext, DelegatorAddr sdk.AccAddress, validatorAddr, Ubdshares string) ([]sdk.Msg, error) {
 	if err := cliCtx.EnsureAccountExistsFromAddr(DelegatorAddr); err != nil {
-		return err.Error()
+		return nil, nodeError(err)
 	}
 
 	//validator to address type []byte
 	ValidatorAddr, err := sdk.ValAddressFromBech32(validatorAddr)
 	if err != nil {
-		return err.Error()
+		return nil, errcode.InvalidInput(err)
 	}
 
-	// make a query to get the existing delegation shares
-	//key := staking.GetDelegationKey(DelegatorAddr, ValidatorAddr)
-	//res, err := cliCtx.QueryStore(key, storeStake)
-	//if err != nil {
-	//	return err.Error()
-	//}
-	//
-	//// parse out the delegation
-	//delegation, err := types.UnmarshalDelegation(cdc, res)
-	//if err != nil {
-	//	return err.Error()
-	//}
-
 	//create the unbond message
-	//sharesAmount := delegation.Shares
 	sharesAmount, err := sdk.ParseCoin(Ubdshares)
 	if err != nil {
-		return err.Error()
-	}
-	msg := staking.NewMsgUndelegate(DelegatorAddr, ValidatorAddr, sharesAmount)
-
-	//build-->sign-->broadcast
-	//sign the stake message
-	//init the txbldr
-	txBldr := authtxb.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc)).WithFees(feeStr).WithChainID(chainID)
-
-	//accNum added to txBldr
-	accNum, err := cliCtx.GetAccountNumber(DelegatorAddr)
-	if err != nil {
-		return err.Error()
+		return nil, errcode.InvalidInput(err)
 	}
-	txBldr = txBldr.WithAccountNumber(accNum)
+	return []sdk.Msg{staking.NewMsgUndelegate(DelegatorAddr, ValidatorAddr, sharesAmount)}, nil
+}
 
-	//accSequence added
-	accSeq, err := cliCtx.GetAccountSequence(DelegatorAddr)
+//get all unbonding delegations from a specific delegator
+func GetAllUnbondingDelegations(rootDir, node, chainID, delegatorAddr string) string {
+	ubds, err := QueryUnbondingDelegations(rootDir, node, chainID, delegatorAddr)
 	if err != nil {
 		return err.Error()
 	}
-	txBldr = txBldr.WithSequence(accSeq)
 
-	// build and sign the transaction
-	txBytes, err := txBldr.BuildAndSign(delegatorName, password, []sdk.Msg{msg})
-	if err != nil {
-		return err.Error()
-	}
-	// broadcast to a Tendermint node
-	resb, err := cliCtx.BroadcastTx(txBytes)
-	if err != nil {
-		return err.Error()
-	}
-	resbyte, err := cdc.MarshalJSON(resb)
+	//json output the result
+	output, err := codec.MarshalJSONIndent(cdc, ubds)
 	if err != nil {
 		return err.Error()
 	}
-	return string(resbyte)
-
+	return string(output)
 }
 
-//get all unbonding delegations from a specific delegator
-func GetAllUnbondingDelegations(rootDir, node, chainID, delegatorAddr string) string {
+//QueryUnbondingDelegations returns all unbonding delegations of delegatorAddr
+func QueryUnbondingDelegations(rootDir, node, chainID, delegatorAddr string) (staking.UnbondingDelegations, error) {
 	//convert the delegator string address to sdk form
 	DelAddr, err := sdk.AccAddressFromBech32(delegatorAddr)
 	if err != nil {
-		return err.Error()
+		return nil, errcode.InvalidInput(err)
 	}
 
-	//to be fixed, the trust-node was set true to passby the verifier function, need improvement
-	cliCtx := newCLIContext(rootDir, node, chainID).
-		WithCodec(cdc).WithTrustNode(true)
-
+	cliCtx := queryContext(rootDir, node, chainID)
 	resKVs, err := cliCtx.QuerySubspace(staking.GetUBDsKey(DelAddr), storeStake)
 	if err != nil {
-		return err.Error()
+		return nil, nodeError(err)
 	}
 
 	var ubds staking.UnbondingDelegations
 	for _, kv := range resKVs {
-		ubds = append(ubds, types.MustUnmarshalUBD(cdc, kv.Value))
+		ubd, err := types.UnmarshalUBD(cdc, kv.Value)
+		if err != nil {
+			return nil, err
+		}
+		ubds = append(ubds, ubd)
 	}
+	return ubds, nil
+}
 
-	//json output the result
-	output, err := codec.MarshalJSONIndent(cdc, ubds)
+//Get bonded validators
+func GetBondValidators(rootDir, node, chainID, delegatorAddr string) string {
+	validplus, err := QueryBondedValidators(rootDir, node, chainID, delegatorAddr)
+	if err != nil {
+		return err.Error()
+	}
+	//return specific info if there is no delegation between them
+	if len(validplus) == 0 {
+		return fmt.Sprintf("None of validators delegated!")
+	}
+
+	output, err := cdc.MarshalJSON(validplus)
 	if err != nil {
 		return err.Error()
 	}
 	return string(output)
 }
 
-//Get bonded validators
-func GetBondValidators(rootDir, node, chainID, delegatorAddr string) string {
+//QueryBondedValidators returns the validators delegatorAddr delegated to, with their self bond shares
+func QueryBondedValidators(rootDir, node, chainID, delegatorAddr string) ([]ValidPlus, error) {
 	//convert the delegator string address to sdk form
 	DelAddr, err := sdk.AccAddressFromBech32(delegatorAddr)
 	if err != nil {
-		return err.Error()
+		return nil, errcode.InvalidInput(err)
 	}
 
 	//generate paras for next query
 	params := staking.NewQueryDelegatorParams(DelAddr)
 	bz, err := cdc.MarshalJSON(params)
 	if err != nil {
-		return err.Error()
+		return nil, err
 	}
 
-	//to be fixed, the trust-node was set true to passby the verifier function, need improvement
-	cliCtx := newCLIContext(rootDir, node, chainID).
-		WithCodec(cdc).WithTrustNode(true)
+	cliCtx := queryContext(rootDir, node, chainID)
 
 	//query with data
 	valids, err := cliCtx.QueryWithData("custom/staking/delegatorValidators", bz)
-	//return specific info if there is no delegation between them
-	//fmt.Println(valids)
-	if len(valids) <= 2 {
-		return fmt.Sprintf("None of validators delegated!")
-	}
 	if err != nil {
-		return err.Error()
+		return nil, nodeError(err)
+	}
+	//none of validators delegated
+	if len(valids) <= 2 {
+		return nil, nil
 	}
 
 	var validators []staking.Validator
 	if err := cdc.UnmarshalJSON(valids, &validators); err != nil {
-		return err.Error()
+		return nil, err
 	}
 
 	var validplus []ValidPlus
 	for _, valid := range validators {
-		valAddr := valid.GetOperator()
-		bz := valAddr.Bytes()
-		//var accAddr sdk.AccAddress
-		accAddr := sdk.AccAddress(bz)
-		//cdc.MustUnmarshalJSON(bz,&accAddr)
-		// make a query to get the existing delegation shares
-		key := staking.GetDelegationKey(accAddr, valAddr)
-		res, err := cliCtx.QueryStore(key, storeStake)
-		if err != nil {
-			return err.Error()
-		}
-
-		var validp ValidPlus
-		// parse out the delegation
-		delegation, err := types.UnmarshalDelegation(cdc, res)
+		validp, err := withSelfBondShares(cliCtx, valid)
 		if err != nil {
-			sharesAmount := "0"
-			validp = ValidPlus{
-				valid,
-				sharesAmount,
-			}
-		} else {
-			sharesAmount := delegation.Shares.String()
-			validp = ValidPlus{
-				valid,
-				sharesAmount,
-			}
-
+			return nil, err
 		}
 		validplus = append(validplus, validp)
 	}
-
-	output, err := cdc.MarshalJSON(validplus)
-	if err != nil {
-		return err.Error()
-	}
-	return string(output)
+	return validplus, nil
 }
 
 type ValidPlus struct {
@@ -520,276 +375,297 @@ type ValidPlus struct {
 	SelfBondShares string            `json:"selfbond_shares"`
 }
 
-//get all the validators
-func GetAllValidators(rootDir, node, chainID string) string {
-	key := staking.ValidatorsKey
-	//to be fixed, the trust-node was set true to passby the verifier function, need improvement
-	cliCtx := newCLIContext(rootDir, node, chainID).
-		WithCodec(cdc).WithTrustNode(true)
+//withSelfBondShares attaches the self delegation shares of the validator, "0" if there is none
+func withSelfBondShares(cliCtx context.CLIContext, valid staking.Validator) (ValidPlus, error) {
+	valAddr := valid.OperatorAddress
+	accAddr := sdk.AccAddress(valAddr.Bytes())
+	// make a query to get the existing delegation shares
+	key := staking.GetDelegationKey(accAddr, valAddr)
+	res, err := cliCtx.QueryStore(key, storeStake)
+	if err != nil {
+		return ValidPlus{}, nodeError(err)
+	}
 
-	resKVs, err := cliCtx.QuerySubspace(key, storeStake)
+	// parse out the delegation
+	delegation, err := types.UnmarshalDelegation(cdc, res)
 	if err != nil {
-		return err.Error()
+		return ValidPlus{valid, "0"}, nil
+	}
+	return ValidPlus{valid, delegation.Shares.String()}, nil
+}
+
+//get all the validators
+func GetAllValidators(rootDir, node, chainID string) string {
+	validplus, err := QueryValidators(rootDir, node, chainID)
+	if err != nil {
+		return err.Error()
+	}
+
+	output, err := cdc.MarshalJSON(validplus)
+	if err != nil {
+		return err.Error()
+	}
+	return string(output)
+}
+
+//QueryValidators returns the validators with tendermint power of at least 1, with their self bond shares
+func QueryValidators(rootDir, node, chainID string) ([]ValidPlus, error) {
+	key := staking.ValidatorsKey
+	cliCtx := queryContext(rootDir, node, chainID)
+
+	resKVs, err := cliCtx.QuerySubspace(key, storeStake)
+	if err != nil {
+		return nil, nodeError(err)
 	}
 
 	var validplus []ValidPlus
 	for _, kv := range resKVs {
-		//validators = append(validators, types.MustUnmarshalValidator(cdc, kv.Value))
-
 		//fetch the validator info from the key
-
-		valid := types.MustUnmarshalValidator(cdc, kv.Value)
-		valAddr := valid.OperatorAddress
-		bz := valAddr.Bytes()
-		//var accAddr sdk.AccAddress
-		accAddr := sdk.AccAddress(bz)
-		//cdc.MustUnmarshalJSON(bz,&accAddr)
-		// make a query to get the existing delegation shares
-		key := staking.GetDelegationKey(accAddr, valAddr)
-		res, err := cliCtx.QueryStore(key, storeStake)
+		valid, err := types.UnmarshalValidator(cdc, kv.Value)
 		if err != nil {
-			return err.Error()
+			return nil, err
 		}
-
-		var validp ValidPlus
-		// parse out the delegation
-		delegation, err := types.UnmarshalDelegation(cdc, res)
+		validp, err := withSelfBondShares(cliCtx, valid)
 		if err != nil {
-			sharesAmount := "0"
-			validp = ValidPlus{
-				valid,
-				sharesAmount,
-			}
-		} else {
-			sharesAmount := delegation.Shares.String()
-			validp = ValidPlus{
-				valid,
-				sharesAmount,
-			}
-
+			return nil, err
 		}
 		//add the checkout for tendermint power more than 1
 		if validp.Validator.Tokens.GTE(sdk.NewInt(int64(1000000))) {
 			validplus = append(validplus, validp)
 		}
+	}
+	return validplus, nil
+}
 
+//get all delegations from the delegator
+func GetAllDelegations(rootDir, node, chainID, delegatorAddr string) string {
+	delegations, err := QueryDelegations(rootDir, node, chainID, delegatorAddr)
+	if err != nil {
+		return err.Error()
 	}
 
-	output, err := cdc.MarshalJSON(validplus)
+	output, err := codec.MarshalJSONIndent(cdc, delegations)
 	if err != nil {
 		return err.Error()
 	}
 	return string(output)
 }
 
-//get all delegations from the delegator
-func GetAllDelegations(rootDir, node, chainID, delegatorAddr string) string {
+//QueryDelegations returns all delegations of delegatorAddr
+func QueryDelegations(rootDir, node, chainID, delegatorAddr string) (staking.Delegations, error) {
 	//convert the delegator string address to sdk form
 	DelAddr, err := sdk.AccAddressFromBech32(delegatorAddr)
 	if err != nil {
-		return err.Error()
+		return nil, errcode.InvalidInput(err)
 	}
 
 	key := staking.GetDelegationsKey(DelAddr)
-	//to be fixed, the trust-node was set true to passby the verifier function, need improvement
-	cliCtx := newCLIContext(rootDir, node, chainID).
-		WithCodec(cdc).WithTrustNode(true)
+	cliCtx := queryContext(rootDir, node, chainID)
 
 	resKVs, err := cliCtx.QuerySubspace(key, storeStake)
 	if err != nil {
-		return err.Error()
+		return nil, nodeError(err)
 	}
 
 	// parse out the delegations
 	var delegations staking.Delegations
 	for _, kv := range resKVs {
-		delegations = append(delegations, types.MustUnmarshalDelegation(cdc, kv.Value))
-	}
-
-	output, err := codec.MarshalJSONIndent(cdc, delegations)
-	if err != nil {
-		return err.Error()
+		delegation, err := types.UnmarshalDelegation(cdc, kv.Value)
+		if err != nil {
+			return nil, err
+		}
+		delegations = append(delegations, delegation)
 	}
-
-	return string(output)
+	return delegations, nil
 }
 
 //Withdraw rewards from a specific validator
 func WithdrawDelegationReward(rootDir, node, chainID, delegatorName, password, delegatorAddr, validatorAddr, feeStr, broadcastMode string) string {
-	//build procedure
-	//get the Keybase
-	viper.Set(cli.HomeFlag, rootDir)
-	kb, err1 := keys.NewKeyBaseFromHomeFlag()
-	if err1 != nil {
-		fmt.Println(err1)
-	}
+	res, err := WithdrawReward(rootDir, node, chainID, delegatorName, password, delegatorAddr, validatorAddr, feeStr, broadcastMode)
+	return txResponseOutput(res, err)
+}
 
-	//delegatorName generated from keyspace locally
-	if delegatorName == "" {
-		fmt.Println("no delegatorName input!")
+//WithdrawReward withdraws the rewards of the local key delegatorName from validatorAddr
+func WithdrawReward(rootDir, node, chainID, delegatorName, password, delegatorAddr, validatorAddr, feeStr, broadcastMode string) (sdk.TxResponse, error) {
+	s, err := ownDelegator(rootDir, delegatorName, password, delegatorAddr)
+	if err != nil {
+		return sdk.TxResponse{}, err
 	}
-	info, err := kb.Get(delegatorName)
+	return WithdrawRewardWithSigner(rootDir, node, chainID, s, validatorAddr, feeStr, broadcastMode)
+}
+
+//WithdrawRewardWithSigner is WithdrawReward signed by s, a key which may live outside of the wallet
+func WithdrawRewardWithSigner(rootDir, node, chainID string, s signer.Signer, validatorAddr, feeStr, broadcastMode string) (sdk.TxResponse, error) {
+	DelegatorAddr, err := SignerAddress(s)
 	if err != nil {
-		return err.Error()
+		return sdk.TxResponse{}, err
 	}
-	//checkout with rule of own deligation
-	DelegatorAddr, err := sdk.AccAddressFromBech32(delegatorAddr)
+
+	cliCtx := broadcastContext(rootDir, node, chainID, broadcastMode)
+	msgs, err := withdrawRewardMsgs(cliCtx, DelegatorAddr, validatorAddr)
 	if err != nil {
-		return err.Error()
+		return sdk.TxResponse{}, err
 	}
-	if !bytes.Equal(info.GetPubKey().Address(), DelegatorAddr) {
-		return fmt.Sprintf("Must use own delegator address")
+
+	//build-->sign-->broadcast
+	txBytes, err := signTx(cliCtx, s, chainID, feeStr, DelegatorAddr, msgs)
+	if err != nil {
+		return sdk.TxResponse{}, err
 	}
+	// broadcast to a Tendermint node
+	return broadcast(cliCtx, txBytes)
+}
 
-	////to be fixed, the trust-node was set true to passby the verifier function, need improvement
-	cliCtx := newCLIContext(rootDir, node, chainID).
-		WithCodec(cdc).
-		WithAccountDecoder(cdc).WithTrustNode(true).WithBroadcastMode(broadcastMode)
+//withdrawRewardMsgs checks the withdrawal of DelegatorAddr from validatorAddr and returns its messages
+func withdrawRewardMsgs(cliCtx context.CLIContext, DelegatorAddr sdk.AccAddress, validatorAddr string) ([]sdk.Msg, error) {
 	if err := cliCtx.EnsureAccountExistsFromAddr(DelegatorAddr); err != nil {
-		return err.Error()
+		return nil, nodeError(err)
 	}
 
 	//validator to address type []byte
 	ValidatorAddr, err := sdk.ValAddressFromBech32(validatorAddr)
 	if err != nil {
-		return err.Error()
+		return nil, errcode.InvalidInput(err)
 	}
 
 	//generate messages betweeb delegator and validator
-	msgs := []sdk.Msg{distritypes.NewMsgWithdrawDelegatorReward(DelegatorAddr, ValidatorAddr)}
-
-	//build-->sign-->broadcast
-	//sign the stake message
-	//init the txbldr
-	txBldr := authtxb.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc)).WithFees(feeStr).WithChainID(chainID)
-
-	//accNum added to txBldr
-	accNum, err := cliCtx.GetAccountNumber(DelegatorAddr)
-	if err != nil {
-		return err.Error()
-	}
-	txBldr = txBldr.WithAccountNumber(accNum)
+	return []sdk.Msg{distritypes.NewMsgWithdrawDelegatorReward(DelegatorAddr, ValidatorAddr)}, nil
+}
 
-	//accSequence added
-	accSeq, err := cliCtx.GetAccountSequence(DelegatorAddr)
+//get a delegation reward between delegator and validator
+func GetDelegationRewards(rootDir, node, chainID, delegatorAddr, validatorAddr string) string {
+	result, err := QueryDelegationRewards(rootDir, node, chainID, delegatorAddr, validatorAddr)
 	if err != nil {
 		return err.Error()
 	}
-	txBldr = txBldr.WithSequence(accSeq)
 
-	// build and sign the transaction
-	txBytes, err := txBldr.BuildAndSign(delegatorName, password, msgs)
-	if err != nil {
-		return err.Error()
-	}
-	// broadcast to a Tendermint node
-	resb, err := cliCtx.BroadcastTx(txBytes)
-	if err != nil {
-		return err.Error()
-	}
-	resbyte, err := cdc.MarshalJSON(resb)
+	resbyte, err := cdc.MarshalJSON(result)
 	if err != nil {
 		return err.Error()
 	}
 	return string(resbyte)
-
 }
 
-//get a delegation reward between delegator and validator
-func GetDelegationRewards(rootDir, node, chainID, delegatorAddr, validatorAddr string) string {
+//QueryDelegationRewards returns the outstanding rewards of delegatorAddr from validatorAddr
+func QueryDelegationRewards(rootDir, node, chainID, delegatorAddr, validatorAddr string) (sdk.DecCoins, error) {
 	//convert the delegator string address to sdk form
 	DelAddr, err := sdk.AccAddressFromBech32(delegatorAddr)
 	if err != nil {
-		return err.Error()
+		return nil, errcode.InvalidInput(err)
 	}
 
 	//convert the validator string address to sdk form
 	ValAddr, err := sdk.ValAddressFromBech32(validatorAddr)
 	if err != nil {
-		return err.Error()
+		return nil, errcode.InvalidInput(err)
 	}
 
-	//to be fixed, the trust-node was set true to passby the verifier function, need improvement
-	cliCtx := newCLIContext(rootDir, node, chainID).
-		WithCodec(cdc).
-		WithAccountDecoder(cdc).WithTrustNode(true)
+	cliCtx := queryContext(rootDir, node, chainID)
 	if err := cliCtx.EnsureAccountExistsFromAddr(DelAddr); err != nil {
-		return err.Error()
+		return nil, nodeError(err)
 	}
+	return queryRewards(cliCtx, DelAddr, ValAddr)
+}
 
+func queryRewards(cliCtx context.CLIContext, DelAddr sdk.AccAddress, ValAddr sdk.ValAddress) (sdk.DecCoins, error) {
 	//query the delegation rewards
 	resp, err := cliCtx.QueryWithData("custom/distr/delegation_rewards", cdc.MustMarshalJSON(distr.NewQueryDelegationRewardsParams(DelAddr, ValAddr)))
 	if err != nil {
-		return err.Error()
+		return nil, nodeError(err)
 	}
 
 	var result sdk.DecCoins
-	cdc.MustUnmarshalJSON(resp, &result)
+	if err := cdc.UnmarshalJSON(resp, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
 
-	resbyte, err := cdc.MarshalJSON(result)
+func QueryTx(rootDir, Node, chainID, Txhash string) string {
+	info, err := GetTx(rootDir, Node, chainID, Txhash)
 	if err != nil {
 		return err.Error()
 	}
-	return string(resbyte)
+
+	//json output the result
+	resp, _ := cdc.MarshalJSON(info)
+	return string(resp)
 }
 
-func QueryTx(rootDir, Node, chainID, Txhash string) string {
-	cliCtx := newCLIContext(rootDir, Node, chainID).
-		WithCodec(cdc).
-		WithAccountDecoder(cdc).WithTrustNode(true)
+//GetTx returns the tx of the hex hash Txhash with its result and block time
+func GetTx(rootDir, Node, chainID, Txhash string) (sdk.TxResponse, error) {
+	cliCtx := queryContext(rootDir, Node, chainID)
 	hash, err := hex.DecodeString(Txhash)
 	if err != nil {
-		return err.Error()
+		return sdk.TxResponse{}, errcode.InvalidInput(err)
 	}
 
 	node, err := cliCtx.GetNode()
 	if err != nil {
-		return err.Error()
+		return sdk.TxResponse{}, nodeError(err)
 	}
 
 	resTx, err := node.Tx(hash, !cliCtx.TrustNode)
 	if err != nil {
-		return err.Error()
+		return sdk.TxResponse{}, nodeError(err)
 	}
 
-	//get the resBlocks
-	resTxs := []*ctypes.ResultTx{resTx}
-	resBlocks := make(map[int64]*ctypes.ResultBlock)
-	for _, resTx := range resTxs {
-		if _, ok := resBlocks[resTx.Height]; !ok {
-			resBlock, err := node.Block(&resTx.Height)
-			if err != nil {
-				return err.Error()
-			}
-
-			resBlocks[resTx.Height] = resBlock
+	//get the header for the tx time, verified with the tx in the verified mode
+	var header tmtypes.Header
+	if cliCtx.TrustNode {
+		resBlock, err := node.Block(&resTx.Height)
+		if err != nil {
+			return sdk.TxResponse{}, nodeError(err)
+		}
+		header = resBlock.Block.Header
+	} else {
+		if !bytes.Equal(resTx.Tx.Hash(), hash) {
+			return sdk.TxResponse{}, errcode.Errorf(errcode.CodeUnverified, "got tx %s, want %s", resTx.Hash, Txhash)
 		}
+		check, err := trust.NewVerifier(trustDir(rootDir), chainID, node).VerifyTx(resTx)
+		if err != nil {
+			return sdk.TxResponse{}, err
+		}
+		header = *check.Header
 	}
 
 	//parse Tx
 	var tx auth.StdTx
-	errz := cdc.UnmarshalBinaryLengthPrefixed(resTx.Tx, &tx)
-	if errz != nil {
-		return errz.Error()
+	if err := cdc.UnmarshalBinaryLengthPrefixed(resTx.Tx, &tx); err != nil {
+		return sdk.TxResponse{}, err
 	}
 
 	//format Tx result
-	info := sdk.NewResponseResultTx(resTx, tx, resBlocks[resTx.Height].Block.Time.Format(time.RFC3339))
-
-	//json output the result
-	resp, _ := cdc.MarshalJSON(info)
-	return string(resp)
-
+	return sdk.NewResponseResultTx(resTx, tx, header.Time.Format(time.RFC3339)), nil
 }
 
 //get validator self bond shares
 func GetValSelfBondShares(rootDir, node, chainID, validatorAddr string) string {
 	//get the delegator string address from validatorAddr as self delegation
-	_, valb, _ := bech32.DecodeAndConvert(validatorAddr)
-	delegatorAddr, _ := bech32.ConvertAndEncode("cosmos", valb)
+	delegatorAddr, err := selfDelegatorAddr(validatorAddr)
+	if err != nil {
+		return err.Error()
+	}
 	return GetDelegationShares(rootDir, node, chainID, delegatorAddr, validatorAddr)
 }
 
+//QueryValidatorSelfDelegation returns the self delegation of validatorAddr
+func QueryValidatorSelfDelegation(rootDir, node, chainID, validatorAddr string) (staking.Delegation, error) {
+	delegatorAddr, err := selfDelegatorAddr(validatorAddr)
+	if err != nil {
+		return staking.Delegation{}, err
+	}
+	return QueryDelegation(rootDir, node, chainID, delegatorAddr, validatorAddr)
+}
+
+func selfDelegatorAddr(validatorAddr string) (string, error) {
+	_, valb, err := bech32.DecodeAndConvert(validatorAddr)
+	if err != nil {
+		return "", errcode.InvalidInput(err)
+	}
+	return bech32.ConvertAndEncode("cosmos", valb)
+}
+
 //rewardcoins type sdk.Coins
 type Delrewards struct {
 	RewardsCoins  sdk.DecCoins   `json:"rewards_coins"`
@@ -799,115 +675,119 @@ type Delrewards struct {
 
 //get all the delegation awards list including delegation ties
 func GetDelegtorRewardsShares(rootDir, node, chainID, delegatorAddr string) string {
+	delrews, err := QueryDelegatorRewards(rootDir, node, chainID, delegatorAddr)
+	if err != nil {
+		return err.Error()
+	}
+	respbyte, err := cdc.MarshalJSON(delrews)
+	if err != nil {
+		return err.Error()
+	}
+	return string(respbyte)
+}
+
+//QueryDelegatorRewards returns the rewards and shares of delegatorAddr on every validator it delegated to
+func QueryDelegatorRewards(rootDir, node, chainID, delegatorAddr string) ([]Delrewards, error) {
 	//convert the delegator string address to sdk form
 	DelAddr, err := sdk.AccAddressFromBech32(delegatorAddr)
 	if err != nil {
-		return err.Error()
+		return nil, errcode.InvalidInput(err)
 	}
 
-	//to be fixed, the trust-node was set true to passby the verifier function, need improvement
-	cliCtx := newCLIContext(rootDir, node, chainID).
-		WithCodec(cdc).
-		WithAccountDecoder(cdc).WithTrustNode(true)
+	cliCtx := queryContext(rootDir, node, chainID)
 	if err := cliCtx.EnsureAccountExistsFromAddr(DelAddr); err != nil {
-		return err.Error()
+		return nil, nodeError(err)
 	}
 
 	//get all the validators with delegation of the specific delegator
-	ValAddrs, err := cliCtx.QueryWithData("custom/distr/delegator_validators", cdc.MustMarshalJSON(distr.NewQueryDelegatorParams(DelAddr)))
+	validators, err := delegatorValidators(cliCtx, DelAddr)
 	if err != nil {
-		return err.Error()
-	}
-	var validators []sdk.ValAddress
-	if err := cdc.UnmarshalJSON(ValAddrs, &validators); err != nil {
-		return err.Error()
+		return nil, err
 	}
 
 	var delrews []Delrewards
-	//query the delegation rewards
 	for _, valAddr := range validators {
-		rewards, err := cliCtx.QueryWithData("custom/distr/delegation_rewards", cdc.MustMarshalJSON(distr.NewQueryDelegationRewardsParams(DelAddr, valAddr)))
+		rewardsresult, err := queryRewards(cliCtx, DelAddr, valAddr)
 		if err != nil {
-			return err.Error()
+			return nil, err
 		}
-		var rewardsresult sdk.DecCoins
-		cdc.MustUnmarshalJSON(rewards, &rewardsresult)
 
 		// make a query to get the existing delegation shares
 		key := staking.GetDelegationKey(DelAddr, valAddr)
 		res, err := cliCtx.QueryStore(key, storeStake)
 		if err != nil {
-			return err.Error()
+			return nil, nodeError(err)
 		}
 
 		// parse out the delegation
 		delegation, err := types.UnmarshalDelegation(cdc, res)
 		if err != nil {
-			return err.Error()
+			return nil, err
 		}
 
-		//create the unbond message
-		sharesAmount := delegation.Shares
-
-		delrew := Delrewards{
-			rewardsresult,
-			sharesAmount,
-			valAddr,
-		}
-
-		delrews = append(delrews, delrew)
-
+		delrews = append(delrews, Delrewards{rewardsresult, delegation.Shares, valAddr})
 	}
-	respbyte, err := cdc.MarshalJSON(delrews)
+	return delrews, nil
+}
+
+func delegatorValidators(cliCtx context.CLIContext, DelAddr sdk.AccAddress) ([]sdk.ValAddress, error) {
+	ValAddrs, err := cliCtx.QueryWithData("custom/distr/delegator_validators", cdc.MustMarshalJSON(distr.NewQueryDelegatorParams(DelAddr)))
 	if err != nil {
-		return err.Error()
+		return nil, nodeError(err)
 	}
-	return string(respbyte)
-
+	var validators []sdk.ValAddress
+	if err := cdc.UnmarshalJSON(ValAddrs, &validators); err != nil {
+		return nil, err
+	}
+	return validators, nil
 }
 
 func WithdrawDelegatorAllRewards(rootDir, node, chainID, delegatorName, password, delegatorAddr, feeStr, broadcastMode string) string {
-	//build procedure
-	//get the Keybase
-	viper.Set(cli.HomeFlag, rootDir)
-	kb, err1 := keys.NewKeyBaseFromHomeFlag()
-	if err1 != nil {
-		fmt.Println(err1)
-	}
+	res, err := WithdrawAllRewards(rootDir, node, chainID, delegatorName, password, delegatorAddr, feeStr, broadcastMode)
+	return txResponseOutput(res, err)
+}
 
-	//delegatorName generated from keyspace locally
-	if delegatorName == "" {
-		fmt.Println("no delegatorName input!")
+//WithdrawAllRewards withdraws the rewards of the local key delegatorName from all its validators in one tx
+func WithdrawAllRewards(rootDir, node, chainID, delegatorName, password, delegatorAddr, feeStr, broadcastMode string) (sdk.TxResponse, error) {
+	s, err := ownDelegator(rootDir, delegatorName, password, delegatorAddr)
+	if err != nil {
+		return sdk.TxResponse{}, err
 	}
-	info, err := kb.Get(delegatorName)
+	return WithdrawAllRewardsWithSigner(rootDir, node, chainID, s, feeStr, broadcastMode)
+}
+
+//WithdrawAllRewardsWithSigner is WithdrawAllRewards signed by s, a key which may live outside of the wallet
+func WithdrawAllRewardsWithSigner(rootDir, node, chainID string, s signer.Signer, feeStr, broadcastMode string) (sdk.TxResponse, error) {
+	DelAddr, err := SignerAddress(s)
 	if err != nil {
-		return err.Error()
+		return sdk.TxResponse{}, err
 	}
-	//checkout with rule of own deligation
-	DelAddr, err := sdk.AccAddressFromBech32(delegatorAddr)
+
+	cliCtx := broadcastContext(rootDir, node, chainID, broadcastMode)
+	msgs, err := withdrawAllRewardsMsgs(cliCtx, DelAddr)
 	if err != nil {
-		return err.Error()
+		return sdk.TxResponse{}, err
 	}
-	if !bytes.Equal(info.GetPubKey().Address(), DelAddr) {
-		return fmt.Sprintf("Must use own delegator address")
+
+	//build-->sign-->broadcast
+	txBytes, err := signTx(cliCtx, s, chainID, feeStr, DelAddr, msgs)
+	if err != nil {
+		return sdk.TxResponse{}, err
 	}
+	// broadcast to a Tendermint node
+	return broadcast(cliCtx, txBytes)
+}
 
-	//to be fixed, the trust-node was set true to passby the verifier function, need improvement
-	cliCtx := newCLIContext(rootDir, node, chainID).
-		WithCodec(cdc).
-		WithAccountDecoder(cdc).WithTrustNode(true).WithBroadcastMode(broadcastMode)
+//withdrawAllRewardsMsgs returns the withdrawal messages of DelAddr from all its validators
+func withdrawAllRewardsMsgs(cliCtx context.CLIContext, DelAddr sdk.AccAddress) ([]sdk.Msg, error) {
 	if err := cliCtx.EnsureAccountExistsFromAddr(DelAddr); err != nil {
-		return err.Error()
+		return nil, nodeError(err)
 	}
 
 	//get all the validators with delegation of the specific delegator
-	ValAddrs, err := cliCtx.QueryWithData("custom/distr/delegator_validators", cdc.MustMarshalJSON(distr.NewQueryDelegatorParams(DelAddr)))
+	validators, err := delegatorValidators(cliCtx, DelAddr)
 	if err != nil {
-		return err.Error()
-	}
-	var validators []sdk.ValAddress
-	if err := cdc.UnmarshalJSON(ValAddrs, &validators); err != nil {
-		return err.Error()
+		return nil, err
 	}
 
 	// build multi-message transaction
@@ -915,314 +795,309 @@ func WithdrawDelegatorAllRewards(rootDir, node, chainID, delegatorName, password
 	for _, valAddr := range validators {
 		msg := distr.NewMsgWithdrawDelegatorReward(DelAddr, valAddr)
 		if err := msg.ValidateBasic(); err != nil {
-			return err.Error()
+			return nil, errcode.InvalidInput(err)
 		}
 		msgs = append(msgs, msg)
 	}
+	return msgs, nil
+}
 
-	//build-->sign-->broadcast
-	//sign the stake message
-	//init the txbldr
-	txBldr := authtxb.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc)).WithFees(feeStr).WithChainID(chainID)
-
-	//accNum added to txBldr
-	accNum, err := cliCtx.GetAccountNumber(DelAddr)
+//Only partial process with following sequence {Send coins (build -> sign -> Not send)}
+func TransferB4send(rootDir, node, chainID, fromName, password, toStr, coinStr, feeStr string) string {
+	txBytes, err := SignTransfer(rootDir, node, chainID, fromName, password, toStr, coinStr, feeStr)
 	if err != nil {
 		return err.Error()
 	}
-	txBldr = txBldr.WithAccountNumber(accNum)
+	return string(hex.EncodeToString(txBytes))
+}
 
-	//accSequence added
-	accSeq, err := cliCtx.GetAccountSequence(DelAddr)
+//SignTransfer returns the signed transfer tx bytes without broadcasting them
+func SignTransfer(rootDir, node, chainID, fromName, password, toStr, coinStr, feeStr string) ([]byte, error) {
+	s, err := KeySigner(rootDir, fromName, password)
 	if err != nil {
-		return err.Error()
+		return nil, err
 	}
-	txBldr = txBldr.WithSequence(accSeq)
+	return SignTransferWithSigner(rootDir, node, chainID, s, toStr, coinStr, feeStr)
+}
 
-	// build and sign the transaction
-	txBytes, err := txBldr.BuildAndSign(delegatorName, password, msgs)
+//SignTransferWithSigner is SignTransfer signed by s
+func SignTransferWithSigner(rootDir, node, chainID string, s signer.Signer, toStr, coinStr, feeStr string) ([]byte, error) {
+	fromAddr, err := SignerAddress(s)
 	if err != nil {
-		return err.Error()
+		return nil, err
 	}
-	// broadcast to a Tendermint node
-	resb, err := cliCtx.BroadcastTx(txBytes)
+	txBldr, msg, err := prepareTransfer(rootDir, node, chainID, fromAddr, toStr, coinStr, feeStr)
 	if err != nil {
-		return err.Error()
+		return nil, err
 	}
-	resbyte, err := cdc.MarshalJSON(resb)
+	// build and sign the transaction
+	stdTx, err := signStdTx(txBldr, s, []sdk.Msg{msg})
 	if err != nil {
-		return err.Error()
+		return nil, err
 	}
-	return string(resbyte)
-
+	return encodeTx(txBldr, stdTx)
 }
 
-//Only partial process with following sequence {Send coins (build -> sign -> Not send)}
-func TransferB4send(rootDir, node, chainID, fromName, password, toStr, coinStr, feeStr string) string {
-	//get the Keybase
-	viper.Set(cli.HomeFlag, rootDir)
-	kb, err1 := keys.NewKeyBaseFromHomeFlag()
-	if err1 != nil {
-		fmt.Println(err1)
-	}
-	//SetKeyBase(rootDir)
-	//fromName generated from keyspace locally
-	if fromName == "" {
-		fmt.Println("no fromName input!")
-	}
-	var info cskeys.Info
-	var err error
-	info, err = kb.Get(fromName)
+//broadcast the tx
+func BroadcastTransferTx(rootDir, node, chainID, txString, broadcastMode string) string {
+	txBytes, err := hex.DecodeString(txString)
 	if err != nil {
-		fmt.Printf("could not find key %s\n", fromName)
-		os.Exit(1)
-	}
-
-	fromAddr := info.GetAddress()
-	cliCtx := newCLIContext(rootDir, node, chainID).
-		WithCodec(cdc).
-		WithAccountDecoder(cdc).WithTrustNode(true)
-	if err := cliCtx.EnsureAccountExistsFromAddr(fromAddr); err != nil {
 		return err.Error()
 	}
+	res, err := BroadcastTxBytes(rootDir, node, chainID, txBytes, broadcastMode)
+	return txResponseOutput(res, err)
+}
 
-	to, err := sdk.AccAddressFromBech32(toStr)
-	if err != nil {
-		return err.Error()
-	}
+//BroadcastTxBytes broadcasts the signed tx bytes in broadcastMode
+func BroadcastTxBytes(rootDir, node, chainID string, txBytes []byte, broadcastMode string) (sdk.TxResponse, error) {
+	return broadcast(broadcastContext(rootDir, node, chainID, broadcastMode), txBytes)
+}
 
-	// parse coins trying to be sent
-	coins, err := sdk.ParseCoins(coinStr)
+func LocalGenTx(rootDir, node, chainID, fromName, password, toStr, coinStr, feeStr string) string {
+	txstd, err := GenSignedTransfer(rootDir, node, chainID, fromName, password, toStr, coinStr, feeStr)
 	if err != nil {
 		return err.Error()
 	}
+	txb, _ := cdc.MarshalJSON(txstd)
+	return string(txb)
+}
 
-	account, err := cliCtx.GetAccount(fromAddr)
+//GenSignedTransfer returns the transfer as a StdTx signed apart from the build
+func GenSignedTransfer(rootDir, node, chainID, fromName, password, toStr, coinStr, feeStr string) (auth.StdTx, error) {
+	s, err := KeySigner(rootDir, fromName, password)
 	if err != nil {
-		return err.Error()
+		return auth.StdTx{}, err
 	}
-
-	// ensure account has enough coins
-	if !account.GetCoins().IsAllGTE(coins) {
-		return fmt.Sprintf("Address %s doesn't have enough coins to pay for this transaction.", fromAddr)
+	fromAddr, err := SignerAddress(s)
+	if err != nil {
+		return auth.StdTx{}, err
 	}
-
-	// build and sign the transaction, then broadcast to Tendermint
-	msg := bank.NewMsgSend(fromAddr, to, coins)
-
-	//init a txBuilder for the transaction with fee
-	txBldr := authtxb.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc)).WithFees(feeStr).WithChainID(chainID)
-	//txBldr := authtxb.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc)).WithGasPrices(feeStr).WithChainID(chainID)
-
-	//accNum added to txBldr
-	accNum, err := cliCtx.GetAccountNumber(fromAddr)
+	txBldr, msg, err := prepareTransfer(rootDir, node, chainID, fromAddr, toStr, coinStr, feeStr)
 	if err != nil {
-		return err.Error()
+		return auth.StdTx{}, err
 	}
-	txBldr = txBldr.WithAccountNumber(accNum)
 
-	//accSequence added
-	accSeq, err := cliCtx.GetAccountSequence(fromAddr)
+	//separate build and sign the transaction
+	return signStdTx(txBldr, s, []sdk.Msg{msg})
+}
+
+//QueryTxsWithTags for query txs with tags for event parsing：Search for paginated transactions that match a set of tags
+func QueryTxsWithTags(rootDir, Node, chainID, addr string, page, limit int) string {
+	txs, err := SearchAddressTxs(rootDir, Node, chainID, addr, page, limit)
 	if err != nil {
 		return err.Error()
 	}
-	txBldr = txBldr.WithSequence(accSeq)
 
-	// build and sign the transaction
-	txBytes, err := txBldr.BuildAndSign(fromName, password, []sdk.Msg{msg})
+	var output []byte
+	output, err = cdc.MarshalJSONIndent(txs, "", "  ")
 	if err != nil {
 		return err.Error()
 	}
-	return string(hex.EncodeToString(txBytes))
+	return string(output)
 }
 
-//broadcast the tx
-func BroadcastTransferTx(rootDir, node, chainID, txString, broadcastMode string) string {
-	//initiate context
-	cliCtx := newCLIContext(rootDir, node, chainID).
-		WithCodec(cdc).
-		WithAccountDecoder(cdc).WithTrustNode(true).WithBroadcastMode(broadcastMode)
-	// broadcast to a Tendermint node
-	txBytes, err := hex.DecodeString(txString)
-	if err != nil {
-		return err.Error()
+//SearchAddressTxs returns a page of the txs addr sent, delegated or received
+func SearchAddressTxs(rootDir, Node, chainID, addr string, page, limit int) ([]sdk.TxResponse, error) {
+	cliCtx := queryContext(rootDir, Node, chainID)
+	if page <= 0 {
+		return nil, errcode.New(errcode.CodeInvalidInput, "page must greater than 0")
 	}
-	res, err := cliCtx.BroadcastTx(txBytes)
-	if err != nil {
-		return err.Error()
+
+	if limit <= 0 {
+		return nil, errcode.New(errcode.CodeInvalidInput, "limit must greater than 0")
 	}
-	resbyte, err := cdc.MarshalJSON(res)
-	if err != nil {
-		return err.Error()
+
+	//use the tags with specific tx.SearchTxs() method for querying
+	var txs []sdk.TxResponse
+	for _, tag := range []string{"sender:", "delegator:", "recipient:"} {
+		found, err := tx.SearchTxs(cliCtx, cdc, tagsRevert([]string{tag + addr}), page, limit)
+		if err != nil {
+			return nil, nodeError(err)
+		}
+		txs = append(txs, found...)
 	}
-	return string(resbyte)
+	return txs, nil
 }
 
-func LocalGenTx(rootDir, node, chainID, fromName, password, toStr, coinStr, feeStr string) string {
-	//get the Keybase
-	viper.Set(cli.HomeFlag, rootDir)
-	kb, err1 := keys.NewKeyBaseFromHomeFlag()
-	if err1 != nil {
-		fmt.Println(err1)
-	}
-	//SetKeyBase(rootDir)
-	//fromName generated from keyspace locally
-	if fromName == "" {
-		fmt.Println("no fromName input!")
-	}
-	var info cskeys.Info
-	var err error
-	info, err = kb.Get(fromName)
-	if err != nil {
-		fmt.Printf("could not find key %s\n", fromName)
-		os.Exit(1)
+func tagsRevert(tags []string) []string {
+	var tmTags []string
+	for _, tag := range tags {
+		// if !strings.Contains(tag, ":") {
+		// 	return fmt.Errorf("%s should be of the format <key>:<value>", tagsStr)
+		// } else if strings.Count(tag, ":") > 1 {
+		// 	return fmt.Errorf("%s should only contain one <key>:<value> pair", tagsStr)
+		// }
+
+		keyValue := strings.Split(tag, ":")
+		if keyValue[0] == tmtypes.TxHeightKey {
+			tag = fmt.Sprintf("%s=%s", keyValue[0], keyValue[1])
+		} else {
+			tag = fmt.Sprintf("%s='%s'", keyValue[0], keyValue[1])
+		}
+		tmTags = append(tmTags, tag)
 	}
+	return tmTags
+
+}
 
-	fromAddr := info.GetAddress()
+//queryContext is the context for the queries, it trusts the node unless the verified mode is on
+func queryContext(rootDir, node, chainID string) context.CLIContext {
 	cliCtx := newCLIContext(rootDir, node, chainID).
 		WithCodec(cdc).
-		WithAccountDecoder(cdc).WithTrustNode(true)
-	if err := cliCtx.EnsureAccountExistsFromAddr(fromAddr); err != nil {
-		return err.Error()
+		WithAccountDecoder(cdc)
+	if !Verified() {
+		return cliCtx.WithTrustNode(true)
 	}
+	verifier := trust.NewVerifier(trustDir(rootDir), chainID, cliCtx.Client)
+	cliCtx.Client = verifiedClient{Client: cliCtx.Client, verifier: verifier}
+	return cliCtx.WithTrustNode(false).WithVerifier(verifier)
+}
 
-	to, err := sdk.AccAddressFromBech32(toStr)
+//broadcastContext is the context for the txs broadcast in broadcastMode
+func broadcastContext(rootDir, node, chainID, broadcastMode string) context.CLIContext {
+	return queryContext(rootDir, node, chainID).WithBroadcastMode(broadcastMode)
+}
+
+//ownDelegator returns the signer of the local key delegatorName and checks it owns delegatorAddr
+func ownDelegator(rootDir, delegatorName, password, delegatorAddr string) (signer.Signer, error) {
+	s, err := KeySigner(rootDir, delegatorName, password)
 	if err != nil {
-		return err.Error()
+		return nil, err
 	}
-
-	// parse coins trying to be sent
-	coins, err := sdk.ParseCoins(coinStr)
+	//checkout with rule of own deligation
+	DelegatorAddr, err := sdk.AccAddressFromBech32(delegatorAddr)
 	if err != nil {
-		return err.Error()
+		return nil, errcode.InvalidInput(err)
 	}
+	if addr, err := SignerAddress(s); err != nil || !addr.Equals(DelegatorAddr) {
+		return nil, errcode.New(errcode.CodeInvalidInput, "Must use own delegator address")
+	}
+	return s, nil
+}
 
-	account, err := cliCtx.GetAccount(fromAddr)
+//signTx builds msgs with the account number and sequence of addr, then signs them with s
+func signTx(cliCtx context.CLIContext, s signer.Signer, chainID, feeStr string, addr sdk.AccAddress, msgs []sdk.Msg) ([]byte, error) {
+	txBldr, err := txBuilder(cliCtx, chainID, feeStr, addr)
 	if err != nil {
-		return err.Error()
+		return nil, err
 	}
-
-	// ensure account has enough coins
-	if !account.GetCoins().IsAllGTE(coins) {
-		return fmt.Sprintf("Address %s doesn't have enough coins to pay for this transaction.", fromAddr)
+	stdTx, err := signStdTx(txBldr, s, msgs)
+	if err != nil {
+		return nil, err
 	}
+	return encodeTx(txBldr, stdTx)
+}
 
-	// build and sign the transaction, then broadcast to Tendermint
-	msg := bank.NewMsgSend(fromAddr, to, coins)
-
+func txBuilder(cliCtx context.CLIContext, chainID, feeStr string, addr sdk.AccAddress) (authtxb.TxBuilder, error) {
 	//init a txBuilder for the transaction with fee
 	txBldr := authtxb.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc)).WithFees(feeStr).WithChainID(chainID)
 	//txBldr := authtxb.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc)).WithGasPrices(feeStr).WithChainID(chainID)
 
 	//accNum added to txBldr
-	accNum, err := cliCtx.GetAccountNumber(fromAddr)
+	accNum, err := cliCtx.GetAccountNumber(addr)
 	if err != nil {
-		return err.Error()
+		return txBldr, nodeError(err)
 	}
 	txBldr = txBldr.WithAccountNumber(accNum)
 
 	//accSequence added
-	accSeq, err := cliCtx.GetAccountSequence(fromAddr)
+	accSeq, err := cliCtx.GetAccountSequence(addr)
 	if err != nil {
-		return err.Error()
+		return txBldr, nodeError(err)
 	}
-	txBldr = txBldr.WithSequence(accSeq)
+	return txBldr.WithSequence(accSeq), nil
+}
 
-	//separate build and sign the transaction
-	signmsg, err := txBldr.BuildSignMsg([]sdk.Msg{msg})
-	if err != nil {
-		return err.Error()
+//prepareTransfer checks the transfer from fromAddr and returns the builder and message to sign
+func prepareTransfer(rootDir, node, chainID string, fromAddr sdk.AccAddress, toStr, coinStr, feeStr string) (authtxb.TxBuilder, sdk.Msg, error) {
+	var txBldr authtxb.TxBuilder
+	cliCtx := queryContext(rootDir, node, chainID)
+	if err := cliCtx.EnsureAccountExistsFromAddr(fromAddr); err != nil {
+		return txBldr, nil, nodeError(err)
 	}
 
-	//make signature
-	sigBytes, pubkey, err := kb.Sign(fromName, password, signmsg.Bytes())
+	to, err := sdk.AccAddressFromBech32(toStr)
 	if err != nil {
-		return err.Error()
-	}
-	signa := auth.StdSignature{
-		PubKey:    pubkey,
-		Signature: sigBytes,
+		return txBldr, nil, errcode.InvalidInput(err)
 	}
 
-	txstd := auth.NewStdTx(signmsg.Msgs, signmsg.Fee, []auth.StdSignature{signa}, signmsg.Memo)
-	txb, _ := cdc.MarshalJSON(txstd)
-	fmt.Println(txb)
-	return string(txb)
-}
-
-//QueryTxsWithTags for query txs with tags for event parsing：Search for paginated transactions that match a set of tags
-func QueryTxsWithTags(rootDir, Node, chainID, addr string, page, limit int) string {
-	cliCtx := newCLIContext(rootDir, Node, chainID).
-		WithCodec(cdc).WithTrustNode(true)
-	if page <= 0 {
-		return errors.New("page must greater than 0").Error()
+	// parse coins trying to be sent
+	coins, err := sdk.ParseCoins(coinStr)
+	if err != nil {
+		return txBldr, nil, errcode.InvalidInput(err)
 	}
 
-	if limit <= 0 {
-		return errors.New("limit must greater than 0").Error()
+	account, err := cliCtx.GetAccount(fromAddr)
+	if err != nil {
+		return txBldr, nil, nodeError(err)
 	}
 
-	//use the tags with specific tx.SearchTxs() method for querying
-	tgs := "sender:" + addr
-	tags_sender := []string{tgs}
-	tags_recipient := []string{"recipient:" + addr}
-	tags_delegator := []string{"delegator:" + addr}
-
-	sendTags := tagsRevert(tags_sender)
-	recvTgas := tagsRevert(tags_recipient)
-	deleTgas := tagsRevert(tags_delegator)
+	// ensure account has enough coins
+	if !account.GetCoins().IsAllGTE(coins) {
+		return txBldr, nil, errcode.Errorf(errcode.CodeInsufficientFunds, "Address %s doesn't have enough coins to pay for this transaction.", fromAddr)
+	}
 
-	txs_s, err := tx.SearchTxs(cliCtx, cdc, sendTags, page, limit)
+	txBldr, err = txBuilder(cliCtx, chainID, feeStr, fromAddr)
 	if err != nil {
-		return err.Error()
+		return txBldr, nil, err
 	}
+	// build and sign the transaction, then broadcast to Tendermint
+	return txBldr, bank.NewMsgSend(fromAddr, to, coins), nil
+}
 
-	txs_r, err := tx.SearchTxs(cliCtx, cdc, deleTgas, page, limit)
+//txResponseOutput is the json of the broadcast result, or the error message
+func txResponseOutput(res sdk.TxResponse, err error) string {
 	if err != nil {
 		return err.Error()
 	}
-
-	txs_d, err := tx.SearchTxs(cliCtx, cdc, recvTgas, page, limit)
+	resbyte, err := cdc.MarshalJSON(res)
 	if err != nil {
 		return err.Error()
 	}
-	var txs []sdk.TxResponse
-	for i := 0; i < len(txs_s); i++ {
-		txs = append(txs, txs_s[i])
-	}
-	for j := 0; j < len(txs_r); j++ {
-		txs = append(txs, txs_r[j])
-	}
-	for k := 0; k < len(txs_d); k++ {
-		txs = append(txs, txs_d[k])
-	}
+	return string(resbyte)
+}
 
-	var output []byte
-	output, err = cdc.MarshalJSONIndent(txs, "", "  ")
+//broadcast sends the signed tx, the tx refused by the node is an error along with its response
+func broadcast(cliCtx context.CLIContext, txBytes []byte) (sdk.TxResponse, error) {
+	res, err := cliCtx.BroadcastTx(txBytes)
 	if err != nil {
-		return err.Error()
+		return res, nodeError(err)
 	}
-
-	return string(output)
+	if res.Code != uint32(sdk.CodeOK) {
+		if res.Codespace == string(sdk.CodespaceRoot) && (res.Code == uint32(sdk.CodeInsufficientFunds) ||
+			res.Code == uint32(sdk.CodeInsufficientCoins) || res.Code == uint32(sdk.CodeInsufficientFee)) {
+			return res, errcode.New(errcode.CodeInsufficientFunds, res.RawLog)
+		}
+		return res, errcode.New(errcode.CodeNodeRejected, res.RawLog)
+	}
+	return res, nil
 }
 
-func tagsRevert(tags []string) []string {
-	var tmTags []string
-	for _, tag := range tags {
-		// if !strings.Contains(tag, ":") {
-		// 	return fmt.Errorf("%s should be of the format <key>:<value>", tagsStr)
-		// } else if strings.Count(tag, ":") > 1 {
-		// 	return fmt.Errorf("%s should only contain one <key>:<value> pair", tagsStr)
-		// }
-
-		keyValue := strings.Split(tag, ":")
-		if keyValue[0] == tmtypes.TxHeightKey {
-			tag = fmt.Sprintf("%s=%s", keyValue[0], keyValue[1])
-		} else {
-			tag = fmt.Sprintf("%s='%s'", keyValue[0], keyValue[1])
-		}
-		tmTags = append(tmTags, tag)
+//nodeError categorizes the error of a query or broadcast: the node could not be reached,
+//or it answered with an error
+func nodeError(err error) error {
+	if err == nil {
+		return nil
 	}
-	return tmTags
+	if _, ok := pkgerrors.Cause(err).(net.Error); ok {
+		return errcode.Network(err)
+	}
+	//the proofs failed by the verifier, maybe wrapped by the sdk
+	if errcode.Code(pkgerrors.Cause(err)) == errcode.CodeUnverified {
+		return errcode.Unverified(err)
+	}
+	return errcode.NodeRejected(err)
+}
 
+//keyError categorizes the error of the keybase
+func keyError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case keyerror.IsErrKeyNotFound(err):
+		return errcode.KeyNotFound(err)
+	case keyerror.IsErrWrongPassword(err):
+		return errcode.WrongPassword(err)
+	}
+	return err
 }
@@ -1,242 +1,578 @@
 package sdksource
 
 import (
-	"os/user"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"os"
+	"strings"
 	"testing"
+
+	"github.com/QOSGroup/litewallet/litewallet/errcode"
+	"github.com/QOSGroup/litewallet/litewallet/offline"
+	"github.com/QOSGroup/litewallet/litewallet/rpctest"
+	"github.com/QOSGroup/litewallet/litewallet/rpctest/cosmos"
+	"github.com/QOSGroup/litewallet/litewallet/signer/signertest"
+	"github.com/QOSGroup/litewallet/litewallet/slim/tendermint/crypto/funcInlocal/ed25519local"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	"github.com/tendermint/tendermint/crypto/secp256k1"
+)
+
+const (
+	chainId  = "cosmosv34"
+	fromName = "c34banker"
+	password = "wm131421"
+	seed     = "tomorrow room limit true galaxy dove chicken fine resemble tonight record yellow"
+	toStr    = "cosmos1nelm60csnn6204tav8s5ypkvevm6k2xsch8x5r"
 )
 
+var validatorAddr = sdk.ValAddress(ed25519.GenPrivKeyFromSecret([]byte("validator")).PubKey().Address())
+
+//testChain is a chain where the local key fromName holds 1000000000stake and one validator is bonded
+type testChain struct {
+	*cosmos.Chain
+	rootDir string
+	addr    string
+}
+
+func newTestChain(t *testing.T) *testChain {
+	rootDir, err := ioutil.TempDir("", "sdksource")
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := CreateKey(rootDir, fromName, password, seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr, _ := sdk.AccAddressFromBech32(key.Address)
+
+	chain := cosmos.NewChain(chainId)
+	chain.SetAccount(addr, sdk.NewCoins(sdk.NewInt64Coin("stake", 1000000000)))
+	chain.SetValidator(validatorAddr, ed25519.GenPrivKeyFromSecret([]byte("consensus")).PubKey(), 10000000)
+	return &testChain{Chain: chain, rootDir: rootDir, addr: key.Address}
+}
+
+func (c *testChain) Close() {
+	c.Chain.Close()
+	os.RemoveAll(c.rootDir)
+}
+
+func (c *testChain) balance(t *testing.T, addr string) sdk.Int {
+	accAddr, _ := sdk.AccAddressFromBech32(addr)
+	acc := c.Account(accAddr)
+	if acc == nil {
+		return sdk.ZeroInt()
+	}
+	return acc.GetCoins().AmountOf("stake")
+}
+
+func (c *testChain) delegate(t *testing.T, amount string) {
+	if _, err := DelegateCoins(c.rootDir, c.URI(), chainId, fromName, password, c.addr, validatorAddr.String(), amount, "10stake", "block"); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func TestGetAccount(t *testing.T) {
-	addr := "cosmos1ktecz4dr56j9tsfh7nwg8s9suvhfu70qpzrfcr"
-	node := "tcp://47.105.52.237:36657"
-	chainId := "cosmoshub-2"
-	usr, _ := user.Current()
-	rootDir := usr.HomeDir
-	acout := GetAccount(rootDir, node, chainId, addr)
-	t.Log(acout)
+	chain := newTestChain(t)
+	defer chain.Close()
+
+	acc, err := QueryAccount(chain.rootDir, chain.URI(), chainId, chain.addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !acc.GetCoins().IsEqual(sdk.NewCoins(sdk.NewInt64Coin("stake", 1000000000))) {
+		t.Errorf("got coins %s", acc.GetCoins())
+	}
+
+	_, err = QueryAccount(chain.rootDir, chain.URI(), chainId, toStr)
+	if errcode.Code(err) != errcode.CodeNodeRejected {
+		t.Errorf("got %v for an unknown account, want a node rejected error", err)
+	}
+}
+
+func TestGetAccountNodeDown(t *testing.T) {
+	chain := newTestChain(t)
+	chain.Close()
+
+	_, err := QueryAccount(chain.rootDir, chain.URI(), chainId, chain.addr)
+	if errcode.Code(err) != errcode.CodeNetwork {
+		t.Errorf("got %v, want a network error", err)
+	}
 }
 
 func TestTransfer(t *testing.T) {
-	usr, _ := user.Current()
-	rootDir := usr.HomeDir
-	node := "tcp://192.168.1.184:26657"
-	chainId := "cosmosv34"
-	fromName := "c34banker"
-	password := "wm131421"
-	toStr := "cosmos1nelm60csnn6204tav8s5ypkvevm6k2xsch8x5r"
-	coinStr := "10000000stake"
-	feeStr := "20stake"
-	broadcastMode := "async"
-	transout := Transfer(rootDir, node, chainId, fromName, password, toStr, coinStr, feeStr, broadcastMode)
-	t.Log(transout)
+	chain := newTestChain(t)
+	defer chain.Close()
+
+	for i := 0; i < 2; i++ {
+		res, err := SendCoins(chain.rootDir, chain.URI(), chainId, fromName, password, toStr, "10000000stake", "20stake", "block")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Code != 0 || res.Height != chain.Height() {
+			t.Fatalf("got %+v, want a tx committed at height %d", res, chain.Height())
+		}
+	}
+	if got := chain.balance(t, toStr); got.Int64() != 20000000 {
+		t.Errorf("receiver has %s, want 20000000stake", got)
+	}
+	if got := chain.balance(t, chain.addr); got.Int64() != 1000000000-20000040 {
+		t.Errorf("sender has %s, want %d", got, 1000000000-20000040)
+	}
+}
+
+func TestTransferErrors(t *testing.T) {
+	chain := newTestChain(t)
+	defer chain.Close()
+
+	_, err := SendCoins(chain.rootDir, chain.URI(), chainId, fromName, password, toStr, "2000000000stake", "20stake", "block")
+	if errcode.Code(err) != errcode.CodeInsufficientFunds {
+		t.Errorf("got %v, want an insufficient funds error", err)
+	}
+	_, err = SendCoins(chain.rootDir, chain.URI(), chainId, fromName, "wrong password", toStr, "1stake", "20stake", "block")
+	if errcode.Code(err) != errcode.CodeWrongPassword {
+		t.Errorf("got %v, want a wrong password error", err)
+	}
+	_, err = SendCoins(chain.rootDir, chain.URI(), chainId, "unknown", password, toStr, "1stake", "20stake", "block")
+	if errcode.Code(err) != errcode.CodeKeyNotFound {
+		t.Errorf("got %v, want a key not found error", err)
+	}
+	_, err = SendCoins(chain.rootDir, chain.URI(), "other-chain", fromName, password, toStr, "1stake", "20stake", "block")
+	if errcode.Code(err) != errcode.CodeNodeRejected {
+		t.Errorf("got %v, want a node rejected error", err)
+	}
 }
 
 func TestDelegate(t *testing.T) {
-	usr, _ := user.Current()
-	rootDir := usr.HomeDir
-	node := "tcp://192.168.1.184:26657"
-	chainId := "cosmosv34"
-	delegatorName := "c34banker"
-	password := "wm131421"
-	delegatorAddr := "cosmos1xwz2req975fqnvrrx9me7vwyz25paxflnjw6d2"
-	validatorAddr := "cosmosvaloper13zaglnf494jd5550mm74rlmknfk8tntrfectk9"
-	delegationCoinStr := "1000000000stake"
-	feeStr := "10stake"
-	broadcastMode := "block"
-	delout := Delegate(rootDir, node, chainId, delegatorName, password, delegatorAddr, validatorAddr, delegationCoinStr, feeStr, broadcastMode)
-	t.Log(delout)
-}
-
-func TestGetDelegationShares(t *testing.T) {
-	usr, _ := user.Current()
-	rootDir := usr.HomeDir
-	node := "tcp://192.168.1.184:26657"
-	chainId := "cosmosv34"
-	delegatorAddr := "cosmos1xwz2req975fqnvrrx9me7vwyz25paxflnjw6d2"
-	validatorAddr := "cosmosvaloper1xwz2req975fqnvrrx9me7vwyz25paxflkx60pe"
-	getDelout := GetDelegationShares(rootDir, node, chainId, delegatorAddr, validatorAddr)
-	t.Log(getDelout)
+	chain := newTestChain(t)
+	defer chain.Close()
+
+	chain.delegate(t, "1000000stake")
+	delegation, err := QueryDelegation(chain.rootDir, chain.URI(), chainId, chain.addr, validatorAddr.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !delegation.Shares.Equal(sdk.NewDec(1000000)) {
+		t.Errorf("got shares %s, want 1000000", delegation.Shares)
+	}
+	validator, _ := chain.Validator(validatorAddr)
+	if validator.Tokens.Int64() != 11000000 {
+		t.Errorf("validator has %s tokens, want 11000000", validator.Tokens)
+	}
+
+	_, err = DelegateCoins(chain.rootDir, chain.URI(), chainId, fromName, password, toStr, validatorAddr.String(), "1stake", "10stake", "block")
+	if errcode.Code(err) != errcode.CodeInvalidInput {
+		t.Errorf("got %v delegating from another address, want an invalid input error", err)
+	}
+}
+
+func TestVerifiedQuery(t *testing.T) {
+	chain := newTestChain(t)
+	defer chain.Close()
+	chain.delegate(t, "1000000stake")
+	SetVerified(true)
+	defer SetVerified(false)
+
+	delegation, err := QueryDelegation(chain.rootDir, chain.URI(), chainId, chain.addr, validatorAddr.String())
+	if err != nil || !delegation.Shares.Equal(sdk.NewDec(1000000)) {
+		t.Fatalf("got %+v, %v, want the proved delegation", delegation, err)
+	}
+	//the store answers of a dishonest node fail their proof
+	chain.Forge(func(res *abci.ResponseQuery) {
+		if res.Proof != nil && len(res.Value) > 0 {
+			res.Value = append([]byte(nil), res.Value...)
+			res.Value[len(res.Value)-1] ^= 1
+		}
+	})
+	_, err = QueryDelegation(chain.rootDir, chain.URI(), chainId, chain.addr, validatorAddr.String())
+	if errcode.Code(err) != errcode.CodeUnverified {
+		t.Errorf("got %v for a forged delegation, want an unverified error", err)
+	}
 }
 
 func TestUnbondingDelegation(t *testing.T) {
-	usr, _ := user.Current()
-	rootDir := usr.HomeDir
-	node := "tcp://192.168.1.184:26657"
-	chainId := "cosmosv34"
-	delegatorName := "c34banker"
-	password := "wm131421"
-	delegatorAddr := "cosmos1xwz2req975fqnvrrx9me7vwyz25paxflnjw6d2"
-	validatorAddr := "cosmosvaloper1xwz2req975fqnvrrx9me7vwyz25paxflkx60pe"
-	Ubdshares := "20000000stake"
-	feeStr := "1stake"
-	broadcastMode := "block"
-	unbondDel := UnbondingDelegation(rootDir, node, chainId, delegatorName, password, delegatorAddr, validatorAddr, Ubdshares, feeStr, broadcastMode)
-	t.Log(unbondDel)
-}
-
-func TestGetAllUnbondingDelegations(t *testing.T) {
-	usr, _ := user.Current()
-	rootDir := usr.HomeDir
-	node := "tcp://192.168.1.184:26657"
-	chainId := "cosmosv34"
-	delegatorAddr := "cosmos1ne8hnx92k8x7cvluvhkphtk5kpvzenvns7g48g"
-	//validatorAddr := "cosmosvaloper1a8e4nvxw26c9ug9x687s65vxquszu3j82zezuc"
-	getUbns := GetAllUnbondingDelegations(rootDir, node, chainId, delegatorAddr)
-	t.Log(getUbns)
+	chain := newTestChain(t)
+	defer chain.Close()
+
+	chain.delegate(t, "1000000stake")
+	_, err := UndelegateCoins(chain.rootDir, chain.URI(), chainId, fromName, password, chain.addr, validatorAddr.String(), "400000stake", "1stake", "block")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ubds, err := QueryUnbondingDelegations(chain.rootDir, chain.URI(), chainId, chain.addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ubds) != 1 || len(ubds[0].Entries) != 1 || ubds[0].Entries[0].Balance.Int64() != 400000 {
+		t.Fatalf("got unbondings %v, want one of 400000", ubds)
+	}
+	entry := ubds[0].Entries[0]
+	if completion := rpctest.BlockTime(chain.Height()).Add(cosmos.UnbondingTime); entry.CreationHeight != chain.Height() || !entry.CompletionTime.Equal(completion) {
+		t.Errorf("got entry %+v, want created at %d and completed at %s", entry, chain.Height(), completion)
+	}
 }
 
 func TestGetBondValidators(t *testing.T) {
-	usr, _ := user.Current()
-	rootDir := usr.HomeDir
-	node := "tcp://192.168.1.184:26657"
-	chainId := "cosmosv34"
-	delegatorAddr := "cosmos1xwz2req975fqnvrrx9me7vwyz25paxflnjw6d2"
-	getBd := GetBondValidators(rootDir, node, chainId, delegatorAddr)
-	t.Log(getBd)
+	chain := newTestChain(t)
+	defer chain.Close()
+
+	if out := GetBondValidators(chain.rootDir, chain.URI(), chainId, chain.addr); out != "None of validators delegated!" {
+		t.Errorf("got %s before delegating", out)
+	}
+
+	chain.delegate(t, "1000000stake")
+	validators, err := QueryBondedValidators(chain.rootDir, chain.URI(), chainId, chain.addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(validators) != 1 || !validators[0].Validator.OperatorAddress.Equals(validatorAddr) || validators[0].SelfBondShares != sdk.NewDec(10000000).String() {
+		t.Errorf("got validators %+v", validators)
+	}
 }
 
 func TestGetAllValidators(t *testing.T) {
-	usr, _ := user.Current()
-	rootDir := usr.HomeDir
-	node := "tcp://192.168.1.184:26657"
-	chainId := "cosmosv34"
-	getVals := GetAllValidators(rootDir, node, chainId)
-	t.Log(getVals)
+	chain := newTestChain(t)
+	defer chain.Close()
+
+	//a validator under the tendermint power of 1 is left out
+	small := sdk.ValAddress(ed25519.GenPrivKeyFromSecret([]byte("small")).PubKey().Address())
+	chain.SetValidator(small, ed25519.GenPrivKeyFromSecret([]byte("small consensus")).PubKey(), 10)
+
+	validators, err := QueryValidators(chain.rootDir, chain.URI(), chainId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(validators) != 1 || !validators[0].Validator.OperatorAddress.Equals(validatorAddr) {
+		t.Errorf("got validators %+v, want %s only", validators, validatorAddr)
+	}
 }
 
 func TestGetAllDelegations(t *testing.T) {
-	usr, _ := user.Current()
-	rootDir := usr.HomeDir
-	node := "tcp://192.168.1.184:26657"
-	chainId := "cosmosv34"
-	delegatorAddr := "cosmos1xwz2req975fqnvrrx9me7vwyz25paxflnjw6d2"
-	getDels := GetAllDelegations(rootDir, node, chainId, delegatorAddr)
-	t.Log(getDels)
+	chain := newTestChain(t)
+	defer chain.Close()
+
+	chain.delegate(t, "1000000stake")
+	chain.delegate(t, "500000stake")
+	delegations, err := QueryDelegations(chain.rootDir, chain.URI(), chainId, chain.addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(delegations) != 1 || !delegations[0].Shares.Equal(sdk.NewDec(1500000)) {
+		t.Errorf("got delegations %v, want one of 1500000", delegations)
+	}
 }
 
 func TestWithdrawDelegationReward(t *testing.T) {
-	usr, _ := user.Current()
-	rootDir := usr.HomeDir
-	node := "tcp://192.168.1.184:26657"
-	chainId := "cosmosv34"
-	delegatorName := "c34banker"
-	password := "wm131421"
-	delegatorAddr := "cosmos1xwz2req975fqnvrrx9me7vwyz25paxflnjw6d2"
-	validatorAddr := "cosmosvaloper1xwz2req975fqnvrrx9me7vwyz25paxflkx60pe"
-	feeStr := "1stake"
-	broadcastMode := "block"
-	withdrawRew := WithdrawDelegationReward(rootDir, node, chainId, delegatorName, password, delegatorAddr, validatorAddr, feeStr, broadcastMode)
-	t.Log(withdrawRew)
-}
-
-func TestGetDelegationRewards(t *testing.T) {
-	usr, _ := user.Current()
-	rootDir := usr.HomeDir
-	node := "tcp://192.168.1.184:26657"
-	chainId := "cosmosv34"
-	delegatorAddr := "cosmos1ne8hnx92k8x7cvluvhkphtk5kpvzenvns7g48g"
-	validatorAddr := "cosmosvaloper1xwz2req975fqnvrrx9me7vwyz25paxflkx60pe"
-	getWithdraw := GetDelegationRewards(rootDir, node, chainId, delegatorAddr, validatorAddr)
-	t.Log(getWithdraw)
+	chain := newTestChain(t)
+	defer chain.Close()
+
+	chain.delegate(t, "1000000stake")
+	delAddr, _ := sdk.AccAddressFromBech32(chain.addr)
+	chain.SetRewards(delAddr, validatorAddr, sdk.NewDecCoins(sdk.NewCoins(sdk.NewInt64Coin("stake", 300))))
+
+	rewards, err := QueryDelegationRewards(chain.rootDir, chain.URI(), chainId, chain.addr, validatorAddr.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rewards.AmountOf("stake").Equal(sdk.NewDec(300)) {
+		t.Errorf("got rewards %s, want 300stake", rewards)
+	}
+
+	before := chain.balance(t, chain.addr)
+	if _, err := WithdrawReward(chain.rootDir, chain.URI(), chainId, fromName, password, chain.addr, validatorAddr.String(), "1stake", "block"); err != nil {
+		t.Fatal(err)
+	}
+	if got := chain.balance(t, chain.addr).Sub(before); got.Int64() != 299 {
+		t.Errorf("got %s after withdrawing 300 with a fee of 1, want 299", got)
+	}
 }
 
-func TestQueryTx(t *testing.T) {
-	usr, _ := user.Current()
-	rootDir := usr.HomeDir
-	node := "tcp://47.105.52.237:36657"
-	chainId := "cosmoshub-2"
-	txHash := "6C022D72749D0F11E80BE9F21969BA80DBBD7DA39D686F13C9A41A71CB038D96"
-	qTx := QueryTx(rootDir, node, chainId, txHash)
-	t.Log(qTx)
+func TestGetDelegtorRewardsShares(t *testing.T) {
+	chain := newTestChain(t)
+	defer chain.Close()
+
+	chain.delegate(t, "1000000stake")
+	delAddr, _ := sdk.AccAddressFromBech32(chain.addr)
+	chain.SetRewards(delAddr, validatorAddr, sdk.NewDecCoins(sdk.NewCoins(sdk.NewInt64Coin("stake", 300))))
+
+	delrews, err := QueryDelegatorRewards(chain.rootDir, chain.URI(), chainId, chain.addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(delrews) != 1 || !delrews[0].Shares.Equal(sdk.NewDec(1000000)) || !delrews[0].RewardsCoins.AmountOf("stake").Equal(sdk.NewDec(300)) {
+		t.Errorf("got %+v", delrews)
+	}
+}
+
+func TestWithdrawDelegatorAllRewards(t *testing.T) {
+	chain := newTestChain(t)
+	defer chain.Close()
+
+	chain.delegate(t, "1000000stake")
+	delAddr, _ := sdk.AccAddressFromBech32(chain.addr)
+	chain.SetRewards(delAddr, validatorAddr, sdk.NewDecCoins(sdk.NewCoins(sdk.NewInt64Coin("stake", 300))))
+
+	before := chain.balance(t, chain.addr)
+	if _, err := WithdrawAllRewards(chain.rootDir, chain.URI(), chainId, fromName, password, chain.addr, "10stake", "block"); err != nil {
+		t.Fatal(err)
+	}
+	if got := chain.balance(t, chain.addr).Sub(before); got.Int64() != 290 {
+		t.Errorf("got %s after withdrawing 300 with a fee of 10, want 290", got)
+	}
+}
+
+func TestTxsWithSigner(t *testing.T) {
+	chain := newTestChain(t)
+	defer chain.Close()
+
+	//the key of the wallet held by a device
+	kb, _ := keyBase(chain.rootDir)
+	priv, err := kb.ExportPrivateKeyObject(fromName, password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := priv.(secp256k1.PrivKeySecp256k1)
+	ecdsaKey, _ := ethcrypto.ToECDSA(key[:])
+	device := signertest.NewSecp256k1(ecdsaKey)
+	s := device.Signer()
+	if addr, err := SignerAddress(s); err != nil || addr.String() != chain.addr {
+		t.Fatalf("got %s, %v, want the address of the key", addr, err)
+	}
+
+	uri := chain.URI()
+	if _, err := SendCoinsWithSigner(chain.rootDir, uri, chainId, s, toStr, "100stake", "1stake", "block"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := DelegateCoinsWithSigner(chain.rootDir, uri, chainId, s, validatorAddr.String(), "1000000stake", "1stake", "block"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := UndelegateCoinsWithSigner(chain.rootDir, uri, chainId, s, validatorAddr.String(), "400000stake", "1stake", "block"); err != nil {
+		t.Fatal(err)
+	}
+	delAddr, _ := sdk.AccAddressFromBech32(chain.addr)
+	chain.SetRewards(delAddr, validatorAddr, sdk.NewDecCoins(sdk.NewCoins(sdk.NewInt64Coin("stake", 300))))
+	if _, err := WithdrawRewardWithSigner(chain.rootDir, uri, chainId, s, validatorAddr.String(), "1stake", "block"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := WithdrawAllRewardsWithSigner(chain.rootDir, uri, chainId, s, "1stake", "block"); err != nil {
+		t.Fatal(err)
+	}
+	//the device signs with a high S every other time, the node only takes the low one
+	if device.Signs() != 5 {
+		t.Errorf("got %d signatures of the device, want 5", device.Signs())
+	}
+	if got := chain.balance(t, toStr); got.Int64() != 100 {
+		t.Errorf("receiver has %s, want 100stake", got)
+	}
+
+	device.Refuse(errors.New("cancelled"))
+	if _, err := SendCoinsWithSigner(chain.rootDir, uri, chainId, s, toStr, "100stake", "1stake", "block"); err == nil {
+		t.Error("want the error of the device")
+	}
+	ed := signertest.NewEd25519(ed25519local.GenPrivKeyFromSecret([]byte("qos"))).Signer()
+	if _, err := SendCoinsWithSigner(chain.rootDir, uri, chainId, ed, toStr, "100stake", "1stake", "block"); errcode.Code(err) != errcode.CodeInvalidInput {
+		t.Errorf("got %v for an ed25519 signer, want an invalid input error", err)
+	}
+}
+
+func TestOffline(t *testing.T) {
+	chain := newTestChain(t)
+	defer chain.Close()
+
+	//the offline device holds the key, the online one only knows the address
+	kb, _ := keyBase(chain.rootDir)
+	priv, err := kb.ExportPrivateKeyObject(fromName, password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := priv.(secp256k1.PrivKeySecp256k1)
+	ecdsaKey, _ := ethcrypto.ToECDSA(key[:])
+	device := signertest.NewSecp256k1(ecdsaKey).Signer()
+
+	uri := chain.URI()
+	delAddr, _ := sdk.AccAddressFromBech32(chain.addr)
+	builds := []func() (offline.Tx, error){
+		func() (offline.Tx, error) {
+			return BuildOfflineTransfer(chain.rootDir, uri, chainId, chain.addr, toStr, "100stake", "1stake")
+		},
+		func() (offline.Tx, error) {
+			return BuildOfflineDelegate(chain.rootDir, uri, chainId, chain.addr, validatorAddr.String(), "1000000stake", "1stake")
+		},
+		func() (offline.Tx, error) {
+			return BuildOfflineUndelegate(chain.rootDir, uri, chainId, chain.addr, validatorAddr.String(), "400000stake", "1stake")
+		},
+		func() (offline.Tx, error) {
+			chain.SetRewards(delAddr, validatorAddr, sdk.NewDecCoins(sdk.NewCoins(sdk.NewInt64Coin("stake", 300))))
+			return BuildOfflineWithdrawReward(chain.rootDir, uri, chainId, chain.addr, validatorAddr.String(), "1stake")
+		},
+		func() (offline.Tx, error) {
+			return BuildOfflineWithdrawAllRewards(chain.rootDir, uri, chainId, chain.addr, "1stake")
+		},
+	}
+	//build online -> sign offline -> broadcast, one tx after the other as each build takes the next sequence
+	for i, build := range builds {
+		unsigned, err := build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		doc, err := offline.Parse(unsigned.String())
+		if err != nil || doc.Sequence != uint64(i) || doc.ChainID != chainId {
+			t.Fatalf("got %+v, %v, want the unsigned tx with the sequence %d", doc, err, i)
+		}
+		signed, err := SignOffline(doc, device)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res, err := BroadcastOffline(chain.rootDir, uri, signed, "block")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.TxHash != signed.Hash {
+			t.Errorf("got the hash %s of the node, want %s", res.TxHash, signed.Hash)
+		}
+	}
+	if got := chain.balance(t, toStr); got.Int64() != 100 {
+		t.Errorf("receiver has %s, want 100stake", got)
+	}
+
+	unsigned, err := BuildOfflineTransfer(chain.rootDir, uri, chainId, chain.addr, toStr, "100stake", "1stake")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, _ := ethcrypto.GenerateKey()
+	if _, err := SignOffline(unsigned, signertest.NewSecp256k1(other).Signer()); errcode.Code(err) != errcode.CodeInvalidInput {
+		t.Errorf("got %v for another key, want an invalid input error", err)
+	}
+	if _, err := BroadcastOffline(chain.rootDir, uri, unsigned, "block"); errcode.Code(err) != errcode.CodeInvalidInput {
+		t.Errorf("got %v broadcasting the unsigned tx, want an invalid input error", err)
+	}
+	unsigned.Tx = []byte(strings.Replace(string(unsigned.Tx), `"100"`, `"1000"`, 1))
+	if _, err := SignOffline(unsigned, device); errcode.Code(err) != errcode.CodeInvalidInput {
+		t.Errorf("got %v for a modified tx, want an invalid input error", err)
+	}
 }
 
 func TestGetValSelfBondShares(t *testing.T) {
-	usr, _ := user.Current()
-	rootDir := usr.HomeDir
-	node := "tcp://192.168.1.184:26657"
-	chainId := "cosmosv34"
-	validatorAddr := "cosmosvaloper1xwz2req975fqnvrrx9me7vwyz25paxflkx60pe"
-	vsb := GetValSelfBondShares(rootDir, node, chainId, validatorAddr)
-	t.Log(vsb)
+	chain := newTestChain(t)
+	defer chain.Close()
+
+	delegation, err := QueryValidatorSelfDelegation(chain.rootDir, chain.URI(), chainId, validatorAddr.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !delegation.Shares.Equal(sdk.NewDec(10000000)) {
+		t.Errorf("got self bond shares %s, want 10000000", delegation.Shares)
+	}
 }
 
-func TestGetDelegtorRewardsShares(t *testing.T) {
-	usr, _ := user.Current()
-	rootDir := usr.HomeDir
-	node := "tcp://192.168.1.184:26657"
-	chainId := "cosmosv34"
-	delegatorAddr := "cosmos1xwz2req975fqnvrrx9me7vwyz25paxflnjw6d2"
-	daa := GetDelegtorRewardsShares(rootDir, node, chainId, delegatorAddr)
-	t.Log(daa)
-}
-
-func TestTransferB4send(t *testing.T) {
-	usr, _ := user.Current()
-	rootDir := usr.HomeDir
-	node := "tcp://192.168.1.184:26657"
-	chainId := "cosmosv34"
-	fromName := "c34banker"
-	password := "wm131421"
-	toStr := "cosmos1kklk4eqye6pla97dzmc03pw5lst7x0n4zt8syw"
-	coinStr := "100stake"
-	feeStr := "1stake"
-	Tx := TransferB4send(rootDir, node, chainId, fromName, password, toStr, coinStr, feeStr)
-	t.Log(Tx)
+func TestQueryTx(t *testing.T) {
+	chain := newTestChain(t)
+	defer chain.Close()
+
+	res, err := SendCoins(chain.rootDir, chain.URI(), chainId, fromName, password, toStr, "100stake", "1stake", "sync")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx, err := GetTx(chain.rootDir, chain.URI(), chainId, res.TxHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx.Height != chain.Height() || tx.Timestamp == "" || len(tx.Tx.GetMsgs()) != 1 {
+		t.Errorf("got %+v", tx)
+	}
 
+	_, err = GetTx(chain.rootDir, chain.URI(), chainId, hex.EncodeToString(make([]byte, 32)))
+	if errcode.Code(err) != errcode.CodeNodeRejected {
+		t.Errorf("got %v for an unknown hash, want a node rejected error", err)
+	}
 }
 
 func TestBroadcastTransferTx(t *testing.T) {
-	usr, _ := user.Current()
-	rootDir := usr.HomeDir
-	node := "tcp://192.168.1.184:26657"
-	chainId := "cosmosv34"
-	//fromName := "cosmos341"
-	//password := "wm131421"
-	//toStr := "cosmos1kklk4eqye6pla97dzmc03pw5lst7x0n4zt8syw"
-	//coinStr := "10000stake"
-	//feeStr := "1stake"
-	broadcastMode := "block"
-	txString := "c201f0625dee0a3ea8a3619a0a149e4f7998aab1cdec33fc65ec1baed4b0582ccd931214b5bf6ae404ce83fe97cd16f0f885d4fc17e33e751a0c0a057374616b65120331303012100a0a0a057374616b6512013110c09a0c1a6a0a26eb5ae9872102ae6ea19e838daa09b8e614dbb59636bae7007b86b85c165d8100ba50b365d4861240647008bcdc33854d7270045e18ad28e61f03d062ce7a63c60aca6026fb3b4d812bbc1898fd08f0b726b0299c87be74f4870aa0ee099db8778a6cfe038685eb8f"
-	Bt := BroadcastTransferTx(rootDir, node, chainId, txString, broadcastMode)
-	t.Log(Bt)
+	chain := newTestChain(t)
+	defer chain.Close()
+
+	txString := TransferB4send(chain.rootDir, chain.URI(), chainId, fromName, password, toStr, "100stake", "1stake")
+	if _, err := hex.DecodeString(txString); err != nil {
+		t.Fatalf("got %s, want the hex of the signed tx", txString)
+	}
+	if got := chain.balance(t, toStr); !got.IsZero() {
+		t.Fatalf("receiver got %s before the broadcast", got)
+	}
+
+	out := BroadcastTransferTx(chain.rootDir, chain.URI(), chainId, txString, "block")
+	if !strings.Contains(out, `"txhash"`) {
+		t.Fatal(out)
+	}
+	if got := chain.balance(t, toStr); got.Int64() != 100 {
+		t.Errorf("receiver has %s, want 100stake", got)
+	}
+
+	//the sequence is used, the same tx can not be replayed
+	_, err := BroadcastTxBytes(chain.rootDir, chain.URI(), chainId, mustDecodeHex(t, txString), "block")
+	if errcode.Code(err) != errcode.CodeNodeRejected {
+		t.Errorf("got %v replaying the tx, want a node rejected error", err)
+	}
 }
 
-func TestWithdrawDelegatorAllRewards(t *testing.T) {
-	usr, _ := user.Current()
-	rootDir := usr.HomeDir
-	node := "tcp://192.168.1.184:26657"
-	chainId := "cosmosv34"
-	delegatorName := "c34banker"
-	password := "wm131421"
-	delegatorAddr := "cosmos1xwz2req975fqnvrrx9me7vwyz25paxflnjw6d2"
-	feeStr := "10stake"
-	broadcastMode := "block"
-	wda := WithdrawDelegatorAllRewards(rootDir, node, chainId, delegatorName, password, delegatorAddr, feeStr, broadcastMode)
-	t.Log(wda)
+func mustDecodeHex(t *testing.T, s string) []byte {
+	bz, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bz
 }
 
 func TestLocalGenTx(t *testing.T) {
-	usr, _ := user.Current()
-	rootDir := usr.HomeDir
-	node := "tcp://192.168.1.184:26657"
-	chainId := "cosmosv34"
-	fromName := "c34banker"
-	password := "wm131421"
-	toStr := "cosmos1kklk4eqye6pla97dzmc03pw5lst7x0n4zt8syw"
-	coinStr := "100stake"
-	feeStr := "1stake"
-	Txs := LocalGenTx(rootDir, node, chainId, fromName, password, toStr, coinStr, feeStr)
-	//txb := []byte(Txs)
-	t.Log(Txs)
+	chain := newTestChain(t)
+	defer chain.Close()
+
+	stdTx, err := GenSignedTransfer(chain.rootDir, chain.URI(), chainId, fromName, password, toStr, "100stake", "1stake")
+	if err != nil {
+		t.Fatal(err)
+	}
+	txBytes, err := cdc.MarshalBinaryLengthPrefixed(stdTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := BroadcastTxBytes(chain.rootDir, chain.URI(), chainId, txBytes, "block"); err != nil {
+		t.Fatal(err)
+	}
+	if got := chain.balance(t, toStr); got.Int64() != 100 {
+		t.Errorf("receiver has %s, want 100stake", got)
+	}
 }
 
 func TestQueryTxsWithTags(t *testing.T) {
-	usr, _ := user.Current()
-	rootDir := usr.HomeDir
-	node := "tcp://47.105.52.237:36657"
-	chainId := "cosmoshub-2"
-	addr := "cosmos1ktecz4dr56j9tsfh7nwg8s9suvhfu70qpzrfcr"
-	page := 1
-	limit := 30
-	out := QueryTxsWithTags(rootDir, node, chainId, addr, page, limit)
-	t.Log(out)
+	chain := newTestChain(t)
+	defer chain.Close()
+
+	if _, err := SendCoins(chain.rootDir, chain.URI(), chainId, fromName, password, toStr, "100stake", "1stake", "block"); err != nil {
+		t.Fatal(err)
+	}
+	chain.delegate(t, "1000000stake")
+
+	txs, err := SearchAddressTxs(chain.rootDir, chain.URI(), chainId, chain.addr, 1, 30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txs) != 2 {
+		t.Errorf("got %d txs of the sender, want the send and the delegation", len(txs))
+	}
+
+	txs, err = SearchAddressTxs(chain.rootDir, chain.URI(), chainId, toStr, 1, 30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txs) != 1 {
+		t.Errorf("got %d txs of the recipient, want 1", len(txs))
+	}
+
+	if _, err := SearchAddressTxs(chain.rootDir, chain.URI(), chainId, chain.addr, 0, 30); errcode.Code(err) != errcode.CodeInvalidInput {
+		t.Errorf("got %v for page 0, want an invalid input error", err)
+	}
 }
@@ -0,0 +1,126 @@
+package sdksource
+
+import (
+	"github.com/QOSGroup/litewallet/litewallet/errcode"
+	"github.com/QOSGroup/litewallet/litewallet/signer"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/auth"
+	authtxb "github.com/cosmos/cosmos-sdk/x/auth/client/txbuilder"
+	"github.com/tendermint/tendermint/crypto"
+	"github.com/tendermint/tendermint/crypto/secp256k1"
+)
+
+//keySigner is the local key name as a signer. The key is only decrypted to sign, so a wrong password is
+//found after the checks of the tx as with the keybase.
+type keySigner struct {
+	kb       keybase
+	name     string
+	password string
+	pubKey   secp256k1.PubKeySecp256k1
+}
+
+//KeySigner returns the signer of the local key name
+func KeySigner(rootDir, name, password string) (signer.Signer, error) {
+	//name generated from keyspace locally
+	if name == "" {
+		return nil, errMissingName()
+	}
+	kb, err := keyBase(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	info, err := kb.Get(name)
+	if err != nil {
+		return nil, keyError(err)
+	}
+	pubKey, ok := info.GetPubKey().(secp256k1.PubKeySecp256k1)
+	if !ok {
+		return nil, errcode.Errorf(errcode.CodeInvalidInput, "the key %s is not a secp256k1 key", name)
+	}
+	return keySigner{kb: kb, name: name, password: password, pubKey: pubKey}, nil
+}
+
+func (s keySigner) Algo() string {
+	return signer.AlgoSecp256k1
+}
+
+func (s keySigner) PubKey() []byte {
+	return append([]byte(nil), s.pubKey[:]...)
+}
+
+func (s keySigner) Sign(digest []byte) ([]byte, error) {
+	priv, err := s.kb.ExportPrivateKeyObject(s.name, s.password)
+	if err != nil {
+		return nil, keyError(err)
+	}
+	key, ok := priv.(secp256k1.PrivKeySecp256k1)
+	if !ok {
+		return nil, errcode.Errorf(errcode.CodeInternal, "the key %s is not a secp256k1 key", s.name)
+	}
+	ks, err := signer.ParseSecp256k1(key[:])
+	if err != nil {
+		return nil, errcode.Internal(err)
+	}
+	return ks.Sign(digest)
+}
+
+//signerPubKey returns the public key of the secp256k1 signer s
+func signerPubKey(s signer.Signer) (secp256k1.PubKeySecp256k1, error) {
+	var pubKey secp256k1.PubKeySecp256k1
+	if s.Algo() != signer.AlgoSecp256k1 {
+		return pubKey, errcode.Errorf(errcode.CodeInvalidInput, "Cosmos signs with secp256k1, not %s", s.Algo())
+	}
+	if len(s.PubKey()) != secp256k1.PubKeySecp256k1Size {
+		return pubKey, errcode.Errorf(errcode.CodeInvalidInput, "invalid secp256k1 public key of %d bytes", len(s.PubKey()))
+	}
+	copy(pubKey[:], s.PubKey())
+	return pubKey, nil
+}
+
+//SignerAddress returns the account address of the secp256k1 signer s
+func SignerAddress(s signer.Signer) (sdk.AccAddress, error) {
+	pubKey, err := signerPubKey(s)
+	if err != nil {
+		return nil, err
+	}
+	return sdk.AccAddress(pubKey.Address()), nil
+}
+
+//signStdTx builds msgs with txBldr and signs them with s
+func signStdTx(txBldr authtxb.TxBuilder, s signer.Signer, msgs []sdk.Msg) (auth.StdTx, error) {
+	if _, err := signerPubKey(s); err != nil {
+		return auth.StdTx{}, err
+	}
+	signMsg, err := txBldr.BuildSignMsg(msgs)
+	if err != nil {
+		return auth.StdTx{}, err
+	}
+	return signStdSignMsg(signMsg, s)
+}
+
+//signStdSignMsg signs signMsg with s, which signs the sha256 of the sign bytes
+func signStdSignMsg(signMsg authtxb.StdSignMsg, s signer.Signer) (auth.StdTx, error) {
+	pubKey, err := signerPubKey(s)
+	if err != nil {
+		return auth.StdTx{}, err
+	}
+	sig, err := s.Sign(crypto.Sha256(signMsg.Bytes()))
+	if err != nil {
+		return auth.StdTx{}, err
+	}
+	if len(sig) != signer.Secp256k1SignatureSize {
+		return auth.StdTx{}, errcode.Errorf(errcode.CodeInternal, "invalid secp256k1 signature of %d bytes", len(sig))
+	}
+	//Cosmos takes r||s without the recovery id
+	stdSig := auth.StdSignature{PubKey: pubKey, Signature: sig[:64]}
+	return auth.NewStdTx(signMsg.Msgs, signMsg.Fee, []auth.StdSignature{stdSig}, signMsg.Memo), nil
+}
+
+//encodeTx encodes the signed stdTx with the encoder of txBldr
+func encodeTx(txBldr authtxb.TxBuilder, stdTx auth.StdTx) ([]byte, error) {
+	txBytes, err := txBldr.TxEncoder()(stdTx)
+	if err != nil {
+		return nil, errcode.Internal(err)
+	}
+	return txBytes, nil
+}
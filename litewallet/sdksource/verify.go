@@ -0,0 +1,70 @@
+package sdksource
+
+import (
+	"path/filepath"
+	"sync"
+
+	"github.com/QOSGroup/litewallet/litewallet/trust"
+	cmn "github.com/tendermint/tendermint/libs/common"
+	rpcclient "github.com/tendermint/tendermint/rpc/client"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+)
+
+//The verified mode checks the answers of the node instead of trusting it: the account, staking and
+//distribution queries are proved against the app hash, and the txs against the data hash, of a header
+//verified by the light client of the trust package. The trusted headers are kept under rootDir.
+//
+//A query at height H is proved by the header H+1, so the queries of the latest state are made at the
+//height before the latest block, whose proving header the node already has.
+
+var (
+	verifiedMtx sync.RWMutex
+	verified    bool
+)
+
+//SetVerified turns the verified mode on or off for all Cosmos queries, it is off by default
+func SetVerified(on bool) {
+	verifiedMtx.Lock()
+	defer verifiedMtx.Unlock()
+	verified = on
+}
+
+//Verified tells if the Cosmos queries are verified
+func Verified() bool {
+	verifiedMtx.RLock()
+	defer verifiedMtx.RUnlock()
+	return verified
+}
+
+//trustDir is the directory of the trusted headers of all chains
+func trustDir(rootDir string) string {
+	return filepath.Join(rootDir, ".gaiacli", ".gaialite")
+}
+
+//verifiedClient proves the answers to the store queries with the verifier before the sdk reads them, so
+//a failed proof is an errcode.CodeUnverified error of the trust package instead of a message of the sdk
+type verifiedClient struct {
+	rpcclient.Client
+	verifier *trust.Verifier
+}
+
+func (c verifiedClient) ABCIQueryWithOptions(path string, data cmn.HexBytes, opts rpcclient.ABCIQueryOptions) (*ctypes.ResultABCIQuery, error) {
+	opts.Prove = true
+	if opts.Height == 0 {
+		height, err := c.verifier.ProvedHeight()
+		if err != nil {
+			return nil, err
+		}
+		opts.Height = height
+	}
+	res, err := c.Client.ABCIQueryWithOptions(path, data, opts)
+	if err != nil {
+		return nil, err
+	}
+	if res.Response.IsOK() {
+		if err := c.verifier.VerifyQuery(path, data, res.Response); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,130 @@
+package signer
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/asn1"
+	"math/big"
+
+	"github.com/QOSGroup/litewallet/litewallet/errcode"
+	"github.com/QOSGroup/litewallet/litewallet/slim/tendermint/crypto/funcInlocal/ed25519local"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	secp256k1N     = crypto.S256().Params().N
+	secp256k1HalfN = new(big.Int).Rsh(secp256k1N, 1)
+)
+
+//callbackSigner is a key the app keeps, sign is the app signing with it
+type callbackSigner struct {
+	algo   string
+	pubKey []byte
+	//uncompressed is the uncompressed secp256k1 public key the recovered keys are compared with
+	uncompressed []byte
+	sign         func(msg []byte) ([]byte, error)
+}
+
+//NewCallback returns the signer of the key pubKey the app signs with. The secp256k1 pubKey is compressed or
+//uncompressed and sign may return a DER signature, as the Android Keystore and the Secure Enclave do, r||s or
+//r||s||v: the signature is normalized to the low S R||S||V of the Signer, V found by the recovery of pubKey.
+//The ed25519 sign returns the 64 bytes signature. A signature which does not verify with pubKey is an error.
+func NewCallback(algo string, pubKey []byte, sign func(msg []byte) ([]byte, error)) (Signer, error) {
+	if sign == nil {
+		return nil, errcode.New(errcode.CodeInvalidInput, "missing the sign callback")
+	}
+	switch algo {
+	case AlgoSecp256k1:
+		var key *ecdsa.PublicKey
+		var err error
+		switch len(pubKey) {
+		case 33:
+			key, err = crypto.DecompressPubkey(pubKey)
+		case 65:
+			key, err = crypto.UnmarshalPubkey(pubKey)
+		default:
+			return nil, errcode.Errorf(errcode.CodeInvalidInput, "invalid secp256k1 public key of %d bytes", len(pubKey))
+		}
+		if err != nil {
+			return nil, errcode.InvalidInput(err)
+		}
+		return callbackSigner{
+			algo:         algo,
+			pubKey:       crypto.CompressPubkey(key),
+			uncompressed: crypto.FromECDSAPub(key),
+			sign:         sign,
+		}, nil
+	case AlgoEd25519:
+		if len(pubKey) != ed25519local.PubKeyEd25519Size {
+			return nil, errcode.Errorf(errcode.CodeInvalidInput, "invalid ed25519 public key of %d bytes", len(pubKey))
+		}
+		return callbackSigner{algo: algo, pubKey: append([]byte(nil), pubKey...), sign: sign}, nil
+	}
+	return nil, errcode.Errorf(errcode.CodeInvalidInput, "unknown signature algorithm %q", algo)
+}
+
+func (s callbackSigner) Algo() string {
+	return s.algo
+}
+
+func (s callbackSigner) PubKey() []byte {
+	return append([]byte(nil), s.pubKey...)
+}
+
+func (s callbackSigner) Sign(msg []byte) ([]byte, error) {
+	if s.algo == AlgoSecp256k1 && len(msg) != DigestSize {
+		return nil, errcode.Errorf(errcode.CodeInvalidInput, "secp256k1 signs a %d bytes digest, got %d bytes", DigestSize, len(msg))
+	}
+	sig, err := s.sign(msg)
+	if err != nil {
+		return nil, err
+	}
+	if s.algo == AlgoEd25519 {
+		var pub ed25519local.PubKeyEd25519
+		copy(pub[:], s.pubKey)
+		if !pub.VerifyBytes(msg, sig) {
+			return nil, errMismatch()
+		}
+		return sig, nil
+	}
+	r, sv, err := parseSecp256k1Signature(sig)
+	if err != nil {
+		return nil, err
+	}
+	//the Android Keystore and the Secure Enclave do not normalize S, ETH and Cosmos only accept the low one
+	if sv.Cmp(secp256k1HalfN) > 0 {
+		sv.Sub(secp256k1N, sv)
+	}
+	out := make([]byte, Secp256k1SignatureSize)
+	copy(out, math.PaddedBigBytes(r, 32))
+	copy(out[32:], math.PaddedBigBytes(sv, 32))
+	for v := byte(0); v < 2; v++ {
+		out[64] = v
+		if pub, err := crypto.Ecrecover(msg, out); err == nil && bytes.Equal(pub, s.uncompressed) {
+			return out, nil
+		}
+	}
+	return nil, errMismatch()
+}
+
+//parseSecp256k1Signature reads r and s of a DER, r||s or r||s||v signature
+func parseSecp256k1Signature(sig []byte) (r, s *big.Int, err error) {
+	var der struct{ R, S *big.Int }
+	switch rest, err := asn1.Unmarshal(sig, &der); {
+	case err == nil && len(rest) == 0:
+		r, s = der.R, der.S
+	case len(sig) == 64 || len(sig) == 65:
+		r, s = new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:64])
+	default:
+		return nil, nil, errcode.Errorf(errcode.CodeInternal, "invalid secp256k1 signature of %d bytes", len(sig))
+	}
+	if r.Sign() <= 0 || r.Cmp(secp256k1N) >= 0 || s.Sign() <= 0 || s.Cmp(secp256k1N) >= 0 {
+		return nil, nil, errcode.New(errcode.CodeInternal, "invalid secp256k1 signature values")
+	}
+	return r, s, nil
+}
+
+func errMismatch() error {
+	return errcode.New(errcode.CodeInternal, "the signature does not verify with the public key of the signer")
+}
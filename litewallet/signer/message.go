@@ -0,0 +1,41 @@
+package signer
+
+import (
+	"github.com/QOSGroup/litewallet/litewallet/errcode"
+)
+
+//the amino names of the public keys of the message signatures
+const (
+	PubKeySecp256k1Type = "tendermint/PubKeySecp256k1"
+	PubKeyEd25519Type   = "tendermint/PubKeyEd25519"
+)
+
+//MessageSignature is the signature of a message along with the public key it verifies with, as the Cosmos
+//wallets return it, the bytes are base64 in the JSON. The Cosmos and QOS signatures can not recover the key.
+type MessageSignature struct {
+	PubKey    MessagePubKey `json:"pub_key"`
+	Signature []byte        `json:"signature"`
+}
+
+//MessagePubKey is the public key of a MessageSignature
+type MessagePubKey struct {
+	Type  string `json:"type"`
+	Value []byte `json:"value"`
+}
+
+//NewMessageSignature returns the signature sig of s
+func NewMessageSignature(s Signer, sig []byte) MessageSignature {
+	pubKeyType := PubKeySecp256k1Type
+	if s.Algo() == AlgoEd25519 {
+		pubKeyType = PubKeyEd25519Type
+	}
+	return MessageSignature{PubKey: MessagePubKey{Type: pubKeyType, Value: s.PubKey()}, Signature: sig}
+}
+
+//CheckType checks the public key of sig is a pubKeyType one
+func (sig MessageSignature) CheckType(pubKeyType string) error {
+	if sig.PubKey.Type != pubKeyType {
+		return errcode.Errorf(errcode.CodeInvalidInput, "got a %s signature, want %s", sig.PubKey.Type, pubKeyType)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+//Package signer signs the txs of litewallet with keys which may live outside of the Go process.
+//
+//A Signer is a public key and the signature of a message with its private key, the tx builders of the
+//ETH, Cosmos and QOS wallets only use these two. The software signers hold the private key in memory, the
+//callback signers leave it to the app, such as the Android Keystore, the iOS Secure Enclave or an external
+//device, and check what the app returns.
+//
+//The secp256k1 signers sign a 32 bytes digest, each chain hashes its sign bytes itself: keccak256 for ETH,
+//sha256 for Cosmos. The signature is 65 bytes R||S||V with a low S and V the recovery id 0 or 1, the form of
+//go-ethereum, Cosmos takes its first 64 bytes. The ed25519 signers sign the message, ed25519 hashes it itself.
+package signer
+
+import (
+	"crypto/ecdsa"
+
+	"github.com/QOSGroup/litewallet/litewallet/errcode"
+	"github.com/QOSGroup/litewallet/litewallet/slim/tendermint/crypto/funcInlocal/ed25519local"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+//the algorithms of the signers
+const (
+	AlgoSecp256k1 = "secp256k1"
+	AlgoEd25519   = "ed25519"
+)
+
+const (
+	//DigestSize is the size of the digests the secp256k1 signers sign
+	DigestSize = 32
+	//Secp256k1SignatureSize is the size of the R||S||V signatures of the secp256k1 signers
+	Secp256k1SignatureSize = 65
+	//Ed25519SignatureSize is the size of the signatures of the ed25519 signers
+	Ed25519SignatureSize = 64
+)
+
+//Signer is a key the txs are signed with
+type Signer interface {
+	//Algo is AlgoSecp256k1 or AlgoEd25519
+	Algo() string
+	//PubKey is the 33 bytes compressed secp256k1 public key or the 32 bytes ed25519 one
+	PubKey() []byte
+	//Sign signs a digest with secp256k1 or a message with ed25519
+	Sign(msg []byte) ([]byte, error)
+}
+
+//secp256k1Signer is a secp256k1 key in memory
+type secp256k1Signer struct {
+	key *ecdsa.PrivateKey
+}
+
+//NewSecp256k1 returns the software signer of a secp256k1 key
+func NewSecp256k1(key *ecdsa.PrivateKey) Signer {
+	return secp256k1Signer{key}
+}
+
+//ParseSecp256k1 returns the software signer of the 32 bytes secp256k1 private key priv
+func ParseSecp256k1(priv []byte) (Signer, error) {
+	key, err := crypto.ToECDSA(priv)
+	if err != nil {
+		return nil, errcode.InvalidInput(err)
+	}
+	return secp256k1Signer{key}, nil
+}
+
+func (s secp256k1Signer) Algo() string {
+	return AlgoSecp256k1
+}
+
+func (s secp256k1Signer) PubKey() []byte {
+	return crypto.CompressPubkey(&s.key.PublicKey)
+}
+
+func (s secp256k1Signer) Sign(digest []byte) ([]byte, error) {
+	if len(digest) != DigestSize {
+		return nil, errcode.Errorf(errcode.CodeInvalidInput, "secp256k1 signs a %d bytes digest, got %d bytes", DigestSize, len(digest))
+	}
+	sig, err := crypto.Sign(digest, s.key)
+	if err != nil {
+		return nil, errcode.Internal(err)
+	}
+	return sig, nil
+}
+
+//ed25519Signer is an ed25519 key in memory
+type ed25519Signer struct {
+	key ed25519local.PrivKeyEd25519
+}
+
+//NewEd25519 returns the software signer of an ed25519 key
+func NewEd25519(key ed25519local.PrivKeyEd25519) Signer {
+	return ed25519Signer{key}
+}
+
+func (s ed25519Signer) Algo() string {
+	return AlgoEd25519
+}
+
+func (s ed25519Signer) PubKey() []byte {
+	pub := s.key.PubKey().(ed25519local.PubKeyEd25519)
+	return pub[:]
+}
+
+func (s ed25519Signer) Sign(msg []byte) ([]byte, error) {
+	sig, err := s.key.Sign(msg)
+	if err != nil {
+		return nil, errcode.Internal(err)
+	}
+	return sig, nil
+}
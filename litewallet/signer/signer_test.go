@@ -0,0 +1,123 @@
+package signer_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/QOSGroup/litewallet/litewallet/errcode"
+	"github.com/QOSGroup/litewallet/litewallet/signer"
+	"github.com/QOSGroup/litewallet/litewallet/signer/signertest"
+	"github.com/QOSGroup/litewallet/litewallet/slim/tendermint/crypto/funcInlocal/ed25519local"
+	"github.com/ethereum/go-ethereum/crypto"
+	tmcrypto "github.com/tendermint/tendermint/crypto"
+	"github.com/tendermint/tendermint/crypto/secp256k1"
+)
+
+func TestSecp256k1(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	soft := signer.NewSecp256k1(key)
+	device := signertest.NewSecp256k1(key)
+	callback := device.Signer()
+	if !bytes.Equal(callback.PubKey(), soft.PubKey()) || len(soft.PubKey()) != 33 {
+		t.Fatalf("got %X, want the compressed key %X", callback.PubKey(), soft.PubKey())
+	}
+
+	var cosmosPub secp256k1.PubKeySecp256k1
+	copy(cosmosPub[:], soft.PubKey())
+	msg := []byte("sign bytes")
+	digest := crypto.Keccak256(msg)
+	//the device signs with a high S every other time, the signatures are normalized either way
+	for i := 0; i < 4; i++ {
+		for _, s := range []signer.Signer{soft, callback} {
+			sig, err := s.Sign(digest)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if pub, err := crypto.SigToPub(digest, sig); err != nil || crypto.PubkeyToAddress(*pub) != crypto.PubkeyToAddress(key.PublicKey) {
+				t.Errorf("got %v, %v, want the signature to recover the key", pub, err)
+			}
+			if !crypto.VerifySignature(s.PubKey(), digest, sig[:64]) {
+				t.Error("want a low S signature")
+			}
+		}
+	}
+	if device.Signs() != 4 {
+		t.Errorf("got %d signatures of the device, want 4", device.Signs())
+	}
+	//Cosmos verifies r||s over the sha256 of the sign bytes
+	sig, _ := callback.Sign(tmcrypto.Sha256(msg))
+	if !cosmosPub.VerifyBytes(msg, sig[:64]) {
+		t.Error("want the signature to verify with the Cosmos key")
+	}
+
+	if _, err := callback.Sign(msg); errcode.Code(err) != errcode.CodeInvalidInput {
+		t.Errorf("got %v, want an invalid input error for a message which is not a digest", err)
+	}
+	refused := errors.New("cancelled by the user")
+	device.Refuse(refused)
+	if _, err := callback.Sign(digest); err != refused {
+		t.Errorf("got %v, want the error of the device", err)
+	}
+	device.Refuse(nil)
+
+	//a device with another key
+	other, _ := crypto.GenerateKey()
+	wrong, err := signer.NewCallback(signer.AlgoSecp256k1, soft.PubKey(), signertest.NewSecp256k1(other).Sign)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wrong.Sign(digest); err == nil {
+		t.Error("want an error for a signature of another key")
+	}
+	for _, sig := range [][]byte{nil, {0x30, 0x02}, make([]byte, 64)} {
+		bad, _ := signer.NewCallback(signer.AlgoSecp256k1, soft.PubKey(), func([]byte) ([]byte, error) { return sig, nil })
+		if _, err := bad.Sign(digest); err == nil {
+			t.Errorf("want an error for the signature %X", sig)
+		}
+	}
+}
+
+func TestEd25519(t *testing.T) {
+	key := ed25519local.GenPrivKeyFromSecret([]byte("secret"))
+	soft := signer.NewEd25519(key)
+	device := signertest.NewEd25519(key)
+	callback := device.Signer()
+	if !bytes.Equal(callback.PubKey(), soft.PubKey()) || callback.Algo() != signer.AlgoEd25519 {
+		t.Fatalf("got %X, want the key %X", callback.PubKey(), soft.PubKey())
+	}
+	msg := []byte("sign bytes of any size")
+	pub := key.PubKey()
+	for _, s := range []signer.Signer{soft, callback} {
+		sig, err := s.Sign(msg)
+		if err != nil || !pub.VerifyBytes(msg, sig) {
+			t.Errorf("got %X, %v, want a signature of the key", sig, err)
+		}
+	}
+
+	other := signertest.NewEd25519(ed25519local.GenPrivKeyFromSecret([]byte("other")))
+	wrong, _ := signer.NewCallback(signer.AlgoEd25519, soft.PubKey(), other.Sign)
+	if _, err := wrong.Sign(msg); err == nil {
+		t.Error("want an error for a signature of another key")
+	}
+}
+
+func TestNewCallback(t *testing.T) {
+	sign := func(msg []byte) ([]byte, error) { return nil, nil }
+	key, _ := crypto.GenerateKey()
+	for _, c := range []struct {
+		algo   string
+		pubKey []byte
+		sign   func([]byte) ([]byte, error)
+	}{
+		{signer.AlgoSecp256k1, crypto.CompressPubkey(&key.PublicKey), nil},
+		{signer.AlgoSecp256k1, make([]byte, 33), sign},
+		{signer.AlgoSecp256k1, make([]byte, 32), sign},
+		{signer.AlgoEd25519, make([]byte, 33), sign},
+		{"p256", crypto.CompressPubkey(&key.PublicKey), sign},
+	} {
+		if _, err := signer.NewCallback(c.algo, c.pubKey, c.sign); errcode.Code(err) != errcode.CodeInvalidInput {
+			t.Errorf("got %v for %s %X, want an invalid input error", err, c.algo, c.pubKey)
+		}
+	}
+}
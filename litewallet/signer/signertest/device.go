@@ -0,0 +1,95 @@
+//Package signertest is a stand-in of the devices the apps keep their keys in, the Android Keystore, the iOS
+//Secure Enclave or an external device, so the callback signers are tested without any special hardware.
+package signertest
+
+import (
+	"crypto/ecdsa"
+	"encoding/asn1"
+	"math/big"
+	"sync"
+
+	"github.com/QOSGroup/litewallet/litewallet/signer"
+	"github.com/QOSGroup/litewallet/litewallet/slim/tendermint/crypto/funcInlocal/ed25519local"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+//Device holds a key and signs like the devices do: the secp256k1 public key is uncompressed and the
+//signatures are DER with a high S every other time, as a device does not normalize S. Device has the methods
+//of the callback signers of the apps.
+type Device struct {
+	mtx   sync.Mutex
+	secp  *ecdsa.PrivateKey
+	ed    *ed25519local.PrivKeyEd25519
+	err   error
+	signs int
+}
+
+//NewSecp256k1 returns a device of the secp256k1 key
+func NewSecp256k1(key *ecdsa.PrivateKey) *Device {
+	return &Device{secp: key}
+}
+
+//NewEd25519 returns a device of the ed25519 key
+func NewEd25519(key ed25519local.PrivKeyEd25519) *Device {
+	return &Device{ed: &key}
+}
+
+func (d *Device) Algo() string {
+	if d.ed != nil {
+		return signer.AlgoEd25519
+	}
+	return signer.AlgoSecp256k1
+}
+
+func (d *Device) PubKey() []byte {
+	if d.ed != nil {
+		pub := d.ed.PubKey().(ed25519local.PubKeyEd25519)
+		return pub[:]
+	}
+	return crypto.FromECDSAPub(&d.secp.PublicKey)
+}
+
+func (d *Device) Sign(msg []byte) ([]byte, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	if d.err != nil {
+		return nil, d.err
+	}
+	d.signs++
+	if d.ed != nil {
+		return d.ed.Sign(msg)
+	}
+	sig, err := crypto.Sign(msg, d.secp)
+	if err != nil {
+		return nil, err
+	}
+	r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:64])
+	if d.signs%2 == 0 {
+		s.Sub(crypto.S256().Params().N, s)
+	}
+	return asn1.Marshal(struct{ R, S *big.Int }{r, s})
+}
+
+//Refuse makes the device return err instead of the signatures, as a user cancelling its prompt, nil
+//makes it sign again
+func (d *Device) Refuse(err error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	d.err = err
+}
+
+//Signs returns the number of signatures of the device
+func (d *Device) Signs() int {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	return d.signs
+}
+
+//Signer returns the callback signer of the device
+func (d *Device) Signer() signer.Signer {
+	s, err := signer.NewCallback(d.Algo(), d.PubKey(), d.Sign)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
@@ -6,57 +6,66 @@ import (
 	"crypto/rand"
 	"encoding/base64"
 	"fmt"
+	"github.com/QOSGroup/litewallet/litewallet/errcode"
 	"github.com/QOSGroup/litewallet/litewallet/slim/tendermint/crypto/funcInlocal/respwrap"
 	"github.com/QOSGroup/litewallet/litewallet/slim/txs"
-	"github.com/pkg/errors"
 	"io"
 )
 
 func AesEncrypt(keystring, text string) string {
+	result, err := Encrypt(keystring, text)
+	if err != nil {
+		resp, _ := respwrap.ResponseWrapper(txs.Cdc, nil, err)
+		return string(resp)
+	}
+	resp, _ := respwrap.ResponseWrapper(txs.Cdc, result, nil)
+	out := string(resp)
+	return out
+}
+
+//Encrypt encrypts text with AES-CFB under keystring and returns it in url base64
+func Encrypt(keystring, text string) (string, error) {
 	key := []byte(keystring)
 	plaintext := []byte(text)
 	block, err := aes.NewCipher(key)
 	if err != nil {
-		//panic(err)
-		resp, _ := respwrap.ResponseWrapper(txs.Cdc, nil, err)
-		return string(resp)
+		return "", errcode.InvalidInput(err)
 	}
 	ciphertext := make([]byte, aes.BlockSize+len(plaintext))
 	iv := ciphertext[:aes.BlockSize]
 	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
-		//panic(err)
-		resp, _ := respwrap.ResponseWrapper(txs.Cdc, nil, err)
-		return string(resp)
+		return "", err
 	}
 	stream := cipher.NewCFBEncrypter(block, iv)
 	stream.XORKeyStream(ciphertext[aes.BlockSize:], plaintext)
-	result := base64.URLEncoding.EncodeToString(ciphertext)
+	return base64.URLEncoding.EncodeToString(ciphertext), nil
+}
+
+func AesDecrypt(keystring, cryptoText string) string {
+	result, err := Decrypt(keystring, cryptoText)
+	if err != nil {
+		resp, _ := respwrap.ResponseWrapper(txs.Cdc, nil, err)
+		return string(resp)
+	}
 	resp, _ := respwrap.ResponseWrapper(txs.Cdc, result, nil)
 	out := string(resp)
 	return out
 }
 
-func AesDecrypt(keystring, cryptoText string) string {
+//Decrypt reverses Encrypt
+func Decrypt(keystring, cryptoText string) (string, error) {
 	key := []byte(keystring)
 	ciphertext, _ := base64.URLEncoding.DecodeString(cryptoText)
 	block, err := aes.NewCipher(key)
 	if err != nil {
-		//panic(err)
-		resp, _ := respwrap.ResponseWrapper(txs.Cdc, nil, err)
-		return string(resp)
+		return "", errcode.InvalidInput(err)
 	}
 	if len(ciphertext) < aes.BlockSize {
-		//panic("Ciphertext too short")
-		err := errors.Errorf("Ciphertext too short")
-		resp, _ := respwrap.ResponseWrapper(txs.Cdc, nil, err)
-		return string(resp)
+		return "", errcode.New(errcode.CodeInvalidInput, "Ciphertext too short")
 	}
 	iv := ciphertext[:aes.BlockSize]
 	ciphertext = ciphertext[aes.BlockSize:]
 	stream := cipher.NewCFBDecrypter(block, iv)
 	stream.XORKeyStream(ciphertext, ciphertext)
-	result := fmt.Sprintf("%s", ciphertext)
-	resp, _ := respwrap.ResponseWrapper(txs.Cdc, result, nil)
-	out := string(resp)
-	return out
+	return fmt.Sprintf("%s", ciphertext), nil
 }
@@ -2,15 +2,23 @@ package context
 
 import (
 	"fmt"
+	"path/filepath"
+
 	cmn "github.com/QOSGroup/litewallet/litewallet/slim/tendermint/libs/common"
 	rpcclient "github.com/QOSGroup/litewallet/litewallet/slim/tendermint/rpc/client"
+	"github.com/QOSGroup/litewallet/litewallet/trust"
 	"github.com/pkg/errors"
 	go_amino "github.com/tendermint/go-amino"
+	tmclient "github.com/tendermint/tendermint/rpc/client"
 	ctypes "github.com/tendermint/tendermint/rpc/core/types"
 )
 
 // CLIContext implements a typical CLI context created in SDK modules for
-// transaction handling and queries.
+// transaction handling and queries. Unless TrustNode is set, the answers to
+// the key queries of the stores are verified against the app hash of a header
+// verified by the light client of the trust package. The node has to serve the
+// chain ChainID, taken from its header at height 1 when empty, and the trusted
+// headers are kept under RootDir, in memory when empty.
 type CLIContext struct {
 	Codec        *go_amino.Codec
 	Client       *rpcclient.HTTP
@@ -18,6 +26,24 @@ type CLIContext struct {
 	NodeURI      string
 	TrustNode    bool
 	NonceNodeURI string
+	RootDir      string
+	ChainID      string
+	LatestState  bool
+}
+
+//TrustDir is the directory of the trusted headers of the QOS chains under rootDir
+func TrustDir(rootDir string) string {
+	return filepath.Join(rootDir, ".qoscli", ".qoslite")
+}
+
+//NewVerifier returns the verifier of the headers of chainID from client, trusted under rootDir. The chain id
+//is taken from the header at height 1 when it is empty, the trusted headers are kept in memory without rootDir.
+func NewVerifier(rootDir, chainID string, client tmclient.Client) *trust.Verifier {
+	dir := ""
+	if rootDir != "" {
+		dir = TrustDir(rootDir)
+	}
+	return trust.NewVerifier(dir, chainID, client)
 }
 
 // NewCLIContext returns a new initialized CLIContext with parameters from the
@@ -29,10 +55,25 @@ func NewCLIContext(remote string) CLIContext {
 		Client:    rpc,
 		NodeURI:   remote,
 		Height:    0,
-		TrustNode: true,
+		TrustNode: false,
 	}
 }
 
+// WithTrust returns a copy of the context verifying the chain chainID, whose
+// trusted headers are kept under rootDir.
+func (ctx CLIContext) WithTrust(rootDir, chainID string) CLIContext {
+	ctx.RootDir, ctx.ChainID = rootDir, chainID
+	return ctx
+}
+
+// WithLatestState returns a copy of the context whose queries without a height
+// read the state of the latest block, waiting for the block proving it, such
+// as the nonce of an account right after its last tx.
+func (ctx CLIContext) WithLatestState(latest bool) CLIContext {
+	ctx.LatestState = latest
+	return ctx
+}
+
 // WithCodec returns a copy of the context with an updated codec.
 func (ctx CLIContext) WithCodec(cdc *go_amino.Codec) CLIContext {
 	ctx.Codec = cdc
@@ -79,7 +120,19 @@ func (ctx CLIContext) query(path string, key cmn.HexBytes) (res []byte, err erro
 
 	opts := rpcclient.ABCIQueryOptions{
 		Height: ctx.Height,
-		Prove:  ctx.TrustNode,
+		Prove:  !ctx.TrustNode,
+	}
+
+	var verifier *trust.Verifier
+	if !ctx.TrustNode {
+		verifier = NewVerifier(ctx.RootDir, ctx.ChainID, tmclient.NewHTTP(ctx.NodeURI, "/websocket"))
+		//the state of the latest block is proved by the next header, which the node has yet to make, so
+		//the queries read the state one block old unless they wait for it with LatestState
+		if opts.Height == 0 && !ctx.LatestState {
+			if opts.Height, err = verifier.ProvedHeight(); err != nil {
+				return res, err
+			}
+		}
 	}
 
 	result, err := node.ABCIQueryWithOptions(path, key, opts)
@@ -92,6 +145,12 @@ func (ctx CLIContext) query(path string, key cmn.HexBytes) (res []byte, err erro
 		return res, fmt.Errorf(resp.Log)
 	}
 
+	if verifier != nil {
+		if err := verifier.VerifyQuery(path, key, resp); err != nil {
+			return res, err
+		}
+	}
+
 	return resp.Value, nil
 }
 
@@ -2,6 +2,8 @@ package tx
 
 import (
 	"fmt"
+	"github.com/QOSGroup/litewallet/litewallet/errcode"
+	"github.com/QOSGroup/litewallet/litewallet/signer"
 	"github.com/QOSGroup/litewallet/litewallet/slim/base/client/account"
 	"github.com/QOSGroup/litewallet/litewallet/slim/base/client/context"
 	"github.com/QOSGroup/litewallet/litewallet/slim/base/txs"
@@ -20,7 +22,17 @@ const (
 
 type ITxBuilder func() (txs.ITx, error)
 
-func BuildAndSignTx(ctx context.CLIContext, privkey, chainId string, txBuilder ITxBuilder) (signedTx []byte, err error) {
+//BuildAndSignTx builds the tx of txBuilder and signs it with the base64 ed25519 privkey
+func BuildAndSignTx(ctx context.CLIContext, privkey, chainId string, txBuilder ITxBuilder) ([]byte, error) {
+	s, err := PrivKeySigner(privkey)
+	if err != nil {
+		return nil, err
+	}
+	return BuildAndSignTxWithSigner(ctx, s, chainId, txBuilder)
+}
+
+//BuildAndSignTxWithSigner builds the tx of txBuilder and signs it with s, a key which may live outside of the wallet
+func BuildAndSignTxWithSigner(ctx context.CLIContext, s signer.Signer, chainId string, txBuilder ITxBuilder) (signedTx []byte, err error) {
 	defer func() {
 		if r := recover(); r != nil {
 			log := fmt.Sprintf("buildAndSignTx recovered: %v\n", string(debug.Stack()))
@@ -41,7 +53,7 @@ func BuildAndSignTx(ctx context.CLIContext, privkey, chainId string, txBuilder I
 	//} else {
 	//	return BuildAndSignStdTx(ctx, []txs.ITx{itx}, "", toChainID)
 	//}
-	msg, err := BuildAndSignStdTx(ctx, itx, privkey, chainId)
+	msg, err := BuildAndSignStdTxWithSigner(ctx, itx, s, chainId)
 	if err != nil {
 		return nil, err
 	}
@@ -56,28 +68,32 @@ func BuildAndSignTx(ctx context.CLIContext, privkey, chainId string, txBuilder I
 }
 
 func BuildAndSignStdTx(ctx context.CLIContext, tx txs.ITx, privkey, chainId string) (*txs.TxStd, error) {
+	s, err := PrivKeySigner(privkey)
+	if err != nil {
+		return nil, err
+	}
+	return BuildAndSignStdTxWithSigner(ctx, tx, s, chainId)
+}
+
+//BuildAndSignStdTxWithSigner is BuildAndSignStdTx signed by s
+func BuildAndSignStdTxWithSigner(ctx context.CLIContext, tx txs.ITx, s signer.Signer, chainId string) (*txs.TxStd, error) {
 	gas := types.NewInt(int64(MaxGas))
 	txStd := txs.NewTxStd(tx, chainId, gas)
 
-	var key ed25519local.PrivKeyEd25519
-	ts := "{\"type\": \"tendermint/PrivKeyEd25519\",\"value\": \"" + privkey + "\"}"
-	err := ctx.Codec.UnmarshalJSON([]byte(ts), &key)
+	addr, err := SignerAddress(s)
 	if err != nil {
-		fmt.Println(err)
+		return nil, err
 	}
-	addrben32, _ := bech32local.ConvertAndEncode(types.PREF_ADD, key.PubKey().Address().Bytes())
+	addrben32, _ := bech32local.ConvertAndEncode(types.PREF_ADD, addr)
 	from, err := account.GetAddrFromValue(addrben32)
 	if err != nil {
 		return nil, err
 	}
 
-	//Get "nonce" from the func RpcQueryAccount
-	acc, _ := qtxs.QueryAccount(from)
-	var qscnonce int64
-	if acc != nil {
-		qscnonce = int64(acc.Nonce)
+	qscnonce, err := nextNonce(ctx, from)
+	if err != nil {
+		return nil, err
 	}
-	qscnonce++
 	//var actualNonce int64
 	//nonce, err := getDefaultAccountNonce(ctx, from.Bytes())
 	//if err != nil || nonce < 0 {
@@ -85,23 +101,94 @@ func BuildAndSignStdTx(ctx context.CLIContext, tx txs.ITx, privkey, chainId stri
 	//}
 	//actualNonce = nonce + 1
 
-	return signStdTx(key, qscnonce, txStd, chainId, ""), nil
+	return SignStdTx(s, qscnonce, txStd, chainId, "")
+}
+
+//BuildStdTx builds the unsigned tx of txBuilder from the account from and returns it with the nonce it is signed
+//with, the one following the nonce of from on the node of ctx
+func BuildStdTx(ctx context.CLIContext, from types.AccAddress, chainId string, txBuilder ITxBuilder) (*txs.TxStd, int64, error) {
+	itx, err := txBuilder()
+	if err != nil {
+		return nil, 0, err
+	}
+	nonce, err := nextNonce(ctx, from)
+	if err != nil {
+		return nil, 0, err
+	}
+	gas := types.NewInt(int64(MaxGas))
+	return txs.NewTxStd(itx, chainId, gas), nonce, nil
 }
 
-func signStdTx(priKey ed25519local.PrivKeyEd25519, nonce int64, txStd *txs.TxStd, chainid string, fromChainID string) *txs.TxStd {
+//nextNonce returns the nonce following the one of from in the latest state on the node of ctx, which has
+//the txs of the latest block signed by from
+func nextNonce(ctx context.CLIContext, from types.AccAddress) (int64, error) {
+	nonce, err := account.GetAccountNonce(ctx.WithLatestState(true), from)
+	if err != nil {
+		return 0, err
+	}
+	return nonce + 1, nil
+}
+
+//SignStdTx adds the signature of s with nonce to txStd, the signature of the chain chainid, or of fromChainID
+//in a cross chain tx
+func SignStdTx(s signer.Signer, nonce int64, txStd *txs.TxStd, chainid string, fromChainID string) (*txs.TxStd, error) {
 	//gas := NewBigInt(int64(MaxGas))
 	//stx := txs.NewTxStd(sendTx, chainid, gas)
 
-	signature, _ := txStd.SignTx(priKey, nonce, "", chainid)
-	//sigdata := txStd.BuildSignatureBytes(nonce, fromChainID)
+	pubKey, err := signerPubKey(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(txStd.ITxs) == 0 {
+		return nil, errcode.New(errcode.CodeInvalidInput, "Signature txstd err(itx is nil)")
+	}
+	if txStd.ChainID != chainid {
+		return nil, errcode.New(errcode.CodeInvalidInput, "toChainID not match txStd's chainID")
+	}
+	signature, err := s.Sign(txStd.BuildSignatureBytes(nonce, fromChainID))
+	if err != nil {
+		return nil, err
+	}
 	//sig, pubkey := signData(ctx, signerKeyName, sigdata)
 	txStd.Signature = append(txStd.Signature, txs.Signature{
-		Pubkey:    priKey.PubKey(),
+		Pubkey:    pubKey,
 		Signature: signature,
 		Nonce:     nonce,
 	})
 
-	return txStd
+	return txStd, nil
+}
+
+//PrivKeySigner returns the signer of the base64 ed25519 privkey
+func PrivKeySigner(privkey string) (signer.Signer, error) {
+	var key ed25519local.PrivKeyEd25519
+	ts := "{\"type\": \"tendermint/PrivKeyEd25519\",\"value\": \"" + privkey + "\"}"
+	if err := qtxs.Cdc.UnmarshalJSON([]byte(ts), &key); err != nil {
+		return nil, errcode.InvalidInput(err)
+	}
+	return signer.NewEd25519(key), nil
+}
+
+//signerPubKey returns the public key of the ed25519 signer s
+func signerPubKey(s signer.Signer) (ed25519local.PubKeyEd25519, error) {
+	var pubKey ed25519local.PubKeyEd25519
+	if s.Algo() != signer.AlgoEd25519 {
+		return pubKey, errcode.Errorf(errcode.CodeInvalidInput, "QOS signs with ed25519, not %s", s.Algo())
+	}
+	if len(s.PubKey()) != ed25519local.PubKeyEd25519Size {
+		return pubKey, errcode.Errorf(errcode.CodeInvalidInput, "invalid ed25519 public key of %d bytes", len(s.PubKey()))
+	}
+	copy(pubKey[:], s.PubKey())
+	return pubKey, nil
+}
+
+//SignerAddress returns the account address of the ed25519 signer s
+func SignerAddress(s signer.Signer) (types.AccAddress, error) {
+	pubKey, err := signerPubKey(s)
+	if err != nil {
+		return nil, err
+	}
+	return types.AccAddress(pubKey.Address()), nil
 }
 
 func getDefaultAccountNonce(ctx context.CLIContext, address []byte) (int64, error) {
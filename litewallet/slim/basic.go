@@ -1,15 +1,13 @@
 package slim
 
 import (
-	"fmt"
+	"github.com/QOSGroup/litewallet/litewallet/errcode"
 	"github.com/QOSGroup/litewallet/litewallet/slim/base/types"
 	"github.com/QOSGroup/litewallet/litewallet/slim/tendermint/crypto/funcInlocal/bech32local"
 	"github.com/QOSGroup/litewallet/litewallet/slim/tendermint/crypto/funcInlocal/bip39local"
 	"github.com/QOSGroup/litewallet/litewallet/slim/tendermint/crypto/funcInlocal/ed25519local"
 	"github.com/QOSGroup/litewallet/litewallet/slim/tendermint/crypto/funcInlocal/respwrap"
 	"github.com/QOSGroup/litewallet/litewallet/slim/txs"
-	"github.com/pkg/errors"
-	"log"
 )
 
 type ResultCreateAccount struct {
@@ -38,53 +36,29 @@ const (
 	DenomQOS          = "qos"
 )
 
+//Deprecated: use CreateAccount, AccountCreate returns nil on failure
 func AccountCreate(password string) *ResultCreateAccount {
-	entropy, _ := bip39local.NewEntropy(256)
-	mnemonic, _ := bip39local.NewMnemonic(entropy)
-	if len(password) == 0 {
-		password = "DNWTTY"
-	}
-	seedo := bip39local.NewSeed(mnemonic, password)
+	result, _ := CreateAccount(password)
+	return result
+}
 
-	key := ed25519local.GenPrivKeyFromSecret(seedo)
-	//pub := key.PubKey().Bytes()
-	pub := key.PubKey()
-	pubkeyAmino, _ := txs.Cdc.MarshalJSON(pub)
-	var pubkeyAminoStc PubkeyAmino
-	err := txs.Cdc.UnmarshalJSON(pubkeyAmino, &pubkeyAminoStc)
+//CreateAccount generates a new mnemonic and derives the account from it with the password, by the legacy
+//derivation, the keystore functions derive SLIP-0010 keys
+func CreateAccount(password string) (*ResultCreateAccount, error) {
+	mnemonic, err := bip39local.GenerateMnemonic(MnemonicWords, bip39local.English, nil)
 	if err != nil {
-		log.Fatalln(err.Error())
-	}
-	pubkeyAminoStr := pubkeyAminoStc.Value
-
-	addr := key.PubKey().Address()
-	//bech32Pub, _ := bech32local.ConvertAndEncode(Bech32PrefixAccPub, pub)
-	bech32Addr, _ := bech32local.ConvertAndEncode(types.PREF_ADD, addr.Bytes())
-
-	privkeyAmino, _ := txs.Cdc.MarshalJSON(key)
-	var privkeyAminoStc PrivkeyAmino
-	err1 := txs.Cdc.UnmarshalJSON(privkeyAmino, &privkeyAminoStc)
-	if err1 != nil {
-		log.Fatalln(err1.Error())
+		return nil, err
 	}
-	privkeyAminoStr := privkeyAminoStc.Value
-
-	Type := AccountResultType
-
-	result := &ResultCreateAccount{}
-	result.PubKey = pubkeyAminoStr
-	result.PrivKey = privkeyAminoStr
-	result.Addr = bech32Addr
-	result.Mnemonic = mnemonic
-	result.Type = Type
-	result.Denom = DenomQOS
-
-	return result
+	return RecoverAccount(mnemonic, password)
 }
 
 //convert the output to json string format
 func AccountCreateStr(password string) string {
-	acc := AccountCreate(password)
+	acc, err := CreateAccount(password)
+	if err != nil {
+		resp, _ := respwrap.ResponseWrapper(txs.Cdc, nil, err)
+		return string(resp)
+	}
 	result, _ := respwrap.ResponseWrapper(txs.Cdc, acc, nil)
 	out := string(result)
 
@@ -92,52 +66,42 @@ func AccountCreateStr(password string) string {
 }
 
 func AccountRecoverStr(mncode, password string) string {
+	result, err := RecoverAccount(mncode, password)
+	if err != nil {
+		resp, _ := respwrap.ResponseWrapper(txs.Cdc, nil, err)
+		return string(resp)
+	}
+	resp, _ := respwrap.ResponseWrapper(txs.Cdc, result, nil)
+	out := string(resp)
+	return out
+}
+
+//RecoverAccount derives the account of the mnemonic by the legacy derivation, an empty password falls back
+//to LegacyDefaultPassphrase
+func RecoverAccount(mncode, password string) (*ResultCreateAccount, error) {
 	if len(password) == 0 {
-		password = "DNWTTY"
+		password = LegacyDefaultPassphrase
 	}
 	// add mnemonics validation
 	if bip39local.IsMnemonicValid(mncode) == false {
-		err := errors.Errorf("Invalid mnemonic!")
-		resp, _ := respwrap.ResponseWrapper(txs.Cdc, nil, err)
-		return string(resp)
-
+		return nil, errcode.New(errcode.CodeInvalidInput, "Invalid mnemonic!")
 	}
 
 	seed := bip39local.NewSeed(mncode, password)
 	key := ed25519local.GenPrivKeyFromSecret(seed)
-	pub := key.PubKey()
-	pubkeyAmino, _ := txs.Cdc.MarshalJSON(pub)
-	var pubkeyAminoStc PubkeyAmino
-	err := txs.Cdc.UnmarshalJSON(pubkeyAmino, &pubkeyAminoStc)
+	pubkeyAminoStr, privkeyAminoStr, bech32Addr, err := aminoKeyValues(key)
 	if err != nil {
-		log.Fatalln(err.Error())
-	}
-	pubkeyAminoStr := pubkeyAminoStc.Value
-
-	addr := key.PubKey().Address()
-	//bech32Pub, _ := bech32local.ConvertAndEncode("cosmosaccpub", pub)
-	bech32Addr, _ := bech32local.ConvertAndEncode(types.PREF_ADD, addr.Bytes())
-
-	privkeyAmino, _ := txs.Cdc.MarshalJSON(key)
-	var privkeyAminoStc PrivkeyAmino
-	err1 := txs.Cdc.UnmarshalJSON(privkeyAmino, &privkeyAminoStc)
-	if err1 != nil {
-		log.Fatalln(err1.Error())
+		return nil, err
 	}
-	privkeyAminoStr := privkeyAminoStc.Value
 
-	Type := AccountResultType
 	result := &ResultCreateAccount{}
 	result.PubKey = pubkeyAminoStr
 	result.PrivKey = privkeyAminoStr
 	result.Addr = bech32Addr
 	result.Mnemonic = mncode
-	result.Type = Type
+	result.Type = AccountResultType
 	result.Denom = DenomQOS
-
-	resp, _ := respwrap.ResponseWrapper(txs.Cdc, result, nil)
-	out := string(resp)
-	return out
+	return result, nil
 }
 
 type PubAddrRetrieval struct {
@@ -146,34 +110,35 @@ type PubAddrRetrieval struct {
 }
 
 func PubAddrRetrievalStr(s string) string {
+	result, err := RetrievePubAddr(s)
+	if err != nil {
+		resp, _ := respwrap.ResponseWrapper(txs.Cdc, nil, err)
+		return string(resp)
+	}
+	resp, _ := respwrap.ResponseWrapper(txs.Cdc, result, nil)
+	out := string(resp)
+	return out
+}
+
+//RetrievePubAddr returns the pubkey and address of the base64 private key
+func RetrievePubAddr(s string) (*PubAddrRetrieval, error) {
 	//change the private unmarshal format according to the other pack
 	ts := "{\"type\": \"tendermint/PrivKeyEd25519\",\"value\": \"" + s + "\"}"
 	var key ed25519local.PrivKeyEd25519
 
 	err := txs.Cdc.UnmarshalJSON([]byte(ts), &key)
 	if err != nil {
-		fmt.Println(err)
+		return nil, errcode.InvalidInput(err)
 	}
-	pub := key.PubKey()
-	pubkeyAmino, _ := txs.Cdc.MarshalJSON(pub)
-	var pubkeyAminoStc PubkeyAmino
-	err1 := txs.Cdc.UnmarshalJSON(pubkeyAmino, &pubkeyAminoStc)
-	if err1 != nil {
-		log.Fatalln(err1.Error())
+	pubkeyAminoStr, _, bech32Addr, err := aminoKeyValues(key)
+	if err != nil {
+		return nil, err
 	}
-	pubkeyAminoStr := pubkeyAminoStc.Value
-
-	addr := key.PubKey().Address()
-	//bech32Pub, _ := bech32local.ConvertAndEncode(Bech32PrefixAccPub, pub)
-	bech32Addr, _ := bech32local.ConvertAndEncode(types.PREF_ADD, addr.Bytes())
 
 	result := &PubAddrRetrieval{}
 	result.PubKey = pubkeyAminoStr
 	result.Addr = bech32Addr
-
-	resp, _ := respwrap.ResponseWrapper(txs.Cdc, result, nil)
-	out := string(resp)
-	return out
+	return result, nil
 }
 
 //new account result with field of Denom
@@ -188,53 +153,59 @@ type AccountKeyOut struct {
 
 //add new function for Account Creation with seed input
 func AccountCreateFromSeed(mncode string) string {
-	// add mnemonics validation
-	if bip39local.IsMnemonicValid(mncode) == false {
-		err := errors.Errorf("Invalid mnemonic!")
+	result, err := CreateAccountFromSeed(mncode)
+	if err != nil {
 		resp, _ := respwrap.ResponseWrapper(txs.Cdc, nil, err)
 		return string(resp)
+	}
+	resp, _ := respwrap.ResponseWrapper(txs.Cdc, result, nil)
+	out := string(resp)
+	return out
 
+}
+
+//CreateAccountFromSeed derives the account of the mnemonic by the legacy derivation with an empty bip39 passphrase
+func CreateAccountFromSeed(mncode string) (*AccountKeyOut, error) {
+	// add mnemonics validation
+	if bip39local.IsMnemonicValid(mncode) == false {
+		return nil, errcode.New(errcode.CodeInvalidInput, "Invalid mnemonic!")
 	}
 
 	var defaultBIP39Passphrase = ""
 	seed := bip39local.NewSeed(mncode, defaultBIP39Passphrase)
 	key := ed25519local.GenPrivKeyFromSecret(seed)
-	pub := key.PubKey()
-	pubkeyAmino, _ := txs.Cdc.MarshalJSON(pub)
-	var pubkeyAminoStc PubkeyAmino
-	err := txs.Cdc.UnmarshalJSON(pubkeyAmino, &pubkeyAminoStc)
+	pubkeyAminoStr, privkeyAminoStr, bech32Addr, err := aminoKeyValues(key)
 	if err != nil {
-		log.Fatalln(err.Error())
-	}
-	pubkeyAminoStr := pubkeyAminoStc.Value
-
-	addr := key.PubKey().Address()
-	//bech32Pub, _ := bech32local.ConvertAndEncode("cosmosaccpub", pub)
-	bech32Addr, _ := bech32local.ConvertAndEncode(types.PREF_ADD, addr.Bytes())
-
-	privkeyAmino, _ := txs.Cdc.MarshalJSON(key)
-	var privkeyAminoStc PrivkeyAmino
-	err1 := txs.Cdc.UnmarshalJSON(privkeyAmino, &privkeyAminoStc)
-	if err1 != nil {
-		log.Fatalln(err1.Error())
+		return nil, err
 	}
-	privkeyAminoStr := privkeyAminoStc.Value
-
-	Type := AccountResultType
-	Denom := DenomQOS
 
 	result := &AccountKeyOut{}
 	result.PubKey = pubkeyAminoStr
 	result.PrivKey = privkeyAminoStr
 	result.Addr = bech32Addr
 	result.Mnemonic = mncode
-	result.Type = Type
-	result.Denom = Denom
+	result.Type = AccountResultType
+	result.Denom = DenomQOS
+	return result, nil
+}
 
-	resp, _ := respwrap.ResponseWrapper(txs.Cdc, result, nil)
-	out := string(resp)
-	return out
+//aminoKeyValues returns the amino json values of the pubkey and privkey, and the bech32 address
+func aminoKeyValues(key ed25519local.PrivKeyEd25519) (pubKey, privKey, addr string, err error) {
+	pubkeyAmino, _ := txs.Cdc.MarshalJSON(key.PubKey())
+	var pubkeyAminoStc PubkeyAmino
+	if err = txs.Cdc.UnmarshalJSON(pubkeyAmino, &pubkeyAminoStc); err != nil {
+		return
+	}
 
+	privkeyAmino, _ := txs.Cdc.MarshalJSON(key)
+	var privkeyAminoStc PrivkeyAmino
+	if err = txs.Cdc.UnmarshalJSON(privkeyAmino, &privkeyAminoStc); err != nil {
+		return
+	}
+
+	//bech32Pub, _ := bech32local.ConvertAndEncode(Bech32PrefixAccPub, pub)
+	addr, err = bech32local.ConvertAndEncode(types.PREF_ADD, key.PubKey().Address().Bytes())
+	return pubkeyAminoStc.Value, privkeyAminoStc.Value, addr, err
 }
 
 ////Local Tx generation
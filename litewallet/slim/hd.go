@@ -0,0 +1,144 @@
+package slim
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/QOSGroup/litewallet/litewallet/errcode"
+	"github.com/QOSGroup/litewallet/litewallet/slim/tendermint/crypto/funcInlocal/bip39local"
+	"github.com/QOSGroup/litewallet/litewallet/slim/tendermint/crypto/funcInlocal/ed25519local"
+)
+
+//The keys of the keystore are derived from the mnemonic, of any bip39 language, by SLIP-0010 for ed25519,
+//where all the levels of a path are hardened. The legacy derivation of AccountCreate and AccountRecover
+//hashes the bip39 seed into a single key, it is kept to recover the existing accounts.
+
+const (
+	//CoinType is the SLIP-44 coin type of the paths, QOS has none registered and uses the Cosmos one
+	CoinType = 118
+	//DefaultHDPath is the path of the first account
+	DefaultHDPath = "m/44'/118'/0'/0'/0'"
+	//LegacyDefaultPassphrase is the bip39 passphrase of the legacy accounts created without a password
+	LegacyDefaultPassphrase = "DNWTTY"
+	//MnemonicWords is the length of the mnemonics generated for the QOS accounts
+	MnemonicWords = 24
+
+	hardenedOffset = 0x80000000
+	maxDerive      = 100
+)
+
+//DerivedAccount is an account of a mnemonic
+type DerivedAccount struct {
+	Index   uint32 `json:"index"`
+	Path    string `json:"path"`
+	Address string `json:"address"`
+	PubKey  string `json:"pubkey"`
+}
+
+//AccountPath returns the path of the account index of a mnemonic, AccountPath(0) is DefaultHDPath
+func AccountPath(index uint32) string {
+	return fmt.Sprintf("m/44'/%d'/0'/0'/%d'", CoinType, index)
+}
+
+//DeriveKey returns the key at hdPath of the mnemonic, without a bip39 passphrase
+func DeriveKey(mnemonic, hdPath string) (ed25519local.PrivKeyEd25519, error) {
+	path, err := parsePath(hdPath)
+	if err != nil {
+		return ed25519local.PrivKeyEd25519{}, err
+	}
+	seed, err := mnemonicSeed(mnemonic)
+	if err != nil {
+		return ed25519local.PrivKeyEd25519{}, err
+	}
+	return derivePrivKey(seed, path), nil
+}
+
+//LegacyKey returns the key of AccountRecover, the passphrase is the password of the account, or
+//LegacyDefaultPassphrase when it had none, and empty for the accounts of AccountCreateFromSeed
+func LegacyKey(mnemonic, passphrase string) (ed25519local.PrivKeyEd25519, error) {
+	if !bip39local.IsMnemonicValid(mnemonic) {
+		return ed25519local.PrivKeyEd25519{}, errcode.New(errcode.CodeInvalidInput, "Invalid mnemonic!")
+	}
+	return ed25519local.GenPrivKeyFromSecret(bip39local.NewSeed(mnemonic, passphrase)), nil
+}
+
+//DeriveAccounts returns count accounts of the mnemonic from the index start
+func DeriveAccounts(mnemonic string, start uint32, count int) ([]DerivedAccount, error) {
+	if count <= 0 || count > maxDerive {
+		return nil, errcode.Errorf(errcode.CodeInvalidInput, "count %d is out of 1 to %d", count, maxDerive)
+	}
+	seed, err := mnemonicSeed(mnemonic)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]DerivedAccount, 0, count)
+	for i := 0; i < count; i++ {
+		index := start + uint32(i)
+		if index >= hardenedOffset {
+			return nil, errcode.Errorf(errcode.CodeInvalidInput, "account index %d out of range", index)
+		}
+		path, _ := parsePath(AccountPath(index))
+		pubkey, _, addr, err := aminoKeyValues(derivePrivKey(seed, path))
+		if err != nil {
+			return nil, errcode.Internal(err)
+		}
+		out = append(out, DerivedAccount{Index: index, Path: AccountPath(index), Address: addr, PubKey: pubkey})
+	}
+	return out, nil
+}
+
+//mnemonicSeed returns the bip39 seed of the mnemonic of any language without a passphrase
+func mnemonicSeed(mnemonic string) ([]byte, error) {
+	seed, err := bip39local.MnemonicToSeed(mnemonic, "")
+	if err != nil {
+		return nil, errcode.Errorf(errcode.CodeInvalidInput, "Invalid mnemonic! %v", err)
+	}
+	return seed, nil
+}
+
+//parsePath parses a path such as m/44'/118'/0'/0'/0', h marks a hardened level too
+func parsePath(hdPath string) ([]uint32, error) {
+	parts := strings.Split(strings.TrimSpace(hdPath), "/")
+	if parts[0] != "m" {
+		return nil, errcode.Errorf(errcode.CodeInvalidInput, "path %s does not start with m", hdPath)
+	}
+	path := make([]uint32, 0, len(parts)-1)
+	for _, part := range parts[1:] {
+		if !strings.HasSuffix(part, "'") && !strings.HasSuffix(part, "h") {
+			return nil, errcode.Errorf(errcode.CodeInvalidInput, "path %s has the unhardened level %s, ed25519 only derives hardened keys", hdPath, part)
+		}
+		n, err := strconv.ParseUint(part[:len(part)-1], 10, 32)
+		if err != nil || n >= hardenedOffset {
+			return nil, errcode.Errorf(errcode.CodeInvalidInput, "path %s has the invalid level %s", hdPath, part)
+		}
+		path = append(path, uint32(n)+hardenedOffset)
+	}
+	return path, nil
+}
+
+//derivePrivKey derives the key at path from the bip39 seed by SLIP-0010
+func derivePrivKey(seed []byte, path []uint32) ed25519local.PrivKeyEd25519 {
+	key, chainCode := slip10Step([]byte("ed25519 seed"), seed)
+	for _, index := range path {
+		data := make([]byte, 37)
+		copy(data[1:33], key)
+		binary.BigEndian.PutUint32(data[33:], index)
+		key, chainCode = slip10Step(chainCode, data)
+	}
+	var privKey [64]byte
+	copy(privKey[:32], key)
+	//the public key goes in the last 32 bytes
+	ed25519local.MakePublicKey(&privKey)
+	return ed25519local.PrivKeyEd25519(privKey)
+}
+
+func slip10Step(hmacKey, data []byte) (key, chainCode []byte) {
+	mac := hmac.New(sha512.New, hmacKey)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+	return sum[:32], sum[32:]
+}
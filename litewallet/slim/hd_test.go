@@ -0,0 +1,78 @@
+package slim
+
+import (
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/QOSGroup/litewallet/litewallet/errcode"
+)
+
+func TestDerivePrivKey(t *testing.T) {
+	//the ed25519 test vector 1 of SLIP-0010
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	for _, tc := range []struct {
+		path, priv, pub string
+	}{
+		{"m", "2b4be7f19ee27bbf30c667b642d5f4aa69fd169872f8fc3059c08ebae2eb19e7", "a4b2856bfec510abab89753fac1ac0e1112364e7d250545963f135f2a33188ed"},
+		{"m/0'", "68e0fe46dfb67e368c75379acec591dad19df3cde26e63b93a8e704f1dade7a3", "8c8a13df77a28f3445213a0f432fde644acaa215fc72dcdf300d5efaa85d350c"},
+		{"m/0h/1h", "b1d0bad404bf35da785a64ca1ac54b2617211d2777696fbffaf208f746ae84f2", "1932a5270f335bed617d5b935c80aedb1a35bd9fc1e31acafd5372c30f5c1187"},
+		{"m/0'/1'/2'", "92a5b23c0b8a99e37d07df3fb9966917f5d06e02ddbd909c7e184371463e9fc9", "ae98736566d30ed0e9d2f4486a64bc95740d89c7db33f52121f8ea8f76ff0fc1"},
+	} {
+		path, err := parsePath(tc.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		key := derivePrivKey(seed, path)
+		if priv, pub := hex.EncodeToString(key[:32]), hex.EncodeToString(key[32:]); priv != tc.priv || pub != tc.pub {
+			t.Errorf("got %s %s at %s, want %s %s", priv, pub, tc.path, tc.priv, tc.pub)
+		}
+	}
+
+	for _, path := range []string{"44'/118'", "m/44'/118'/0'/0/0", "m/44'/x'", "m/2147483648'"} {
+		if _, err := parsePath(path); errcode.Code(err) != errcode.CodeInvalidInput {
+			t.Errorf("got %v for %s, want an invalid input error", err, path)
+		}
+	}
+}
+
+func TestDeriveAccounts(t *testing.T) {
+	mncode := "oyster leave weird tiger road rose anger garden planet price small rain cradle rhythm wine spider manual wave plastic solar spray battle parent match"
+	accounts, err := DeriveAccounts(mncode, 0, 3)
+	if err != nil || len(accounts) != 3 || accounts[2].Path != "m/44'/118'/0'/0'/2'" {
+		t.Fatalf("got %+v, %v", accounts, err)
+	}
+	if accounts[0].Address == accounts[1].Address {
+		t.Errorf("got the same address for the accounts 0 and 1")
+	}
+	if _, err := DeriveAccounts(mncode, 0, maxDerive+1); errcode.Code(err) != errcode.CodeInvalidInput {
+		t.Errorf("got %v, want an invalid input error", err)
+	}
+
+	rootDir, err := ioutil.TempDir("", "qoskeys")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(rootDir)
+	ko, err := CreateKeyAt(rootDir, "third", "qstars", mncode, 2)
+	if err != nil || ko.Address != accounts[2].Address || ko.Path != accounts[2].Path {
+		t.Fatalf("got %+v, %v, want %+v", ko, err, accounts[2])
+	}
+	ko, err = RestoreKeyWithPath(rootDir, "second", "qstars", mncode, "m/44h/118h/0h/0h/1h")
+	if err != nil || ko.Address != accounts[1].Address {
+		t.Fatalf("got %+v, %v, want %+v", ko, err, accounts[1])
+	}
+
+	//the legacy accounts recover to the same addresses
+	legacy, _ := RecoverAccount(mncode, "")
+	ko, err = RestoreLegacyKey(rootDir, "legacy", "qstars", mncode, LegacyDefaultPassphrase)
+	if err != nil || ko.Address != legacy.Addr || ko.Path != "" {
+		t.Fatalf("got %+v, %v, want %s", ko, err, legacy.Addr)
+	}
+	fromSeed, _ := CreateAccountFromSeed(mncode)
+	ko, err = RestoreLegacyKey(rootDir, "fromseed", "qstars", mncode, "")
+	if err != nil || ko.Address != fromSeed.Addr {
+		t.Fatalf("got %+v, %v, want %s", ko, err, fromSeed.Addr)
+	}
+}
@@ -2,7 +2,13 @@ package slim
 
 import (
 	"fmt"
+	"net"
+
+	"github.com/QOSGroup/litewallet/litewallet/errcode"
+	"github.com/QOSGroup/litewallet/litewallet/signer"
 	"github.com/QOSGroup/litewallet/litewallet/slim/app"
+	baccount "github.com/QOSGroup/litewallet/litewallet/slim/base/account"
+	"github.com/QOSGroup/litewallet/litewallet/slim/base/types"
 	"github.com/QOSGroup/litewallet/litewallet/slim/base/client/account"
 	"github.com/QOSGroup/litewallet/litewallet/slim/base/client/context"
 	"github.com/QOSGroup/litewallet/litewallet/slim/module"
@@ -11,79 +17,177 @@ import (
 	distribution_client "github.com/QOSGroup/litewallet/litewallet/slim/module/distribution/client"
 	gov_client "github.com/QOSGroup/litewallet/litewallet/slim/module/gov/client"
 	stake_client "github.com/QOSGroup/litewallet/litewallet/slim/module/stake/client"
+	"github.com/QOSGroup/litewallet/litewallet/slim/txs"
+	pkgerrors "github.com/pkg/errors"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
 )
 
 //only need the following arguments, it`s enough!
 func QueryAccount(remote, addr string) ([]byte, error) {
-	var cliCtx = context.NewCLIContext(remote).WithCodec(app.Cdc)
-	qosAccount, err := account.QueryAccount(cliCtx, addr)
+	qosAccount, err := FetchAccount(remote, addr)
 	if err != nil {
 		return nil, err
 	}
 	return app.Cdc.MarshalJSON(qosAccount)
 }
 
+//FetchAccount returns the QOS account of addr, verified on the chain of the header at height 1 of remote
+func FetchAccount(remote, addr string) (baccount.Account, error) {
+	return FetchAccountOnChain(remote, "", addr)
+}
+
+//FetchAccountOnChain is FetchAccount verified on the chain chainID, remote has to serve it
+func FetchAccountOnChain(remote, chainID, addr string) (baccount.Account, error) {
+	var cliCtx = context.NewCLIContext(remote).WithCodec(app.Cdc).WithTrust("", chainID)
+	acc, err := account.QueryAccount(cliCtx, addr)
+	if err != nil {
+		return nil, nodeError(err)
+	}
+	return acc, nil
+}
+
 //only need the following arguments, it`s enough!
 func Transfer(remote, addrto, coinstr, privkey, chainid string) ([]byte, error) {
-	var cliCtx = context.NewCLIContext(remote).WithCodec(app.Cdc)
+	return marshalResult(SendTransfer(remote, addrto, coinstr, privkey, chainid))
+}
+
+//SendTransfer signs a transfer of coinstr to addrto and broadcasts it in sync mode
+func SendTransfer(remote, addrto, coinstr, privkey, chainid string) (*ctypes.ResultBroadcastTx, error) {
+	return sendTransfer(context.NewCLIContext(remote).WithCodec(app.Cdc), addrto, coinstr, privkey, chainid)
+}
+
+//SendTransferOnChain is SendTransfer with the nonce verified on the chain chainid, remote has to serve it
+func SendTransferOnChain(remote, addrto, coinstr, privkey, chainid string) (*ctypes.ResultBroadcastTx, error) {
+	return sendTransfer(context.NewCLIContext(remote).WithCodec(app.Cdc).WithTrust("", chainid), addrto, coinstr, privkey, chainid)
+}
+
+func sendTransfer(cliCtx context.CLIContext, addrto, coinstr, privkey, chainid string) (*ctypes.ResultBroadcastTx, error) {
 	tx, err := bank_client.CreateTransfer(cliCtx, addrto, coinstr, privkey, chainid)
 	if err != nil {
 		return nil, err
 	}
-	//datas := bytes.NewBuffer([]byte(jasonpayload))
-	//aurl := txs.Accounturl + "txSend"
-	//req, _ := http.NewRequest("POST", aurl, datas)
-	//req.Header.Set("Content-Type", "application/json")
-	//clt := http.Client{}
-	//resp, _ := clt.Do(req)
-	//body, err := ioutil.ReadAll(resp.Body)
-	//defer resp.Body.Close()
-	//output := string(body)
-	result, err := cliCtx.BroadcastTxSync(tx)
+	return broadcastSync(cliCtx, tx)
+}
+
+//SendTransferWithSigner is SendTransfer signed by s, a key which may live outside of the wallet
+func SendTransferWithSigner(remote, addrto, coinstr string, s signer.Signer, chainid string) (*ctypes.ResultBroadcastTx, error) {
+	var cliCtx = context.NewCLIContext(remote).WithCodec(app.Cdc)
+	tx, err := bank_client.CreateTransferWithSigner(cliCtx, addrto, coinstr, s, chainid)
 	if err != nil {
 		return nil, err
 	}
-	return cliCtx.Codec.MarshalJSON(result)
+	return broadcastSync(cliCtx, tx)
 }
 
 // stake
 func Delegation(remote, addrto string, coins int64, privkey, chainid string) ([]byte, error) {
-	var cliCtx = context.NewCLIContext(remote).WithCodec(app.Cdc)
+	return marshalResult(SendDelegation(remote, addrto, coins, privkey, chainid))
+}
+
+//SendDelegation signs a delegation of coins to the validator addrto and broadcasts it in sync mode
+func SendDelegation(remote, addrto string, coins int64, privkey, chainid string) (*ctypes.ResultBroadcastTx, error) {
+	return sendDelegation(context.NewCLIContext(remote).WithCodec(app.Cdc), addrto, coins, privkey, chainid)
+}
+
+//SendDelegationOnChain is SendDelegation with the nonce verified on the chain chainid, remote has to serve it
+func SendDelegationOnChain(remote, addrto string, coins int64, privkey, chainid string) (*ctypes.ResultBroadcastTx, error) {
+	return sendDelegation(context.NewCLIContext(remote).WithCodec(app.Cdc).WithTrust("", chainid), addrto, coins, privkey, chainid)
+}
+
+func sendDelegation(cliCtx context.CLIContext, addrto string, coins int64, privkey, chainid string) (*ctypes.ResultBroadcastTx, error) {
 	tx, err := stake_client.CreateDelegation(cliCtx, addrto, coins, privkey, chainid)
 	if err != nil {
 		return nil, err
 	}
-	result, err := cliCtx.BroadcastTxSync(tx)
+	return broadcastSync(cliCtx, tx)
+}
+
+//SendDelegationWithSigner is SendDelegation signed by s
+func SendDelegationWithSigner(remote, addrto string, coins int64, s signer.Signer, chainid string) (*ctypes.ResultBroadcastTx, error) {
+	var cliCtx = context.NewCLIContext(remote).WithCodec(app.Cdc)
+	tx, err := stake_client.CreateDelegationWithSigner(cliCtx, addrto, coins, s, chainid)
 	if err != nil {
 		return nil, err
 	}
-	return cliCtx.Codec.MarshalJSON(result)
+	return broadcastSync(cliCtx, tx)
 }
 
 func UnbondDelegation(remote, addrto string, coins int64, privkey, chainid string) ([]byte, error) {
+	return marshalResult(SendUnbondDelegation(remote, addrto, coins, privkey, chainid))
+}
+
+//SendUnbondDelegation signs an unbond of coins from the validator addrto and broadcasts it in sync mode
+func SendUnbondDelegation(remote, addrto string, coins int64, privkey, chainid string) (*ctypes.ResultBroadcastTx, error) {
 	var cliCtx = context.NewCLIContext(remote).WithCodec(app.Cdc)
 	tx, err := stake_client.CreateUnbondDelegation(cliCtx, addrto, coins, privkey, chainid)
 	if err != nil {
 		return nil, err
 	}
-	result, err := cliCtx.BroadcastTxSync(tx)
+	return broadcastSync(cliCtx, tx)
+}
+
+//SendUnbondDelegationWithSigner is SendUnbondDelegation signed by s
+func SendUnbondDelegationWithSigner(remote, addrto string, coins int64, s signer.Signer, chainid string) (*ctypes.ResultBroadcastTx, error) {
+	var cliCtx = context.NewCLIContext(remote).WithCodec(app.Cdc)
+	tx, err := stake_client.CreateUnbondDelegationWithSigner(cliCtx, addrto, coins, s, chainid)
 	if err != nil {
 		return nil, err
 	}
-	return cliCtx.Codec.MarshalJSON(result)
+	return broadcastSync(cliCtx, tx)
 }
 
 func ReDelegation(remote, fromValidatorAddr, toValidatorAddr string, coins int64, privkey, chainid string) ([]byte, error) {
+	return marshalResult(SendReDelegation(remote, fromValidatorAddr, toValidatorAddr, coins, privkey, chainid))
+}
+
+//SendReDelegation signs a redelegation of coins between two validators and broadcasts it in sync mode
+func SendReDelegation(remote, fromValidatorAddr, toValidatorAddr string, coins int64, privkey, chainid string) (*ctypes.ResultBroadcastTx, error) {
 	var cliCtx = context.NewCLIContext(remote).WithCodec(app.Cdc)
 	tx, err := stake_client.CreateReDelegationCommand(cliCtx, fromValidatorAddr, toValidatorAddr, coins, privkey, chainid)
 	if err != nil {
 		return nil, err
 	}
-	result, err := cliCtx.BroadcastTxSync(tx)
+	return broadcastSync(cliCtx, tx)
+}
+
+//SendReDelegationWithSigner is SendReDelegation signed by s
+func SendReDelegationWithSigner(remote, fromValidatorAddr, toValidatorAddr string, coins int64, s signer.Signer, chainid string) (*ctypes.ResultBroadcastTx, error) {
+	var cliCtx = context.NewCLIContext(remote).WithCodec(app.Cdc)
+	tx, err := stake_client.CreateReDelegationCommandWithSigner(cliCtx, fromValidatorAddr, toValidatorAddr, coins, s, chainid)
 	if err != nil {
 		return nil, err
 	}
-	return cliCtx.Codec.MarshalJSON(result)
+	return broadcastSync(cliCtx, tx)
+}
+
+//broadcastSync broadcasts tx in sync mode, a tx refused by the CheckTx of the node is an error
+func broadcastSync(cliCtx context.CLIContext, tx []byte) (*ctypes.ResultBroadcastTx, error) {
+	res, err := cliCtx.BroadcastTxSync(tx)
+	if err != nil {
+		return nil, nodeError(err)
+	}
+	if res.Code != 0 {
+		return nil, errcode.Errorf(errcode.CodeNodeRejected, "tx %s rejected with code %d: %s", res.Hash, res.Code, res.Log)
+	}
+	return res, nil
+}
+
+//nodeError categorizes the error of a query or a broadcast to remote, other errors are left as they are
+func nodeError(err error) error {
+	if err == account.ErrAccountNotExsits {
+		return errcode.KeyNotFound(err)
+	}
+	if _, ok := pkgerrors.Cause(err).(net.Error); ok {
+		return errcode.Network(err)
+	}
+	return err
+}
+
+func marshalResult(result *ctypes.ResultBroadcastTx, err error) ([]byte, error) {
+	if err != nil {
+		return nil, err
+	}
+	return app.Cdc.MarshalJSON(result)
 }
 
 func QueryValidatorInfo(remote, validatorAddr string) ([]byte, error) {
@@ -238,16 +342,38 @@ func QueryTally(remote string, pId uint64, addrStr string) ([]byte, error) {
 }
 
 func QueryTx(remote, hashHex string) ([]byte, error) {
-	//var cliCtx = context.NewCLIContext(remote).WithCodec(app.Cdc)
-	txResponse, err := module.QueryTx(remote, hashHex)
+	txResponse, err := FetchTx(remote, hashHex)
 	if err != nil {
 		return nil, err
 	}
+	return app.Cdc.MarshalJSON(txResponse)
+}
+
+//FetchTx returns the tx of the hex hash hashHex, it is an error if there is none
+func FetchTx(remote, hashHex string) (types.TxResponse, error) {
+	txResponse, err := module.QueryTx(remote, hashHex)
+	if err != nil {
+		return types.TxResponse{}, nodeError(err)
+	}
 
 	if txResponse.Empty() {
-		return nil, fmt.Errorf("No transaction found with hash %s", hashHex)
+		return types.TxResponse{}, fmt.Errorf("No transaction found with hash %s", hashHex)
 	}
-	return app.Cdc.MarshalJSON(txResponse)
+	return txResponse, nil
+}
+
+//QueryBalance returns the coins of addrs in the aoeaccount store of the blockchain entrance
+func QueryBalance(addrs string) (types.BaseCoins, error) {
+	path := fmt.Sprintf("/store/%s/%s", "aoeaccount", "key")
+	output, err := txs.Query(path, []byte(addrs))
+	if err != nil {
+		return nil, nodeError(err)
+	}
+	var basecoin types.BaseCoins
+	if err := txs.Cdc.UnmarshalBinaryBare(output, &basecoin); err != nil {
+		return nil, err
+	}
+	return basecoin, nil
 }
 
 //func GetTx(tx string) string {
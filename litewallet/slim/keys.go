@@ -0,0 +1,408 @@
+package slim
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/QOSGroup/litewallet/litewallet/errcode"
+	"github.com/QOSGroup/litewallet/litewallet/keycrypt"
+	"github.com/QOSGroup/litewallet/litewallet/slim/tendermint/crypto/funcInlocal/bip39local"
+	"github.com/QOSGroup/litewallet/litewallet/slim/tendermint/crypto/funcInlocal/ed25519local"
+	"github.com/QOSGroup/litewallet/litewallet/slim/txs"
+	dbm "github.com/tendermint/tendermint/libs/db"
+)
+
+//The QOS keystore keeps the ed25519 keys under rootDir/qoskeys by name, the private key is encrypted
+//with the password in the envelope of keycrypt as the keys of the Cosmos and ETH wallets are. The key of a mnemonic is derived at a
+//SLIP-0010 path without a bip39 passphrase, the password only encrypts it. The accounts of AccountCreate
+//are moved into the keystore with RestoreLegacyKey, or ImportKey of their private key.
+
+const (
+	blockTypePrivKey = "TENDERMINT PRIVATE KEY"
+	infoSuffix       = "info"
+)
+
+//LocalInfo is the stored key, the private key is armored and encrypted, the path is empty for the
+//legacy and imported keys
+type LocalInfo struct {
+	Name         string `json:"name"`
+	PubKey       string `json:"pubkey"`
+	PrivKeyArmor string `json:"privkey"`
+	Address      string `json:"address"`
+	Path         string `json:"path,omitempty"`
+}
+
+//KeyOutput is a key of the keystore, the mnemonic is only returned when the key is created
+type KeyOutput struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Address  string `json:"address"`
+	PubKey   string `json:"pubkey"`
+	Mnemonic string `json:"mnemonic,omitempty"`
+	Denom    string `json:"denom"`
+	Path     string `json:"path,omitempty"`
+}
+
+//CreateKey stores the key at DefaultHDPath of mnemonic under name, a new mnemonic is generated when it is empty
+func CreateKey(rootDir, name, password, mnemonic string) (*KeyOutput, error) {
+	return CreateKeyWithPath(rootDir, name, password, mnemonic, DefaultHDPath)
+}
+
+//CreateKeyAt stores the account index of mnemonic under name
+func CreateKeyAt(rootDir, name, password, mnemonic string, index uint32) (*KeyOutput, error) {
+	if index >= hardenedOffset {
+		return nil, errcode.Errorf(errcode.CodeInvalidInput, "account index %d out of range", index)
+	}
+	return CreateKeyWithPath(rootDir, name, password, mnemonic, AccountPath(index))
+}
+
+//CreateKeyWithPath stores the key at hdPath of mnemonic under name, a new mnemonic is generated when it is empty
+func CreateKeyWithPath(rootDir, name, password, mnemonic, hdPath string) (*KeyOutput, error) {
+	if mnemonic == "" {
+		var err error
+		if mnemonic, err = bip39local.GenerateMnemonic(MnemonicWords, bip39local.English, nil); err != nil {
+			return nil, err
+		}
+	}
+	key, err := DeriveKey(mnemonic, hdPath)
+	if err != nil {
+		return nil, err
+	}
+	ko, err := storeKey(rootDir, name, password, key, strings.TrimSpace(hdPath))
+	if err != nil {
+		return nil, err
+	}
+	ko.Mnemonic = mnemonic
+	return ko, nil
+}
+
+//RestoreKey stores the key at DefaultHDPath of mnemonic under name
+func RestoreKey(rootDir, name, password, mnemonic string) (*KeyOutput, error) {
+	return RestoreKeyWithPath(rootDir, name, password, mnemonic, DefaultHDPath)
+}
+
+//RestoreKeyWithPath stores the key at hdPath of mnemonic under name
+func RestoreKeyWithPath(rootDir, name, password, mnemonic, hdPath string) (*KeyOutput, error) {
+	if mnemonic == "" {
+		return nil, errcode.New(errcode.CodeInvalidInput, "mnemonic is required")
+	}
+	return CreateKeyWithPath(rootDir, name, password, mnemonic, hdPath)
+}
+
+//RestoreLegacyKey stores the key of the legacy derivation of mnemonic under name, see LegacyKey for the passphrase
+func RestoreLegacyKey(rootDir, name, password, mnemonic, passphrase string) (*KeyOutput, error) {
+	key, err := LegacyKey(mnemonic, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	ko, err := storeKey(rootDir, name, password, key, "")
+	if err != nil {
+		return nil, err
+	}
+	ko.Mnemonic = mnemonic
+	return ko, nil
+}
+
+//ImportKey stores the base64 private key, as returned by AccountCreate, under name
+func ImportKey(rootDir, name, password, privkey string) (*KeyOutput, error) {
+	key, err := parsePrivKey(privkey)
+	if err != nil {
+		return nil, err
+	}
+	return storeKey(rootDir, name, password, key, "")
+}
+
+//ListKeys returns the keys in alphabetical order of name
+func ListKeys(rootDir string) ([]KeyOutput, error) {
+	db, err := openKeys(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	var kos []KeyOutput
+	iter := db.Iterator(nil, nil)
+	defer iter.Close()
+	for ; iter.Valid(); iter.Next() {
+		if !strings.HasSuffix(string(iter.Key()), "."+infoSuffix) {
+			continue
+		}
+		var info LocalInfo
+		if err := json.Unmarshal(iter.Value(), &info); err != nil {
+			return nil, errcode.Internal(err)
+		}
+		kos = append(kos, keyOutput(info))
+	}
+	return kos, nil
+}
+
+//HasKey tells whether the keystore holds a key name
+func HasKey(rootDir, name string) (bool, error) {
+	db, err := openKeys(rootDir)
+	if err != nil {
+		return false, err
+	}
+	defer db.Close()
+	return db.Has(infoKey(name)), nil
+}
+
+//DeleteKey removes the key name, the password has to match
+func DeleteKey(rootDir, name, password string) error {
+	db, err := openKeys(rootDir)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	info, err := readInfo(db, name)
+	if err != nil {
+		return err
+	}
+	if _, err := unarmorDecryptPrivKey(info.PrivKeyArmor, password); err != nil {
+		return err
+	}
+	//the info and the pointer to it by address are deleted together, the pointer only when it is the one of name
+	batch := db.NewBatch()
+	defer batch.Close()
+	if owner, ok := addressOwner(db, info.Address); ok && owner == name {
+		batch.Delete(addrKey(info.Address))
+	}
+	batch.Delete(infoKey(name))
+	batch.WriteSync()
+	return nil
+}
+
+//ChangePassword encrypts the key name with newpass instead of oldpass
+func ChangePassword(rootDir, name, oldpass, newpass string) error {
+	if newpass == "" {
+		return errMissingPassword()
+	}
+	db, err := openKeys(rootDir)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	info, err := readInfo(db, name)
+	if err != nil {
+		return err
+	}
+	key, err := unarmorDecryptPrivKey(info.PrivKeyArmor, oldpass)
+	if err != nil {
+		return err
+	}
+	if info.PrivKeyArmor, err = encryptArmorPrivKey(key, newpass); err != nil {
+		return err
+	}
+	bz, err := json.Marshal(info)
+	if err != nil {
+		return errcode.Internal(err)
+	}
+	db.SetSync(infoKey(name), bz)
+	return nil
+}
+
+//ExportKey returns the base64 private key of name, the value AccountCreate returns
+func ExportKey(rootDir, name, password string) (string, error) {
+	key, err := FetchKey(rootDir, name, password)
+	if err != nil {
+		return "", err
+	}
+	_, privkey, _, err := aminoKeyValues(key)
+	return privkey, err
+}
+
+//FetchKey decrypts the key name for signing, a key of an outdated envelope is encrypted again with the current one
+func FetchKey(rootDir, name, password string) (ed25519local.PrivKeyEd25519, error) {
+	db, err := openKeys(rootDir)
+	if err != nil {
+		return ed25519local.PrivKeyEd25519{}, err
+	}
+	info, err := readInfo(db, name)
+	//close the db to release the lock
+	db.Close()
+	if err != nil {
+		return ed25519local.PrivKeyEd25519{}, err
+	}
+	key, current, err := decryptPrivKey(info.PrivKeyArmor, password)
+	if err != nil {
+		return key, err
+	}
+	if !current {
+		//the upgrade is done again on the next unlock if it fails
+		upgradeKey(rootDir, info, key, password)
+	}
+	return key, nil
+}
+
+//UpgradeKey encrypts the key name again with the current envelope of keycrypt if it is another one, and
+//tells whether it did
+func UpgradeKey(rootDir, name, password string) (bool, error) {
+	db, err := openKeys(rootDir)
+	if err != nil {
+		return false, err
+	}
+	info, err := readInfo(db, name)
+	db.Close()
+	if err != nil {
+		return false, err
+	}
+	if upgrade, err := keycrypt.NeedsUpgrade(info.PrivKeyArmor); err != nil || !upgrade {
+		return false, err
+	}
+	key, err := unarmorDecryptPrivKey(info.PrivKeyArmor, password)
+	if err != nil {
+		return false, err
+	}
+	return true, upgradeKey(rootDir, info, key, password)
+}
+
+//upgradeKey writes the key of info encrypted with the current envelope, unless the key was changed since info
+//was read
+func upgradeKey(rootDir string, info LocalInfo, key ed25519local.PrivKeyEd25519, password string) error {
+	armorStr := info.PrivKeyArmor
+	var err error
+	if info.PrivKeyArmor, err = encryptArmorPrivKey(key, password); err != nil {
+		return err
+	}
+	bz, err := json.Marshal(info)
+	if err != nil {
+		return errcode.Internal(err)
+	}
+	db, err := openKeys(rootDir)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	if stored, err := readInfo(db, info.Name); err != nil || stored.PrivKeyArmor != armorStr {
+		return errcode.Errorf(errcode.CodeInternal, "the key %s changed while it was upgraded", info.Name)
+	}
+	db.SetSync(infoKey(info.Name), bz)
+	return nil
+}
+
+//storeKey encrypts key with password and stores it under name, an address is stored under one name only
+func storeKey(rootDir, name, password string, key ed25519local.PrivKeyEd25519, hdPath string) (*KeyOutput, error) {
+	if name == "" {
+		return nil, errMissingName()
+	}
+	if password == "" {
+		return nil, errMissingPassword()
+	}
+	pubkey, _, addr, err := aminoKeyValues(key)
+	if err != nil {
+		return nil, errcode.Internal(err)
+	}
+	privArmor, err := encryptArmorPrivKey(key, password)
+	if err != nil {
+		return nil, err
+	}
+	info := LocalInfo{Name: name, PubKey: pubkey, PrivKeyArmor: privArmor, Address: addr, Path: hdPath}
+	bz, err := json.Marshal(info)
+	if err != nil {
+		return nil, errcode.Internal(err)
+	}
+
+	db, err := openKeys(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	if db.Has(infoKey(name)) {
+		return nil, errcode.Errorf(errcode.CodeInvalidInput, "account with name %s already exists", name)
+	}
+	if owner, ok := addressOwner(db, addr); ok && owner != name {
+		return nil, errcode.Errorf(errcode.CodeInvalidInput, "the address %s is already stored as %s", addr, owner)
+	}
+	//the info and a pointer to it by address for the lookups are written together
+	batch := db.NewBatch()
+	defer batch.Close()
+	batch.Set(infoKey(name), bz)
+	batch.Set(addrKey(addr), infoKey(name))
+	batch.WriteSync()
+	ko := keyOutput(info)
+	return &ko, nil
+}
+
+func openKeys(rootDir string) (dbm.DB, error) {
+	db, err := dbm.NewGoLevelDB("keys", filepath.Join(rootDir, "qoskeys"))
+	if err != nil {
+		return nil, errcode.Internal(err)
+	}
+	return db, nil
+}
+
+func readInfo(db dbm.DB, name string) (info LocalInfo, err error) {
+	bz := db.Get(infoKey(name))
+	if len(bz) == 0 {
+		return info, errcode.Errorf(errcode.CodeKeyNotFound, "Key %s not found", name)
+	}
+	if err = json.Unmarshal(bz, &info); err != nil {
+		err = errcode.Internal(err)
+	}
+	return
+}
+
+//addressOwner returns the name of the key stored with address
+func addressOwner(db dbm.DB, address string) (string, bool) {
+	pointer := db.Get(addrKey(address))
+	if len(pointer) == 0 {
+		return "", false
+	}
+	var info LocalInfo
+	if err := json.Unmarshal(db.Get(pointer), &info); err != nil || info.Address != address {
+		return "", false
+	}
+	return info.Name, true
+}
+
+func keyOutput(info LocalInfo) KeyOutput {
+	return KeyOutput{Name: info.Name, Type: AccountResultType, Address: info.Address, PubKey: info.PubKey, Denom: DenomQOS, Path: info.Path}
+}
+
+//parsePrivKey decodes the base64 private key of the amino json
+func parsePrivKey(privkey string) (key ed25519local.PrivKeyEd25519, err error) {
+	ts := "{\"type\": \"tendermint/PrivKeyEd25519\",\"value\": \"" + privkey + "\"}"
+	if err = txs.Cdc.UnmarshalJSON([]byte(ts), &key); err != nil {
+		err = errcode.InvalidInput(err)
+	}
+	return
+}
+
+//encryptArmorPrivKey encrypts the key with passphrase in the current envelope of keycrypt
+func encryptArmorPrivKey(key ed25519local.PrivKeyEd25519, passphrase string) (string, error) {
+	return keycrypt.Encrypt(blockTypePrivKey, key[:], passphrase)
+}
+
+func unarmorDecryptPrivKey(armorStr, passphrase string) (key ed25519local.PrivKeyEd25519, err error) {
+	key, _, err = decryptPrivKey(armorStr, passphrase)
+	return
+}
+
+//decryptPrivKey decrypts the key and tells whether its envelope is the current one
+func decryptPrivKey(armorStr, passphrase string) (key ed25519local.PrivKeyEd25519, current bool, err error) {
+	privKeyBytes, current, err := keycrypt.Decrypt(armorStr, blockTypePrivKey, passphrase)
+	if err != nil {
+		return key, false, err
+	}
+	if len(privKeyBytes) != len(key) {
+		return key, false, errcode.Errorf(errcode.CodeInternal, "got a private key of %d bytes", len(privKeyBytes))
+	}
+	copy(key[:], privKeyBytes)
+	return key, current, nil
+}
+
+func infoKey(name string) []byte {
+	return []byte(fmt.Sprintf("%s.%s", name, infoSuffix))
+}
+
+func addrKey(addr string) []byte {
+	return []byte(fmt.Sprintf("%s.%s", addr, "addr"))
+}
+
+func errMissingName() error {
+	return errcode.New(errcode.CodeInvalidInput, "you have to specify a name for the locally stored account")
+}
+
+func errMissingPassword() error {
+	return errcode.New(errcode.CodeInvalidInput, "you have to specify a password for the locally stored account")
+}
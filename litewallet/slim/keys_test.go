@@ -0,0 +1,136 @@
+package slim
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/QOSGroup/litewallet/litewallet/errcode"
+	"github.com/QOSGroup/litewallet/litewallet/signer"
+)
+
+func TestKeystore(t *testing.T) {
+	rootDir, err := ioutil.TempDir("", "qoskeys")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(rootDir)
+
+	mncode := "oyster leave weird tiger road rose anger garden planet price small rain cradle rhythm wine spider manual wave plastic solar spray battle parent match"
+	ko, err := CreateKey(rootDir, "alice", "qstars", mncode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	//the key is the first account of the mnemonic
+	accounts, _ := DeriveAccounts(mncode, 0, 1)
+	if ko.Address != accounts[0].Address || ko.Mnemonic != mncode || ko.Path != DefaultHDPath {
+		t.Errorf("got %+v, want the account %+v", ko, accounts[0])
+	}
+	if _, err := CreateKey(rootDir, "alice", "qstars", ""); errcode.Code(err) != errcode.CodeInvalidInput {
+		t.Errorf("got %v for a name conflict, want an invalid input error", err)
+	}
+	bob, err := CreateKey(rootDir, "bob", "bobpass", "")
+	if err != nil || bob.Mnemonic == "" {
+		t.Fatalf("got %+v, %v, want a new mnemonic", bob, err)
+	}
+
+	kos, err := ListKeys(rootDir)
+	if err != nil || len(kos) != 2 || kos[0].Name != "alice" || kos[1].Address != bob.Address || kos[0].Mnemonic != "" {
+		t.Fatalf("got %+v, %v, want alice and bob without the mnemonics", kos, err)
+	}
+
+	if _, err := ExportKey(rootDir, "alice", "wrong"); errcode.Code(err) != errcode.CodeWrongPassword {
+		t.Errorf("got %v, want a wrong password error", err)
+	}
+	if _, err := ExportKey(rootDir, "carol", "qstars"); errcode.Code(err) != errcode.CodeKeyNotFound {
+		t.Errorf("got %v, want a key not found error", err)
+	}
+	if err := ChangePassword(rootDir, "alice", "qstars", "newpass"); err != nil {
+		t.Fatal(err)
+	}
+	privkey, err := ExportKey(rootDir, "alice", "newpass")
+	key, _ := DeriveKey(mncode, DefaultHDPath)
+	if _, want, _, _ := aminoKeyValues(key); err != nil || privkey != want {
+		t.Fatalf("got %s, %v, want the private key of the mnemonic", privkey, err)
+	}
+
+	//the private keys of AccountCreate are imported
+	old, _ := CreateAccount("qstars")
+	imported, err := ImportKey(rootDir, "carol", "carolpass", old.PrivKey)
+	if err != nil || imported.Address != old.Addr {
+		t.Fatalf("got %+v, %v, want the account %s", imported, err, old.Addr)
+	}
+	if _, err := ImportKey(rootDir, "dave", "davepass", "not a key"); errcode.Code(err) != errcode.CodeInvalidInput {
+		t.Errorf("got %v for an invalid key, want an invalid input error", err)
+	}
+	//an address is stored under one name
+	if _, err := ImportKey(rootDir, "dave", "davepass", old.PrivKey); errcode.Code(err) != errcode.CodeInvalidInput {
+		t.Errorf("got %v for the address of carol, want an invalid input error", err)
+	}
+	if ok, _ := HasKey(rootDir, "dave"); ok {
+		t.Error("the key dave should not be written")
+	}
+
+	if err := DeleteKey(rootDir, "bob", "wrong"); errcode.Code(err) != errcode.CodeWrongPassword {
+		t.Errorf("got %v, want a wrong password error", err)
+	}
+	if err := DeleteKey(rootDir, "bob", "bobpass"); err != nil {
+		t.Fatal(err)
+	}
+	if kos, _ := ListKeys(rootDir); len(kos) != 2 || kos[1].Name != "carol" {
+		t.Errorf("got %+v after deleting bob, want alice and carol", kos)
+	}
+}
+
+func TestSignMessage(t *testing.T) {
+	rootDir, err := ioutil.TempDir("", "qoskeys")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(rootDir)
+	alice, err := CreateKey(rootDir, "alice", "qstars", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg := []byte("login to litewallet, nonce 42")
+	sig, err := SignMessageByName(rootDir, "alice", "qstars", msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	//the signature goes to the login service as JSON
+	bz, err := json.Marshal(sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got signer.MessageSignature
+	if err := json.Unmarshal(bz, &got); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := VerifyMessage(alice.Address, msg, got); err != nil || !ok {
+		t.Errorf("got %v %v, want the signature verified", ok, err)
+	}
+	if ok, err := VerifyMessage(alice.Address, []byte("login to litewallet, nonce 43"), got); err != nil || ok {
+		t.Errorf("got %v %v, want another message rejected", ok, err)
+	}
+	if ok, err := VerifyMessage(addr, msg, got); err != nil || ok {
+		t.Errorf("got %v %v, want another address rejected", ok, err)
+	}
+
+	//the private keys sign the same
+	byKey, err := SignMessage(privKey, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := VerifyMessage(addr, msg, byKey); err != nil || !ok {
+		t.Errorf("got %v %v, want the signature of the private key verified", ok, err)
+	}
+	byKey.PubKey.Type = signer.PubKeySecp256k1Type
+	if _, err := VerifyMessage(addr, msg, byKey); errcode.Code(err) != errcode.CodeInvalidInput {
+		t.Errorf("got %v for a secp256k1 signature, want an invalid input error", err)
+	}
+	if _, err := SignMessageByName(rootDir, "alice", "wrong", msg); errcode.Code(err) != errcode.CodeWrongPassword {
+		t.Errorf("got %v, want a wrong password error", err)
+	}
+}
@@ -0,0 +1,69 @@
+package slim
+
+import (
+	"fmt"
+
+	"github.com/QOSGroup/litewallet/litewallet/errcode"
+	"github.com/QOSGroup/litewallet/litewallet/signer"
+	"github.com/QOSGroup/litewallet/litewallet/slim/base/client/account"
+	"github.com/QOSGroup/litewallet/litewallet/slim/base/client/tx"
+	"github.com/QOSGroup/litewallet/litewallet/slim/base/types"
+	"github.com/QOSGroup/litewallet/litewallet/slim/tendermint/crypto/funcInlocal/ed25519local"
+)
+
+//MessageSignBytes returns the bytes the ed25519 key signs for msg, the message is prefixed as EIP-191 does
+//so that it can not be a tx
+func MessageSignBytes(msg []byte) []byte {
+	return append([]byte(fmt.Sprintf("\x19QOS Signed Message:\n%d", len(msg))), msg...)
+}
+
+//SignMessage signs msg with the base64 private key privkey
+func SignMessage(privkey string, msg []byte) (signer.MessageSignature, error) {
+	s, err := tx.PrivKeySigner(privkey)
+	if err != nil {
+		return signer.MessageSignature{}, err
+	}
+	return SignMessageWithSigner(s, msg)
+}
+
+//SignMessageByName signs msg with the local key name
+func SignMessageByName(rootDir, name, password string, msg []byte) (signer.MessageSignature, error) {
+	key, err := FetchKey(rootDir, name, password)
+	if err != nil {
+		return signer.MessageSignature{}, err
+	}
+	return SignMessageWithSigner(signer.NewEd25519(key), msg)
+}
+
+//SignMessageWithSigner is SignMessage signed by s
+func SignMessageWithSigner(s signer.Signer, msg []byte) (signer.MessageSignature, error) {
+	if _, err := tx.SignerAddress(s); err != nil {
+		return signer.MessageSignature{}, err
+	}
+	sig, err := s.Sign(MessageSignBytes(msg))
+	if err != nil {
+		return signer.MessageSignature{}, err
+	}
+	return signer.NewMessageSignature(s, sig), nil
+}
+
+//VerifyMessage tells whether sig is the signature of msg by the account address addr, the public key of sig
+//has to be the one of addr
+func VerifyMessage(addr string, msg []byte, sig signer.MessageSignature) (bool, error) {
+	accAddr, err := account.GetAddrFromValue(addr)
+	if err != nil {
+		return false, errcode.InvalidInput(err)
+	}
+	if err := sig.CheckType(signer.PubKeyEd25519Type); err != nil {
+		return false, err
+	}
+	var pubKey ed25519local.PubKeyEd25519
+	if len(sig.PubKey.Value) != len(pubKey) {
+		return false, errcode.Errorf(errcode.CodeInvalidInput, "invalid ed25519 public key of %d bytes", len(sig.PubKey.Value))
+	}
+	copy(pubKey[:], sig.PubKey.Value)
+	if !accAddr.Equals(types.AccAddress(pubKey.Address())) {
+		return false, nil
+	}
+	return pubKey.VerifyBytes(MessageSignBytes(msg), sig.Signature), nil
+}
@@ -1,8 +1,7 @@
 package module
 
 import (
-	"encoding/json"
-	"fmt"
+	"github.com/QOSGroup/litewallet/litewallet/signer"
 	"github.com/QOSGroup/litewallet/litewallet/slim/base/client/account"
 	"github.com/QOSGroup/litewallet/litewallet/slim/base/client/tx"
 	"github.com/QOSGroup/litewallet/litewallet/slim/base/txs"
@@ -11,7 +10,6 @@ import (
 	"github.com/QOSGroup/litewallet/litewallet/slim/tendermint/crypto/funcInlocal/ed25519local"
 	ctxs "github.com/QOSGroup/litewallet/litewallet/slim/txs"
 	ctypes "github.com/QOSGroup/litewallet/litewallet/slim/types"
-	"log"
 	"strconv"
 )
 
@@ -30,29 +28,20 @@ func AcutionAd(articleHash, privatekey, coinsType, coinAmount, qscchainid string
 		result.Reason = "AcutionAd invalid amount"
 		return result.Marshal()
 	}
-	tx, buff := acutionAd(articleHash, privatekey, coinsType, amount, qscchainid)
-	if buff != "" {
-		return buff
-	}
-
-	js, err := ctxs.Cdc.MarshalBinaryBare(tx)
+	tx, err := AcutionAdTx(articleHash, privatekey, coinsType, amount, qscchainid)
 	if err != nil {
-		log.Printf("AcutionAd err:%s", err.Error())
-		result.Code = ctypes.ResultCodeInternalError
-		result.Reason = err.Error()
-		return result.Marshal()
+		return err.Error()
 	}
-	result.Result = json.RawMessage(js)
-	return result.Marshal()
+	return InvestResult("AcutionAd", tx)
 }
 
-// acutionAd 竞拍广告
-func acutionAd(articleHash, privatekey, coinsType string, coinAmount int, qscchainid string) (*txs.TxStd, string) {
+//AcutionAdTx builds and signs the auction tx of an ad slot
+func AcutionAdTx(articleHash, privatekey, coinsType string, coinAmount int, qscchainid string) (*txs.TxStd, error) {
 	var key ed25519local.PrivKeyEd25519
 	ts := "{\"type\": \"tendermint/PrivKeyEd25519\",\"value\": \"" + privatekey + "\"}"
 	err1 := ctxs.Cdc.UnmarshalJSON([]byte(ts), &key)
 	if err1 != nil {
-		fmt.Println(err1)
+		return nil, err1
 	}
 	priv := key
 	gas := types.NewInt(int64(tx.MaxGas))
@@ -71,11 +60,5 @@ func acutionAd(articleHash, privatekey, coinsType string, coinAmount int, qsccha
 	it.Gas = types.ZeroInt()
 	it.CoinAmount = types.NewInt(int64(coinAmount))
 	tx2 := txs.NewTxStd(it, qscchainid, gas)
-	signature2, _ := tx2.SignTx(priv, qscnonce, "", qscchainid)
-	tx2.Signature = []txs.Signature{txs.Signature{
-		Pubkey:    priv.PubKey(),
-		Signature: signature2,
-		Nonce:     qscnonce,
-	}}
-	return tx2, ""
+	return tx.SignStdTx(signer.NewEd25519(priv), qscnonce, tx2, qscchainid, "")
 }
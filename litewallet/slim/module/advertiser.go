@@ -1,8 +1,9 @@
 package module
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
+	"github.com/QOSGroup/litewallet/litewallet/signer"
 	"github.com/QOSGroup/litewallet/litewallet/slim/base/client/account"
 	tx3 "github.com/QOSGroup/litewallet/litewallet/slim/base/client/tx"
 	"github.com/QOSGroup/litewallet/litewallet/slim/base/txs"
@@ -11,7 +12,6 @@ import (
 	"github.com/QOSGroup/litewallet/litewallet/slim/tendermint/crypto/funcInlocal/ed25519local"
 	ctxs "github.com/QOSGroup/litewallet/litewallet/slim/txs"
 	ctypes "github.com/QOSGroup/litewallet/litewallet/slim/types"
-	"log"
 	"strconv"
 )
 
@@ -35,35 +35,25 @@ func AdvertisersFalse(privatekey, coinsType, coinAmount, qscchainid string) stri
 
 //广告商押金或赎回
 func Advertisers(amount, privatekey, cointype, isDeposit, qscchainid string) string {
-	var result ctypes.ResultInvest
-	result.Code = ctypes.ResultCodeSuccess
-	tx, berr := advertisers(amount, privatekey, cointype, isDeposit, qscchainid)
-	if berr != "" {
-		return berr
-	}
-	js, err := ctxs.Cdc.MarshalBinaryBare(tx)
+	tx, err := AdvertisersTx(amount, privatekey, cointype, isDeposit, qscchainid)
 	if err != nil {
-		log.Printf("Advertisers err:%s", err.Error())
-		result.Code = ctypes.ResultCodeInternalError
-		result.Reason = err.Error()
-		return result.Marshal()
+		return err.Error()
 	}
-	result.Result = json.RawMessage(js)
-	return result.Marshal()
+	return InvestResult("Advertisers", tx)
 }
 
-// investAd 投资广告
-func advertisers(coins, privatekey, cointype, isDeposit, qscchainid string) (*txs.TxStd, string) {
+//AdvertisersTx builds and signs the deposit or redeem tx of an advertiser
+func AdvertisersTx(coins, privatekey, cointype, isDeposit, qscchainid string) (*txs.TxStd, error) {
 	amount, err := strconv.Atoi(coins)
 	if err != nil {
-		return nil, ctypes.NewErrorResult("601", 0, "", "amount format error").Marshal()
+		return nil, errors.New("amount format error")
 	}
 
 	var key ed25519local.PrivKeyEd25519
 	ts := "{\"type\": \"tendermint/PrivKeyEd25519\",\"value\": \"" + privatekey + "\"}"
 	err1 := ctxs.Cdc.UnmarshalJSON([]byte(ts), &key)
 	if err1 != nil {
-		fmt.Println(err1)
+		return nil, err1
 	}
 	priv := key
 	gas := types.NewInt(int64(tx3.MaxGas))
@@ -85,11 +75,5 @@ func advertisers(coins, privatekey, cointype, isDeposit, qscchainid string) (*tx
 	tx := ctxs.AdvertisersTx{it}
 	fmt.Println(investor, amount, cointype, isDeposit)
 	tx2 := txs.NewTxStd(tx, qscchainid, gas)
-	signature2, _ := tx2.SignTx(priv, qscnonce, "", qscchainid)
-	tx2.Signature = []txs.Signature{txs.Signature{
-		Pubkey:    priv.PubKey(),
-		Signature: signature2,
-		Nonce:     qscnonce,
-	}}
-	return tx2, ""
+	return tx3.SignStdTx(signer.NewEd25519(priv), qscnonce, tx2, qscchainid, "")
 }
@@ -1,89 +1,43 @@
 package module
 
 import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/QOSGroup/litewallet/litewallet/rpctest/qos"
+	"github.com/QOSGroup/litewallet/litewallet/slim/app"
+	"github.com/QOSGroup/litewallet/litewallet/slim/base/client/context"
+	btypes "github.com/QOSGroup/litewallet/litewallet/slim/base/types"
 	"github.com/QOSGroup/litewallet/litewallet/slim/module/approve/client"
+	approvetypes "github.com/QOSGroup/litewallet/litewallet/slim/module/approve/types"
+	"github.com/QOSGroup/litewallet/litewallet/slim/tendermint/crypto/funcInlocal/ed25519local"
 	"github.com/QOSGroup/litewallet/litewallet/slim/txs"
-	"testing"
 )
 
-func TestQueryApprove(t *testing.T) {
-	txs.SetBlockchainEntrance("47.103.78.91:26657", "forQmoonAddr")
-	toAddr := "address1nzv9awha9606jp5rpqe2kujckddpyauggu56ru"
-	privkey := "GkPnj9kRit0IMQNyY3k3KgQapl4l0o1hCQg4yqk1iw0kyVH28bOOMahIjzKOnUPLgv7A5fX3wQjV6qPdGOWeVA=="
-	Tout, err := client.QueryApprove(toAddr, privkey)
-	if err != nil {
-		t.Log(err)
-		return
-	}
-	t.Log(Tout)
-}
-
-func TestCreateApprove(t *testing.T) {
-	txs.SetBlockchainEntrance("47.103.78.91:26657", "forQmoonAddr")
-	toAddr := "address1nzv9awha9606jp5rpqe2kujckddpyauggu56ru"
-	coinstr := "10000qos"
-	privkey := "GkPnj9kRit0IMQNyY3k3KgQapl4l0o1hCQg4yqk1iw0kyVH28bOOMahIjzKOnUPLgv7A5fX3wQjV6qPdGOWeVA=="
-	chainid := "aquarius-1000"
-	Tout, err := client.CreateApprove(toAddr, coinstr, privkey, chainid)
-	if err != nil {
-		t.Log(err)
-		return
-	}
-	t.Log(Tout)
+//testKey returns the base64 private key and the address of a key derived from secret
+func testKey(secret string) (string, btypes.AccAddress) {
+	key := ed25519local.GenPrivKeyFromSecret([]byte(secret))
+	return base64.StdEncoding.EncodeToString(key[:]), btypes.AccAddress(key.PubKey().Address())
 }
 
-func TestIncreaseApprove(t *testing.T) {
-	txs.SetBlockchainEntrance("47.103.78.91:26657", "forQmoonAddr")
-	toAddr := "address1nzv9awha9606jp5rpqe2kujckddpyauggu56ru"
-	coinstr := "10000qos"
-	privkey := "GkPnj9kRit0IMQNyY3k3KgQapl4l0o1hCQg4yqk1iw0kyVH28bOOMahIjzKOnUPLgv7A5fX3wQjV6qPdGOWeVA=="
-	chainid := "aquarius-1000"
-	Tout, err := client.IncreaseApprove(toAddr, coinstr, privkey, chainid)
-	if err != nil {
-		t.Log(err)
-		return
-	}
-	t.Log(Tout)
-}
+func TestQueryApprove(t *testing.T) {
+	chain := qos.NewChain("aquarius-1000")
+	defer chain.Close()
+	txs.SetBlockchainEntrance(chain.Remote(), "forQmoonAddr")
+	cliCtx := context.NewCLIContext(chain.Remote()).WithCodec(app.Cdc)
 
-func TestDecreaseApprove(t *testing.T) {
-	txs.SetBlockchainEntrance("47.103.78.91:26657", "forQmoonAddr")
-	toAddr := "address1nzv9awha9606jp5rpqe2kujckddpyauggu56ru"
-	coinstr := "10000qos"
-	privkey := "GkPnj9kRit0IMQNyY3k3KgQapl4l0o1hCQg4yqk1iw0kyVH28bOOMahIjzKOnUPLgv7A5fX3wQjV6qPdGOWeVA=="
-	chainid := "aquarius-1000"
-	Tout, err := client.DecreaseApprove(toAddr, coinstr, privkey, chainid)
-	if err != nil {
-		t.Log(err)
-		return
+	privkey, from := testKey("approver")
+	_, to := testKey("approvee")
+	if _, err := client.QueryApprove(cliCtx, to.String(), privkey); err == nil {
+		t.Fatal("got an approve before it is created")
 	}
-	t.Log(Tout)
-}
 
-func TestUseApprove(t *testing.T) {
-	txs.SetBlockchainEntrance("47.103.78.91:26657", "forQmoonAddr")
-	toAddr := "address1nzv9awha9606jp5rpqe2kujckddpyauggu56ru"
-	coinstr := "10000qos"
-	privkey := "GkPnj9kRit0IMQNyY3k3KgQapl4l0o1hCQg4yqk1iw0kyVH28bOOMahIjzKOnUPLgv7A5fX3wQjV6qPdGOWeVA=="
-	chainid := "aquarius-1000"
-	Tout, err := client.UseApprove(toAddr, coinstr, privkey, chainid)
+	chain.SetApprove(approvetypes.NewApprove(from, to, btypes.NewInt(10000), nil))
+	approve, err := client.QueryApprove(cliCtx, to.String(), privkey)
 	if err != nil {
-		t.Log(err)
-		return
+		t.Fatal(err)
 	}
-	t.Log(Tout)
-}
-
-func TestCancelApprove(t *testing.T) {
-	txs.SetBlockchainEntrance("47.103.78.91:26657", "forQmoonAddr")
-	toAddr := "address1nzv9awha9606jp5rpqe2kujckddpyauggu56ru"
-	coinstr := "10000qos"
-	privkey := "GkPnj9kRit0IMQNyY3k3KgQapl4l0o1hCQg4yqk1iw0kyVH28bOOMahIjzKOnUPLgv7A5fX3wQjV6qPdGOWeVA=="
-	chainid := "aquarius-1000"
-	Tout, err := client.CancelApprove(toAddr, coinstr, privkey, chainid)
-	if err != nil {
-		t.Log(err)
-		return
+	if !approve.From.Equals(from) || !approve.To.Equals(to) || approve.QOS.Int64() != 10000 {
+		t.Errorf("got approve %+v, want 10000qos from %s to %s", approve, from, to)
 	}
-	t.Log(Tout)
 }
@@ -2,6 +2,7 @@ package client
 
 import (
 	"fmt"
+	"github.com/QOSGroup/litewallet/litewallet/signer"
 	qcliacc "github.com/QOSGroup/litewallet/litewallet/slim/base/client/account"
 	"github.com/QOSGroup/litewallet/litewallet/slim/base/client/context"
 	"github.com/QOSGroup/litewallet/litewallet/slim/base/client/tx"
@@ -10,40 +11,50 @@ import (
 	bank_txs "github.com/QOSGroup/litewallet/litewallet/slim/module/bank/txs"
 	"github.com/QOSGroup/litewallet/litewallet/slim/module/bank/types"
 	"github.com/QOSGroup/litewallet/litewallet/slim/tendermint/crypto/funcInlocal/bech32local"
-	"github.com/QOSGroup/litewallet/litewallet/slim/tendermint/crypto/funcInlocal/ed25519local"
-	ctxs "github.com/QOSGroup/litewallet/litewallet/slim/txs"
 	qtypes "github.com/QOSGroup/litewallet/litewallet/slim/types"
 	"strings"
 )
 
 func CreateTransfer(cliCtx context.CLIContext, addrto, coinstr, privkey, chainid string) ([]byte, error) {
-	return tx.BuildAndSignTx(cliCtx, privkey, chainid, func() (txs.ITx, error) {
-		var key ed25519local.PrivKeyEd25519
-		ts := "{\"type\": \"tendermint/PrivKeyEd25519\",\"value\": \"" + privkey + "\"}"
-		err := ctxs.Cdc.UnmarshalJSON([]byte(ts), &key)
-		if err != nil {
-			fmt.Println(err)
-		}
-		addrben32, _ := bech32local.ConvertAndEncode(btypes.PREF_ADD, key.PubKey().Address().Bytes())
-
-		sendersStr := addrben32 + `,` + coinstr
-		senders, err := parseTransItem(cliCtx, sendersStr)
-		if err != nil {
-			return nil, err
-		}
+	s, err := tx.PrivKeySigner(privkey)
+	if err != nil {
+		return nil, err
+	}
+	return CreateTransferWithSigner(cliCtx, addrto, coinstr, s, chainid)
+}
 
-		receiversStr := addrto + `,` + coinstr
-		receivers, err := parseTransItem(cliCtx, receiversStr)
+//CreateTransferWithSigner is CreateTransfer signed by s
+func CreateTransferWithSigner(cliCtx context.CLIContext, addrto, coinstr string, s signer.Signer, chainid string) ([]byte, error) {
+	return tx.BuildAndSignTxWithSigner(cliCtx, s, chainid, func() (txs.ITx, error) {
+		from, err := tx.SignerAddress(s)
 		if err != nil {
 			return nil, err
 		}
-		return bank_txs.TxTransfer{
-			Senders:   senders,
-			Receivers: receivers,
-		}, nil
+		return TransferTx(cliCtx, from, addrto, coinstr)
 	})
 }
 
+//TransferTx returns the transfer of coinstr from from to addrto
+func TransferTx(cliCtx context.CLIContext, from btypes.AccAddress, addrto, coinstr string) (txs.ITx, error) {
+	addrben32, _ := bech32local.ConvertAndEncode(btypes.PREF_ADD, from)
+
+	sendersStr := addrben32 + `,` + coinstr
+	senders, err := parseTransItem(cliCtx, sendersStr)
+	if err != nil {
+		return nil, err
+	}
+
+	receiversStr := addrto + `,` + coinstr
+	receivers, err := parseTransItem(cliCtx, receiversStr)
+	if err != nil {
+		return nil, err
+	}
+	return bank_txs.TxTransfer{
+		Senders:   senders,
+		Receivers: receivers,
+	}, nil
+}
+
 // Parse flags from string
 func parseTransItem(cliCtx context.CLIContext, str string) (types.TransItems, error) {
 	items := make(types.TransItems, 0)
@@ -1,18 +1,45 @@
 package client
 
 import (
+	"encoding/base64"
 	"testing"
+
+	"github.com/QOSGroup/litewallet/litewallet/rpctest/qos"
+	"github.com/QOSGroup/litewallet/litewallet/slim/app"
+	"github.com/QOSGroup/litewallet/litewallet/slim/base/client/context"
+	btypes "github.com/QOSGroup/litewallet/litewallet/slim/base/types"
+	"github.com/QOSGroup/litewallet/litewallet/slim/tendermint/crypto/funcInlocal/ed25519local"
+	"github.com/QOSGroup/litewallet/litewallet/slim/txs"
 )
 
-func TestQSCCreateSignedTransfer(t *testing.T) {
-	//txs.SetBlockchainEntrance("47.103.78.91:26657", "forQmoonAddr")
-	addrto := "address13l90zvt26szkrquutwpgj7kef58mgyntfs46l2"
-	coinstr := "10000qos"
-	privkey := "xGZuHJYesaYlgNJi7yeugj9A6Sc34f6plx5on6DDTTCVRb5f7neBxIsLUHgO+13Og38maO2E4kz55kX+4obHWQ=="
-	chainid := "qos-test"
-	Tout, err := CreateSignedTransfer(addrto, coinstr, privkey, chainid)
+func testKey(secret string) (string, btypes.AccAddress) {
+	key := ed25519local.GenPrivKeyFromSecret([]byte(secret))
+	return base64.StdEncoding.EncodeToString(key[:]), btypes.AccAddress(key.PubKey().Address())
+}
+
+func TestCreateTransfer(t *testing.T) {
+	chain := qos.NewChain("qos-test")
+	defer chain.Close()
+
+	privkey, from := testKey("sender")
+	_, to := testKey("receiver")
+	chain.SetAccount(from, 100000)
+	txs.SetBlockchainEntrance(chain.Remote(), "forQmoonAddr")
+	cliCtx := context.NewCLIContext(chain.Remote()).WithCodec(app.Cdc)
+
+	tx, err := CreateTransfer(cliCtx, to.String(), "10000qos", privkey, chain.ChainID)
 	if err != nil {
-		t.Log(err)
+		t.Fatal(err)
+	}
+	res, err := cliCtx.Client.BroadcastTxSync(tx)
+	if err != nil || res.Code != 0 {
+		t.Fatalf("broadcast: %v %+v", err, res)
+	}
+	if acc := chain.Account(to); acc == nil || acc.QOS.Int64() != 10000 {
+		t.Errorf("receiver got %v, want 10000qos", acc)
+	}
+
+	if _, err := CreateTransfer(cliCtx, to.String(), "qos10000", privkey, chain.ChainID); err == nil {
+		t.Error("got a transfer of a malformed coin")
 	}
-	t.Log(Tout)
 }
@@ -1,6 +1,7 @@
 package client
 
 import (
+	"errors"
 	"fmt"
 	qcliacc "github.com/QOSGroup/litewallet/litewallet/slim/base/client/account"
 	"github.com/QOSGroup/litewallet/litewallet/slim/base/client/context"
@@ -53,7 +54,11 @@ func QueryCommunityFeePool(cliCtx context.CLIContext, ) (btypes.BigInt, error) {
 		return btypes.BigInt{}, err
 	}
 
+	if len(res) == 0 {
+		return btypes.BigInt{}, errors.New("community fee pool not exists")
+	}
+
 	var result btypes.BigInt
-	cliCtx.Codec.MustUnmarshalBinaryBare(res, &result)
+	err = cliCtx.Codec.UnmarshalBinaryBare(res, &result)
 	return result, err
 }
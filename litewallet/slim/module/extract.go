@@ -1,8 +1,9 @@
 package module
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
+	"github.com/QOSGroup/litewallet/litewallet/signer"
 	"github.com/QOSGroup/litewallet/litewallet/slim/base/client/account"
 	tx3 "github.com/QOSGroup/litewallet/litewallet/slim/base/client/tx"
 	"github.com/QOSGroup/litewallet/litewallet/slim/base/txs"
@@ -11,40 +12,30 @@ import (
 	"github.com/QOSGroup/litewallet/litewallet/slim/tendermint/crypto/funcInlocal/ed25519local"
 	ctxs "github.com/QOSGroup/litewallet/litewallet/slim/txs"
 	ctypes "github.com/QOSGroup/litewallet/litewallet/slim/types"
-	"log"
 	"strconv"
 )
 
 //广告商押金或赎回
 func Extract(amount, privatekey, cointype, qscchainid string) string {
-	var result ctypes.ResultInvest
-	result.Code = ctypes.ResultCodeSuccess
-	tx, berr := extract(amount, privatekey, cointype, qscchainid)
-	if berr != "" {
-		return berr
-	}
-	js, err := ctxs.Cdc.MarshalBinaryBare(tx)
+	tx, err := ExtractTx(amount, privatekey, cointype, qscchainid)
 	if err != nil {
-		log.Printf("Extract err:%s", err.Error())
-		result.Code = ctypes.ResultCodeInternalError
-		result.Reason = err.Error()
-		return result.Marshal()
+		return err.Error()
 	}
-	result.Result = json.RawMessage(js)
-	return result.Marshal()
+	return InvestResult("Extract", tx)
 }
 
-func extract(coins, privatekey, cointype, qscchainid string) (*txs.TxStd, string) {
+//ExtractTx builds and signs the extract tx
+func ExtractTx(coins, privatekey, cointype, qscchainid string) (*txs.TxStd, error) {
 	amount, err := strconv.Atoi(coins)
 	if err != nil {
-		return nil, ctypes.NewErrorResult("601", 0, "", "amount format error").Marshal()
+		return nil, errors.New("amount format error")
 	}
 
 	var key ed25519local.PrivKeyEd25519
 	ts := "{\"type\": \"tendermint/PrivKeyEd25519\",\"value\": \"" + privatekey + "\"}"
 	err1 := ctxs.Cdc.UnmarshalJSON([]byte(ts), &key)
 	if err1 != nil {
-		fmt.Println(err1)
+		return nil, err1
 	}
 	priv := key
 	gas := types.NewInt(int64(tx3.MaxGas))
@@ -66,11 +57,5 @@ func extract(coins, privatekey, cointype, qscchainid string) (*txs.TxStd, string
 	tx := ctxs.ExtractTx{it}
 	fmt.Println(investor, amount, cointype, "2")
 	tx2 := txs.NewTxStd(tx, qscchainid, gas)
-	signature2, _ := tx2.SignTx(priv, qscnonce, "", qscchainid)
-	tx2.Signature = []txs.Signature{txs.Signature{
-		Pubkey:    priv.PubKey(),
-		Signature: signature2,
-		Nonce:     qscnonce,
-	}}
-	return tx2, ""
+	return tx3.SignStdTx(signer.NewEd25519(priv), qscnonce, tx2, qscchainid, "")
 }
@@ -21,7 +21,7 @@ func QueryProposal(cliCtx context.CLIContext, pID uint64) (types.Proposal, error
 	res, err := cliCtx.Query(path, []byte{})
 
 	if err != nil {
-		return types.Proposal{}, nil
+		return types.Proposal{}, err
 	}
 
 	if len(res) == 0 {
@@ -167,7 +167,7 @@ func QueryDeposit(cliCtx context.CLIContext, pID uint64, addrStr string) (types.
 
 	var deposit types.Deposit
 	if err := cliCtx.Codec.UnmarshalJSON(res, &deposit); err != nil {
-		return types.Deposit{}, nil
+		return types.Deposit{}, err
 	}
 
 	return deposit, err
@@ -214,7 +214,7 @@ func QueryTally(cliCtx context.CLIContext, pID uint64, addrStr string) (types.Ta
 
 	var result types.TallyResult
 	if err := cliCtx.Codec.UnmarshalJSON(res, &result); err != nil {
-		return types.TallyResult{}, nil
+		return types.TallyResult{}, err
 	}
 
 	return result, err
@@ -3,6 +3,7 @@ package module
 import (
 	"encoding/json"
 	"fmt"
+	"github.com/QOSGroup/litewallet/litewallet/signer"
 	"github.com/QOSGroup/litewallet/litewallet/slim/base/client/account"
 	"github.com/QOSGroup/litewallet/litewallet/slim/base/client/tx"
 	"github.com/QOSGroup/litewallet/litewallet/slim/base/txs"
@@ -12,6 +13,7 @@ import (
 	ctxs "github.com/QOSGroup/litewallet/litewallet/slim/txs"
 	ctypes "github.com/QOSGroup/litewallet/litewallet/slim/types"
 	"github.com/pkg/errors"
+	"log"
 	"regexp"
 	"strconv"
 	"strings"
@@ -31,7 +33,7 @@ func InvestAd(QOSchainId, QSCchainId, articleHash, coins, privatekey string) str
 	var result ctypes.ResultInvest
 	result.Code = ctypes.ResultCodeSuccess
 
-	tx, err := investAd(QOSchainId, QSCchainId, articleHash, coins, privatekey)
+	tx, err := InvestAdTx(QOSchainId, QSCchainId, articleHash, coins, privatekey)
 	if err != nil {
 		fmt.Printf("investAd err:%s", err.Error())
 		result.Code = ctypes.ResultCodeInternalError
@@ -51,7 +53,23 @@ func InvestAd(QOSchainId, QSCchainId, articleHash, coins, privatekey string) str
 	return result.Marshal()
 }
 
-func investAd(QOSchainId, QSCchainId, articleHash, coins, privatekey string) (*txs.TxStd, error) {
+//InvestResult marshals the signed tx into the ResultInvest json returned by the qsc functions
+func InvestResult(name string, tx *txs.TxStd) string {
+	var result ctypes.ResultInvest
+	result.Code = ctypes.ResultCodeSuccess
+	js, err := ctxs.Cdc.MarshalBinaryBare(tx)
+	if err != nil {
+		log.Printf("%s err:%s", name, err.Error())
+		result.Code = ctypes.ResultCodeInternalError
+		result.Reason = err.Error()
+		return result.Marshal()
+	}
+	result.Result = json.RawMessage(js)
+	return result.Marshal()
+}
+
+//InvestAdTx builds and signs the tx investing coins, in AOE, in the article articleHash
+func InvestAdTx(QOSchainId, QSCchainId, articleHash, coins, privatekey string) (*txs.TxStd, error) {
 	cs, err := ParseCoins(coins)
 	if err != nil {
 		return nil, err
@@ -96,14 +114,7 @@ func investAd(QOSchainId, QSCchainId, articleHash, coins, privatekey string) (*t
 	it.Invest = ccs[0].Amount
 	gas := types.NewInt(int64(tx.MaxGas))
 	tx2 := txs.NewTxStd(it, QSCchainId, gas)
-	signature2, _ := tx2.SignTx(priv, qscnonce, "", QSCchainId)
-	tx2.Signature = []txs.Signature{txs.Signature{
-		Pubkey:    priv.PubKey(),
-		Signature: signature2,
-		Nonce:     qscnonce,
-	}}
-
-	return tx2, nil
+	return tx.SignStdTx(signer.NewEd25519(priv), qscnonce, tx2, QSCchainId, "")
 }
 
 func ParseCoins(coinsStr string) (coins ctypes.Coins, err error) {
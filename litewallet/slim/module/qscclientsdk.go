@@ -2,7 +2,7 @@ package module
 
 import (
 	"encoding/json"
-	"fmt"
+	"github.com/QOSGroup/litewallet/litewallet/signer"
 	"github.com/QOSGroup/litewallet/litewallet/slim/base/client/account"
 	tx3 "github.com/QOSGroup/litewallet/litewallet/slim/base/client/tx"
 	"github.com/QOSGroup/litewallet/litewallet/slim/base/txs"
@@ -27,27 +27,20 @@ func CommHandler(funcName, privatekey, argstr, qscchainid string) string {
 		return result.Marshal()
 	}
 
-	tx, berr := commHandler(funcName, privatekey, args, qscchainid)
-	if berr != "" {
-		return berr
-	}
-	js, err := ctxs.Cdc.MarshalBinaryBare(tx)
+	tx, err := CommHandlerTx(funcName, privatekey, args, qscchainid)
 	if err != nil {
-		log.Printf("CommHandler err:%s", err.Error())
-		result.Code = ctypes.ResultCodeInternalError
-		result.Reason = err.Error()
-		return result.Marshal()
+		return err.Error()
 	}
-	result.Result = json.RawMessage(js)
-	return result.Marshal()
+	return InvestResult("CommHandler", tx)
 }
 
-func commHandler(funcName, privatekey string, args []string, qscchainid string) (*txs.TxStd, string) {
+//CommHandlerTx builds and signs the tx calling funcName of the QSC contract with args
+func CommHandlerTx(funcName, privatekey string, args []string, qscchainid string) (*txs.TxStd, error) {
 	var key ed25519local.PrivKeyEd25519
 	ts := "{\"type\": \"tendermint/PrivKeyEd25519\",\"value\": \"" + privatekey + "\"}"
 	err1 := ctxs.Cdc.UnmarshalJSON([]byte(ts), &key)
 	if err1 != nil {
-		fmt.Println(err1)
+		return nil, err1
 	}
 	priv := key
 	gas := types.NewInt(int64(tx3.MaxGas))
@@ -69,11 +62,5 @@ func commHandler(funcName, privatekey string, args []string, qscchainid string)
 	tx.Gas = gas
 
 	tx2 := txs.NewTxStd(tx, qscchainid, gas)
-	signature2, _ := tx2.SignTx(priv, qscnonce, "", qscchainid)
-	tx2.Signature = []txs.Signature{txs.Signature{
-		Pubkey:    priv.PubKey(),
-		Signature: signature2,
-		Nonce:     qscnonce,
-	}}
-	return tx2, ""
+	return tx3.SignStdTx(signer.NewEd25519(priv), qscnonce, tx2, qscchainid, "")
 }
@@ -1,7 +1,7 @@
 package client
 
 import (
-	"fmt"
+	"github.com/QOSGroup/litewallet/litewallet/signer"
 	qcliacc "github.com/QOSGroup/litewallet/litewallet/slim/base/client/account"
 	"github.com/QOSGroup/litewallet/litewallet/slim/base/client/context"
 	"github.com/QOSGroup/litewallet/litewallet/slim/base/client/tx"
@@ -9,116 +9,150 @@ import (
 	"github.com/QOSGroup/litewallet/litewallet/slim/base/types"
 	stake_txs "github.com/QOSGroup/litewallet/litewallet/slim/module/stake/txs"
 	"github.com/QOSGroup/litewallet/litewallet/slim/tendermint/crypto/funcInlocal/bech32local"
-	"github.com/QOSGroup/litewallet/litewallet/slim/tendermint/crypto/funcInlocal/ed25519local"
-	ctxs "github.com/QOSGroup/litewallet/litewallet/slim/txs"
 	"github.com/pkg/errors"
 )
 
 func CreateDelegation(ctx context.CLIContext, validatorAddress string, coins int64, privKey, chainId string) ([]byte, error) {
-	return tx.BuildAndSignTx(ctx, privKey, chainId, func() (txs.ITx, error) {
-		var key ed25519local.PrivKeyEd25519
-		ts := "{\"type\": \"tendermint/PrivKeyEd25519\",\"value\": \"" + privKey + "\"}"
-		err := ctxs.Cdc.UnmarshalJSON([]byte(ts), &key)
-		if err != nil {
-			fmt.Println(err)
-		}
-		addrben32, _ := bech32local.ConvertAndEncode(types.PREF_ADD, key.PubKey().Address().Bytes())
-
-		validatorAddr, err := qcliacc.GetValidatorAddrFromValue(validatorAddress)
-		if err != nil {
-			return nil, err
-		}
+	s, err := tx.PrivKeySigner(privKey)
+	if err != nil {
+		return nil, err
+	}
+	return CreateDelegationWithSigner(ctx, validatorAddress, coins, s, chainId)
+}
 
-		delegator, err := qcliacc.GetAddrFromValue(addrben32)
+//CreateDelegationWithSigner is CreateDelegation signed by s
+func CreateDelegationWithSigner(ctx context.CLIContext, validatorAddress string, coins int64, s signer.Signer, chainId string) ([]byte, error) {
+	return tx.BuildAndSignTxWithSigner(ctx, s, chainId, func() (txs.ITx, error) {
+		from, err := tx.SignerAddress(s)
 		if err != nil {
 			return nil, err
 		}
-
-		if coins <= 0 {
-			return nil, errors.New("delegate QOS amount must gt 0")
-		}
-		tokens := types.NewInt(coins)
-
-		return &stake_txs.TxCreateDelegation{
-			Delegator:     delegator,
-			ValidatorAddr: validatorAddr,
-			Amount:        tokens,
-			IsCompound:    false,
-		}, nil
+		return DelegationTx(ctx, from, validatorAddress, coins)
 	})
 }
 
-func CreateUnbondDelegation(ctx context.CLIContext, validatorAddress string, coins int64, privKey, chainId string) ([]byte, error) {
-	return tx.BuildAndSignTx(ctx, privKey, chainId, func() (txs.ITx, error) {
-		var key ed25519local.PrivKeyEd25519
-		ts := "{\"type\": \"tendermint/PrivKeyEd25519\",\"value\": \"" + privKey + "\"}"
-		err := ctxs.Cdc.UnmarshalJSON([]byte(ts), &key)
-		if err != nil {
-			fmt.Println(err)
-		}
-		addrben32, _ := bech32local.ConvertAndEncode(types.PREF_ADD, key.PubKey().Address().Bytes())
+//DelegationTx returns the delegation of coins of from to validatorAddress
+func DelegationTx(ctx context.CLIContext, from types.AccAddress, validatorAddress string, coins int64) (txs.ITx, error) {
+	addrben32, _ := bech32local.ConvertAndEncode(types.PREF_ADD, from)
+
+	validatorAddr, err := qcliacc.GetValidatorAddrFromValue(validatorAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	delegator, err := qcliacc.GetAddrFromValue(addrben32)
+	if err != nil {
+		return nil, err
+	}
+
+	if coins <= 0 {
+		return nil, errors.New("delegate QOS amount must gt 0")
+	}
+	tokens := types.NewInt(coins)
+
+	return &stake_txs.TxCreateDelegation{
+		Delegator:     delegator,
+		ValidatorAddr: validatorAddr,
+		Amount:        tokens,
+		IsCompound:    false,
+	}, nil
+}
 
-		validatorAddr, err := qcliacc.GetValidatorAddrFromValue(validatorAddress)
-		if err != nil {
-			return nil, err
-		}
+func CreateUnbondDelegation(ctx context.CLIContext, validatorAddress string, coins int64, privKey, chainId string) ([]byte, error) {
+	s, err := tx.PrivKeySigner(privKey)
+	if err != nil {
+		return nil, err
+	}
+	return CreateUnbondDelegationWithSigner(ctx, validatorAddress, coins, s, chainId)
+}
 
-		delegator, err := qcliacc.GetAddrFromValue(addrben32)
+//CreateUnbondDelegationWithSigner is CreateUnbondDelegation signed by s
+func CreateUnbondDelegationWithSigner(ctx context.CLIContext, validatorAddress string, coins int64, s signer.Signer, chainId string) ([]byte, error) {
+	return tx.BuildAndSignTxWithSigner(ctx, s, chainId, func() (txs.ITx, error) {
+		from, err := tx.SignerAddress(s)
 		if err != nil {
 			return nil, err
 		}
-
-		tokens := types.NewInt(coins)
-		if !tokens.GT(types.ZeroInt()) {
-			return nil, errors.New("unbond QOS amount must gt 0")
-		}
-
-		return &stake_txs.TxUnbondDelegation{
-			Delegator:     delegator,
-			ValidatorAddr: validatorAddr,
-			UnbondAmount:  tokens,
-			IsUnbondAll:   false,
-		}, nil
+		return UnbondDelegationTx(ctx, from, validatorAddress, coins)
 	})
 }
 
-func CreateReDelegationCommand(ctx context.CLIContext, fromValidatorAddr, toValidatorAddr string, coins int64, privKey, chainId string) ([]byte, error) {
-	return tx.BuildAndSignTx(ctx, privKey, chainId, func() (txs.ITx, error) {
-		var key ed25519local.PrivKeyEd25519
-		ts := "{\"type\": \"tendermint/PrivKeyEd25519\",\"value\": \"" + privKey + "\"}"
-		err := ctxs.Cdc.UnmarshalJSON([]byte(ts), &key)
-		if err != nil {
-			fmt.Println(err)
-		}
-		addrben32, _ := bech32local.ConvertAndEncode(types.PREF_ADD, key.PubKey().Address().Bytes())
-
-		tokens :=types.NewInt(coins)
-		if !tokens.GT(types.ZeroInt())  {
-			return nil, errors.New("redelegate QOS amount must gt 0")
-		}
-
-		delegator, err := qcliacc.GetAddrFromValue(addrben32)
-		if err != nil {
-			return nil, err
-		}
+//UnbondDelegationTx returns the unbonding of coins of from from validatorAddress
+func UnbondDelegationTx(ctx context.CLIContext, from types.AccAddress, validatorAddress string, coins int64) (txs.ITx, error) {
+	addrben32, _ := bech32local.ConvertAndEncode(types.PREF_ADD, from)
+
+	validatorAddr, err := qcliacc.GetValidatorAddrFromValue(validatorAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	delegator, err := qcliacc.GetAddrFromValue(addrben32)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := types.NewInt(coins)
+	if !tokens.GT(types.ZeroInt()) {
+		return nil, errors.New("unbond QOS amount must gt 0")
+	}
+
+	return &stake_txs.TxUnbondDelegation{
+		Delegator:     delegator,
+		ValidatorAddr: validatorAddr,
+		UnbondAmount:  tokens,
+		IsUnbondAll:   false,
+	}, nil
+}
 
-		fromValidatorAddr, err := qcliacc.GetValidatorAddrFromValue(fromValidatorAddr)
-		if err != nil {
-			return nil, err
-		}
+func CreateReDelegationCommand(ctx context.CLIContext, fromValidatorAddr, toValidatorAddr string, coins int64, privKey, chainId string) ([]byte, error) {
+	s, err := tx.PrivKeySigner(privKey)
+	if err != nil {
+		return nil, err
+	}
+	return CreateReDelegationCommandWithSigner(ctx, fromValidatorAddr, toValidatorAddr, coins, s, chainId)
+}
 
-		toValidatorAddr, err := qcliacc.GetValidatorAddrFromValue(toValidatorAddr)
+//CreateReDelegationCommandWithSigner is CreateReDelegationCommand signed by s
+func CreateReDelegationCommandWithSigner(ctx context.CLIContext, fromValidatorAddr, toValidatorAddr string, coins int64, s signer.Signer, chainId string) ([]byte, error) {
+	return tx.BuildAndSignTxWithSigner(ctx, s, chainId, func() (txs.ITx, error) {
+		from, err := tx.SignerAddress(s)
 		if err != nil {
 			return nil, err
 		}
-
-		return &stake_txs.TxCreateReDelegation{
-			Delegator:         delegator,
-			FromValidatorAddr: fromValidatorAddr,
-			ToValidatorAddr:   toValidatorAddr,
-			Amount:            tokens,
-			IsCompound:         true,
-			IsRedelegateAll:    false,
-		}, nil
+		return ReDelegationTx(ctx, from, fromValidatorAddr, toValidatorAddr, coins)
 	})
 }
+
+//ReDelegationTx returns the redelegation of coins of from from fromValidatorAddr to toValidatorAddr
+func ReDelegationTx(ctx context.CLIContext, from types.AccAddress, fromValidatorAddr, toValidatorAddr string, coins int64) (txs.ITx, error) {
+	addrben32, _ := bech32local.ConvertAndEncode(types.PREF_ADD, from)
+
+	tokens :=types.NewInt(coins)
+	if !tokens.GT(types.ZeroInt())  {
+		return nil, errors.New("redelegate QOS amount must gt 0")
+	}
+
+	delegator, err := qcliacc.GetAddrFromValue(addrben32)
+	if err != nil {
+		return nil, err
+	}
+
+	fromValidator, err := qcliacc.GetValidatorAddrFromValue(fromValidatorAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	toValidator, err := qcliacc.GetValidatorAddrFromValue(toValidatorAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &stake_txs.TxCreateReDelegation{
+		Delegator:         delegator,
+		FromValidatorAddr: fromValidator,
+		ToValidatorAddr:   toValidator,
+		Amount:            tokens,
+		IsCompound:         true,
+		IsRedelegateAll:    false,
+	}, nil
+}
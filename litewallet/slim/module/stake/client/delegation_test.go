@@ -1,33 +1,79 @@
 package client
 
 import (
-	"github.com/QOSGroup/litewallet/litewallet/slim/txs"
+	"encoding/base64"
 	"testing"
+
+	"github.com/QOSGroup/litewallet/litewallet/rpctest/qos"
+	"github.com/QOSGroup/litewallet/litewallet/slim/app"
+	"github.com/QOSGroup/litewallet/litewallet/slim/base/client/context"
+	btypes "github.com/QOSGroup/litewallet/litewallet/slim/base/types"
+	"github.com/QOSGroup/litewallet/litewallet/slim/module/stake/types"
+	"github.com/QOSGroup/litewallet/litewallet/slim/tendermint/crypto/funcInlocal/ed25519local"
+	"github.com/QOSGroup/litewallet/litewallet/slim/txs"
 )
 
-func TestCreateSignedDelegation(t *testing.T) {
-	txs.SetBlockchainEntrance("47.103.78.91:26657", "forQmoonAddr")
-	validatorAddress := "address1nzv9awha9606jp5rpqe2kujckddpyauggu56ru"
-	//coinstr := "10000qos"
-	privkey := "GkPnj9kRit0IMQNyY3k3KgQapl4l0o1hCQg4yqk1iw0kyVH28bOOMahIjzKOnUPLgv7A5fX3wQjV6qPdGOWeVA=="
-	chainid := "aquarius-1000"
-	Tout, err := CreateSignedDelegation(validatorAddress, 100, privkey, chainid)
+var validatorAddr = btypes.ValAddress(ed25519local.GenPrivKeyFromSecret([]byte("validator")).PubKey().Address())
+
+//newChain starts a chain where the returned key holds 100000qos and validatorAddr is bonded
+func newChain(t *testing.T) (*qos.Chain, context.CLIContext, string, btypes.AccAddress) {
+	chain := qos.NewChain("aquarius-1000")
+	key := ed25519local.GenPrivKeyFromSecret([]byte("delegator"))
+	delegator := btypes.AccAddress(key.PubKey().Address())
+	chain.SetAccount(delegator, 100000)
+	chain.SetValidator(types.Validator{
+		OperatorAddress: validatorAddr,
+		Owner:           btypes.AccAddress(validatorAddr),
+		ConsPubKey:      ed25519local.GenPrivKeyFromSecret([]byte("consensus")).PubKey(),
+		BondTokens:      btypes.NewInt(1000000),
+	})
+	txs.SetBlockchainEntrance(chain.Remote(), "forQmoonAddr")
+	cliCtx := context.NewCLIContext(chain.Remote()).WithCodec(app.Cdc)
+	return chain, cliCtx, base64.StdEncoding.EncodeToString(key[:]), delegator
+}
+
+func broadcast(t *testing.T, cliCtx context.CLIContext, tx []byte, err error) {
 	if err != nil {
-		t.Log(err)
-		return
+		t.Fatal(err)
+	}
+	res, err := cliCtx.Client.BroadcastTxSync(tx)
+	if err != nil || res.Code != 0 {
+		t.Fatalf("broadcast: %v %+v", err, res)
 	}
-	t.Log(Tout)
 }
 
-func TestCreateSignedUnbondDelegation(t *testing.T) {
-	txs.SetBlockchainEntrance("47.103.78.91:26657", "forQmoonAddr")
-	validatorAddress := "address1nzv9awha9606jp5rpqe2kujckddpyauggu56ru"
-	//coinstr := "10000qos"
-	privkey := "xGZuHJYesaYlgNJi7yeugj9A6Sc34f6plx5on6DDTTCVRb5f7neBxIsLUHgO+13Og38maO2E4kz55kX+4obHWQ=="
-	chainid := "aquarius-1000"
-	Tout, err := CreateSignedUnbondDelegation(validatorAddress, 1000, privkey, chainid)
+func TestCreateDelegation(t *testing.T) {
+	chain, cliCtx, privkey, delegator := newChain(t)
+	defer chain.Close()
+
+	tx, err := CreateDelegation(cliCtx, validatorAddr.String(), 100, privkey, chain.ChainID)
+	broadcast(t, cliCtx, tx, err)
+	if amount := chain.Delegation(delegator, validatorAddr); amount != 100 {
+		t.Errorf("got delegation %d, want 100", amount)
+	}
+
+	if _, err := CreateDelegation(cliCtx, validatorAddr.String(), 0, privkey, chain.ChainID); err == nil {
+		t.Error("got a delegation of 0 qos")
+	}
+}
+
+func TestCreateUnbondDelegation(t *testing.T) {
+	chain, cliCtx, privkey, delegator := newChain(t)
+	defer chain.Close()
+
+	tx, err := CreateDelegation(cliCtx, validatorAddr.String(), 1000, privkey, chain.ChainID)
+	broadcast(t, cliCtx, tx, err)
+	tx, err = CreateUnbondDelegation(cliCtx, validatorAddr.String(), 400, privkey, chain.ChainID)
+	broadcast(t, cliCtx, tx, err)
+	if amount := chain.Delegation(delegator, validatorAddr); amount != 600 {
+		t.Errorf("got delegation %d, want 600", amount)
+	}
+
+	unbondings, err := QueryUnbondings(cliCtx, delegator.String())
 	if err != nil {
-		t.Log(err)
+		t.Fatal(err)
+	}
+	if len(unbondings) != 1 || unbondings[0].Amount.Int64() != 400 {
+		t.Errorf("got unbondings %+v, want one of 400", unbondings)
 	}
-	t.Log(Tout)
 }
@@ -93,12 +93,11 @@ func QueryValidatorInfo(ctx context.CLIContext, address string) (types.Validator
 }
 
 func getValidator(ctx context.CLIContext, validatorAddr btypes.ValAddress) (types.Validator, error) {
-	result, err := ctx.Client.ABCIQueryWithOptions(string(types.BuildStakeStoreQueryPath()), types.BuildValidatorKey(validatorAddr), buildQueryOptions())
+	valueBz, err := ctx.Query(string(types.BuildStakeStoreQueryPath()), types.BuildValidatorKey(validatorAddr))
 	if err != nil {
 		return types.Validator{}, err
 	}
 
-	valueBz := result.Response.GetValue()
 	if len(valueBz) == 0 {
 		return types.Validator{}, errors.New("owner does't have validator")
 	}
@@ -161,18 +160,19 @@ func QueryValidators(cliCtx context.CLIContext) ([]byte, error) {
 	return txs.Cdc.MarshalJSON(validators)
 }
 
-func QueryValidatorMissedVoteInfo(cliCtx context.CLIContext, address string) (voteSummary, error) {
+func QueryValidatorMissedVoteInfo(cliCtx context.CLIContext, address string) (VoteSummary, error) {
 	//cliCtx := context.NewCLIContext(remote).WithCodec(txs.Cdc)
 
 	ownerAddress, err := qcliacc.GetValidatorAddrFromValue(address)
 	if err != nil {
-		return voteSummary{}, err
+		return VoteSummary{}, err
 	}
 
 	return queryVotesInfoByOwner(cliCtx, ownerAddress)
 }
 
-type voteSummary struct {
+//VoteSummary is the vote record of a validator in the current window
+type VoteSummary struct {
 	StartHeight int64            `json:"startHeight"`
 	EndHeight   int64            `json:"endHeight"`
 	MissCount   int8             `json:"missCount"`
@@ -184,8 +184,8 @@ type voteInfoDetail struct {
 	Vote   bool
 }
 
-func queryVotesInfoByOwner(ctx context.CLIContext, validatorAddr btypes.ValAddress) (voteSummary, error) {
-	voteSummaryDisplay := voteSummary{}
+func queryVotesInfoByOwner(ctx context.CLIContext, validatorAddr btypes.ValAddress) (VoteSummary, error) {
+	voteSummaryDisplay := VoteSummary{}
 
 	windownLength, err := getStakeConfig(ctx)
 	if err != nil {
@@ -243,20 +243,14 @@ func queryVotesInfoByOwner(ctx context.CLIContext, validatorAddr btypes.ValAddre
 }
 
 func getStakeConfig(ctx context.CLIContext) (int64, error) {
-	node, err := ctx.GetNode()
-	if err != nil {
-		return 0, err
-	}
-
 	path := "/store/params/key"
 	key := BuildParamKey(types.ParamSpace, types.KeyValidatorVotingStatusLen)
 
-	result, err := node.ABCIQueryWithOptions(path, key, buildQueryOptions())
+	valueBz, err := ctx.Query(path, key)
 	if err != nil {
 		return 0, err
 	}
 
-	valueBz := result.Response.GetValue()
 	if len(valueBz) == 0 {
 		return 0, errors.New("response empty value. getStakeConfig is empty")
 	}
@@ -274,20 +268,14 @@ func BuildParamKey(paramSpace string, key []byte) []byte {
 }
 
 func getValidatorVoteInfo(ctx context.CLIContext, validatorAddr btypes.ValAddress) (types.ValidatorVoteInfo, error) {
-	node, err := ctx.GetNode()
-	if err != nil {
-		return types.ValidatorVoteInfo{}, err
-	}
-
 	path := string(types.BuildStakeStoreQueryPath())
 	key := types.BuildValidatorVoteInfoKey(validatorAddr)
 
-	result, err := node.ABCIQueryWithOptions(path, key, buildQueryOptions())
+	valueBz, err := ctx.Query(path, key)
 	if err != nil {
 		return types.ValidatorVoteInfo{}, err
 	}
 
-	valueBz := result.Response.GetValue()
 	if len(valueBz) == 0 {
 		return types.ValidatorVoteInfo{}, errors.New("response empty value. validatorVoteInfo is empty")
 	}
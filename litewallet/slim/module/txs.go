@@ -2,11 +2,11 @@ package module
 
 import (
 	"encoding/hex"
+	"github.com/QOSGroup/litewallet/litewallet/slim/app"
 	"github.com/QOSGroup/litewallet/litewallet/slim/base/client/context"
 	btypes "github.com/QOSGroup/litewallet/litewallet/slim/base/types"
 	ctypes "github.com/QOSGroup/litewallet/litewallet/slim/tendermint/rpc/core/types"
 	tendermint_types "github.com/QOSGroup/litewallet/litewallet/slim/tendermint/types"
-	"github.com/QOSGroup/litewallet/litewallet/slim/txs"
 	go_amino "github.com/tendermint/go-amino"
 	"time"
 )
@@ -33,7 +33,7 @@ func QueryTx(remote, hashHexStr string) (btypes.TxResponse, error) {
 		return btypes.TxResponse{}, err
 	}
 
-	out, err := formatTxResult(txs.Cdc, resTx, resBlocks[resTx.Height])
+	out, err := formatTxResult(app.Cdc, resTx, resBlocks[resTx.Height])
 	if err != nil {
 		return out, err
 	}
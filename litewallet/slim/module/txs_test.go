@@ -1,16 +1,41 @@
 package module
 
 import (
-	"github.com/QOSGroup/litewallet/litewallet/slim/txs"
 	"testing"
+
+	"github.com/QOSGroup/litewallet/litewallet/rpctest/qos"
+	"github.com/QOSGroup/litewallet/litewallet/slim/app"
+	"github.com/QOSGroup/litewallet/litewallet/slim/base/client/context"
+	bank_client "github.com/QOSGroup/litewallet/litewallet/slim/module/bank/client"
+	"github.com/QOSGroup/litewallet/litewallet/slim/txs"
 )
 
 func TestQueryTx(t *testing.T) {
-	txs.SetBlockchainEntrance("47.103.78.91:26657", "forQmoonAddr")
-	hash := "B5EECB27939D969556C61E00BDE8C910FFE3BE47BFA0356B57F58847D6502B70"
-	Tout, err := QueryTx("47.103.78.91:26657", hash)
+	chain := qos.NewChain("aquarius-1000")
+	defer chain.Close()
+	txs.SetBlockchainEntrance(chain.Remote(), "forQmoonAddr")
+
+	privkey, from := testKey("sender")
+	_, to := testKey("receiver")
+	chain.SetAccount(from, 100)
+	cliCtx := context.NewCLIContext(chain.Remote()).WithCodec(app.Cdc)
+	tx, err := bank_client.CreateTransfer(cliCtx, to.String(), "10qos", privkey, chain.ChainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := cliCtx.Client.BroadcastTxSync(tx)
+	if err != nil || res.Code != 0 {
+		t.Fatalf("broadcast: %v %+v", err, res)
+	}
+
+	Tout, err := QueryTx(chain.Remote(), res.Hash.String())
 	if err != nil {
-		t.Log(err)
+		t.Fatal(err)
+	}
+	if Tout.TxHash != res.Hash.String() || Tout.Height != chain.Height() || Tout.Tx == nil {
+		t.Errorf("got %+v, want the tx %s at height %d", Tout, res.Hash, chain.Height())
+	}
+	if Tout.Timestamp == "" {
+		t.Error("got no block time")
 	}
-	t.Log(Tout)
 }
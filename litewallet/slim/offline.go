@@ -0,0 +1,133 @@
+package slim
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/QOSGroup/litewallet/litewallet/errcode"
+	"github.com/QOSGroup/litewallet/litewallet/offline"
+	"github.com/QOSGroup/litewallet/litewallet/signer"
+	"github.com/QOSGroup/litewallet/litewallet/slim/app"
+	"github.com/QOSGroup/litewallet/litewallet/slim/base/client/account"
+	"github.com/QOSGroup/litewallet/litewallet/slim/base/client/context"
+	"github.com/QOSGroup/litewallet/litewallet/slim/base/client/tx"
+	btxs "github.com/QOSGroup/litewallet/litewallet/slim/base/txs"
+	"github.com/QOSGroup/litewallet/litewallet/slim/base/types"
+	bank_client "github.com/QOSGroup/litewallet/litewallet/slim/module/bank/client"
+	stake_client "github.com/QOSGroup/litewallet/litewallet/slim/module/stake/client"
+	"github.com/tendermint/tendermint/crypto/tmhash"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+)
+
+//The offline txs are built from the address of the sender with its next nonce, signed by SignOffline on a
+//device without network and broadcast by BroadcastOffline, see the offline package.
+
+//BuildOfflineTransfer builds the unsigned transfer of coinstr from from to addrto
+func BuildOfflineTransfer(remote, from, addrto, coinstr, chainid string) (offline.Tx, error) {
+	return buildOffline(remote, from, chainid, offline.TypeTransfer, func(cliCtx context.CLIContext, fromAddr types.AccAddress) (btxs.ITx, error) {
+		return bank_client.TransferTx(cliCtx, fromAddr, addrto, coinstr)
+	})
+}
+
+//BuildOfflineDelegation builds the unsigned delegation of coins of from to the validator addrto
+func BuildOfflineDelegation(remote, from, addrto string, coins int64, chainid string) (offline.Tx, error) {
+	return buildOffline(remote, from, chainid, offline.TypeDelegate, func(cliCtx context.CLIContext, fromAddr types.AccAddress) (btxs.ITx, error) {
+		return stake_client.DelegationTx(cliCtx, fromAddr, addrto, coins)
+	})
+}
+
+//BuildOfflineUnbondDelegation builds the unsigned unbonding of coins of from from the validator addrto
+func BuildOfflineUnbondDelegation(remote, from, addrto string, coins int64, chainid string) (offline.Tx, error) {
+	return buildOffline(remote, from, chainid, offline.TypeUndelegate, func(cliCtx context.CLIContext, fromAddr types.AccAddress) (btxs.ITx, error) {
+		return stake_client.UnbondDelegationTx(cliCtx, fromAddr, addrto, coins)
+	})
+}
+
+//BuildOfflineReDelegation builds the unsigned redelegation of coins of from between the validators
+func BuildOfflineReDelegation(remote, from, fromValidatorAddr, toValidatorAddr string, coins int64, chainid string) (offline.Tx, error) {
+	return buildOffline(remote, from, chainid, offline.TypeRedelegate, func(cliCtx context.CLIContext, fromAddr types.AccAddress) (btxs.ITx, error) {
+		return stake_client.ReDelegationTx(cliCtx, fromAddr, fromValidatorAddr, toValidatorAddr, coins)
+	})
+}
+
+//SignOffline signs the unsigned QOS tx with s, without any node
+func SignOffline(otx offline.Tx, s signer.Signer) (offline.Tx, error) {
+	if err := otx.CheckUnsigned(offline.ChainQOS); err != nil {
+		return offline.Tx{}, err
+	}
+	from, err := tx.SignerAddress(s)
+	if err != nil {
+		return offline.Tx{}, err
+	}
+	if signerAddr, err := account.GetAddrFromValue(otx.Signer); err != nil || !signerAddr.Equals(from) {
+		return offline.Tx{}, errcode.Errorf(errcode.CodeInvalidInput, "the offline tx is signed by %s, not %s", otx.Signer, from)
+	}
+
+	var txStd btxs.TxStd
+	if err := app.Cdc.UnmarshalJSON(otx.Tx, &txStd); err != nil {
+		return offline.Tx{}, errcode.InvalidInput(err)
+	}
+	if len(txStd.Signature) != 0 {
+		return offline.Tx{}, errcode.New(errcode.CodeInvalidInput, "the offline tx is already signed")
+	}
+	nonce := int64(otx.Sequence)
+	signHash := fmt.Sprintf("%X", tmhash.Sum(txStd.BuildSignatureBytes(nonce, "")))
+	if err := otx.CheckSignHash(signHash); err != nil {
+		return offline.Tx{}, err
+	}
+	signed, err := tx.SignStdTx(s, nonce, &txStd, otx.ChainID, "")
+	if err != nil {
+		return offline.Tx{}, err
+	}
+	txBytes, err := app.Cdc.MarshalBinaryBare(signed)
+	if err != nil {
+		return offline.Tx{}, errcode.Internal(err)
+	}
+	otx.SignHash = signHash
+	otx.Signed = hex.EncodeToString(txBytes)
+	otx.Hash = fmt.Sprintf("%X", tmhash.Sum(txBytes))
+	return otx, nil
+}
+
+//BroadcastOffline broadcasts the signed QOS tx to remote in sync mode
+func BroadcastOffline(remote string, otx offline.Tx) (*ctypes.ResultBroadcastTx, error) {
+	if err := otx.CheckSigned(offline.ChainQOS); err != nil {
+		return nil, err
+	}
+	txBytes, err := hex.DecodeString(otx.Signed)
+	if err != nil {
+		return nil, errcode.InvalidInput(err)
+	}
+	if hash := fmt.Sprintf("%X", tmhash.Sum(txBytes)); hash != otx.Hash {
+		return nil, errcode.Errorf(errcode.CodeInvalidInput, "the signed tx has the hash %s, not %s", hash, otx.Hash)
+	}
+	return broadcastSync(context.NewCLIContext(remote).WithCodec(app.Cdc), txBytes)
+}
+
+//buildOffline builds the tx of build from the account from with its next nonce on remote
+func buildOffline(remote, from, chainid, txType string, build func(cliCtx context.CLIContext, fromAddr types.AccAddress) (btxs.ITx, error)) (offline.Tx, error) {
+	fromAddr, err := account.GetAddrFromValue(from)
+	if err != nil {
+		return offline.Tx{}, errcode.InvalidInput(err)
+	}
+	var cliCtx = context.NewCLIContext(remote).WithCodec(app.Cdc)
+	txStd, nonce, err := tx.BuildStdTx(cliCtx, fromAddr, chainid, func() (btxs.ITx, error) {
+		return build(cliCtx, fromAddr)
+	})
+	if err != nil {
+		return offline.Tx{}, nodeError(err)
+	}
+	bz, err := app.Cdc.MarshalJSON(txStd)
+	if err != nil {
+		return offline.Tx{}, errcode.Internal(err)
+	}
+	return offline.Tx{
+		Chain:    offline.ChainQOS,
+		ChainID:  chainid,
+		Type:     txType,
+		Signer:   fromAddr.String(),
+		Sequence: uint64(nonce),
+		Tx:       bz,
+		SignHash: fmt.Sprintf("%X", tmhash.Sum(txStd.BuildSignatureBytes(nonce, ""))),
+	}, nil
+}
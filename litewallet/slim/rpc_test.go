@@ -1,406 +1,555 @@
 package slim
 
 import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/QOSGroup/litewallet/litewallet/errcode"
+	"github.com/QOSGroup/litewallet/litewallet/offline"
+	"github.com/QOSGroup/litewallet/litewallet/rpctest/qos"
+	"github.com/QOSGroup/litewallet/litewallet/signer"
+	"github.com/QOSGroup/litewallet/litewallet/signer/signertest"
+	"github.com/QOSGroup/litewallet/litewallet/slim/app"
+	baccount "github.com/QOSGroup/litewallet/litewallet/slim/base/account"
+	btypes "github.com/QOSGroup/litewallet/litewallet/slim/base/types"
 	"github.com/QOSGroup/litewallet/litewallet/slim/module"
+	approvetypes "github.com/QOSGroup/litewallet/litewallet/slim/module/approve/types"
+	"github.com/QOSGroup/litewallet/litewallet/slim/module/stake/mapper"
+	staketypes "github.com/QOSGroup/litewallet/litewallet/slim/module/stake/types"
+	"github.com/QOSGroup/litewallet/litewallet/slim/tendermint/crypto/funcInlocal/ed25519local"
 	"github.com/QOSGroup/litewallet/litewallet/slim/txs"
-	"testing"
+	"github.com/QOSGroup/litewallet/litewallet/slim/types"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	abci "github.com/tendermint/tendermint/abci/types"
 )
 
+const chainId = "pre-2002"
+
 var (
-	remote  = "39.97.234.227:26657"
-	chainId = "pre-2002"
-	privKey = "1kXd3ZQ53dcCJzoHOK8bI6hY4j49lyfWIdqva3qsfWSMAixKeEWamOMIenvzTkiHOg4Io8DF+tGRQZur8xPQyA=="
-	addr    = "qosacc10z5uu2fqrfy5jl8rv5kwkm835lpu8ncadvgcea"
+	privKey, addr = testKey("sender")
+	_, addrTo     = testKey("receiver")
+
+	validatorAddr  = testValidator("validator-1")
+	validatorAddr2 = testValidator("validator-2")
 )
 
-func TestQueryAccount(t *testing.T) {
-	txs.SetBlockchainEntrance(remote, "forQmoonAddr")
-	//addr := "address1v26ael2jh0q7aetuk45yqf3jcyyywg2g6wq2tv"
-	bytes, err := QueryAccount(remote, addr)
-	if err != nil {
-		t.Log(err)
-		return
-	}
-	t.Log(string(bytes))
+//testKey returns the base64 private key and the address of a key derived from secret
+func testKey(secret string) (string, string) {
+	key := ed25519local.GenPrivKeyFromSecret([]byte(secret))
+	return base64.StdEncoding.EncodeToString(key[:]), btypes.AccAddress(key.PubKey().Address()).String()
 }
 
-//func TestQSCQueryAccountGet(t *testing.T) {
-//	txs.SetBlockchainEntrance("192.168.1.23:1317", "forQmoonAddr")
-//	addr := "address13l90zvt26szkrquutwpgj7kef58mgyntfs46l2"
-//	Aout := txs.QSCQueryAccountGet(addr)
-//	t.Log(Aout)
-//}
+func testValidator(secret string) btypes.ValAddress {
+	return btypes.ValAddress(ed25519local.GenPrivKeyFromSecret([]byte(secret)).PubKey().Address())
+}
 
-func TestTransfer(t *testing.T) {
-	txs.SetBlockchainEntrance(remote, "forQmoonAddr")
-	//addrto := "address1v26ael2jh0q7aetuk45yqf3jcyyywg2g6wq2tv"
-	coinstr := "10000qos"
-	//privkey := "xGZuHJYesaYlgNJi7yeugj9A6Sc34f6plx5on6DDTTCVRb5f7neBxIsLUHgO+13Og38maO2E4kz55kX+4obHWQ=="
-	Tout, err := Transfer(remote, addr, coinstr, privKey, chainId)
-	if err != nil {
-		t.Log(err)
+//newChain starts a chain where addr holds 100000qos and two validators are bonded
+func newChain(t *testing.T) *qos.Chain {
+	chain := qos.NewChain(chainId)
+	from, _ := btypes.AccAddressFromBech32(addr)
+	chain.SetAccount(from, 100000)
+	for i, valAddr := range []btypes.ValAddress{validatorAddr, validatorAddr2} {
+		consKey := ed25519local.GenPrivKeyFromSecret([]byte{byte(i)})
+		chain.SetValidator(staketypes.Validator{
+			OperatorAddress: valAddr,
+			Owner:           btypes.AccAddress(valAddr),
+			ConsPubKey:      consKey.PubKey(),
+			BondTokens:      btypes.NewInt(1000000),
+			Description:     staketypes.Description{Moniker: valAddr.String()},
+		})
 	}
-	t.Log(string(Tout))
+	txs.SetBlockchainEntrance(chain.Remote(), "forQmoonAddr")
+	return chain
 }
 
-//func TestQSCCreateSignedTransfer(t *testing.T) {
-//	txs.SetBlockchainEntrance("47.103.78.91:26657", "forQmoonAddr")
-//	addrto := "address13l90zvt26szkrquutwpgj7kef58mgyntfs46l2"
-//	coinstr := "10000qos"
-//	privkey := "xGZuHJYesaYlgNJi7yeugj9A6Sc34f6plx5on6DDTTCVRb5f7neBxIsLUHgO+13Og38maO2E4kz55kX+4obHWQ=="
-//	chainid := "qos-test"
-//	Tout, err := client.CreateSignedTransfer(addrto, coinstr, privkey, chainid)
-//	if err != nil {
-//		t.Log(err)
-//	}
-//	t.Log(Tout)
-//}
-
-//stake
-func TestDelegation(t *testing.T) {
-	txs.SetBlockchainEntrance(remote, "forQmoonAddr")
-	//validatorAddress := "address13l90zvt26szkrquutwpgj7kef58mgyntfs46l2"
-	validatorAddress := "qosval1e97fvkm8hh2836d7l0ga52dcdwasj4dxwtcm2q"
-	//coinstr := "10000qos"
-	coins := int64(1000)
-	//privkey := "xGZuHJYesaYlgNJi7yeugj9A6Sc34f6plx5on6DDTTCVRb5f7neBxIsLUHgO+13Og38maO2E4kz55kX+4obHWQ=="
-	Tout, err := Delegation(remote, validatorAddress, coins, privKey, chainId)
+func accAddress(t *testing.T, s string) btypes.AccAddress {
+	a, err := btypes.AccAddressFromBech32(s)
 	if err != nil {
-		t.Log(err)
-		return
+		t.Fatal(err)
 	}
-	t.Log(string(Tout))
+	return a
 }
 
-func TestUnbondDelegation(t *testing.T) {
-	txs.SetBlockchainEntrance(remote, "forQmoonAddr")
-	validatorAddress := "qosval1d880y3hmj62m4c9xx3spfkjm3lh32swrh9mffj"
-	//coinstr := "10000qos"
-	coins := int64(123)
-	//privkey := "xGZuHJYesaYlgNJi7yeugj9A6Sc34f6plx5on6DDTTCVRb5f7neBxIsLUHgO+13Og38maO2E4kz55kX+4obHWQ=="
-	Tout, err := UnbondDelegation(remote, validatorAddress, coins, privKey, chainId)
+func TestQueryAccount(t *testing.T) {
+	chain := newChain(t)
+	defer chain.Close()
+
+	bz, err := QueryAccount(chain.Remote(), addr)
 	if err != nil {
-		t.Log(err)
-		return
+		t.Fatal(err)
+	}
+	var acc *types.QOSAccount
+	if err := app.Cdc.UnmarshalJSON(bz, &acc); err != nil {
+		t.Fatal(err)
+	}
+	if acc.QOS.Int64() != 100000 || acc.Nonce != 0 {
+		t.Errorf("got %s qos nonce %d, want 100000 qos nonce 0", acc.QOS, acc.Nonce)
 	}
-	t.Log(string(Tout))
 }
 
-func TestRedelegations(t *testing.T) {
-	txs.SetBlockchainEntrance(remote, "forQmoonAddr")
-	formValidatorAddr := "qosval1d880y3hmj62m4c9xx3spfkjm3lh32swrh9mffj"
-	toValidatorAddr := "qosval1d880y3hmj62m4c9xx3spfkjm3lh32swrh9mffj"
-	coins := int64(500)
-	//privkey := "xGZuHJYesaYlgNJi7yeugj9A6Sc34f6plx5on6DDTTCVRb5f7neBxIsLUHgO+13Og38maO2E4kz55kX+4obHWQ=="
-	Tout, err := ReDelegation(remote, formValidatorAddr, toValidatorAddr, coins, privKey, chainId)
-	if err != nil {
-		t.Log(err)
-		return
+func TestQueryAccountNotExists(t *testing.T) {
+	chain := newChain(t)
+	defer chain.Close()
+
+	_, err := QueryAccount(chain.Remote(), addrTo)
+	if errcode.Code(err) != errcode.CodeKeyNotFound {
+		t.Errorf("got %v, want a key not found error", err)
 	}
-	t.Log(string(Tout))
 }
 
-func TestQueryValidatorInfo(t *testing.T) {
-	txs.SetBlockchainEntrance(remote, "forQmoonAddr")
-	validatorAddr := "address1tl0gdpdwjz2g77s7qcf0jvr4sxc0szw0nlk08t"
-	Tout, err := QueryValidatorInfo(remote, validatorAddr)
-	if err != nil {
-		t.Log(err)
-		return
+func TestQueryAccountNodeDown(t *testing.T) {
+	chain := newChain(t)
+	remote := chain.Remote()
+	chain.Close()
+
+	_, err := QueryAccount(remote, addr)
+	if errcode.Code(err) != errcode.CodeNetwork {
+		t.Errorf("got %v, want a network error", err)
 	}
-	t.Log(string(Tout))
 }
 
-func TestQueryValidators(t *testing.T) {
-	txs.SetBlockchainEntrance(remote, "forQmoonAddr")
-	Tout, err := QueryValidators(remote)
-	if err != nil {
-		t.Log(err)
-		return
+func TestQueryAccountForged(t *testing.T) {
+	chain := newChain(t)
+	defer chain.Close()
+
+	//the node answers with a richer account than the one in its state
+	from := accAddress(t, addr)
+	var forged baccount.Account = types.NewQOSAccount(from, btypes.NewInt(1000000000), nil)
+	chain.Forge(func(res *abci.ResponseQuery) {
+		if len(res.Value) > 0 {
+			res.Value = app.Cdc.MustMarshalBinaryBare(forged)
+		}
+	})
+	_, err := QueryAccount(chain.Remote(), addr)
+	if errcode.Code(err) != errcode.CodeUnverified {
+		t.Errorf("got %v, want an unverified error", err)
 	}
-	t.Log(string(Tout))
 }
 
-func TestQueryValidatorMissedVoteInfo(t *testing.T) {
-	txs.SetBlockchainEntrance(remote, "forQmoonAddr")
-	address := "address1tl0gdpdwjz2g77s7qcf0jvr4sxc0szw0nlk08t"
-	Tout, err := QueryValidatorMissedVoteInfo(remote, address)
-	if err != nil {
-		t.Log(err)
-		return
+func TestTransfer(t *testing.T) {
+	chain := newChain(t)
+	defer chain.Close()
+
+	for i := 0; i < 2; i++ {
+		res, err := SendTransfer(chain.Remote(), addrTo, "10000qos", privKey, chainId)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Code != 0 {
+			t.Fatalf("transfer %d rejected: %s", i, res.Log)
+		}
 	}
-	t.Log(string(Tout))
-}
 
-func TestQueryDelegationInfo(t *testing.T) {
-	txs.SetBlockchainEntrance(remote, "forQmoonAddr")
-	ownerAddr := "address1demk4rqhfc5ewlsefa2g805ycnzse26mr534nu"
-	delegatorAddr := "address1v26ael2jh0q7aetuk45yqf3jcyyywg2g6wq2tv"
-	Tout, err := QueryDelegationInfo(remote, ownerAddr, delegatorAddr)
-	if err != nil {
-		t.Log(err)
-		return
+	from := chain.Account(accAddress(t, addr))
+	if from.QOS.Int64() != 80000 || from.Nonce != 2 {
+		t.Errorf("sender has %s qos nonce %d, want 80000 qos nonce 2", from.QOS, from.Nonce)
+	}
+	if to := chain.Account(accAddress(t, addrTo)); to.QOS.Int64() != 20000 {
+		t.Errorf("receiver has %s qos, want 20000", to.QOS)
 	}
-	t.Log(string(Tout))
 }
 
-func TestQueryDelegations(t *testing.T) {
-	txs.SetBlockchainEntrance(remote, "forQmoonAddr")
-	Tout, err := QueryDelegations(remote, addr)
-	if err != nil {
-		t.Log(err)
-		return
+func TestTransferInARow(t *testing.T) {
+	chain := newChain(t)
+	defer chain.Close()
+	chain.Lag()
+
+	//the second tx is signed with the nonce of the state the first tx made, before a block proves it
+	for i := 0; i < 2; i++ {
+		res, err := SendTransferOnChain(chain.Remote(), addrTo, "10000qos", privKey, chainId)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Code != 0 {
+			t.Fatalf("transfer %d rejected: %s", i, res.Log)
+		}
+	}
+	if from := chain.Account(accAddress(t, addr)); from.Nonce != 2 {
+		t.Errorf("sender has nonce %d, want 2", from.Nonce)
+	}
+	//the queries read the state proved without waiting, the one before the latest block
+	acc, err := FetchAccount(chain.Remote(), addr)
+	if err != nil || acc.GetNonce() != 1 {
+		t.Errorf("got %v, %v, want the account of nonce 1", acc, err)
 	}
-	t.Log(string(Tout))
 }
 
-func TestQueryUnbondings(t *testing.T) {
-	txs.SetBlockchainEntrance(remote, "forQmoonAddr")
-	Tout, err := QueryUnbondings(remote, addr)
-	if err != nil {
-		t.Log(err)
-		return
+func TestFetchAccountOnChain(t *testing.T) {
+	chain := newChain(t)
+	defer chain.Close()
+
+	if acc, err := FetchAccountOnChain(chain.Remote(), chainId, addr); err != nil || acc.GetNonce() != 0 {
+		t.Errorf("got %v, %v, want the account of nonce 0", acc, err)
+	}
+	_, err := FetchAccountOnChain(chain.Remote(), "other-chain", addr)
+	if errcode.Code(err) != errcode.CodeUnverified {
+		t.Errorf("got %v from another chain, want an unverified error", err)
+	}
+	_, err = SendTransferOnChain(chain.Remote(), addrTo, "10qos", privKey, "other-chain")
+	if errcode.Code(err) != errcode.CodeUnverified {
+		t.Errorf("got %v sending to another chain, want an unverified error", err)
 	}
-	t.Log(string(Tout))
 }
 
-func TestQueryRedelegations(t *testing.T) {
-	txs.SetBlockchainEntrance(remote, "forQmoonAddr")
-	Tout, err := QueryRedelegations(remote, addr)
-	if err != nil {
-		t.Log(err)
-		return
+func TestTransferInsufficientFunds(t *testing.T) {
+	chain := newChain(t)
+	defer chain.Close()
+
+	_, err := Transfer(chain.Remote(), addrTo, "200000qos", privKey, chainId)
+	if errcode.Code(err) != errcode.CodeNodeRejected {
+		t.Errorf("got %v, want a node rejected error", err)
+	}
+	if to := chain.Account(accAddress(t, addrTo)); to != nil {
+		t.Errorf("receiver got %s qos from a rejected tx", to.QOS)
 	}
-	t.Log(string(Tout))
 }
 
-//approve
-func TestQueryApprove(t *testing.T) {
-	txs.SetBlockchainEntrance(remote, "forQmoonAddr")
-	//privkey := "xGZuHJYesaYlgNJi7yeugj9A6Sc34f6plx5on6DDTTCVRb5f7neBxIsLUHgO+13Og38maO2E4kz55kX+4obHWQ=="
-	Tout, err := QueryApprove(remote, addr, privKey)
-	if err != nil {
-		t.Log(err)
-		return
+func TestTransferWrongChain(t *testing.T) {
+	chain := newChain(t)
+	defer chain.Close()
+
+	_, err := Transfer(chain.Remote(), addrTo, "10qos", privKey, "other-chain")
+	if errcode.Code(err) != errcode.CodeNodeRejected {
+		t.Errorf("got %v, want a node rejected error", err)
 	}
-	t.Log(string(Tout))
 }
 
-//distribution
-func TestQueryDelegatorIncomeInfo(t *testing.T) {
-	txs.SetBlockchainEntrance(remote, "forQmoonAddr")
-	//privkey := "xGZuHJYesaYlgNJi7yeugj9A6Sc34f6plx5on6DDTTCVRb5f7neBxIsLUHgO+13Og38maO2E4kz55kX+4obHWQ=="
-	validatorAddr := "qosval1d880y3hmj62m4c9xx3spfkjm3lh32swrh9mffj"
-	Tout, err := QueryDelegatorIncomeInfo(remote, privKey, validatorAddr)
+//stake
+func TestDelegation(t *testing.T) {
+	chain := newChain(t)
+	defer chain.Close()
+
+	if _, err := Delegation(chain.Remote(), validatorAddr.String(), 1000, privKey, chainId); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Delegation(chain.Remote(), validatorAddr.String(), 500, privKey, chainId); err != nil {
+		t.Fatal(err)
+	}
+
+	bz, err := QueryDelegationInfo(chain.Remote(), validatorAddr.String(), addr)
 	if err != nil {
-		t.Log(err)
-		return
+		t.Fatal(err)
+	}
+	var delegation mapper.DelegationQueryResult
+	if err := app.Cdc.UnmarshalJSON(bz, &delegation); err != nil {
+		t.Fatal(err)
+	}
+	if delegation.Amount.Int64() != 1500 || delegation.ValidatorConsensusPubKey == "" {
+		t.Errorf("got delegation %+v, want 1500 with the consensus key", delegation)
+	}
+	if acc := chain.Account(accAddress(t, addr)); acc.QOS.Int64() != 98500 {
+		t.Errorf("delegator has %s qos, want 98500", acc.QOS)
 	}
-	t.Log(string(Tout))
 }
 
-func TestQueryCommunityFeePool(t *testing.T) {
-	txs.SetBlockchainEntrance(remote, "forQmoonAddr")
-	Tout, err := QueryCommunityFeePool(remote)
-	if err != nil {
-		t.Log(err)
-		return
+func TestDelegationUnknownValidator(t *testing.T) {
+	chain := newChain(t)
+	defer chain.Close()
+
+	_, err := Delegation(chain.Remote(), testValidator("unknown").String(), 1000, privKey, chainId)
+	if errcode.Code(err) != errcode.CodeNodeRejected {
+		t.Errorf("got %v, want a node rejected error", err)
 	}
-	t.Log(string(Tout))
 }
 
-func TestQueryProposal(t *testing.T) {
-	txs.SetBlockchainEntrance(remote, "forQmoonAddr")
-	Tout, err := QueryProposal(remote, 1)
+func TestUnbondDelegation(t *testing.T) {
+	chain := newChain(t)
+	defer chain.Close()
+
+	if _, err := Delegation(chain.Remote(), validatorAddr.String(), 1000, privKey, chainId); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := UnbondDelegation(chain.Remote(), validatorAddr.String(), 123, privKey, chainId); err != nil {
+		t.Fatal(err)
+	}
+	if amount := chain.Delegation(accAddress(t, addr), validatorAddr); amount != 877 {
+		t.Errorf("got delegation %d, want 877", amount)
+	}
+
+	bz, err := QueryUnbondings(chain.Remote(), addr)
 	if err != nil {
-		t.Log(err)
-		return
+		t.Fatal(err)
+	}
+	var unbondings []staketypes.UnbondingDelegationInfo
+	if err := app.Cdc.UnmarshalJSON(bz, &unbondings); err != nil {
+		t.Fatal(err)
+	}
+	if len(unbondings) != 1 || unbondings[0].Amount.Int64() != 123 {
+		t.Fatalf("got unbondings %+v, want one of 123", unbondings)
+	}
+	if u := unbondings[0]; u.CompleteHeight != u.Height+qos.UnbondPeriod {
+		t.Errorf("unbonding completes at %d, want %d", u.CompleteHeight, u.Height+qos.UnbondPeriod)
 	}
-	t.Log(string(Tout))
 }
 
-func TestQueryProposals(t *testing.T) {
-	txs.SetBlockchainEntrance(remote, "forQmoonAddr")
-	Tout, err := QueryProposals(remote, "", "", "deposit_period")
+func TestRedelegations(t *testing.T) {
+	chain := newChain(t)
+	defer chain.Close()
+
+	if _, err := Delegation(chain.Remote(), validatorAddr.String(), 1000, privKey, chainId); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReDelegation(chain.Remote(), validatorAddr.String(), validatorAddr2.String(), 500, privKey, chainId); err != nil {
+		t.Fatal(err)
+	}
+
+	bz, err := QueryRedelegations(chain.Remote(), addr)
 	if err != nil {
-		t.Log(err)
-		return
+		t.Fatal(err)
+	}
+	var redelegations []staketypes.RedelegationInfo
+	if err := app.Cdc.UnmarshalJSON(bz, &redelegations); err != nil {
+		t.Fatal(err)
+	}
+	if len(redelegations) != 1 || redelegations[0].Amount.Int64() != 500 || !redelegations[0].ToValidator.Equals(validatorAddr2) {
+		t.Errorf("got redelegations %+v, want 500 to %s", redelegations, validatorAddr2)
 	}
-	t.Log(string(Tout))
 }
 
-func TestQueryVote(t *testing.T) {
-	txs.SetBlockchainEntrance(remote, "forQmoonAddr")
-	Tout, err := QueryVote(remote, 1, "")
-	if err != nil {
-		t.Log(err)
-		return
+func TestTxsWithSigner(t *testing.T) {
+	chain := newChain(t)
+	defer chain.Close()
+
+	//the key of sender held by a device
+	device := signertest.NewEd25519(ed25519local.GenPrivKeyFromSecret([]byte("sender")))
+	s := device.Signer()
+	if _, err := SendTransferWithSigner(chain.Remote(), addrTo, "10000qos", s, chainId); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := SendDelegationWithSigner(chain.Remote(), validatorAddr.String(), 1000, s, chainId); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := SendReDelegationWithSigner(chain.Remote(), validatorAddr.String(), validatorAddr2.String(), 400, s, chainId); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := SendUnbondDelegationWithSigner(chain.Remote(), validatorAddr.String(), 100, s, chainId); err != nil {
+		t.Fatal(err)
+	}
+	if device.Signs() != 4 {
+		t.Errorf("got %d signatures of the device, want 4", device.Signs())
+	}
+	if to := chain.Account(accAddress(t, addrTo)); to.QOS.Int64() != 10000 {
+		t.Errorf("receiver has %s qos, want 10000", to.QOS)
+	}
+	if amount := chain.Delegation(accAddress(t, addr), validatorAddr); amount != 500 {
+		t.Errorf("got delegation %d, want 500", amount)
+	}
+
+	device.Refuse(errors.New("cancelled"))
+	if _, err := SendTransferWithSigner(chain.Remote(), addrTo, "10qos", s, chainId); err == nil {
+		t.Error("want the error of the device")
+	}
+	key, _ := ethcrypto.GenerateKey()
+	if _, err := SendTransferWithSigner(chain.Remote(), addrTo, "10qos", signer.NewSecp256k1(key), chainId); errcode.Code(err) != errcode.CodeInvalidInput {
+		t.Errorf("got %v for a secp256k1 signer, want an invalid input error", err)
 	}
-	t.Log(string(Tout))
 }
 
-func TestQueryVotes(t *testing.T) {
-	txs.SetBlockchainEntrance(remote, "forQmoonAddr")
-	Tout, err := QueryVotes(remote, 1)
+func TestOffline(t *testing.T) {
+	chain := newChain(t)
+	defer chain.Close()
+
+	device := signertest.NewEd25519(ed25519local.GenPrivKeyFromSecret([]byte("sender"))).Signer()
+	remote := chain.Remote()
+	//build online -> sign offline -> broadcast, one tx after the other as each build takes the next nonce
+	builds := []func() (offline.Tx, error){
+		func() (offline.Tx, error) { return BuildOfflineTransfer(remote, addr, addrTo, "10000qos", chainId) },
+		func() (offline.Tx, error) {
+			return BuildOfflineDelegation(remote, addr, validatorAddr.String(), 1000, chainId)
+		},
+		func() (offline.Tx, error) {
+			return BuildOfflineReDelegation(remote, addr, validatorAddr.String(), validatorAddr2.String(), 400, chainId)
+		},
+		func() (offline.Tx, error) {
+			return BuildOfflineUnbondDelegation(remote, addr, validatorAddr.String(), 100, chainId)
+		},
+	}
+	for i, build := range builds {
+		unsigned, err := build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		doc, err := offline.Parse(unsigned.String())
+		if err != nil || doc.Sequence != uint64(i+1) || doc.Signer != addr {
+			t.Fatalf("got %+v, %v, want the unsigned tx of %s with the nonce %d", doc, err, addr, i+1)
+		}
+		signed, err := SignOffline(doc, device)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res, err := BroadcastOffline(remote, signed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Hash.String() != signed.Hash {
+			t.Errorf("got the hash %s of the node, want %s", res.Hash, signed.Hash)
+		}
+	}
+	if to := chain.Account(accAddress(t, addrTo)); to.QOS.Int64() != 10000 {
+		t.Errorf("receiver has %s qos, want 10000", to.QOS)
+	}
+	if amount := chain.Delegation(accAddress(t, addr), validatorAddr); amount != 500 {
+		t.Errorf("got delegation %d, want 500", amount)
+	}
+
+	unsigned, err := BuildOfflineTransfer(remote, addr, addrTo, "10qos", chainId)
 	if err != nil {
-		t.Log(err)
-		return
+		t.Fatal(err)
+	}
+	other := signertest.NewEd25519(ed25519local.GenPrivKeyFromSecret([]byte("receiver"))).Signer()
+	if _, err := SignOffline(unsigned, other); errcode.Code(err) != errcode.CodeInvalidInput {
+		t.Errorf("got %v for another key, want an invalid input error", err)
+	}
+	if _, err := BroadcastOffline(remote, unsigned); errcode.Code(err) != errcode.CodeInvalidInput {
+		t.Errorf("got %v broadcasting the unsigned tx, want an invalid input error", err)
+	}
+	unsigned.ChainID = "other"
+	if _, err := SignOffline(unsigned, device); errcode.Code(err) != errcode.CodeInvalidInput {
+		t.Errorf("got %v for a modified tx, want an invalid input error", err)
 	}
-	t.Log(string(Tout))
 }
 
-func TestQueryDeposit(t *testing.T) {
-	txs.SetBlockchainEntrance(remote, "forQmoonAddr")
-	Tout, err := QueryDeposit(remote, 1, "")
+func TestQueryValidatorInfo(t *testing.T) {
+	chain := newChain(t)
+	defer chain.Close()
+
+	bz, err := QueryValidatorInfo(chain.Remote(), validatorAddr.String())
 	if err != nil {
-		t.Log(err)
-		return
+		t.Fatal(err)
+	}
+	var validator staketypes.Validator
+	if err := app.Cdc.UnmarshalJSON(bz, &validator); err != nil {
+		t.Fatal(err)
+	}
+	if !validator.OperatorAddress.Equals(validatorAddr) || validator.BondTokens.Int64() != 1000000 {
+		t.Errorf("got validator %+v", validator)
 	}
-	t.Log(string(Tout))
 }
 
-func TestQueryDeposits(t *testing.T) {
-	txs.SetBlockchainEntrance(remote, "forQmoonAddr")
-	Tout, err := QueryDeposits(remote, 1)
+func TestQueryValidators(t *testing.T) {
+	chain := newChain(t)
+	defer chain.Close()
+
+	bz, err := QueryValidators(chain.Remote())
 	if err != nil {
-		t.Log(err)
-		return
+		t.Fatal(err)
+	}
+	var validators []json.RawMessage
+	if err := json.Unmarshal(bz, &validators); err != nil {
+		t.Fatal(err)
+	}
+	if len(validators) != 2 {
+		t.Errorf("got %d validators, want 2", len(validators))
 	}
-	t.Log(string(Tout))
 }
 
-func TestQueryTally(t *testing.T) {
-	txs.SetBlockchainEntrance(remote, "forQmoonAddr")
-	Tout, err := QueryTally(remote, 1, "")
+func TestQueryDelegations(t *testing.T) {
+	chain := newChain(t)
+	defer chain.Close()
+
+	for _, valAddr := range []btypes.ValAddress{validatorAddr, validatorAddr2} {
+		if _, err := Delegation(chain.Remote(), valAddr.String(), 100, privKey, chainId); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	bz, err := QueryDelegations(chain.Remote(), addr)
 	if err != nil {
-		t.Log(err)
-		return
+		t.Fatal(err)
+	}
+	var delegations []mapper.DelegationQueryResult
+	if err := app.Cdc.UnmarshalJSON(bz, &delegations); err != nil {
+		t.Fatal(err)
+	}
+	if len(delegations) != 2 {
+		t.Errorf("got %d delegations, want 2", len(delegations))
 	}
-	t.Log(string(Tout))
 }
 
-func TestQueryTx(t *testing.T) {
-	txs.SetBlockchainEntrance(remote, "forQmoonAddr")
-	hashHex := "B5EECB27939D969556C61E00BDE8C910FFE3BE47BFA0356B57F58847D6502B70"
-	Tout, err := QueryTx(remote, hashHex)
+//approve
+func TestQueryApprove(t *testing.T) {
+	chain := newChain(t)
+	defer chain.Close()
+
+	if _, err := QueryApprove(chain.Remote(), addrTo, privKey); err == nil {
+		t.Fatal("got an approve before it is created")
+	}
+
+	chain.SetApprove(approvetypes.NewApprove(accAddress(t, addr), accAddress(t, addrTo), btypes.NewInt(300), nil))
+	bz, err := QueryApprove(chain.Remote(), addrTo, privKey)
 	if err != nil {
-		t.Log(err)
-		return
+		t.Fatal(err)
+	}
+	var approve approvetypes.Approve
+	if err := app.Cdc.UnmarshalJSON(bz, &approve); err != nil {
+		t.Fatal(err)
+	}
+	if approve.QOS.Int64() != 300 {
+		t.Errorf("got approve of %s qos, want 300", approve.QOS)
 	}
-	t.Log(string(Tout))
 }
 
-//func TestTransferTxToQSC(t *testing.T) {
-//	t.Log("1111111111111111")
-//
-//	txs.SetBlockchainEntrance("47.105.52.237:26657", "forQmoonAddr")
-//	t.Log("2222222222222222")
-//
-//	privkey := "wnEmxnWFgT93M5a9l7aPTdkxM8MLoenyMe60sD/8rqzslA7MvfoHydXqL4QGbplLhIlEbLAZ/0ue9G1rjBFMfQ=="
-//	chainid := "test-chain-xHEkEv"
-//	Tout := client.AdvertisersTrue(privkey, "ATOM", "100000", chainid)
-//	t.Log(Tout)
-//
-//	result := txs.BroadcastTransferTxToQSC(Tout, "sync")
-//	t.Log(result)
-//}
-
-//func TestQueryAccount(t *testing.T) {
-//	t.Log("1111111111111111")
-//
-//	txs.SetBlockchainEntrance("47.105.52.237:26657", "forQmoonAddr")
-//	t.Log("2222222222222222")
-//
-//	privkey := "j7wbT1lfG0ZRObMKwyYkVFpwRW0OlzFcgVL6ZwQJjeicPcStxppyWMpD1tfFg2YEcDu5JH+M6g+EhSBmjHq0bg=="
-//
-//	var key ed25519local.PrivKeyEd25519
-//	ts := "{\"type\": \"tendermint/PrivKeyEd25519\",\"value\": \"" + privkey + "\"}"
-//	err1 := txs.Cdc.UnmarshalJSON([]byte(ts), &key)
-//	if err1 != nil {
-//		fmt.Println(err1)
-//	}
-//
-//	addrben32, _ := bech32local.ConvertAndEncode(PREF_ADD, key.PubKey().Address().Bytes())
-//	address, _ := types.GetAddrFromBech32(addrben32)
-//
-//	fmt.Println("address", string(address))
-//
-//	account, err := txs.RpcQueryAccount(address)
-//
-//	if err != nil {
-//		t.Error(err.Error())
-//	} else {
-//		t.Log("111", account.Nonce)
-//	}
-//}
+//the chain keeps no vote, distribution or governance state, these queries report the missing data
+func TestQueryUnsupported(t *testing.T) {
+	chain := newChain(t)
+	defer chain.Close()
+
+	remote := chain.Remote()
+	queries := map[string]func() ([]byte, error){
+		"QueryValidatorMissedVoteInfo": func() ([]byte, error) { return QueryValidatorMissedVoteInfo(remote, validatorAddr.String()) },
+		"QueryDelegatorIncomeInfo":     func() ([]byte, error) { return QueryDelegatorIncomeInfo(remote, privKey, validatorAddr.String()) },
+		"QueryCommunityFeePool":        func() ([]byte, error) { return QueryCommunityFeePool(remote) },
+		"QueryProposal":                func() ([]byte, error) { return QueryProposal(remote, 1) },
+		"QueryProposals":               func() ([]byte, error) { return QueryProposals(remote, "", "", "deposit_period") },
+		"QueryVote":                    func() ([]byte, error) { return QueryVote(remote, 1, addr) },
+		"QueryVotes":                   func() ([]byte, error) { return QueryVotes(remote, 1) },
+		"QueryDeposit":                 func() ([]byte, error) { return QueryDeposit(remote, 1, addr) },
+		"QueryDeposits":                func() ([]byte, error) { return QueryDeposits(remote, 1) },
+		"QueryTally":                   func() ([]byte, error) { return QueryTally(remote, 1, addr) },
+	}
+	for name, query := range queries {
+		if bz, err := query(); err == nil {
+			t.Errorf("%s: got %s, want an error", name, bz)
+		}
+	}
+}
 
-func TestCommHandler(t *testing.T) {
-	t.Log("1111111111111111")
+func TestQueryTx(t *testing.T) {
+	chain := newChain(t)
+	defer chain.Close()
 
-	txs.SetBlockchainEntrance("localhost:26657", "forQmoonAddr")
-	t.Log("2222222222222222")
+	res, err := SendTransfer(chain.Remote(), addrTo, "10qos", privKey, chainId)
+	if err != nil {
+		t.Fatal(err)
+	}
 
-	//privkey := "9QkouVPl29N2v1lBO1+azUDqm38fAgs6d3Xo8DcnCus7xjMqsavhc190xCGzZuXcjapUahi7Y7v2DD4hzVCAsQ=="
-	chainid := "test-chain-dpYlL3"
+	txResponse, err := FetchTx(chain.Remote(), res.Hash.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if txResponse.Height != chain.Height() || txResponse.Code != 0 {
+		t.Errorf("got tx at height %d code %d, want height %d code 0", txResponse.Height, txResponse.Code, chain.Height())
+	}
 
-	args := "[\"address1y9r4pjjnvkmpvw46de8tmwunw4nx4qnz2ax5ux\",\"0\",\"abcde\",\"20\",\"20\",\"10\",\"50\",\"20\",\"3\",\"ATOM\"]"
+	_, err = FetchTx(chain.Remote(), hex.EncodeToString(make([]byte, 32)))
+	if err == nil {
+		t.Error("got a tx for an unknown hash")
+	}
+}
 
-	Tout := module.CommHandler("ArticleTx", privKey, args, chainid)
-	t.Log(Tout)
+func TestCommHandler(t *testing.T) {
+	chain := newChain(t)
+	defer chain.Close()
 
-	result := txs.BroadcastTransferTxToQSC(Tout, "sync")
-	t.Log(result)
+	args := []string{addrTo, "0", "abcde", "20", "20", "10", "50", "20", "3", "ATOM"}
+	tx, err := module.CommHandlerTx("ArticleTx", privKey, args, chainId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tx.Signature) != 1 || tx.Signature[0].Nonce != 1 {
+		t.Errorf("got signatures %+v, want one with nonce 1", tx.Signature)
+	}
 }
-
-//func TestLocalTxGen(t *testing.T) {
-//	fromStr := "address1vpszt2jp2j8m5l3mutvqserzuu9uylmzydqaj9"
-//
-//	toStr := "address1eep59h9ez4thymept8nxl0padlrc6r78fsjmp3"
-//
-//	coinstr := "2qos"
-//	//generate singed Tx
-//	chainid := "capricorn-2000"
-//	nonce := int64(1)
-//	//gas := NewBigInt(int64(0))
-//
-//	//PrivKey output
-//	//privkey := "sV5sRbwnR8DddL5e4UC1ntKPiOtGEaOFAqvePTfhJFI9GcC28zmPURSUI6C1oBlnk2ykBcAtIbYUazuCexWyqg=="
-//
-//	jasonpayload := LocalTxGen(fromStr, toStr, coinstr, chainid, privKey, nonce)
-//
-//	t.Log("msg\n", string(jasonpayload))
-//}
-
-////HTTP POST to QOS chain
-//func TestHttpBrToChain(t *testing.T) {
-//	fromStr := "address1vpszt2jp2j8m5l3mutvqserzuu9uylmzydqaj9"
-//	toStr := "address1eep59h9ez4thymept8nxl0padlrc6r78fsjmp3"
-//	coinstr := "2qos"
-//	//generate singed Tx
-//	chainid := "capricorn-1000"
-//	nonce := int64(1)
-//	//gas := NewBigInt(int64(0))
-//	//PrivKey output
-//	privkey := "sV5sRbwnR8DddL5e4UC1ntKPiOtGEaOFAqvePTfhJFI9GcC28zmPURSUI6C1oBlnk2ykBcAtIbYUazuCexWyqg=="
-//
-//	jasonpayload := LocalTxGen(fromStr, toStr, coinstr, chainid, privkey, nonce)
-//
-//	//tbt := new(types.Tx)
-//	//err := Cdc.UnmarshalJSON(jasonpayload, tbt)
-//	//if err != nil {
-//	//	fmt.Println(err)
-//	//}
-//	//
-//	//txBytes, err := Cdc.MarshalBinaryBare(jasonpayload)
-//	//if err != nil {
-//	//	panic("use cdc encode object fail")
-//	//}
-//
-//	client := rpc_client.NewHTTP("tcp://192.168.1.183:26657", "/websocket")
-//	result, err := client.BroadcastTxCommit(jasonpayload)
-//	if err != nil {
-//		fmt.Println(err)
-//	}
-//
-//	t.Log(result)
-//}
@@ -0,0 +1,186 @@
+package bip39local
+
+import (
+	"sort"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+//The assistance of the recovery screens: the completions of the typed prefix of a word, and the diagnosis of
+//a whole mnemonic telling the unknown words from a wrong checksum, with the replacements of a single wrong
+//word that give a valid checksum.
+
+const (
+	//MaxReplacements is the number of replacements a diagnosis suggests at most
+	MaxReplacements = 10
+
+	//maxTypoDistance is the edit distance of the words suggested for a word of the list mistyped as another one
+	maxTypoDistance = 2
+)
+
+//Complete returns the words of the list starting with prefix, at most limit of them in the order of the list,
+//in the composed form the users type. An empty prefix completes to nothing.
+func (l *Language) Complete(prefix string, limit int) []string {
+	prefix = norm.NFKD.String(strings.TrimSpace(prefix))
+	if prefix == "" || limit <= 0 {
+		return nil
+	}
+	var out []string
+	for _, word := range l.words {
+		if strings.HasPrefix(word, prefix) {
+			out = append(out, norm.NFC.String(word))
+			if len(out) == limit {
+				break
+			}
+		}
+	}
+	return out
+}
+
+//Replacement is a word which makes the checksum of a mnemonic valid in place of the word at Position
+type Replacement struct {
+	Position int    `json:"position"`
+	Word     string `json:"word"`
+}
+
+//Diagnosis tells what is wrong with a mnemonic, the positions count from 0
+type Diagnosis struct {
+	Language string `json:"language"`
+	Words    int    `json:"words"`
+	//WordCountValid is false unless the mnemonic has 12, 15, 18, 21 or 24 words
+	WordCountValid bool `json:"word_count_valid"`
+	//InvalidWords are the positions of the words missing from the list of Language
+	InvalidWords []int `json:"invalid_words"`
+	//ChecksumValid is only true when all the words are in the list and their checksum is right
+	ChecksumValid bool `json:"checksum_valid"`
+	Valid         bool `json:"valid"`
+	//Replacements fix a single wrong word: the unknown word, or, when all the words are in the list and the
+	//checksum is wrong, the words close to one of them. The closest come first.
+	Replacements []Replacement `json:"replacements,omitempty"`
+}
+
+//Diagnose checks mnemonic in language, or, when language is empty, in the language holding most of its words
+func Diagnose(mnemonic, language string) (*Diagnosis, error) {
+	words := SplitMnemonic(mnemonic)
+	l := closestLanguage(words)
+	if language != "" {
+		var err error
+		if l, err = GetLanguage(language); err != nil {
+			return nil, err
+		}
+	}
+
+	d := &Diagnosis{Language: l.Name, Words: len(words), InvalidWords: []int{}}
+	d.WordCountValid = validateWordCount(len(words)) == nil
+	for i, word := range words {
+		if _, ok := l.index[word]; !ok {
+			d.InvalidWords = append(d.InvalidWords, i)
+		}
+	}
+	if !d.WordCountValid {
+		return d, nil
+	}
+	switch len(d.InvalidWords) {
+	case 0:
+		_, err := l.entropy(words)
+		d.ChecksumValid = err == nil
+		d.Valid = d.ChecksumValid
+		if !d.Valid {
+			for i := range words {
+				d.Replacements = append(d.Replacements, l.replacements(words, i, maxTypoDistance)...)
+			}
+		}
+	case 1:
+		d.Replacements = l.replacements(words, d.InvalidWords[0], -1)
+	}
+	sortReplacements(d.Replacements, words)
+	if len(d.Replacements) > MaxReplacements {
+		d.Replacements = d.Replacements[:MaxReplacements]
+	}
+	return d, nil
+}
+
+//replacements returns the words of the list which make the checksum valid at position, within maxDistance of
+//the word there unless maxDistance is negative
+func (l *Language) replacements(words []string, position int, maxDistance int) []Replacement {
+	typed := words[position]
+	candidate := append([]string(nil), words...)
+	var out []Replacement
+	for _, word := range l.words {
+		if word == typed || maxDistance >= 0 && editDistance(word, typed) > maxDistance {
+			continue
+		}
+		candidate[position] = word
+		if _, err := l.entropy(candidate); err == nil {
+			out = append(out, Replacement{Position: position, Word: norm.NFC.String(word)})
+		}
+	}
+	return out
+}
+
+//sortReplacements puts first the completions of the typed word, then the words closest to it
+func sortReplacements(rs []Replacement, words []string) {
+	rank := func(r Replacement) int {
+		typed, word := words[r.Position], norm.NFKD.String(r.Word)
+		if strings.HasPrefix(word, typed) {
+			return 0
+		}
+		return editDistance(word, typed)
+	}
+	sort.SliceStable(rs, func(i, j int) bool {
+		return rank(rs[i]) < rank(rs[j])
+	})
+}
+
+//closestLanguage returns the language holding most of the words, the first one in the order of detection on
+//a tie, English when none holds any
+func closestLanguage(words []string) *Language {
+	languagesMu.RLock()
+	defer languagesMu.RUnlock()
+	best, bestCount := languages[English], 0
+	for _, name := range languageOrder {
+		l, count := languages[name], 0
+		for _, word := range words {
+			if _, ok := l.index[word]; ok {
+				count++
+			}
+		}
+		if count > bestCount {
+			best, bestCount = l, count
+		}
+	}
+	return best
+}
+
+//editDistance is the Levenshtein distance of the runes of a and b
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
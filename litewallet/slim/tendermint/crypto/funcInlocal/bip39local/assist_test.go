@@ -0,0 +1,72 @@
+package bip39local
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestComplete(t *testing.T) {
+	english, _ := GetLanguage(English)
+	if got := english.Complete("aban", 5); !reflect.DeepEqual(got, []string{"abandon"}) {
+		t.Errorf("got %v", got)
+	}
+	if got := english.Complete("ab", 3); !reflect.DeepEqual(got, []string{"abandon", "ability", "able"}) {
+		t.Errorf("got %v", got)
+	}
+	if got := english.Complete("", 3); len(got) != 0 {
+		t.Errorf("got %v for an empty prefix", got)
+	}
+	//the accents are matched in any form and the words returned composed
+	spanish, _ := GetLanguage(Spanish)
+	if got := spanish.Complete("áb", 1); !reflect.DeepEqual(got, []string{"ábaco"}) {
+		t.Errorf("got %v", got)
+	}
+}
+
+func TestDiagnose(t *testing.T) {
+	valid := "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+	d, err := Diagnose(valid, "")
+	if err != nil || !d.Valid || d.Language != English || len(d.InvalidWords) != 0 || len(d.Replacements) != 0 {
+		t.Fatalf("got %+v, %v, want a valid mnemonic", d, err)
+	}
+
+	//a mistyped word is located and the replacements with a valid checksum start with the completions
+	d, _ = Diagnose(strings.Replace(valid, "about", "abou", 1), "")
+	if d.Valid || d.ChecksumValid || !reflect.DeepEqual(d.InvalidWords, []int{11}) {
+		t.Fatalf("got %+v, want the word 11 invalid", d)
+	}
+	if len(d.Replacements) == 0 || d.Replacements[0] != (Replacement{Position: 11, Word: "about"}) {
+		t.Errorf("got %+v, want about first", d.Replacements)
+	}
+	for _, r := range d.Replacements {
+		if !IsMnemonicValid(strings.Replace(valid, "about", r.Word, 1)) {
+			t.Errorf("the replacement %s is not valid", r.Word)
+		}
+	}
+
+	//a word of the list in place of another one breaks the checksum
+	d, _ = Diagnose(strings.Replace(valid, "about", "above", 1), English)
+	if d.Valid || d.ChecksumValid || len(d.InvalidWords) != 0 {
+		t.Fatalf("got %+v, want a checksum error", d)
+	}
+	found := false
+	for _, r := range d.Replacements {
+		found = found || r == Replacement{Position: 11, Word: "about"}
+	}
+	if !found {
+		t.Errorf("got %+v, want about among the replacements", d.Replacements)
+	}
+
+	d, _ = Diagnose("abandon abandn abandon abandon abandon abandon abandon abandon abandon abandon abandon abut", "")
+	if !reflect.DeepEqual(d.InvalidWords, []int{1, 11}) || len(d.Replacements) != 0 {
+		t.Errorf("got %+v, want two invalid words and no replacement", d)
+	}
+	d, _ = Diagnose("abandon abandon", "")
+	if d.WordCountValid || d.Valid {
+		t.Errorf("got %+v, want a wrong word count", d)
+	}
+	if _, err := Diagnose(valid, "klingon"); err == nil {
+		t.Error("want an error for an unknown language")
+	}
+}
@@ -1,7 +1,6 @@
 package bip39local
 
 import (
-	"crypto/rand"
 	"crypto/sha256"
 	"crypto/sha512"
 	"encoding/binary"
@@ -29,9 +28,7 @@ func NewEntropy(bitSize int) ([]byte, error) {
 		return nil, err
 	}
 
-	entropy := make([]byte, bitSize/8)
-	_, err = rand.Read(entropy)
-	return entropy, err
+	return SystemEntropy.Entropy(bitSize / 8)
 }
 
 // NewMnemonic will return a string consisting of the mnemonic words for
@@ -125,36 +122,20 @@ func padByteSlice(slice []byte, length int) []byte {
 
 // IsMnemonicValid attempts to verify that the provided mnemonic is valid.
 // Validity is determined by both the number of words being appropriate,
-// and that all the words in the mnemonic are present in the word list.
+// and that all the words in the mnemonic are present in the word list
+// of one of the registered languages. The checksum is not checked, see
+// MnemonicToEntropy.
 func IsMnemonicValid(mnemonic string) bool {
 	// Create a list of all the words in the mnemonic sentence
-	words := strings.Fields(mnemonic)
-
-	// Get word count
-	wordCount := len(words)
+	words := SplitMnemonic(mnemonic)
 
 	// The number of words should be 12, 15, 18, 21 or 24
-	if wordCount%3 != 0 || wordCount < 12 || wordCount > 24 {
+	if validateWordCount(len(words)) != nil {
 		return false
 	}
 
-	// Check if all words belong in the wordlist
-	for i := 0; i < wordCount; i++ {
-		if !contains(WordList, words[i]) {
-			return false
-		}
-	}
-
-	return true
-}
-
-func contains(s []string, e string) bool {
-	for _, a := range s {
-		if a == e {
-			return true
-		}
-	}
-	return false
+	// Check if all words belong in one wordlist
+	return len(candidateLanguages(words)) > 0
 }
 
 // Language-specific wordlists
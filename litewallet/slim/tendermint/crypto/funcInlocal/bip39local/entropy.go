@@ -0,0 +1,127 @@
+package bip39local
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+//The entropy of a new mnemonic comes from an EntropySource. The mobile apps mix the output of the secure RNG
+//of the platform or the dice rolls of the user into the system entropy with Mix, the result is as good as the
+//best of its sources. The tests give the fixed bytes of BytesEntropy.
+
+//EntropySource supplies the entropy of the new mnemonics
+type EntropySource interface {
+	//Entropy returns n bytes of entropy
+	Entropy(n int) ([]byte, error)
+}
+
+//EntropyFunc is a function supplying entropy
+type EntropyFunc func(n int) ([]byte, error)
+
+//Entropy calls f
+func (f EntropyFunc) Entropy(n int) ([]byte, error) {
+	return f(n)
+}
+
+//SystemEntropy reads the entropy of crypto/rand, the source of the mnemonics when none is given
+var SystemEntropy EntropySource = EntropyFunc(func(n int) ([]byte, error) {
+	entropy := make([]byte, n)
+	if _, err := rand.Read(entropy); err != nil {
+		return nil, err
+	}
+	return entropy, nil
+})
+
+//BytesEntropy returns the first bytes of b, the entropy of the tests or of an external generator
+func BytesEntropy(b []byte) EntropySource {
+	return EntropyFunc(func(n int) ([]byte, error) {
+		if len(b) < n {
+			return nil, fmt.Errorf("%d bytes of entropy are given, %d are needed", len(b), n)
+		}
+		return append([]byte(nil), b[:n]...), nil
+	})
+}
+
+//DiceEntropy returns the entropy of rolls of a six-sided die, written as the digits 1 to 6 with or without
+//spaces. There have to be enough rolls for the entropy asked, 50 for 128 bits and 99 for 256 bits.
+func DiceEntropy(rolls string) EntropySource {
+	return EntropyFunc(func(n int) ([]byte, error) {
+		digits := strings.Join(strings.Fields(rolls), "")
+		for _, r := range digits {
+			if r < '1' || r > '6' {
+				return nil, fmt.Errorf("the dice roll %q is not 1 to 6", r)
+			}
+		}
+		if need := int(math.Ceil(float64(n*8) / math.Log2(6))); len(digits) < need {
+			return nil, fmt.Errorf("%d dice rolls are given, %d are needed", len(digits), need)
+		}
+		return extract("dice", [][]byte{[]byte(digits)}, n)
+	})
+}
+
+//Mix returns a source hashing together the entropy of all the sources, none of them has to be trusted alone
+func Mix(sources ...EntropySource) EntropySource {
+	return EntropyFunc(func(n int) ([]byte, error) {
+		if len(sources) == 0 {
+			return nil, errors.New("no entropy source to mix")
+		}
+		parts := make([][]byte, len(sources))
+		for i, source := range sources {
+			part, err := source.Entropy(n)
+			if err != nil {
+				return nil, err
+			}
+			if len(part) != n {
+				return nil, fmt.Errorf("the entropy source %d returned %d bytes, not %d", i, len(part), n)
+			}
+			parts[i] = part
+		}
+		return extract("mix", parts, n)
+	})
+}
+
+//extract hashes the length prefixed parts into n bytes, n is at most the 32 bytes of a 24-word mnemonic
+func extract(tag string, parts [][]byte, n int) ([]byte, error) {
+	if n > sha256.Size {
+		return nil, fmt.Errorf("%d bytes of entropy are more than a mnemonic takes", n)
+	}
+	h := sha256.New()
+	h.Write([]byte("bip39local entropy " + tag))
+	for _, part := range parts {
+		h.Write([]byte{byte(len(part) >> 8), byte(len(part))})
+		h.Write(part)
+	}
+	return h.Sum(nil)[:n], nil
+}
+
+//EntropyBits returns the bits of entropy of a mnemonic of words words: 128 for 12 words up to 256 for 24
+func EntropyBits(words int) (int, error) {
+	if err := validateWordCount(words); err != nil {
+		return 0, err
+	}
+	return words * 32 / 3, nil
+}
+
+//GenerateMnemonic returns a new mnemonic of words words in language with the entropy of source, the system
+//entropy when source is nil
+func GenerateMnemonic(words int, language string, source EntropySource) (string, error) {
+	bits, err := EntropyBits(words)
+	if err != nil {
+		return "", err
+	}
+	if source == nil {
+		source = SystemEntropy
+	}
+	entropy, err := source.Entropy(bits / 8)
+	if err != nil {
+		return "", err
+	}
+	if len(entropy) != bits/8 {
+		return "", fmt.Errorf("the entropy source returned %d bytes, not %d", len(entropy), bits/8)
+	}
+	return NewMnemonicIn(entropy, language)
+}
@@ -0,0 +1,64 @@
+package bip39local
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestGenerateMnemonic(t *testing.T) {
+	fixed := bytes.Repeat([]byte{0x7f}, 32)
+	for _, words := range []int{12, 15, 18, 21, 24} {
+		mnemonic, err := GenerateMnemonic(words, English, BytesEntropy(fixed))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := len(SplitMnemonic(mnemonic)); got != words {
+			t.Errorf("got %d words, want %d", got, words)
+		}
+		entropy, _, err := MnemonicToEntropy(mnemonic)
+		if err != nil || !bytes.Equal(entropy, fixed[:words*4/3]) {
+			t.Errorf("got %x, %v, want the fixed entropy", entropy, err)
+		}
+	}
+	if _, err := GenerateMnemonic(13, English, nil); err == nil {
+		t.Error("want an error for 13 words")
+	}
+	if _, err := GenerateMnemonic(24, English, BytesEntropy(fixed[:16])); err == nil {
+		t.Error("want an error for missing entropy")
+	}
+	a, _ := GenerateMnemonic(24, Korean, nil)
+	b, _ := GenerateMnemonic(24, Korean, nil)
+	if a == "" || a == b {
+		t.Errorf("got %q and %q, want two new mnemonics", a, b)
+	}
+}
+
+func TestEntropySources(t *testing.T) {
+	rolls := strings.Repeat("1 6 3 4 2 5 ", 9)
+	dice, err := DiceEntropy(rolls).Entropy(16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, _ := DiceEntropy(strings.Replace(rolls, " ", "", -1)).Entropy(16)
+	if !bytes.Equal(dice, again) {
+		t.Error("the spaces between the rolls should not count")
+	}
+	if _, err := DiceEntropy(rolls).Entropy(32); err == nil {
+		t.Error("want an error for too few rolls")
+	}
+	if _, err := DiceEntropy("1234567").Entropy(1); err == nil {
+		t.Error("want an error for a 7")
+	}
+
+	//the mix depends on every source
+	platform := BytesEntropy(bytes.Repeat([]byte{1}, 32))
+	mixed, _ := Mix(platform, DiceEntropy(rolls)).Entropy(16)
+	other, _ := Mix(platform, DiceEntropy("2"+rolls[1:])).Entropy(16)
+	if len(mixed) != 16 || bytes.Equal(mixed, other) || bytes.Equal(mixed, dice) {
+		t.Errorf("got %x and %x, want different mixes", mixed, other)
+	}
+	if _, err := Mix(SystemEntropy, BytesEntropy(nil)).Entropy(16); err == nil {
+		t.Error("want the error of a source")
+	}
+}
@@ -0,0 +1,306 @@
+package bip39local
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/text/unicode/norm"
+)
+
+//The mnemonics are accepted in every registered language. The words and the passphrase are NFKD normalized
+//as the bip39 specification asks, so the accented words of the Spanish or French lists match whether they are
+//typed composed or not, and the ideographic spaces of the Japanese mnemonics are plain spaces. The language
+//of a mnemonic is detected from its words, the Chinese lists share many characters and the checksum tells them
+//apart. The lists missing from the package, such as the Portuguese one, are added with RegisterLanguage.
+
+//the names of the languages
+const (
+	English            = "english"
+	ChineseSimplified  = "chinese_simplified"
+	ChineseTraditional = "chinese_traditional"
+	Japanese           = "japanese"
+	Korean             = "korean"
+	Spanish            = "spanish"
+	French             = "french"
+	Italian            = "italian"
+	Czech              = "czech"
+	Portuguese         = "portuguese"
+
+	wordListSize = 2048
+)
+
+//ErrChecksumIncorrect is the error of a mnemonic whose words are all in the list but whose checksum is wrong
+var ErrChecksumIncorrect = errors.New("the checksum of the mnemonic is invalid")
+
+//Language is a bip39 wordlist
+type Language struct {
+	Name  string
+	words []string
+	index map[string]int
+}
+
+var (
+	languagesMu sync.RWMutex
+	languages   = map[string]*Language{}
+	//languageOrder is the order of the detection, English first and the simplified Chinese before the traditional
+	languageOrder []string
+)
+
+func init() {
+	builtin := []struct {
+		name, list string
+	}{
+		{English, englishWordList},
+		{ChineseSimplified, chineseSimplifiedWordList},
+		{ChineseTraditional, chineseTraditionalWordList},
+		{Japanese, japaneseWordList},
+		{Korean, koreanWordList},
+		{Spanish, spanishWordList},
+		{French, frenchWordList},
+		{Italian, italianWordList},
+		{Czech, czechWordList},
+	}
+	for _, l := range builtin {
+		if err := RegisterLanguage(l.name, strings.Split(strings.TrimSpace(l.list), "\n")); err != nil {
+			panic(err)
+		}
+	}
+}
+
+//RegisterLanguage adds the wordlist of a language, or replaces it, the list has the 2048 words in the order of
+//the bip39 specification
+func RegisterLanguage(name string, words []string) error {
+	if name == "" {
+		return errors.New("the language needs a name")
+	}
+	if len(words) != wordListSize {
+		return fmt.Errorf("the wordlist of %s has %d words, not %d", name, len(words), wordListSize)
+	}
+	l := &Language{Name: name, words: make([]string, wordListSize), index: make(map[string]int, wordListSize)}
+	for i, word := range words {
+		word = norm.NFKD.String(strings.TrimSpace(word))
+		if word == "" || strings.ContainsAny(word, " \t") {
+			return fmt.Errorf("the wordlist of %s has the invalid word %q at %d", name, word, i)
+		}
+		if _, ok := l.index[word]; ok {
+			return fmt.Errorf("the wordlist of %s has the word %s twice", name, word)
+		}
+		l.words[i] = word
+		l.index[word] = i
+	}
+
+	languagesMu.Lock()
+	defer languagesMu.Unlock()
+	if _, ok := languages[name]; !ok {
+		languageOrder = append(languageOrder, name)
+	}
+	languages[name] = l
+	return nil
+}
+
+//GetLanguage returns the registered language name
+func GetLanguage(name string) (*Language, error) {
+	languagesMu.RLock()
+	defer languagesMu.RUnlock()
+	l, ok := languages[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown mnemonic language %s", name)
+	}
+	return l, nil
+}
+
+//Languages returns the names of the registered languages
+func Languages() []string {
+	languagesMu.RLock()
+	defer languagesMu.RUnlock()
+	return append([]string(nil), languageOrder...)
+}
+
+//Word returns the word of index, the NFKD form
+func (l *Language) Word(index int) string {
+	return l.words[index]
+}
+
+//Index returns the index of the word, which is NFKD normalized first
+func (l *Language) Index(word string) (int, bool) {
+	i, ok := l.index[norm.NFKD.String(word)]
+	return i, ok
+}
+
+//separator joins the words of a mnemonic, the Japanese ones are written with ideographic spaces
+func (l *Language) separator() string {
+	if l.Name == Japanese {
+		return "\u3000"
+	}
+	return " "
+}
+
+//NewMnemonicIn returns the mnemonic of entropy in language, the words are in the composed form the users type
+func NewMnemonicIn(entropy []byte, language string) (string, error) {
+	l, err := GetLanguage(language)
+	if err != nil {
+		return "", err
+	}
+	if err := validateEntropyBitSize(len(entropy) * 8); err != nil {
+		return "", err
+	}
+	indexes := entropyIndexes(entropy)
+	words := make([]string, len(indexes))
+	for i, index := range indexes {
+		words[i] = norm.NFC.String(l.words[index])
+	}
+	return strings.Join(words, l.separator()), nil
+}
+
+//SplitMnemonic returns the NFKD normalized words of mnemonic. A mnemonic of Chinese characters written without
+//spaces is split into its characters.
+func SplitMnemonic(mnemonic string) []string {
+	words := strings.Fields(norm.NFKD.String(mnemonic))
+	if len(words) == 1 {
+		if n := utf8.RuneCountInString(words[0]); n >= 12 && n <= 24 && n%3 == 0 && !isASCII(words[0]) {
+			runes := []rune(words[0])
+			words = make([]string, len(runes))
+			for i, r := range runes {
+				words[i] = string(r)
+			}
+		}
+	}
+	return words
+}
+
+//DetectLanguage returns the language of mnemonic: the first one holding all its words, or, when several do,
+//the first one in which its checksum is valid
+func DetectLanguage(mnemonic string) (*Language, error) {
+	words := SplitMnemonic(mnemonic)
+	if len(words) == 0 {
+		return nil, errors.New("the mnemonic is empty")
+	}
+	candidates := candidateLanguages(words)
+	if len(candidates) == 0 {
+		return nil, errors.New("the words of the mnemonic are not all in one bip39 wordlist")
+	}
+	for _, l := range candidates {
+		if _, err := l.entropy(words); err == nil {
+			return l, nil
+		}
+	}
+	return candidates[0], nil
+}
+
+//MnemonicToEntropy returns the entropy of mnemonic and its language, the checksum has to be valid
+func MnemonicToEntropy(mnemonic string) ([]byte, *Language, error) {
+	words := SplitMnemonic(mnemonic)
+	if err := validateWordCount(len(words)); err != nil {
+		return nil, nil, err
+	}
+	l, err := DetectLanguage(mnemonic)
+	if err != nil {
+		return nil, nil, err
+	}
+	entropy, err := l.entropy(words)
+	if err != nil {
+		return nil, nil, err
+	}
+	return entropy, l, nil
+}
+
+//MnemonicToSeed returns the bip39 seed of mnemonic with passphrase, mnemonic is checked and both are NFKD
+//normalized. Unlike NewSeed, which hashes the strings as they are, it is the seed of the specification for any
+//language, NewSeed is kept for the accounts derived by it.
+func MnemonicToSeed(mnemonic, passphrase string) ([]byte, error) {
+	if _, _, err := MnemonicToEntropy(mnemonic); err != nil {
+		return nil, err
+	}
+	return pbkdf2.Key([]byte(NormalizeMnemonic(mnemonic)), []byte("mnemonic"+norm.NFKD.String(passphrase)), 2048, 64, sha512.New), nil
+}
+
+//NormalizeMnemonic returns the NFKD words of mnemonic joined by single spaces, the sentence of the seed
+func NormalizeMnemonic(mnemonic string) string {
+	return strings.Join(SplitMnemonic(mnemonic), " ")
+}
+
+//candidateLanguages returns the languages holding all the words, in the order of detection
+func candidateLanguages(words []string) []*Language {
+	languagesMu.RLock()
+	defer languagesMu.RUnlock()
+	var out []*Language
+	for _, name := range languageOrder {
+		l := languages[name]
+		if l.hasAll(words) {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+func (l *Language) hasAll(words []string) bool {
+	for _, word := range words {
+		if _, ok := l.index[word]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+//entropy decodes the normalized words, which are all in the list, and checks the checksum
+func (l *Language) entropy(words []string) ([]byte, error) {
+	if err := validateWordCount(len(words)); err != nil {
+		return nil, err
+	}
+	b := new(big.Int)
+	for _, word := range words {
+		b.Lsh(b, 11)
+		b.Or(b, big.NewInt(int64(l.index[word])))
+	}
+	checksumBits := uint(len(words) * 11 / 33)
+	entropyBytes := (uint(len(words)*11) - checksumBits) / 8
+	checksum := new(big.Int).And(b, big.NewInt(int64(1)<<checksumBits-1)).Uint64()
+	entropy := padByteSlice(new(big.Int).Rsh(b, checksumBits).Bytes(), int(entropyBytes))
+
+	hash := sha256.Sum256(entropy)
+	if uint64(hash[0]>>(8-checksumBits)) != checksum {
+		return nil, ErrChecksumIncorrect
+	}
+	return entropy, nil
+}
+
+//entropyIndexes returns the indexes of the words of entropy with its checksum
+func entropyIndexes(entropy []byte) []int {
+	checksumBits := uint(len(entropy) / 4)
+	hash := sha256.Sum256(entropy)
+	b := new(big.Int).SetBytes(entropy)
+	b.Lsh(b, checksumBits)
+	b.Or(b, big.NewInt(int64(hash[0]>>(8-checksumBits))))
+
+	count := (len(entropy)*8 + int(checksumBits)) / 11
+	indexes := make([]int, count)
+	mask := big.NewInt(wordListSize - 1)
+	for i := count - 1; i >= 0; i-- {
+		indexes[i] = int(new(big.Int).And(b, mask).Int64())
+		b.Rsh(b, 11)
+	}
+	return indexes
+}
+
+func validateWordCount(count int) error {
+	if count%3 != 0 || count < 12 || count > 24 {
+		return fmt.Errorf("a mnemonic has 12, 15, 18, 21 or 24 words, not %d", count)
+	}
+	return nil
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,122 @@
+package bip39local
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"hash/crc32"
+	"strings"
+	"testing"
+)
+
+func TestWordLists(t *testing.T) {
+	//the crc32 of the files of the bip39 specification
+	lists := []struct {
+		name, list, crc string
+	}{
+		{ChineseSimplified, chineseSimplifiedWordList, "e3721bbf"},
+		{ChineseTraditional, chineseTraditionalWordList, "3c20b443"},
+		{Japanese, japaneseWordList, "acc1419"},
+		{Korean, koreanWordList, "4ef461eb"},
+		{Spanish, spanishWordList, "266e4f3d"},
+		{French, frenchWordList, "3e56b216"},
+		{Italian, italianWordList, "2fc7d07e"},
+		{Czech, czechWordList, "d1b5fda0"},
+	}
+	for _, l := range lists {
+		if crc := fmt.Sprintf("%x", crc32.ChecksumIEEE([]byte(l.list))); crc != l.crc {
+			t.Errorf("got the crc32 %s for %s, want %s", crc, l.name, l.crc)
+		}
+	}
+	if len(Languages()) != len(lists)+1 {
+		t.Errorf("got the languages %v", Languages())
+	}
+}
+
+func TestMnemonicToSeed(t *testing.T) {
+	//the first vectors of the specification
+	vectors := []struct {
+		language, mnemonic, passphrase, seed string
+	}{
+		{English, "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about", "TREZOR",
+			"c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04"},
+		{Japanese, "あいこくしん　あいこくしん　あいこくしん　あいこくしん　あいこくしん　あいこくしん　あいこくしん　あいこくしん　あいこくしん　あいこくしん　あいこくしん　あおぞら", "㍍ガバヴァぱばぐゞちぢ十人十色",
+			"a262d6fb6122ecf45be09c50492b31f92e9beb7d9a845987a02cefda57a15f9c467a17872029a9e92299b5cbdf306e3a0ee620245cbd508959b6cb7ca637bd55"},
+	}
+	for _, v := range vectors {
+		mnemonic, err := NewMnemonicIn(make([]byte, 16), v.language)
+		if err != nil || mnemonic != v.mnemonic {
+			t.Errorf("got %q, %v, want %q", mnemonic, err, v.mnemonic)
+		}
+		seed, err := MnemonicToSeed(v.mnemonic, v.passphrase)
+		if err != nil || hex.EncodeToString(seed) != v.seed {
+			t.Errorf("got the seed %x, %v for %s", seed, err, v.language)
+		}
+	}
+	//the English seeds are the ones of NewSeed
+	mnemonic := vectors[0].mnemonic
+	if seed, _ := MnemonicToSeed(mnemonic, "TREZOR"); !bytes.Equal(seed, NewSeed(mnemonic, "TREZOR")) {
+		t.Error("MnemonicToSeed should be NewSeed for English")
+	}
+}
+
+func TestDetectLanguage(t *testing.T) {
+	entropy := []byte("litewallet entropy of 32 bytes!!")
+	for _, name := range Languages() {
+		mnemonic, err := NewMnemonicIn(entropy, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, l, err := MnemonicToEntropy(mnemonic)
+		if err != nil || !bytes.Equal(got, entropy) || l.Name != name {
+			t.Errorf("got %x, %v, %v for %s", got, l, err, name)
+		}
+		if !IsMnemonicValid(mnemonic) {
+			t.Errorf("the %s mnemonic should be valid", name)
+		}
+	}
+
+	//the Chinese mnemonics are typed without spaces too
+	mnemonic, _ := NewMnemonicIn(entropy[:16], ChineseTraditional)
+	if l, err := DetectLanguage(strings.Replace(mnemonic, " ", "", -1)); err != nil || l.Name != ChineseTraditional {
+		t.Errorf("got %v, %v, want the traditional Chinese", l, err)
+	}
+	//the accented words match in the decomposed form
+	mnemonic, _ = NewMnemonicIn(entropy[:16], Spanish)
+	if l, err := DetectLanguage(NormalizeMnemonic(mnemonic)); err != nil || l.Name != Spanish {
+		t.Errorf("got %v, %v, want Spanish", l, err)
+	}
+
+	if _, _, err := MnemonicToEntropy("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon"); err == nil {
+		t.Error("want a checksum error")
+	}
+	if _, err := DetectLanguage("abandon 的 abandon"); err == nil {
+		t.Error("want an error for the words of two lists")
+	}
+}
+
+func TestRegisterLanguage(t *testing.T) {
+	if err := RegisterLanguage("short", []string{"a", "b"}); err == nil {
+		t.Error("want an error for a short list")
+	}
+	words := make([]string, wordListSize)
+	for i := range words {
+		words[i] = fmt.Sprintf("w%d", i)
+	}
+	if err := RegisterLanguage("test", words); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		languagesMu.Lock()
+		delete(languages, "test")
+		languageOrder = languageOrder[:len(languageOrder)-1]
+		languagesMu.Unlock()
+	}()
+	mnemonic, err := NewMnemonicIn(make([]byte, 16), "test")
+	if err != nil || !strings.HasPrefix(mnemonic, "w0 w0") {
+		t.Fatalf("got %q, %v", mnemonic, err)
+	}
+	if l, err := DetectLanguage(mnemonic); err != nil || l.Name != "test" {
+		t.Errorf("got %v, %v, want the registered language", l, err)
+	}
+}
@@ -2,6 +2,7 @@ package respwrap
 
 import (
 	"encoding/json"
+	"github.com/QOSGroup/litewallet/litewallet/errcode"
 	"github.com/pkg/errors"
 	"github.com/tendermint/go-amino"
 )
@@ -21,7 +22,7 @@ type RPCResponse struct {
 
 func ResponseWrapper(cdc *amino.Codec, result interface{}, err error) ([]byte, error) {
 	if err != nil {
-		return writeResponse(NewRPCErrorResponse("", 0, err.Error(), ""))
+		return writeResponse(NewRPCErrorResponse("", int(errcode.Code(err)), err.Error(), ""))
 	} else {
 		return writeResponse(NewRPCSuccessResponse(cdc, "", result))
 	}
@@ -58,6 +59,7 @@ func NewRPCSuccessResponse(cdc *amino.Codec, id string, res interface{}) RPCResp
 	return RPCResponse{JSONRPC: "2.0", ID: id, Result: rawMsg}
 }
 
+//RPCInternalError is the response of a result which can not be marshalled, its code is errcode.CodeInternal
 func RPCInternalError(id string, err error) RPCResponse {
-	return NewRPCErrorResponse(id, -32603, "Internal error", err.Error())
+	return NewRPCErrorResponse(id, int(errcode.CodeInternal), "Internal error", err.Error())
 }
@@ -0,0 +1,49 @@
+package slip39local
+
+import (
+	"crypto/sha256"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+//The master secret is encrypted with the passphrase by a four round Feistel network of PBKDF2-HMAC-SHA256
+
+const (
+	baseIterations = 10000
+	rounds         = 4
+)
+
+func roundKey(i int, passphrase []byte, exponent int, salt, r []byte) []byte {
+	password := append([]byte{byte(i)}, passphrase...)
+	return pbkdf2.Key(password, append(append([]byte(nil), salt...), r...), (baseIterations<<uint(exponent))/rounds, len(r), sha256.New)
+}
+
+func cipherSalt(identifier int, extendable bool) []byte {
+	if extendable {
+		return nil
+	}
+	return append([]byte(customization), byte(identifier>>8), byte(identifier))
+}
+
+func feistel(secret, passphrase []byte, exponent, identifier int, extendable bool, order []int) []byte {
+	half := len(secret) / 2
+	l := append([]byte(nil), secret[:half]...)
+	r := append([]byte(nil), secret[half:]...)
+	salt := cipherSalt(identifier, extendable)
+	for _, i := range order {
+		f := roundKey(i, passphrase, exponent, salt, r)
+		for j := range l {
+			l[j] ^= f[j]
+		}
+		l, r = r, l
+	}
+	return append(r, l...)
+}
+
+func encrypt(secret, passphrase []byte, exponent, identifier int, extendable bool) []byte {
+	return feistel(secret, passphrase, exponent, identifier, extendable, []int{0, 1, 2, 3})
+}
+
+func decrypt(secret, passphrase []byte, exponent, identifier int, extendable bool) []byte {
+	return feistel(secret, passphrase, exponent, identifier, extendable, []int{3, 2, 1, 0})
+}
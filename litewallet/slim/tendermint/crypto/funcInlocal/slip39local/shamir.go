@@ -0,0 +1,123 @@
+package slip39local
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"io"
+)
+
+//Shamir's secret sharing over GF(256) with the polynomial of Rijndael. The secret is the value at x 255 and
+//the value at x 254 holds a digest of it, so a combination of wrong shares is detected.
+
+const (
+	secretIndex  = 255
+	digestIndex  = 254
+	digestLength = 4
+)
+
+var expTable, logTable [256]int
+
+func init() {
+	poly := 1
+	for i := 0; i < 255; i++ {
+		expTable[i] = poly
+		logTable[poly] = i
+		//multiply by the generator x + 1
+		poly = poly<<1 ^ poly
+		if poly&0x100 != 0 {
+			poly ^= 0x11B
+		}
+	}
+}
+
+type point struct {
+	x     int
+	value []byte
+}
+
+//interpolate returns the value at x of the polynomial through the points, of distinct x
+func interpolate(points []point, x int) []byte {
+	for _, p := range points {
+		if p.x == x {
+			return append([]byte(nil), p.value...)
+		}
+	}
+	logProd := 0
+	for _, p := range points {
+		logProd += logTable[p.x^x]
+	}
+	result := make([]byte, len(points[0].value))
+	for _, p := range points {
+		logBasis := logProd - logTable[p.x^x]
+		for _, other := range points {
+			if other.x != p.x {
+				logBasis -= logTable[p.x^other.x]
+			}
+		}
+		logBasis = (logBasis%255 + 255) % 255
+		for i, v := range p.value {
+			if v != 0 {
+				result[i] ^= byte(expTable[(logTable[v]+logBasis)%255])
+			}
+		}
+	}
+	return result
+}
+
+func createDigest(randomPart, secret []byte) []byte {
+	mac := hmac.New(sha256.New, randomPart)
+	mac.Write(secret)
+	return mac.Sum(nil)[:digestLength]
+}
+
+//splitSecret returns count shares of secret, threshold of which recover it
+func splitSecret(threshold, count int, secret []byte, random io.Reader) ([]point, error) {
+	if threshold < 1 || threshold > count {
+		return nil, errors.New("the threshold has to be 1 to the share count")
+	}
+	if count > 16 {
+		return nil, errors.New("there are at most 16 shares")
+	}
+	if threshold == 1 {
+		out := make([]point, count)
+		for i := range out {
+			out[i] = point{i, append([]byte(nil), secret...)}
+		}
+		return out, nil
+	}
+
+	randomCount := threshold - 2
+	out := make([]point, 0, count)
+	for i := 0; i < randomCount; i++ {
+		value := make([]byte, len(secret))
+		if _, err := io.ReadFull(random, value); err != nil {
+			return nil, err
+		}
+		out = append(out, point{i, value})
+	}
+	randomPart := make([]byte, len(secret)-digestLength)
+	if _, err := io.ReadFull(random, randomPart); err != nil {
+		return nil, err
+	}
+	base := append(append([]point(nil), out...),
+		point{digestIndex, append(createDigest(randomPart, secret), randomPart...)},
+		point{secretIndex, secret})
+	for i := randomCount; i < count; i++ {
+		out = append(out, point{i, interpolate(base, i)})
+	}
+	return out, nil
+}
+
+//recoverSecret returns the secret of threshold points and checks its digest
+func recoverSecret(threshold int, points []point) ([]byte, error) {
+	if threshold == 1 {
+		return points[0].value, nil
+	}
+	secret := interpolate(points, secretIndex)
+	digest := interpolate(points, digestIndex)
+	if !hmac.Equal(digest[:digestLength], createDigest(digest[digestLength:], secret)) {
+		return nil, errors.New("the shares are not of the same secret")
+	}
+	return secret, nil
+}
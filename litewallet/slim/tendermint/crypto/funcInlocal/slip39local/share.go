@@ -0,0 +1,204 @@
+package slip39local
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	radixBits      = 10
+	radix          = 1 << radixBits
+	idBits         = 15
+	iterationBits  = 4
+	checksumWords  = 3
+	metadataWords  = 4 + checksumWords
+	minSecretBytes = 16
+
+	customization           = "shamir"
+	customizationExtendable = "shamir_extendable"
+)
+
+var (
+	words     []string
+	wordIndex = map[string]int{}
+)
+
+func init() {
+	words = strings.Fields(wordList)
+	if len(words) != radix {
+		panic(fmt.Sprintf("the slip39 wordlist has %d words", len(words)))
+	}
+	for i, word := range words {
+		wordIndex[word] = i
+	}
+}
+
+//Share is a decoded share mnemonic, the indexes count from 0 and the thresholds from 1
+type Share struct {
+	Identifier        int    `json:"identifier"`
+	Extendable        bool   `json:"extendable"`
+	IterationExponent int    `json:"iteration_exponent"`
+	GroupIndex        int    `json:"group_index"`
+	GroupThreshold    int    `json:"group_threshold"`
+	GroupCount        int    `json:"group_count"`
+	MemberIndex       int    `json:"member_index"`
+	MemberThreshold   int    `json:"member_threshold"`
+	Value             []byte `json:"-"`
+}
+
+//commonParams are the parameters the shares of one secret share
+func (s *Share) commonParams() [5]int {
+	ext := 0
+	if s.Extendable {
+		ext = 1
+	}
+	return [5]int{s.Identifier, ext, s.IterationExponent, s.GroupThreshold, s.GroupCount}
+}
+
+//Mnemonic returns the words of the share
+func (s *Share) Mnemonic() string {
+	ext := 0
+	if s.Extendable {
+		ext = 1
+	}
+	var w bitWriter
+	w.write(s.Identifier, idBits)
+	w.write(ext, 1)
+	w.write(s.IterationExponent, iterationBits)
+	w.write(s.GroupIndex, 4)
+	w.write(s.GroupThreshold-1, 4)
+	w.write(s.GroupCount-1, 4)
+	w.write(s.MemberIndex, 4)
+	w.write(s.MemberThreshold-1, 4)
+	//the value is padded with zero bits on the left to whole words
+	valueWords := (len(s.Value)*8 + radixBits - 1) / radixBits
+	w.write(0, valueWords*radixBits-len(s.Value)*8)
+	for _, b := range s.Value {
+		w.write(int(b), 8)
+	}
+	data := w.words
+	data = append(data, rs1024Checksum(s.customization(), data)...)
+
+	out := make([]string, len(data))
+	for i, index := range data {
+		out[i] = words[index]
+	}
+	return strings.Join(out, " ")
+}
+
+func (s *Share) customization() string {
+	if s.Extendable {
+		return customizationExtendable
+	}
+	return customization
+}
+
+//ParseShare decodes the share mnemonic and checks its checksum
+func ParseShare(mnemonic string) (*Share, error) {
+	fields := strings.Fields(strings.ToLower(mnemonic))
+	if len(fields) < metadataWords+(minSecretBytes*8+radixBits-1)/radixBits {
+		return nil, fmt.Errorf("a share has at least 20 words, not %d", len(fields))
+	}
+	data := make([]int, len(fields))
+	for i, field := range fields {
+		index, ok := wordIndex[field]
+		if !ok {
+			return nil, fmt.Errorf("the word %d %q of the share is not in the slip39 wordlist", i+1, field)
+		}
+		data[i] = index
+	}
+	paddingBits := (len(data) - metadataWords) * radixBits % 16
+	if paddingBits > 8 {
+		return nil, errors.New("the share has an invalid length")
+	}
+
+	s := &Share{}
+	s.Extendable = data[1]>>4&1 == 1
+	if rs1024Polymod(s.customization(), data) != 1 {
+		return nil, errors.New("the checksum of the share is invalid")
+	}
+	r := bitReader{words: data[:len(data)-checksumWords]}
+	s.Identifier = r.read(idBits)
+	r.read(1)
+	s.IterationExponent = r.read(iterationBits)
+	s.GroupIndex = r.read(4)
+	s.GroupThreshold = r.read(4) + 1
+	s.GroupCount = r.read(4) + 1
+	s.MemberIndex = r.read(4)
+	s.MemberThreshold = r.read(4) + 1
+	if s.GroupThreshold > s.GroupCount {
+		return nil, errors.New("the group threshold of the share is above its group count")
+	}
+	if r.read(paddingBits) != 0 {
+		return nil, errors.New("the padding of the share is not zero")
+	}
+	s.Value = make([]byte, (len(data)-metadataWords)*radixBits/8)
+	for i := range s.Value {
+		s.Value[i] = byte(r.read(8))
+	}
+	return s, nil
+}
+
+//bitWriter packs bits into words of radixBits
+type bitWriter struct {
+	words []int
+	acc   int
+	n     int
+}
+
+func (w *bitWriter) write(v, bits int) {
+	for i := bits - 1; i >= 0; i-- {
+		w.acc = w.acc<<1 | v>>uint(i)&1
+		w.n++
+		if w.n == radixBits {
+			w.words = append(w.words, w.acc)
+			w.acc, w.n = 0, 0
+		}
+	}
+}
+
+//bitReader reads bits from words of radixBits
+type bitReader struct {
+	words []int
+	pos   int
+}
+
+func (r *bitReader) read(bits int) int {
+	v := 0
+	for i := 0; i < bits; i++ {
+		word, bit := r.words[r.pos/radixBits], radixBits-1-r.pos%radixBits
+		v = v<<1 | word>>uint(bit)&1
+		r.pos++
+	}
+	return v
+}
+
+var rs1024Gen = [10]int{0xE0E040, 0x1C1C080, 0x3838100, 0x7070200, 0xE0E0009, 0x1C0C2412, 0x38086C24, 0x3090FC48, 0x21B1F890, 0x3F3F120}
+
+func rs1024Polymod(customization string, data []int) int {
+	chk := 1
+	values := make([]int, 0, len(customization)+len(data))
+	for i := 0; i < len(customization); i++ {
+		values = append(values, int(customization[i]))
+	}
+	for _, v := range append(values, data...) {
+		b := chk >> 20
+		chk = (chk&0xFFFFF)<<10 ^ v
+		for i := uint(0); i < 10; i++ {
+			if b>>i&1 == 1 {
+				chk ^= rs1024Gen[i]
+			}
+		}
+	}
+	return chk
+}
+
+func rs1024Checksum(customization string, data []int) []int {
+	polymod := rs1024Polymod(customization, append(append([]int(nil), data...), 0, 0, 0)) ^ 1
+	out := make([]int, checksumWords)
+	for i := range out {
+		out[i] = polymod >> uint(radixBits*(checksumWords-1-i)) & (radix - 1)
+	}
+	return out
+}
@@ -0,0 +1,152 @@
+//Package slip39local splits a master secret into the share mnemonics of SLIP-0039 and combines them back.
+//
+//The secret is encrypted with the passphrase, split into groups, group threshold of which are needed, and
+//the secret of each group into member shares, member threshold of which are needed.
+package slip39local
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+)
+
+//DefaultIterationExponent is the exponent of the 10000 << e iterations of the passphrase encryption
+const DefaultIterationExponent = 1
+
+//randReader is the source of the identifiers and of the random shares, the tests replace it
+var randReader io.Reader = rand.Reader
+
+//Group is the member threshold and member count of a group of shares
+type Group struct {
+	MemberThreshold int `json:"member_threshold"`
+	MemberCount     int `json:"member_count"`
+}
+
+//Split returns the share mnemonics of masterSecret for each group, groupThreshold of the groups recover it.
+//The secret has at least 16 bytes and an even length, the passphrase, which may be empty, is printable ASCII.
+func Split(masterSecret []byte, passphrase string, groupThreshold int, groups []Group) ([][]string, error) {
+	if len(masterSecret) < minSecretBytes || len(masterSecret)%2 != 0 {
+		return nil, fmt.Errorf("the master secret has at least %d bytes and an even length, not %d", minSecretBytes, len(masterSecret))
+	}
+	if err := checkPassphrase(passphrase); err != nil {
+		return nil, err
+	}
+	if groupThreshold < 1 || groupThreshold > len(groups) {
+		return nil, fmt.Errorf("the group threshold %d is not 1 to the %d groups", groupThreshold, len(groups))
+	}
+	for i, g := range groups {
+		if g.MemberThreshold < 1 || g.MemberThreshold > g.MemberCount || g.MemberCount > 16 {
+			return nil, fmt.Errorf("the group %d has the member threshold %d of %d members", i+1, g.MemberThreshold, g.MemberCount)
+		}
+		if g.MemberThreshold == 1 && g.MemberCount > 1 {
+			return nil, fmt.Errorf("the group %d has several members with a threshold of 1, use a single member instead", i+1)
+		}
+	}
+
+	var id [2]byte
+	if _, err := io.ReadFull(randReader, id[:]); err != nil {
+		return nil, err
+	}
+	identifier := (int(id[0])<<8 | int(id[1])) & (1<<idBits - 1)
+	encrypted := encrypt(masterSecret, []byte(passphrase), DefaultIterationExponent, identifier, true)
+
+	groupShares, err := splitSecret(groupThreshold, len(groups), encrypted, randReader)
+	if err != nil {
+		return nil, err
+	}
+	out := make([][]string, len(groups))
+	for i, gs := range groupShares {
+		members, err := splitSecret(groups[i].MemberThreshold, groups[i].MemberCount, gs.value, randReader)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range members {
+			s := Share{
+				Identifier:        identifier,
+				Extendable:        true,
+				IterationExponent: DefaultIterationExponent,
+				GroupIndex:        gs.x,
+				GroupThreshold:    groupThreshold,
+				GroupCount:        len(groups),
+				MemberIndex:       m.x,
+				MemberThreshold:   groups[i].MemberThreshold,
+				Value:             m.value,
+			}
+			out[i] = append(out[i], s.Mnemonic())
+		}
+	}
+	return out, nil
+}
+
+//Combine returns the master secret of the share mnemonics, which hold the member threshold of shares of
+//group threshold of groups. The shares of the other groups and the shares over a threshold are ignored.
+func Combine(mnemonics []string, passphrase string) ([]byte, error) {
+	if len(mnemonics) == 0 {
+		return nil, errors.New("no share is given")
+	}
+	if err := checkPassphrase(passphrase); err != nil {
+		return nil, err
+	}
+	var first *Share
+	groups := map[int][]point{}
+	memberThresholds := map[int]int{}
+	for i, mnemonic := range mnemonics {
+		s, err := ParseShare(mnemonic)
+		if err != nil {
+			return nil, fmt.Errorf("share %d: %v", i+1, err)
+		}
+		if first == nil {
+			first = s
+		} else if s.commonParams() != first.commonParams() || len(s.Value) != len(first.Value) {
+			return nil, fmt.Errorf("share %d is not a share of the same secret as share 1", i+1)
+		}
+		if t, ok := memberThresholds[s.GroupIndex]; ok && t != s.MemberThreshold {
+			return nil, fmt.Errorf("share %d has another member threshold than its group", i+1)
+		}
+		memberThresholds[s.GroupIndex] = s.MemberThreshold
+		duplicate := false
+		for _, p := range groups[s.GroupIndex] {
+			if p.x == s.MemberIndex {
+				if string(p.value) != string(s.Value) {
+					return nil, fmt.Errorf("share %d is another value of a member already given", i+1)
+				}
+				duplicate = true
+			}
+		}
+		if !duplicate {
+			groups[s.GroupIndex] = append(groups[s.GroupIndex], point{s.MemberIndex, s.Value})
+		}
+	}
+
+	var groupPoints []point
+	for index := 0; index < first.GroupCount && len(groupPoints) < first.GroupThreshold; index++ {
+		members := groups[index]
+		threshold := memberThresholds[index]
+		if len(members) == 0 || len(members) < threshold {
+			continue
+		}
+		value, err := recoverSecret(threshold, members[:threshold])
+		if err != nil {
+			return nil, fmt.Errorf("group %d: %v", index+1, err)
+		}
+		groupPoints = append(groupPoints, point{index, value})
+	}
+	if len(groupPoints) < first.GroupThreshold {
+		return nil, fmt.Errorf("%d of the %d groups needed are complete", len(groupPoints), first.GroupThreshold)
+	}
+	encrypted, err := recoverSecret(first.GroupThreshold, groupPoints)
+	if err != nil {
+		return nil, err
+	}
+	return decrypt(encrypted, []byte(passphrase), first.IterationExponent, first.Identifier, first.Extendable), nil
+}
+
+func checkPassphrase(passphrase string) error {
+	for i := 0; i < len(passphrase); i++ {
+		if passphrase[i] < 32 || passphrase[i] > 126 {
+			return errors.New("the passphrase is printable ASCII only")
+		}
+	}
+	return nil
+}
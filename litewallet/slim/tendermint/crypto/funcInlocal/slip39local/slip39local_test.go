@@ -0,0 +1,90 @@
+package slip39local
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestVectors(t *testing.T) {
+	//the vectors of the specification
+	vectors := []struct {
+		shares []string
+		secret string
+	}{
+		{[]string{"duckling enlarge academic academic agency result length solution fridge kidney coal piece deal husband erode duke ajar critical decision keyboard"},
+			"bb54aac4b89dc868ba37d9cc21b2cece"},
+		{[]string{"shadow pistol academic always adequate wildlife fancy gross oasis cylinder mustang wrist rescue view short owner flip making coding armed",
+			"shadow pistol academic acid actress prayer class unknown daughter sweater depict flip twice unkind craft early superior advocate guest smoking"},
+			"b43ceb7e57a0ea8766221624d01b0864"},
+	}
+	for _, v := range vectors {
+		secret, err := Combine(v.shares, "TREZOR")
+		if err != nil || hex.EncodeToString(secret) != v.secret {
+			t.Errorf("got %x, %v, want %s", secret, err, v.secret)
+		}
+	}
+	if _, err := Combine([]string{"duckling enlarge academic academic agency result length solution fridge kidney coal piece deal husband erode duke ajar critical decision kidney"}, "TREZOR"); err == nil {
+		t.Error("want a checksum error")
+	}
+	if _, err := Combine(vectors[1].shares[:1], "TREZOR"); err == nil {
+		t.Error("want an error for a share under the threshold")
+	}
+}
+
+func TestSplit(t *testing.T) {
+	for _, secret := range [][]byte{bytes.Repeat([]byte{0x42}, 16), bytes.Repeat([]byte{0x24}, 32)} {
+		//two of three groups: 1-of-1, 2-of-3 and 3-of-5
+		shares, err := Split(secret, "pass", 2, []Group{{1, 1}, {2, 3}, {3, 5}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(shares) != 3 || len(shares[1]) != 3 || len(shares[2]) != 5 {
+			t.Fatalf("got %d groups", len(shares))
+		}
+		if words := len(strings.Fields(shares[0][0])); words != 20 && words != 33 {
+			t.Errorf("got %d words", words)
+		}
+		combinations := [][]string{
+			{shares[0][0], shares[1][2], shares[1][0]},
+			{shares[2][4], shares[1][1], shares[2][0], shares[1][2], shares[2][2]},
+			{shares[2][1], shares[2][3], shares[2][0], shares[0][0]},
+		}
+		for _, c := range combinations {
+			got, err := Combine(c, "pass")
+			if err != nil || !bytes.Equal(got, secret) {
+				t.Errorf("got %x, %v, want %x", got, err, secret)
+			}
+		}
+		//another passphrase is another secret
+		if got, _ := Combine(combinations[0], "other"); bytes.Equal(got, secret) {
+			t.Error("the passphrase should change the secret")
+		}
+		if _, err := Combine([]string{shares[0][0], shares[1][0]}, "pass"); err == nil {
+			t.Error("want an error for an incomplete group")
+		}
+		share, _ := ParseShare(shares[2][3])
+		if share.GroupIndex != 2 || share.MemberIndex != 3 || share.MemberThreshold != 3 || share.GroupThreshold != 2 || !share.Extendable {
+			t.Errorf("got %+v", share)
+		}
+	}
+
+	other, _ := Split(bytes.Repeat([]byte{1}, 16), "", 1, []Group{{2, 2}})
+	mine, _ := Split(bytes.Repeat([]byte{1}, 16), "", 1, []Group{{2, 2}})
+	if _, err := Combine([]string{other[0][0], mine[0][1]}, ""); err == nil {
+		t.Error("want an error for the shares of two splits")
+	}
+	if _, err := Split(make([]byte, 15), "", 1, []Group{{1, 1}}); err == nil {
+		t.Error("want an error for a short secret")
+	}
+	if _, err := Split(make([]byte, 16), "", 1, []Group{{1, 3}}); err == nil {
+		t.Error("want an error for several members of threshold 1")
+	}
+	if _, err := Split(make([]byte, 16), "", 2, []Group{{1, 1}}); err == nil {
+		t.Error("want an error for a group threshold above the groups")
+	}
+	if _, err := Split(make([]byte, 16), "pässword", 1, []Group{{1, 1}}); err == nil {
+		t.Error("want an error for a passphrase out of ASCII")
+	}
+}
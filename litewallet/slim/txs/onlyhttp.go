@@ -3,6 +3,8 @@ package txs
 import (
 	"encoding/hex"
 	"errors"
+
+	"github.com/QOSGroup/litewallet/litewallet/slim/base/client/context"
 	qtypes "github.com/QOSGroup/litewallet/litewallet/slim/base/types"
 	"github.com/QOSGroup/litewallet/litewallet/slim/types"
 	rpcclient "github.com/tendermint/tendermint/rpc/client"
@@ -226,10 +228,18 @@ func AddressStoreKey(addr qtypes.Address) []byte {
 	return append([]byte(accountStoreKey), addr.Bytes()...)
 }
 
-//func Query(addr types.Address) ([]byte, error) {
+//Query queries key under path from the blockchain entrance. The answers to the key queries of the stores
+//are verified against the app hash of a header verified by the light client of the trust package, on the
+//chain of the header at height 1 of the entrance. The state read is the one before the latest block, which
+//the node already proved, so a tx of the latest block is not seen yet.
 func Query(path string, key []byte) ([]byte, error) {
+	verifier := context.NewVerifier("", "", RPC)
+	height, err := verifier.ProvedHeight()
+	if err != nil {
+		return nil, err
+	}
 	opts := rpcclient.ABCIQueryOptions{
-		Height: 0,
+		Height: height,
 		Prove:  true,
 	}
 	result, err := RPC.ABCIQueryWithOptions(path, key, opts)
@@ -240,6 +250,9 @@ func Query(path string, key []byte) ([]byte, error) {
 	if !resp.IsOK() {
 		return nil, errors.New("query failed")
 	}
+	if err := verifier.VerifyQuery(path, key, resp); err != nil {
+		return nil, err
+	}
 	//var acc *QOSAccount
 	//err = Cdc.UnmarshalBinaryBare(resp.Value, &acc)
 	//if err != nil {
@@ -254,14 +267,7 @@ func BroadcastTransferTxToQSC(txb string, broadcastModes string) string {
 	if err != nil {
 		return err.Error()
 	}
-	var res *ctypes.ResultBroadcastTx
-	switch broadcastModes {
-	case "sync":
-		res, err = RPC.BroadcastTxSync(txBytes)
-		//默认异步
-	default:
-		res, err = RPC.BroadcastTxAsync(txBytes)
-	}
+	res, err := BroadcastTxToQSC(txBytes, broadcastModes)
 	if err != nil {
 		return err.Error()
 	}
@@ -272,6 +278,17 @@ func BroadcastTransferTxToQSC(txb string, broadcastModes string) string {
 	return string(resbyte)
 }
 
+//BroadcastTxToQSC broadcasts the tx bytes in "sync" mode, or async by default
+func BroadcastTxToQSC(txBytes []byte, broadcastModes string) (*ctypes.ResultBroadcastTx, error) {
+	switch broadcastModes {
+	case "sync":
+		return RPC.BroadcastTxSync(txBytes)
+		//默认异步
+	default:
+		return RPC.BroadcastTxAsync(txBytes)
+	}
+}
+
 func BroadcastTx(txb []byte) (string, error) {
 	result, err := RPC.BroadcastTxSync(txb)
 	if err != nil {
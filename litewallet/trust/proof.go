@@ -0,0 +1,203 @@
+package trust
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/QOSGroup/litewallet/litewallet/errcode"
+	pkgerrors "github.com/pkg/errors"
+	amino "github.com/tendermint/go-amino"
+	"github.com/tendermint/iavl"
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/crypto/merkle"
+	"github.com/tendermint/tendermint/crypto/tmhash"
+	rpcclient "github.com/tendermint/tendermint/rpc/client"
+)
+
+//The stores of the QOS and Cosmos apps are IAVL trees under a multistore, as in the store/rootmulti
+//package of the Cosmos SDK. A key query is proved by an IAVL proof of the key up to the root of its
+//store, then by a multistore proof of the store roots up to the app hash. The app hash of the state
+//at height H is in the header at height H+1.
+
+//ProofOpMultiStore is the type of the multistore proof op
+const ProofOpMultiStore = "multistore"
+
+//blockWait is how long a query waits for the header proving it
+const blockWait = 30 * time.Second
+
+var cdc = amino.NewCodec()
+
+//CommitID is the version and the root hash of a store
+type CommitID struct {
+	Version int64
+	Hash    []byte
+}
+
+type storeCore struct {
+	CommitID CommitID
+}
+
+//StoreInfo is a store of the multistore
+type StoreInfo struct {
+	Name string
+	Core storeCore
+}
+
+//NewStoreInfo returns the store name at version with the root hash
+func NewStoreInfo(name string, version int64, hash []byte) StoreInfo {
+	return StoreInfo{Name: name, Core: storeCore{CommitID{Version: version, Hash: hash}}}
+}
+
+//Hash is the leaf of the store in the multistore, the name is hashed as the key of the leaf
+func (si StoreInfo) Hash() []byte {
+	return tmhash.Sum(cdc.MustMarshalBinaryLengthPrefixed(si.Core))
+}
+
+//MultiStoreProof holds all stores of the multistore at a version
+type MultiStoreProof struct {
+	StoreInfos []StoreInfo
+}
+
+//ComputeRootHash returns the app hash of the stores
+func (proof *MultiStoreProof) ComputeRootHash() []byte {
+	m := make(map[string][]byte, len(proof.StoreInfos))
+	for _, si := range proof.StoreInfos {
+		m[si.Name] = si.Hash()
+	}
+	return merkle.SimpleHashFromMap(m)
+}
+
+//MultiStoreProofOp proves the root of the store key up to the app hash
+type MultiStoreProofOp struct {
+	key   []byte
+	Proof *MultiStoreProof `json:"proof"`
+}
+
+//NewMultiStoreProofOp returns the proof op of the store key
+func NewMultiStoreProofOp(key []byte, proof *MultiStoreProof) MultiStoreProofOp {
+	return MultiStoreProofOp{key: key, Proof: proof}
+}
+
+//MultiStoreProofOpDecoder decodes the multistore proof ops
+func MultiStoreProofOpDecoder(pop merkle.ProofOp) (merkle.ProofOperator, error) {
+	if pop.Type != ProofOpMultiStore {
+		return nil, fmt.Errorf("unexpected proof op type %s, want %s", pop.Type, ProofOpMultiStore)
+	}
+	var op MultiStoreProofOp
+	if err := cdc.UnmarshalBinaryLengthPrefixed(pop.Data, &op); err != nil {
+		return nil, pkgerrors.Wrap(err, "decode the multistore proof")
+	}
+	if op.Proof == nil {
+		return nil, fmt.Errorf("no multistore proof")
+	}
+	return NewMultiStoreProofOp(pop.Key, op.Proof), nil
+}
+
+func (op MultiStoreProofOp) ProofOp() merkle.ProofOp {
+	return merkle.ProofOp{Type: ProofOpMultiStore, Key: op.key, Data: cdc.MustMarshalBinaryLengthPrefixed(op)}
+}
+
+func (op MultiStoreProofOp) String() string {
+	return fmt.Sprintf("MultiStoreProofOp{%s}", op.key)
+}
+
+func (op MultiStoreProofOp) GetKey() []byte {
+	return op.key
+}
+
+//Run checks that the root of the store is args[0] and returns the app hash
+func (op MultiStoreProofOp) Run(args [][]byte) ([][]byte, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("got %d values, want 1", len(args))
+	}
+	for _, si := range op.Proof.StoreInfos {
+		if si.Name != string(op.key) {
+			continue
+		}
+		if !bytes.Equal(args[0], si.Core.CommitID.Hash) {
+			return nil, fmt.Errorf("hash mismatch for store %s: %X vs %X", si.Name, si.Core.CommitID.Hash, args[0])
+		}
+		return [][]byte{op.Proof.ComputeRootHash()}, nil
+	}
+	return nil, fmt.Errorf("store %s not found in the multistore proof", op.key)
+}
+
+//ProofRuntime decodes the proofs of the IAVL stores under a multistore
+func ProofRuntime() *merkle.ProofRuntime {
+	prt := merkle.NewProofRuntime()
+	prt.RegisterOpDecoder(iavl.ProofOpIAVLValue, iavl.IAVLValueOpDecoder)
+	prt.RegisterOpDecoder(iavl.ProofOpIAVLAbsence, iavl.IAVLAbsenceOpDecoder)
+	prt.RegisterOpDecoder(ProofOpMultiStore, MultiStoreProofOpDecoder)
+	return prt
+}
+
+//storeName returns the store of a key query path, /store/<name>/key, the other queries have no proof
+func storeName(path string) (string, bool) {
+	parts := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	if len(parts) != 3 || parts[0] != "store" || parts[2] != "key" {
+		return "", false
+	}
+	return parts[1], true
+}
+
+//waitForHeight waits for the block at height, for blockWait at most
+func (v *Verifier) waitForHeight(height int64) error {
+	deadline := time.Now().Add(blockWait)
+	return Error(rpcclient.WaitForHeight(v.client, height, func(delta int64) error {
+		if time.Now().After(deadline) {
+			return errcode.Errorf(errcode.CodeNetwork, "no block %d after %s", height, blockWait)
+		}
+		return rpcclient.DefaultWaitStrategy(delta)
+	}))
+}
+
+//ProvedHeight returns the latest height whose state is proved by a header the node already made, the one
+//before its latest block. A query at that height is verified without waiting for a block.
+func (v *Verifier) ProvedHeight() (int64, error) {
+	status, err := v.client.Status()
+	if err != nil {
+		return 0, Error(err)
+	}
+	height := status.SyncInfo.LatestBlockHeight - 1
+	//the state of the first block waits for the second one
+	if height < 1 {
+		height = 1
+	}
+	return height, nil
+}
+
+//VerifyQuery checks resp, the answer to the query of key under path, against the app hash of the
+//verified header after it. It waits for that header when resp is at the latest height. Only the key
+//queries are proved, the answers to the subspace and custom queries are left as they are.
+func (v *Verifier) VerifyQuery(path string, key []byte, resp abci.ResponseQuery) error {
+	name, ok := storeName(path)
+	if !ok {
+		return nil
+	}
+	if resp.Proof == nil || len(resp.Proof.Ops) == 0 {
+		return errcode.Errorf(errcode.CodeUnverified, "no proof of %s", path)
+	}
+	if resp.Height <= 0 {
+		return errcode.Errorf(errcode.CodeUnverified, "no height of %s", path)
+	}
+	if err := v.waitForHeight(resp.Height + 1); err != nil {
+		return err
+	}
+	sh, err := v.Header(resp.Height + 1)
+	if err != nil {
+		return err
+	}
+
+	kp := merkle.KeyPath{}.AppendKey([]byte(name), merkle.KeyEncodingURL).AppendKey(key, merkle.KeyEncodingURL)
+	if len(resp.Value) == 0 {
+		err = ProofRuntime().VerifyAbsence(resp.Proof, sh.AppHash, kp.String())
+	} else {
+		err = ProofRuntime().VerifyValue(resp.Proof, sh.AppHash, kp.String(), resp.Value)
+	}
+	if err != nil {
+		return errcode.Unverified(pkgerrors.Wrapf(err, "prove %s", path))
+	}
+	return nil
+}
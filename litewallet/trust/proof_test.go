@@ -0,0 +1,108 @@
+package trust_test
+
+import (
+	"testing"
+
+	"github.com/QOSGroup/litewallet/litewallet/errcode"
+	"github.com/QOSGroup/litewallet/litewallet/rpctest"
+	"github.com/QOSGroup/litewallet/litewallet/trust"
+	abci "github.com/tendermint/tendermint/abci/types"
+	rpcclient "github.com/tendermint/tendermint/rpc/client"
+)
+
+func TestVerifyQuery(t *testing.T) {
+	app := rpctest.NewApp()
+	node := rpctest.NewNode("test-1", app)
+	defer node.Close()
+	app.Do(func() {
+		app.Store("acc").Set([]byte("alice"), []byte("100"))
+		app.Store("acc").Set([]byte("bob"), []byte("200"))
+		app.Store("stake").Set([]byte("validator"), []byte("carol"))
+	})
+	node.Commit()
+
+	client := rpcclient.NewHTTP(node.Remote(), "/websocket")
+	query := func(path, key string) abci.ResponseQuery {
+		res, err := client.ABCIQueryWithOptions(path, []byte(key), rpcclient.ABCIQueryOptions{Prove: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res.Response
+	}
+	//the chain id is taken from the headers
+	v := trust.NewVerifier("", "", client)
+
+	resp := query("/store/acc/key", "alice")
+	if err := v.VerifyQuery("/store/acc/key", []byte("alice"), resp); err != nil || string(resp.Value) != "100" {
+		t.Fatalf("got %q, %v, want a proved value", resp.Value, err)
+	}
+	if v.ChainID() != "test-1" {
+		t.Errorf("got chain id %s, want test-1", v.ChainID())
+	}
+	//the absence of a key is proved too
+	if err := v.VerifyQuery("store/acc/key", []byte("dave"), query("store/acc/key", "dave")); err != nil {
+		t.Errorf("got %v for a missing key, want it proved", err)
+	}
+
+	forged := resp
+	forged.Value = []byte("1000000")
+	if err := v.VerifyQuery("/store/acc/key", []byte("alice"), forged); errcode.Code(err) != errcode.CodeUnverified {
+		t.Errorf("got %v for a forged value, want an unverified error", err)
+	}
+	if err := v.VerifyQuery("/store/acc/key", []byte("bob"), resp); errcode.Code(err) != errcode.CodeUnverified {
+		t.Errorf("got %v for the proof of another key, want an unverified error", err)
+	}
+	if err := v.VerifyQuery("/store/stake/key", []byte("alice"), resp); errcode.Code(err) != errcode.CodeUnverified {
+		t.Errorf("got %v for the proof of another store, want an unverified error", err)
+	}
+	forged.Value, forged.Proof = nil, nil
+	if err := v.VerifyQuery("/store/acc/key", []byte("alice"), forged); errcode.Code(err) != errcode.CodeUnverified {
+		t.Errorf("got %v for no proof, want an unverified error", err)
+	}
+	//the answer of a node of another chain
+	other := rpctest.NewNode("test-1", rpctest.NewApp())
+	defer other.Close()
+	for other.Height() <= resp.Height {
+		other.Commit()
+	}
+	if err := trust.NewVerifier("", "test-1", rpcclient.NewHTTP(other.Remote(), "/websocket")).VerifyQuery("/store/acc/key", []byte("alice"), resp); errcode.Code(err) != errcode.CodeUnverified {
+		t.Errorf("got %v for a proof against a fork, want an unverified error", err)
+	}
+
+	//a subspace has no proof
+	if err := v.VerifyQuery("/store/acc/subspace", []byte("a"), query("/store/acc/subspace", "a")); err != nil {
+		t.Errorf("got %v for a subspace, want it left as it is", err)
+	}
+}
+
+func TestProvedHeight(t *testing.T) {
+	app := rpctest.NewApp()
+	node := rpctest.NewNode("proved-1", app)
+	defer node.Close()
+	app.Do(func() {
+		app.Store("acc").Set([]byte("alice"), []byte("100"))
+	})
+	node.Commit()
+
+	client := rpcclient.NewHTTP(node.Remote(), "/websocket")
+	v := trust.NewVerifier("", "proved-1", client)
+	height, err := v.ProvedHeight()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if height != node.Height()-1 {
+		t.Errorf("got height %d, want the one before the latest %d", height, node.Height())
+	}
+	//the query at the proved height is verified with the header the node already made
+	res, err := client.ABCIQueryWithOptions("/store/acc/key", []byte("alice"), rpcclient.ABCIQueryOptions{Height: height, Prove: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	latest := node.Height()
+	if err := v.VerifyQuery("/store/acc/key", []byte("alice"), res.Response); err != nil || string(res.Response.Value) != "100" {
+		t.Errorf("got %q, %v, want a proved value", res.Response.Value, err)
+	}
+	if node.Height() != latest {
+		t.Errorf("the node made block %d, want the query verified without a new block", node.Height())
+	}
+}
@@ -0,0 +1,171 @@
+//Package trust is the light client of the wallet. It verifies the headers of a Tendermint chain
+//from the validators of the last trusted header on, following the changes of the validator set,
+//so the answers of a node can be checked against the hashes of a verified header instead of
+//being taken on trust.
+//
+//The trusted headers are kept in a directory, or in memory when there is none. The first header
+//trusted on a chain is the header at height 1 of the first node asked, that node has to be honest.
+package trust
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/QOSGroup/litewallet/litewallet/errcode"
+	pkgerrors "github.com/pkg/errors"
+	dbm "github.com/tendermint/tendermint/libs/db"
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/lite"
+	lclient "github.com/tendermint/tendermint/lite/client"
+	lerr "github.com/tendermint/tendermint/lite/errors"
+	rpcclient "github.com/tendermint/tendermint/rpc/client"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	"github.com/tendermint/tendermint/types"
+)
+
+const (
+	cacheSize = 10
+	//maxFetches is how many times a header is fetched in a verification at most
+	maxFetches = 3
+)
+
+//the trusted headers by directory, the database of a directory can be opened only once
+var (
+	storesMtx sync.Mutex
+	stores    = make(map[string]lite.PersistentProvider)
+)
+
+//store returns the trusted headers of dir, shared by the chains
+func store(dir string) (lite.PersistentProvider, error) {
+	storesMtx.Lock()
+	defer storesMtx.Unlock()
+	if p, ok := stores[dir]; ok {
+		return p, nil
+	}
+	mem := lite.NewDBProvider("trusted.mem", dbm.NewMemDB()).SetLimit(cacheSize)
+	if dir == "" {
+		stores[dir] = mem
+		return mem, nil
+	}
+	db, err := dbm.NewGoLevelDB("trust-base", dir)
+	if err != nil {
+		return nil, errcode.Internal(pkgerrors.Wrap(err, "open the trusted headers"))
+	}
+	p := lite.NewMultiProvider(mem, lite.NewDBProvider("trusted.lvl", db))
+	stores[dir] = p
+	return p, nil
+}
+
+//Verifier verifies the headers of a chain fetched from a node, it implements lite.Verifier
+type Verifier struct {
+	dir     string
+	chainID string
+	client  rpcclient.Client
+}
+
+//NewVerifier returns a verifier of the headers of chainID from client, trusted in dir.
+//An empty chainID is taken from the first header fetched, as the header at height 1 is.
+func NewVerifier(dir, chainID string, client rpcclient.Client) *Verifier {
+	return &Verifier{dir: dir, chainID: chainID, client: client}
+}
+
+func (v *Verifier) ChainID() string {
+	return v.chainID
+}
+
+//Verify checks sh against the trusted headers and keeps it as trusted
+func (v *Verifier) Verify(sh types.SignedHeader) error {
+	trusted, err := store(v.dir)
+	if err != nil {
+		return err
+	}
+	source := &provider{Provider: lclient.NewProvider(v.chainID, v.client), fetched: make(map[int64]int)}
+	if _, err := trusted.LatestFullCommit(v.chainID, 1, 1<<63-1); lerr.IsErrCommitNotFound(err) {
+		fc, err := source.LatestFullCommit(v.chainID, 1, 1)
+		if err != nil {
+			return Error(pkgerrors.Wrap(err, "fetch the header at height 1"))
+		}
+		if err := fc.ValidateFull(v.chainID); err != nil {
+			return Error(err)
+		}
+		if err := trusted.SaveFullCommit(fc); err != nil {
+			return errcode.Internal(err)
+		}
+	} else if err != nil {
+		return errcode.Internal(err)
+	}
+
+	if sh.Header == nil || sh.Commit == nil {
+		return errcode.New(errcode.CodeUnverified, "no signed header")
+	}
+	if sh.ChainID != v.chainID {
+		return errcode.Errorf(errcode.CodeUnverified, "header of chain %s, want %s", sh.ChainID, v.chainID)
+	}
+	dv := lite.NewDynamicVerifier(v.chainID, trusted, source)
+	dv.SetLogger(log.NewNopLogger())
+	return Error(dv.Verify(sh))
+}
+
+//provider is the source of the headers of the node. The bisection of the lite package loops for ever
+//on a header signed by none of the validators of the trusted header right before it, as the headers
+//of a fork are, so a header fetched again and again fails instead.
+type provider struct {
+	lite.Provider
+	fetched map[int64]int
+}
+
+func (s *provider) LatestFullCommit(chainID string, minHeight, maxHeight int64) (lite.FullCommit, error) {
+	s.fetched[maxHeight]++
+	if s.fetched[maxHeight] > maxFetches {
+		return lite.FullCommit{}, fmt.Errorf("the header at height %d does not follow the trusted headers", maxHeight)
+	}
+	return s.Provider.LatestFullCommit(chainID, minHeight, maxHeight)
+}
+
+//Header returns the verified header at height
+func (v *Verifier) Header(height int64) (types.SignedHeader, error) {
+	res, err := v.client.Commit(&height)
+	if err != nil {
+		return types.SignedHeader{}, Error(err)
+	}
+	sh := res.SignedHeader
+	if sh.Header == nil || sh.Height != height {
+		return types.SignedHeader{}, errcode.Errorf(errcode.CodeUnverified, "got no header at height %d", height)
+	}
+	if v.chainID == "" {
+		v.chainID = sh.ChainID
+	}
+	if err := v.Verify(sh); err != nil {
+		return types.SignedHeader{}, err
+	}
+	return sh, nil
+}
+
+//VerifyTx checks that res is in the block of the verified header at its height and returns the header
+func (v *Verifier) VerifyTx(res *ctypes.ResultTx) (types.SignedHeader, error) {
+	if !bytes.Equal(res.Proof.Data, res.Tx) {
+		return types.SignedHeader{}, errcode.New(errcode.CodeUnverified, "the proof is not of the tx")
+	}
+	sh, err := v.Header(res.Height)
+	if err != nil {
+		return sh, err
+	}
+	if err := res.Proof.Validate(sh.DataHash); err != nil {
+		return types.SignedHeader{}, errcode.Unverified(err)
+	}
+	return sh, nil
+}
+
+//Error categorizes the error of a verification: the node could not be reached,
+//or its answer does not match the trusted headers
+func Error(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := pkgerrors.Cause(err).(net.Error); ok {
+		return errcode.Network(err)
+	}
+	return errcode.Unverified(err)
+}
@@ -0,0 +1,119 @@
+package trust_test
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/QOSGroup/litewallet/litewallet/errcode"
+	"github.com/QOSGroup/litewallet/litewallet/rpctest"
+	"github.com/QOSGroup/litewallet/litewallet/trust"
+	rpcclient "github.com/tendermint/tendermint/rpc/client"
+	"github.com/tendermint/tendermint/types"
+)
+
+func newVerifier(t *testing.T, node *rpctest.Node) (*trust.Verifier, string) {
+	dir, err := ioutil.TempDir("", "trust")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return trust.NewVerifier(dir, node.ChainID, rpcclient.NewHTTP(node.Remote(), "/websocket")), dir
+}
+
+func TestHeader(t *testing.T) {
+	node := rpctest.NewNode("test-1", rpctest.NewApp())
+	defer node.Close()
+	for i := 0; i < 3; i++ {
+		node.Commit()
+	}
+
+	v, dir := newVerifier(t, node)
+	defer os.RemoveAll(dir)
+	sh, err := v.Header(node.Height())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sh.Height != 4 || sh.ChainID != "test-1" {
+		t.Errorf("got header %d of %s, want 4 of test-1", sh.Height, sh.ChainID)
+	}
+
+	//a fork of the chain is signed by other validators
+	fork := rpctest.NewNode("test-1", rpctest.NewApp())
+	defer fork.Close()
+	fork.Commit()
+	forged := trust.NewVerifier(dir, fork.ChainID, rpcclient.NewHTTP(fork.Remote(), "/websocket"))
+	if _, err := forged.Header(2); errcode.Code(err) != errcode.CodeUnverified {
+		t.Errorf("got %v for a fork, want an unverified error", err)
+	}
+
+	//another chain id is not trusted from the headers of test-1
+	other := rpctest.NewNode("test-2", rpctest.NewApp())
+	defer other.Close()
+	other.Commit()
+	sh, err = trust.NewVerifier(dir, other.ChainID, rpcclient.NewHTTP(other.Remote(), "/websocket")).Header(2)
+	if err != nil || sh.ChainID != "test-2" {
+		t.Errorf("got %v, want the header of test-2", err)
+	}
+	if err := v.Verify(sh); errcode.Code(err) != errcode.CodeUnverified {
+		t.Errorf("got %v for a header of test-2, want an unverified error", err)
+	}
+}
+
+func TestValidatorChange(t *testing.T) {
+	node := rpctest.NewNode("test-1", rpctest.NewApp())
+	defer node.Close()
+	v, dir := newVerifier(t, node)
+	defer os.RemoveAll(dir)
+	if _, err := v.Header(1); err != nil {
+		t.Fatal(err)
+	}
+
+	//two full replacements of the validators, verified block by block
+	for i := 0; i < 2; i++ {
+		node.SetValidators(types.NewMockPV(), types.NewMockPV(), types.NewMockPV())
+		for j := 0; j < 3; j++ {
+			node.Commit()
+		}
+	}
+	if _, err := v.Header(node.Height()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVerifyTx(t *testing.T) {
+	node := rpctest.NewNode("test-1", rpctest.NewApp())
+	defer node.Close()
+	client := rpcclient.NewHTTP(node.Remote(), "/websocket")
+	res, err := client.BroadcastTxCommit(types.Tx("transfer"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v, dir := newVerifier(t, node)
+	defer os.RemoveAll(dir)
+	resTx, err := client.Tx(res.Hash, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sh, err := v.VerifyTx(resTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sh.Height != res.Height {
+		t.Errorf("got header %d, want %d", sh.Height, res.Height)
+	}
+
+	resTx.Tx = types.Tx("forged")
+	if _, err := v.VerifyTx(resTx); errcode.Code(err) != errcode.CodeUnverified {
+		t.Errorf("got %v for another tx, want an unverified error", err)
+	}
+	resTx.Proof.Data = resTx.Tx
+	if _, err := v.VerifyTx(resTx); errcode.Code(err) != errcode.CodeUnverified {
+		t.Errorf("got %v for a forged proof, want an unverified error", err)
+	}
+
+	node.Close()
+	if _, err := v.Header(res.Height + 1); errcode.Code(err) != errcode.CodeNetwork {
+		t.Errorf("got %v for a stopped node, want a network error", err)
+	}
+}
@@ -0,0 +1,47 @@
+package wallet
+
+import (
+	"github.com/QOSGroup/litewallet/litewallet/errcode"
+	"github.com/QOSGroup/litewallet/litewallet/slim/tendermint/crypto/funcInlocal/bip39local"
+	"github.com/QOSGroup/litewallet/litewallet/slim/tendermint/crypto/funcInlocal/slip39local"
+)
+
+//A wallet is backed up as SLIP-0039 shares of the entropy of its mnemonic: the master secret of the shares
+//is the entropy, so the shares give back the mnemonic and with it the keys of every chain. The language of
+//the mnemonic is not in the shares and is given again to restore it.
+
+//BackupShares returns the shares of mnemonic for each group, groupThreshold of the groups restore it.
+//The passphrase, which may be empty, is needed with the shares.
+func BackupShares(mnemonic, passphrase string, groupThreshold int, groups []slip39local.Group) ([][]string, error) {
+	entropy, _, err := bip39local.MnemonicToEntropy(mnemonic)
+	if err != nil {
+		return nil, errcode.Errorf(errcode.CodeInvalidInput, "Invalid mnemonic! %v", err)
+	}
+	shares, err := slip39local.Split(entropy, passphrase, groupThreshold, groups)
+	if err != nil {
+		return nil, errcode.InvalidInput(err)
+	}
+	return shares, nil
+}
+
+//MnemonicFromShares returns the mnemonic in language of the shares of BackupShares
+func MnemonicFromShares(shares []string, passphrase, language string) (string, error) {
+	entropy, err := slip39local.Combine(shares, passphrase)
+	if err != nil {
+		return "", errcode.InvalidInput(err)
+	}
+	mnemonic, err := bip39local.NewMnemonicIn(entropy, language)
+	if err != nil {
+		return "", errcode.InvalidInput(err)
+	}
+	return mnemonic, nil
+}
+
+//RestoreShares stores under name the wallet of the mnemonic in language of the shares, as Recover
+func RestoreShares(rootDir, name, password string, shares []string, passphrase, language string) (*Wallet, error) {
+	mnemonic, err := MnemonicFromShares(shares, passphrase, language)
+	if err != nil {
+		return nil, err
+	}
+	return Recover(rootDir, name, password, mnemonic)
+}
@@ -0,0 +1,103 @@
+package wallet
+
+import (
+	"github.com/QOSGroup/litewallet/litewallet/errcode"
+	"github.com/QOSGroup/litewallet/litewallet/eth"
+	"github.com/QOSGroup/litewallet/litewallet/sdksource"
+	"github.com/QOSGroup/litewallet/litewallet/slim"
+)
+
+//The keys are encrypted in the envelope of keycrypt. A key of another envelope, such as the bcrypt ones
+//stored before it, is encrypted again in the current one on its next unlock, MigrateKeys does it at once for
+//all the keys under rootDir whose password is known.
+
+//the statuses of the keys of MigrateKeys
+const (
+	MigrationUpgraded = "upgraded"
+	MigrationCurrent  = "current"
+	MigrationSkipped  = "skipped"
+	MigrationFailed   = "failed"
+)
+
+//KeyMigration is the outcome of the migration of a key, Code and Error are the ones of a failed migration
+type KeyMigration struct {
+	Chain  string           `json:"chain"`
+	Name   string           `json:"name"`
+	Status string           `json:"status"`
+	Code   errcode.CodeType `json:"code,omitempty"`
+	Error  string           `json:"error,omitempty"`
+}
+
+//MigrateKeys encrypts the Cosmos, ETH and QOS keys under rootDir again in the current envelope of keycrypt.
+//passwords are the passwords of the keys by name, as the keys of a wallet share their name and password,
+//the keys without a password are skipped. A key which cannot be upgraded, such as a key of a wrong
+//password, does not stop the others.
+func MigrateKeys(rootDir string, passwords map[string]string) ([]KeyMigration, error) {
+	stores := []struct {
+		chain   string
+		list    func(rootDir string) ([]string, error)
+		upgrade func(rootDir, name, password string) (bool, error)
+	}{
+		{ChainCosmos, cosmosKeyNames, sdksource.UpgradeKey},
+		{ChainETH, ethKeyNames, eth.UpgradeKey},
+		{ChainQOS, qosKeyNames, slim.UpgradeKey},
+	}
+	var out []KeyMigration
+	for _, store := range stores {
+		names, err := store.list(rootDir)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			m := KeyMigration{Chain: store.chain, Name: name, Status: MigrationSkipped}
+			if password, ok := passwords[name]; ok {
+				switch upgraded, err := store.upgrade(rootDir, name, password); {
+				case err != nil:
+					m.Status, m.Code, m.Error = MigrationFailed, errcode.Code(err), err.Error()
+				case upgraded:
+					m.Status = MigrationUpgraded
+				default:
+					m.Status = MigrationCurrent
+				}
+			}
+			out = append(out, m)
+		}
+	}
+	return out, nil
+}
+
+func cosmosKeyNames(rootDir string) ([]string, error) {
+	kos, err := sdksource.ListKeys(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(kos))
+	for i, ko := range kos {
+		names[i] = ko.Name
+	}
+	return names, nil
+}
+
+func ethKeyNames(rootDir string) ([]string, error) {
+	kos, err := eth.ListKeys(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(kos))
+	for i, ko := range kos {
+		names[i] = ko.Name
+	}
+	return names, nil
+}
+
+func qosKeyNames(rootDir string) ([]string, error) {
+	kos, err := slim.ListKeys(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(kos))
+	for i, ko := range kos {
+		names[i] = ko.Name
+	}
+	return names, nil
+}
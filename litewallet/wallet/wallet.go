@@ -0,0 +1,239 @@
+//Package wallet keeps the accounts of one mnemonic on every chain of litewallet together.
+//
+//A wallet is created or recovered once from its mnemonic. The first account of each chain is derived
+//from it and stored under the wallet name, encrypted with the wallet password, in the keystore the
+//functions of the chain use: the Cosmos keybase under rootDir, rootDir/ethkeys and rootDir/qoskeys.
+//So the keys of a wallet named alice sign as the key alice of CosmosTransfer, EthTransferETH or
+//QOSTransferSendByName. The wallet record under rootDir/wallets keeps the public part of the accounts.
+package wallet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/QOSGroup/litewallet/litewallet/errcode"
+	"github.com/QOSGroup/litewallet/litewallet/eth"
+	"github.com/QOSGroup/litewallet/litewallet/sdksource"
+	"github.com/QOSGroup/litewallet/litewallet/slim"
+	"github.com/QOSGroup/litewallet/litewallet/slim/tendermint/crypto/funcInlocal/bip39local"
+	dbm "github.com/tendermint/tendermint/libs/db"
+)
+
+//the chains of a wallet, the names WalletAddressCheck returns
+const (
+	ChainCosmos = "COSMOS"
+	ChainETH    = "ETH"
+	ChainQOS    = "QOS"
+
+	//CosmosHDPath is the path of the Cosmos key, the keybase derives the first account of a mnemonic
+	CosmosHDPath = "m/44'/118'/0'/0/0"
+
+	walletSuffix = "wallet"
+)
+
+//Chains are the chains every wallet has an account on, in the order of Wallet.Accounts
+var Chains = []string{ChainCosmos, ChainETH, ChainQOS}
+
+//Account is the account of a wallet on one chain
+type Account struct {
+	Chain   string `json:"chain"`
+	Address string `json:"address"`
+	PubKey  string `json:"pubkey"`
+	Path    string `json:"path"`
+	Denom   string `json:"denom"`
+}
+
+//Wallet is a mnemonic with its accounts, the mnemonic is only returned when the wallet is created
+type Wallet struct {
+	Name     string    `json:"name"`
+	Mnemonic string    `json:"mnemonic,omitempty"`
+	Accounts []Account `json:"accounts"`
+}
+
+//Account returns the account of the wallet on chain
+func (w *Wallet) Account(chain string) (Account, bool) {
+	for _, account := range w.Accounts {
+		if account.Chain == chain {
+			return account, true
+		}
+	}
+	return Account{}, false
+}
+
+//Create stores the wallet of mnemonic under name, a new English mnemonic is generated when it is empty.
+//The mnemonics of every bip39 language of bip39local are accepted.
+func Create(rootDir, name, password, mnemonic string) (*Wallet, error) {
+	if mnemonic == "" {
+		var err error
+		if mnemonic, err = sdksource.GenerateSeed(); err != nil {
+			return nil, errcode.Internal(err)
+		}
+	}
+	return Recover(rootDir, name, password, mnemonic)
+}
+
+//Recover stores the wallet of mnemonic under name. The name has to be free in the wallets and in the
+//keystore of every chain, and the keys already written are deleted when a later one fails, so no key
+//is left unless all of them are written.
+func Recover(rootDir, name, password, mnemonic string) (*Wallet, error) {
+	if name == "" {
+		return nil, errcode.New(errcode.CodeInvalidInput, "you have to specify a name for the wallet")
+	}
+	if password == "" {
+		return nil, errcode.New(errcode.CodeInvalidInput, "you have to specify a password for the wallet")
+	}
+	if mnemonic == "" {
+		return nil, errcode.New(errcode.CodeInvalidInput, "mnemonic is required")
+	}
+	if _, _, err := bip39local.MnemonicToEntropy(mnemonic); err != nil {
+		return nil, errcode.Errorf(errcode.CodeInvalidInput, "Invalid mnemonic! %v", err)
+	}
+
+	db, err := openWallets(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	if db.Has(walletKey(name)) {
+		return nil, errNameConflict(name, "wallet")
+	}
+	if err := checkFree(rootDir, name); err != nil {
+		return nil, err
+	}
+	//the ETH store holds an address under one name
+	ethAccounts, err := eth.DeriveAccounts(context.Background(), "", mnemonic, 0, 1)
+	if err != nil {
+		return nil, err
+	}
+	if owner, err := eth.AddressName(rootDir, ethAccounts[0].Address); err != nil {
+		return nil, err
+	} else if owner != "" {
+		return nil, errcode.Errorf(errcode.CodeInvalidInput, "the ETH account %s is already stored as %s", ethAccounts[0].Address, owner)
+	}
+
+	//the keys written are deleted when a later one fails
+	cosmos, err := sdksource.RestoreKey(rootDir, name, password, mnemonic)
+	if err != nil {
+		return nil, err
+	}
+	ethKey, err := eth.RestoreKey(rootDir, name, password, mnemonic)
+	if err != nil {
+		return nil, rollback(err, rootDir, name, password, sdksource.DeleteKey)
+	}
+	qos, err := slim.RestoreKey(rootDir, name, password, mnemonic)
+	if err != nil {
+		return nil, rollback(err, rootDir, name, password, eth.DeleteKey, sdksource.DeleteKey)
+	}
+	w := &Wallet{
+		Name: name,
+		Accounts: []Account{
+			{Chain: ChainCosmos, Address: cosmos.Address, PubKey: cosmos.PubKey, Path: CosmosHDPath, Denom: cosmos.Denom},
+			{Chain: ChainETH, Address: ethKey.Address, PubKey: ethKey.PubKey, Path: ethKey.Path, Denom: ethKey.Denom},
+			{Chain: ChainQOS, Address: qos.Address, PubKey: qos.PubKey, Path: qos.Path, Denom: qos.Denom},
+		},
+	}
+	bz, err := json.Marshal(w)
+	if err != nil {
+		return nil, errcode.Internal(err)
+	}
+	db.SetSync(walletKey(name), bz)
+	w.Mnemonic = mnemonic
+	return w, nil
+}
+
+//Get returns the wallet name with its accounts
+func Get(rootDir, name string) (*Wallet, error) {
+	db, err := openWallets(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	bz := db.Get(walletKey(name))
+	if len(bz) == 0 {
+		return nil, errcode.Errorf(errcode.CodeKeyNotFound, "Wallet %s not found", name)
+	}
+	var w Wallet
+	if err := json.Unmarshal(bz, &w); err != nil {
+		return nil, errcode.Internal(err)
+	}
+	return &w, nil
+}
+
+//List returns the wallets with their accounts in alphabetical order of name
+func List(rootDir string) ([]Wallet, error) {
+	db, err := openWallets(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	var ws []Wallet
+	iter := db.Iterator(nil, nil)
+	defer iter.Close()
+	for ; iter.Valid(); iter.Next() {
+		if !strings.HasSuffix(string(iter.Key()), "."+walletSuffix) {
+			continue
+		}
+		var w Wallet
+		if err := json.Unmarshal(iter.Value(), &w); err != nil {
+			return nil, errcode.Internal(err)
+		}
+		ws = append(ws, w)
+	}
+	return ws, nil
+}
+
+//rollback deletes the keys name written before err with the delete functions of their chains. When one of
+//them fails too, the error tells which keys are left.
+func rollback(err error, rootDir, name, password string, deletes ...func(rootDir, name, password string) error) error {
+	var left []string
+	for _, del := range deletes {
+		if derr := del(rootDir, name, password); derr != nil {
+			left = append(left, derr.Error())
+		}
+	}
+	if len(left) > 0 {
+		return errcode.Errorf(errcode.Code(err), "%v, and the keys %s written before are left: %s", err, name, strings.Join(left, "; "))
+	}
+	return err
+}
+
+//checkFree fails when a keystore already holds a key name
+func checkFree(rootDir, name string) error {
+	has := []struct {
+		chain string
+		fn    func(rootDir, name string) (bool, error)
+	}{
+		{ChainCosmos, sdksource.HasKey},
+		{ChainETH, eth.HasKey},
+		{ChainQOS, slim.HasKey},
+	}
+	for _, h := range has {
+		ok, err := h.fn(rootDir, name)
+		if err != nil {
+			return errcode.Internal(err)
+		}
+		if ok {
+			return errNameConflict(name, h.chain+" key")
+		}
+	}
+	return nil
+}
+
+func openWallets(rootDir string) (dbm.DB, error) {
+	db, err := dbm.NewGoLevelDB("wallets", filepath.Join(rootDir, "wallets"))
+	if err != nil {
+		return nil, errcode.Internal(err)
+	}
+	return db, nil
+}
+
+func walletKey(name string) []byte {
+	return []byte(fmt.Sprintf("%s.%s", name, walletSuffix))
+}
+
+func errNameConflict(name, what string) error {
+	return errcode.Errorf(errcode.CodeInvalidInput, "%s with name %s already exists", what, name)
+}
@@ -0,0 +1,254 @@
+package wallet
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/QOSGroup/litewallet/litewallet/errcode"
+	"github.com/QOSGroup/litewallet/litewallet/eth"
+	"github.com/QOSGroup/litewallet/litewallet/keycrypt"
+	"github.com/QOSGroup/litewallet/litewallet/sdksource"
+	"github.com/QOSGroup/litewallet/litewallet/slim"
+	"github.com/QOSGroup/litewallet/litewallet/slim/tendermint/crypto/funcInlocal/bip39local"
+	"github.com/QOSGroup/litewallet/litewallet/slim/tendermint/crypto/funcInlocal/slip39local"
+)
+
+const mnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+func TestWallet(t *testing.T) {
+	rootDir, err := ioutil.TempDir("", "wallets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(rootDir)
+
+	w, err := Recover(rootDir, "alice", "alicepass", mnemonic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.Mnemonic != mnemonic || len(w.Accounts) != len(Chains) {
+		t.Fatalf("got %+v, want the mnemonic and an account per chain", w)
+	}
+	//the accounts are the ones the chain functions derive
+	ethAccounts, _ := eth.DeriveAccounts(context.Background(), "", mnemonic, 0, 1)
+	qosAccounts, _ := slim.DeriveAccounts(mnemonic, 0, 1)
+	cosmos, _ := w.Account(ChainCosmos)
+	ethAccount, _ := w.Account(ChainETH)
+	qos, _ := w.Account(ChainQOS)
+	if ethAccount.Address != ethAccounts[0].Address || qos.Address != qosAccounts[0].Address || !strings.HasPrefix(cosmos.Address, "cosmos1") {
+		t.Errorf("got %+v, want the first accounts of the mnemonic", w.Accounts)
+	}
+	if _, err := eth.FetchtoSign(rootDir, "alice", "alicepass"); err != nil {
+		t.Errorf("got %v, want the ETH key alice", err)
+	}
+	if ok, _ := sdksource.HasKey(rootDir, "alice"); !ok {
+		t.Error("want the Cosmos key alice")
+	}
+
+	if _, err := Recover(rootDir, "alice", "alicepass", mnemonic); errcode.Code(err) != errcode.CodeInvalidInput {
+		t.Errorf("got %v for a name conflict, want an invalid input error", err)
+	}
+	//a key of a chain holding the name stops the wallet before any key is written
+	if _, err := slim.CreateKey(rootDir, "bob", "bobpass", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Create(rootDir, "bob", "bobpass", ""); errcode.Code(err) != errcode.CodeInvalidInput {
+		t.Errorf("got %v for a QOS key bob, want an invalid input error", err)
+	}
+	if ok, _ := sdksource.HasKey(rootDir, "bob"); ok {
+		t.Error("the Cosmos key bob should not be written")
+	}
+
+	carol, err := Create(rootDir, "carol", "carolpass", "")
+	if err != nil || carol.Mnemonic == "" {
+		t.Fatalf("got %+v, %v, want a new mnemonic", carol, err)
+	}
+	got, err := Get(rootDir, "alice")
+	if err != nil || got.Mnemonic != "" || got.Accounts[1] != ethAccount {
+		t.Fatalf("got %+v, %v, want alice without the mnemonic", got, err)
+	}
+	if _, err := Get(rootDir, "bob"); errcode.Code(err) != errcode.CodeKeyNotFound {
+		t.Errorf("got %v, want a key not found error", err)
+	}
+	ws, err := List(rootDir)
+	if err != nil || len(ws) != 2 || ws[0].Name != "alice" || ws[1].Accounts[2].Address != carol.Accounts[2].Address {
+		t.Fatalf("got %+v, %v, want alice and carol", ws, err)
+	}
+	if _, err := Recover(rootDir, "dave", "davepass", "monster soap"); errcode.Code(err) != errcode.CodeInvalidInput {
+		t.Errorf("got %v for an invalid mnemonic, want an invalid input error", err)
+	}
+}
+
+func TestRollback(t *testing.T) {
+	rootDir, err := ioutil.TempDir("", "wallets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(rootDir)
+
+	if _, err := sdksource.RestoreKey(rootDir, "alice", "alicepass", mnemonic); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := eth.RestoreKey(rootDir, "alice", "alicepass", mnemonic); err != nil {
+		t.Fatal(err)
+	}
+	failed := errcode.New(errcode.CodeInvalidInput, "the QOS key failed")
+	//the keys written before the failure are deleted
+	if err := rollback(failed, rootDir, "alice", "alicepass", eth.DeleteKey, sdksource.DeleteKey); err != failed {
+		t.Errorf("got %v, want the error of the failed key", err)
+	}
+	if ok, _ := sdksource.HasKey(rootDir, "alice"); ok {
+		t.Error("the Cosmos key alice should be deleted")
+	}
+	if ok, _ := eth.HasKey(rootDir, "alice"); ok {
+		t.Error("the ETH key alice should be deleted")
+	}
+	//a key which can not be deleted is told with the code of the failure
+	if _, err := sdksource.RestoreKey(rootDir, "bob", "bobpass", mnemonic); err != nil {
+		t.Fatal(err)
+	}
+	err = rollback(failed, rootDir, "bob", "wrong", sdksource.DeleteKey)
+	if errcode.Code(err) != errcode.CodeInvalidInput || !strings.Contains(err.Error(), "left") {
+		t.Errorf("got %v, want the keys left told", err)
+	}
+
+	//the QOS address stored under another name fails the wallet after its Cosmos and ETH keys
+	if _, err := slim.RestoreKey(rootDir, "qos", "qospass", mnemonic); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Recover(rootDir, "carol", "carolpass", mnemonic); errcode.Code(err) != errcode.CodeInvalidInput {
+		t.Errorf("got %v for the QOS address of qos, want an invalid input error", err)
+	}
+	if ok, _ := sdksource.HasKey(rootDir, "carol"); ok {
+		t.Error("the Cosmos key carol should be deleted")
+	}
+	if ok, _ := eth.HasKey(rootDir, "carol"); ok {
+		t.Error("the ETH key carol should be deleted")
+	}
+}
+
+func TestWalletLanguages(t *testing.T) {
+	rootDir, err := ioutil.TempDir("", "wallets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(rootDir)
+
+	//the same entropy in another language is another wallet
+	japanese, _ := bip39local.NewMnemonicIn(make([]byte, 16), bip39local.Japanese)
+	w, err := Recover(rootDir, "alice", "alicepass", japanese)
+	if err != nil {
+		t.Fatal(err)
+	}
+	english, _ := Recover(rootDir, "bob", "bobpass", mnemonic)
+	for i := range w.Accounts {
+		if w.Accounts[i].Address == english.Accounts[i].Address {
+			t.Errorf("got the English account %s on %s", w.Accounts[i].Address, w.Accounts[i].Chain)
+		}
+	}
+	ethAccounts, _ := eth.DeriveAccounts(context.Background(), "", japanese, 0, 1)
+	if ethAccount, _ := w.Account(ChainETH); ethAccount.Address != ethAccounts[0].Address {
+		t.Errorf("got %s, want %s", ethAccount.Address, ethAccounts[0].Address)
+	}
+	if err := sdksource.ChangePassword(rootDir, "alice", "alicepass", "newpass"); err != nil {
+		t.Errorf("got %v, want the Cosmos key alice", err)
+	}
+}
+
+func TestBackupShares(t *testing.T) {
+	rootDir, err := ioutil.TempDir("", "wallets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(rootDir)
+
+	alice, err := Recover(rootDir, "alice", "alicepass", mnemonic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shares, err := BackupShares(mnemonic, "", 1, []slip39local.Group{{MemberThreshold: 2, MemberCount: 3}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := MnemonicFromShares([]string{shares[0][2], shares[0][0]}, "", bip39local.English)
+	if err != nil || got != mnemonic {
+		t.Fatalf("got %q, %v, want the mnemonic", got, err)
+	}
+	//the keys of a mnemonic are stored once under rootDir
+	if _, err := RestoreShares(rootDir, "bob", "bobpass", shares[0][1:], "", bip39local.English); errcode.Code(err) != errcode.CodeInvalidInput {
+		t.Errorf("got %v for the keys of alice, want an invalid input error", err)
+	}
+	if ok, _ := sdksource.HasKey(rootDir, "bob"); ok {
+		t.Error("the Cosmos key bob should not be written")
+	}
+	restored, err := RestoreShares(filepath.Join(rootDir, "restored"), "bob", "bobpass", shares[0][1:], "", bip39local.English)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range restored.Accounts {
+		if restored.Accounts[i].Address != alice.Accounts[i].Address {
+			t.Errorf("got %+v, want the accounts of alice", restored.Accounts[i])
+		}
+	}
+	if _, err := RestoreShares(rootDir, "carol", "carolpass", shares[0][:1], "", bip39local.English); errcode.Code(err) != errcode.CodeInvalidInput {
+		t.Errorf("got %v for a single share, want an invalid input error", err)
+	}
+	if _, err := BackupShares("monster soap", "", 1, []slip39local.Group{{MemberThreshold: 1, MemberCount: 1}}); errcode.Code(err) != errcode.CodeInvalidInput {
+		t.Errorf("got %v for an invalid mnemonic, want an invalid input error", err)
+	}
+}
+
+func TestMigrateKeys(t *testing.T) {
+	rootDir, err := ioutil.TempDir("", "wallets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(rootDir)
+	defer keycrypt.SetCurrent(keycrypt.Current())
+	if err := keycrypt.SetCurrent(keycrypt.Params{KDF: keycrypt.KDFScrypt, N: 1 << 10, R: 8, P: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Recover(rootDir, "alice", "alicepass", mnemonic); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := slim.CreateKey(rootDir, "bob", "bobpass", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := slim.CreateKey(rootDir, "carol", "carolpass", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := keycrypt.SetCurrent(keycrypt.Params{KDF: keycrypt.KDFArgon2id, Time: 1, Memory: 64, Threads: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	ms, err := MigrateKeys(rootDir, map[string]string{"alice": "alicepass", "bob": "wrong"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []KeyMigration{
+		{Chain: ChainCosmos, Name: "alice", Status: MigrationUpgraded},
+		{Chain: ChainETH, Name: "alice", Status: MigrationUpgraded},
+		{Chain: ChainQOS, Name: "alice", Status: MigrationUpgraded},
+		{Chain: ChainQOS, Name: "bob", Status: MigrationFailed, Code: errcode.CodeWrongPassword},
+		{Chain: ChainQOS, Name: "carol", Status: MigrationSkipped},
+	}
+	if len(ms) != len(want) {
+		t.Fatalf("got %+v, want %+v", ms, want)
+	}
+	for i := range want {
+		if ms[i].Chain != want[i].Chain || ms[i].Name != want[i].Name || ms[i].Status != want[i].Status || ms[i].Code != want[i].Code {
+			t.Errorf("got %+v, want %+v", ms[i], want[i])
+		}
+	}
+	ms, err = MigrateKeys(rootDir, map[string]string{"alice": "alicepass"})
+	if err != nil || ms[0].Status != MigrationCurrent || ms[1].Status != MigrationCurrent || ms[2].Status != MigrationCurrent {
+		t.Errorf("got %+v, %v, want the keys of alice current", ms, err)
+	}
+	if _, err := eth.FetchtoSign(rootDir, "alice", "alicepass"); err != nil {
+		t.Errorf("got %v, want the upgraded ETH key alice", err)
+	}
+}